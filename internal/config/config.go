@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"runtime"
 	"time"
+
+	"crmterm/internal/dateparse"
 )
 
 // Store manages the runtime configuration for the CRM.
@@ -18,10 +20,36 @@ type Store struct {
 
 // Data represents persisted user preferences.
 type Data struct {
-	Name     string `json:"name"`
-	Timezone string `json:"timezone"`
+	Name       string   `json:"name"`
+	Timezone   string   `json:"timezone"`
+	DealStages []string `json:"deal_stages,omitempty"`
+	// AccountStatuses is the ordered account lifecycle, e.g. lead to customer.
+	AccountStatuses []string `json:"account_statuses,omitempty"`
+	// TrashRetentionDays is how long deleted items stay restorable. Zero
+	// means the default; a negative value keeps them until purged by hand.
+	TrashRetentionDays int `json:"trash_retention_days,omitempty"`
+	// PhoneCountry is the ISO 3166 country code phone numbers without a
+	// country code are read in, e.g. "GB".
+	PhoneCountry string `json:"phone_country,omitempty"`
+	// DateOrder is how numeric dates such as 03/04/2026 are read: "dmy",
+	// "mdy", or blank to guess from the timezone.
+	DateOrder string `json:"date_order,omitempty"`
 }
 
+// DefaultDealStages is the pipeline used until the user configures their own.
+var DefaultDealStages = []string{"Lead", "Qualified", "Proposal", "Negotiation", "Won", "Lost"}
+
+// DefaultAccountStatuses is the account lifecycle used until the user
+// configures their own.
+var DefaultAccountStatuses = []string{"Lead", "Prospect", "Customer", "Churned"}
+
+// DefaultTrashRetentionDays is how long the trash keeps items unless configured.
+const DefaultTrashRetentionDays = 30
+
+// DefaultPhoneCountry is the country phone numbers are read in unless
+// configured.
+const DefaultPhoneCountry = "US"
+
 // Load retrieves the config from disk, creating defaults if needed.
 func Load() (*Store, error) {
 	cfgPath, err := resolvePath()
@@ -128,3 +156,54 @@ func (s *Store) Location() *time.Location {
 	}
 	return time.UTC
 }
+
+// DealStages returns the configured pipeline stages in order.
+func (s *Store) DealStages() []string {
+	if s == nil || len(s.Config.DealStages) == 0 {
+		return append([]string(nil), DefaultDealStages...)
+	}
+	return append([]string(nil), s.Config.DealStages...)
+}
+
+// AccountStatuses returns the configured account statuses in lifecycle order.
+func (s *Store) AccountStatuses() []string {
+	if s == nil || len(s.Config.AccountStatuses) == 0 {
+		return append([]string(nil), DefaultAccountStatuses...)
+	}
+	return append([]string(nil), s.Config.AccountStatuses...)
+}
+
+// TrashRetention returns how long deleted items are kept before they are
+// purged automatically. It returns zero when automatic purging is disabled.
+func (s *Store) TrashRetention() time.Duration {
+	days := DefaultTrashRetentionDays
+	if s != nil && s.Config.TrashRetentionDays != 0 {
+		days = s.Config.TrashRetentionDays
+	}
+	if days < 0 {
+		return 0
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// PhoneCountry returns the country code phone numbers without a country
+// code are read in.
+func (s *Store) PhoneCountry() string {
+	if s == nil || s.Config.PhoneCountry == "" {
+		return DefaultPhoneCountry
+	}
+	return s.Config.PhoneCountry
+}
+
+// DateOrder returns how numeric dates are read, guessed from the timezone
+// unless configured.
+func (s *Store) DateOrder() dateparse.Order {
+	if s == nil {
+		return dateparse.OrderAuto
+	}
+	order, err := dateparse.OrderNamed(s.Config.DateOrder)
+	if err != nil {
+		return dateparse.OrderAuto
+	}
+	return order
+}
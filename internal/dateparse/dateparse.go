@@ -0,0 +1,539 @@
+// Package dateparse reads the dates and times people type into prompts:
+// ISO timestamps, locale dates such as "17/10/2026" or "Oct 17", and
+// relative phrases such as "tomorrow 3pm", "next tue 10:30", "in 2 weeks",
+// "fri" and "+3d". Everything is resolved against a reference time whose
+// location decides the time zone, and numeric dates are read in an Order.
+package dateparse
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrEmpty is returned for blank input so prompts can apply their own
+// default.
+var ErrEmpty = errors.New("no date given")
+
+// Result is a resolved date. Time is midnight when the input named only a
+// day.
+type Result struct {
+	Time    time.Time
+	HasTime bool
+}
+
+// At returns the result's time, or the day at hour:minute when the input
+// did not say when in the day.
+func (r Result) At(hour, minute int) time.Time {
+	if r.HasTime {
+		return r.Time
+	}
+	return time.Date(r.Time.Year(), r.Time.Month(), r.Time.Day(), hour, minute, 0, 0, r.Time.Location())
+}
+
+// String renders the result the way previews show it, e.g.
+// "Fri Oct 17 2026 15:00".
+func (r Result) String() string {
+	if r.HasTime {
+		return r.Time.Format("Mon Jan 02 2006 15:04")
+	}
+	return r.Time.Format("Mon Jan 02 2006")
+}
+
+// Order says which comes first in numeric dates such as "03/04/2026".
+type Order int
+
+const (
+	// OrderAuto reads day first unless the reference time's location is
+	// in the Americas, where month first is common.
+	OrderAuto Order = iota
+	OrderDayFirst
+	OrderMonthFirst
+)
+
+// orderNames are the spellings OrderNamed accepts; the first of each is
+// what String returns.
+var orderNames = map[Order][]string{
+	OrderAuto:       {"auto", ""},
+	OrderDayFirst:   {"dmy", "day", "day first", "dd/mm"},
+	OrderMonthFirst: {"mdy", "month", "month first", "mm/dd"},
+}
+
+// OrderNamed reads an order as written in settings: "dmy", "mdy" or
+// "auto".
+func OrderNamed(name string) (Order, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for order, names := range orderNames {
+		for _, n := range names {
+			if name == n {
+				return order, nil
+			}
+		}
+	}
+	return OrderAuto, fmt.Errorf("date order must be dmy, mdy or auto")
+}
+
+// String returns the order's settings name.
+func (o Order) String() string {
+	if names, ok := orderNames[o]; ok {
+		return names[0]
+	}
+	return "auto"
+}
+
+// dayFirst reports whether numeric dates are read day first at loc.
+func (o Order) dayFirst(loc *time.Location) bool {
+	switch o {
+	case OrderDayFirst:
+		return true
+	case OrderMonthFirst:
+		return false
+	}
+	name := loc.String()
+	if strings.HasPrefix(name, "US/") || monthFirstZones[name] {
+		return false
+	}
+	for _, prefix := range []string{"America/Indiana/", "America/Kentucky/", "America/North_Dakota/"} {
+		if strings.HasPrefix(name, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// monthFirstZones are the United States zones, where 03/04 is March 4.
+// The rest of the Americas, like most of the world, writes the day first.
+var monthFirstZones = map[string]bool{
+	"America/New_York": true, "America/Detroit": true, "America/Chicago": true,
+	"America/Menominee": true, "America/Denver": true, "America/Boise": true,
+	"America/Phoenix": true, "America/Los_Angeles": true, "America/Anchorage": true,
+	"America/Juneau": true, "America/Sitka": true, "America/Metlakatla": true,
+	"America/Yakutat": true, "America/Nome": true, "America/Adak": true,
+	"Pacific/Honolulu": true, "EST5EDT": true, "CST6CDT": true, "MST7MDT": true, "PST8PDT": true,
+}
+
+// layouts are tried against the whole input before it is read word by word.
+var layouts = []struct {
+	layout  string
+	hasTime bool
+}{
+	{time.RFC3339, true},
+	{"2006-01-02T15:04", true},
+	{"2006-01-02 15:04:05", true},
+	{"2006-01-02 15:04", true},
+	{"2006-01-02", false},
+	{"2006/01/02 15:04", true},
+	{"2006/01/02", false},
+}
+
+var months = map[string]time.Month{}
+
+var weekdays = map[string]time.Weekday{}
+
+func init() {
+	for m := time.January; m <= time.December; m++ {
+		name := strings.ToLower(m.String())
+		months[name] = m
+		months[name[:3]] = m
+	}
+	months["sept"] = time.September
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		weekdays[name] = d
+		weekdays[name[:3]] = d
+		weekdays[name+"s"] = d
+	}
+	weekdays["tues"] = time.Tuesday
+	weekdays["wednes"] = time.Wednesday
+	weekdays["thur"] = time.Thursday
+	weekdays["thurs"] = time.Thursday
+}
+
+// Parse resolves value relative to now, reading numeric dates in
+// OrderAuto.
+func Parse(value string, now time.Time) (Result, error) {
+	return ParseWithOrder(value, now, OrderAuto)
+}
+
+// ParseWithOrder resolves value relative to now. A plain weekday means its
+// next occurrence, today included; "next" skips today. Numeric dates such
+// as "03/04/2026" are read in order, and always by whichever reading is
+// possible when only one is. Numbers split by a single dot are a time
+// ("10.30"); dotted dates need their year ("17.10.2026").
+func ParseWithOrder(value string, now time.Time, order Order) (Result, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Result{}, ErrEmpty
+	}
+	loc := now.Location()
+	for _, l := range layouts {
+		if t, err := time.ParseInLocation(l.layout, strings.ToUpper(value), loc); err == nil {
+			return Result{Time: t.In(loc), HasTime: l.hasTime}, nil
+		}
+	}
+	text := strings.ToLower(strings.ReplaceAll(value, ",", " "))
+	p := parser{now: now, order: order, words: strings.Fields(text)}
+	return p.parse()
+}
+
+type parser struct {
+	now   time.Time
+	order Order
+	words []string
+	pos   int
+
+	date    time.Time
+	hasDate bool
+	hour    int
+	minute  int
+	hasTime bool
+	// years, months and days move the date and clock moves the time by
+	// relative offsets, applied last so "+2d fri" is "fri +2d".
+	years, months, days int
+	clock               time.Duration
+}
+
+func (p *parser) peek(offset int) string {
+	if p.pos+offset < len(p.words) {
+		return p.words[p.pos+offset]
+	}
+	return ""
+}
+
+func (p *parser) today() time.Time {
+	return time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.now.Location())
+}
+
+func (p *parser) setDate(t time.Time) error {
+	if p.hasDate {
+		return fmt.Errorf("more than one date in %q", strings.Join(p.words, " "))
+	}
+	p.date, p.hasDate = t, true
+	return nil
+}
+
+func (p *parser) setTime(hour, minute int) error {
+	if p.hasTime {
+		return fmt.Errorf("more than one time in %q", strings.Join(p.words, " "))
+	}
+	if hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return fmt.Errorf("%02d:%02d is not a time of day", hour, minute)
+	}
+	p.hour, p.minute, p.hasTime = hour, minute, true
+	return nil
+}
+
+func (p *parser) parse() (Result, error) {
+	for p.pos < len(p.words) {
+		if err := p.word(); err != nil {
+			return Result{}, err
+		}
+	}
+	loc := p.now.Location()
+	date := p.today()
+	if p.hasDate {
+		date = p.date
+	}
+	date = date.AddDate(p.years, p.months, p.days)
+	result := Result{Time: date}
+	if p.hasTime {
+		result = Result{Time: time.Date(date.Year(), date.Month(), date.Day(), p.hour, p.minute, 0, 0, loc), HasTime: true}
+	}
+	if p.clock != 0 {
+		if !p.hasTime {
+			result.Time = time.Date(date.Year(), date.Month(), date.Day(), p.now.Hour(), p.now.Minute(), 0, 0, loc)
+		}
+		result.Time = result.Time.Add(p.clock)
+		result.HasTime = true
+	}
+	return result, nil
+}
+
+// word consumes the next phrase.
+func (p *parser) word() error {
+	w := p.peek(0)
+	p.pos++
+	switch w {
+	case "at", "on", "this", "the", "by":
+		return nil
+	case "now":
+		if err := p.setDate(p.today()); err != nil {
+			return err
+		}
+		return p.setTime(p.now.Hour(), p.now.Minute())
+	case "today", "tonight":
+		return p.setDate(p.today())
+	case "tomorrow", "tmr", "tmrw", "tom":
+		return p.setDate(p.today().AddDate(0, 0, 1))
+	case "yesterday":
+		return p.setDate(p.today().AddDate(0, 0, -1))
+	case "noon", "midday":
+		return p.setTime(12, 0)
+	case "midnight":
+		return p.setTime(0, 0)
+	case "next", "last":
+		return p.relativeWord(w == "next")
+	case "in":
+		n, err := strconv.Atoi(p.peek(0))
+		if err != nil {
+			if p.peek(0) != "a" && p.peek(0) != "an" {
+				return fmt.Errorf("expected a number after 'in'")
+			}
+			n = 1
+		}
+		p.pos++
+		return p.offset(n, p.take())
+	}
+	if day, ok := weekdays[w]; ok {
+		return p.setDate(p.weekday(day, false))
+	}
+	if month, ok := months[w]; ok {
+		return p.monthDay(month)
+	}
+	if len(w) > 1 && (w[0] == '+' || w[0] == '-') {
+		n, unit := splitNumber(w[1:])
+		count, err := strconv.Atoi(n)
+		if err != nil {
+			return fmt.Errorf("offsets look like +3d or -2w")
+		}
+		if w[0] == '-' {
+			count = -count
+		}
+		if unit == "" {
+			unit = p.take()
+		}
+		return p.offset(count, unit)
+	}
+	if ok, err := p.clockWord(w); ok {
+		return err
+	}
+	if ok, err := p.numericDate(w); ok {
+		return err
+	}
+	if n, err := strconv.Atoi(w); err == nil {
+		if _, ok := months[p.peek(0)]; ok {
+			// "17 oct" or "17 october 2026"
+			month := months[p.take()]
+			return p.setDayOfMonth(n, month, p.year())
+		}
+		if unit := p.peek(0); isUnit(unit) && p.peek(1) == "ago" {
+			p.pos += 2
+			return p.offset(-n, unit)
+		}
+		if p.pos >= 2 && p.words[p.pos-2] == "at" {
+			return p.setTime(n, 0)
+		}
+	}
+	return fmt.Errorf("don't understand %q", w)
+}
+
+// take consumes and returns the next word.
+func (p *parser) take() string {
+	w := p.peek(0)
+	p.pos++
+	return w
+}
+
+// relativeWord reads what follows "next" or "last".
+func (p *parser) relativeWord(next bool) error {
+	w := p.take()
+	if day, ok := weekdays[w]; ok {
+		if next {
+			return p.setDate(p.weekday(day, true))
+		}
+		today := p.today()
+		behind := (int(today.Weekday()) - int(day) + 7) % 7
+		if behind == 0 {
+			behind = 7
+		}
+		return p.setDate(today.AddDate(0, 0, -behind))
+	}
+	if isUnit(w) {
+		if next {
+			return p.offset(1, w)
+		}
+		return p.offset(-1, w)
+	}
+	return fmt.Errorf("expected a weekday, week, month or year after 'next' or 'last'")
+}
+
+// weekday returns the next date falling on day. With strict today does not
+// count.
+func (p *parser) weekday(day time.Weekday, strict bool) time.Time {
+	today := p.today()
+	ahead := (int(day) - int(today.Weekday()) + 7) % 7
+	if ahead == 0 && strict {
+		ahead = 7
+	}
+	return today.AddDate(0, 0, ahead)
+}
+
+// offset moves the date, or the clock for hours and minutes, by n units
+// once everything else is read.
+func (p *parser) offset(n int, unit string) error {
+	switch strings.TrimSuffix(unit, "s") {
+	case "m", "min", "minute":
+		p.clock += time.Duration(n) * time.Minute
+		return nil
+	case "h", "hr", "hour":
+		p.clock += time.Duration(n) * time.Hour
+		return nil
+	}
+	switch strings.TrimSuffix(unit, "s") {
+	case "d", "day":
+		p.days += n
+		return nil
+	case "w", "wk", "week":
+		p.days += 7 * n
+		return nil
+	case "mo", "mon", "month":
+		p.months += n
+		return nil
+	case "y", "yr", "year":
+		p.years += n
+		return nil
+	}
+	return fmt.Errorf("unknown unit %q; use min, h, d, w, mo or y", unit)
+}
+
+func isUnit(w string) bool {
+	switch strings.TrimSuffix(w, "s") {
+	case "m", "min", "minute", "h", "hr", "hour", "d", "day", "w", "wk", "week", "mo", "mon", "month", "y", "yr", "year":
+		return true
+	}
+	return false
+}
+
+// splitNumber splits "3d" into "3" and "d".
+func splitNumber(w string) (string, string) {
+	i := 0
+	for i < len(w) && w[i] >= '0' && w[i] <= '9' {
+		i++
+	}
+	return w[:i], w[i:]
+}
+
+// clockWord reads "15:04", "10.30", "3pm", "3:30pm" and "3 pm".
+func (p *parser) clockWord(w string) (bool, error) {
+	suffix := ""
+	for _, s := range []string{"am", "pm", "a", "p"} {
+		if strings.HasSuffix(w, s) {
+			suffix = s[:1]
+			w = strings.TrimSuffix(w, s)
+			break
+		}
+	}
+	if suffix == "" {
+		switch p.peek(0) {
+		case "am", "pm":
+			if _, err := strconv.Atoi(strings.Replace(w, ":", "", 1)); err == nil {
+				suffix = p.take()[:1]
+			}
+		}
+	}
+	sep := ":"
+	if strings.Count(w, ".") == 1 && !strings.Contains(w, ":") {
+		sep = "."
+	}
+	hourText, minuteText, hasMinutes := strings.Cut(w, sep)
+	if !hasMinutes && suffix == "" {
+		return false, nil
+	}
+	hour, err := strconv.Atoi(hourText)
+	if err != nil || hourText == "" {
+		return false, nil
+	}
+	minute := 0
+	if hasMinutes {
+		if len(minuteText) != 2 {
+			return true, fmt.Errorf("times look like 15:30 or 3:30pm")
+		}
+		if minute, err = strconv.Atoi(minuteText); err != nil {
+			return true, fmt.Errorf("times look like 15:30 or 3:30pm")
+		}
+	}
+	if suffix != "" {
+		if hour < 1 || hour > 12 {
+			return true, fmt.Errorf("%d%sm is not a time of day", hour, suffix)
+		}
+		hour %= 12
+		if suffix == "p" {
+			hour += 12
+		}
+	}
+	return true, p.setTime(hour, minute)
+}
+
+// numericDate reads "17/10/2026", "10/17" and "17.10.2026" style dates.
+// "17.10" never gets here; clockWord reads it as a time.
+func (p *parser) numericDate(w string) (bool, error) {
+	parts := strings.FieldsFunc(w, func(r rune) bool { return r == '/' || r == '-' || r == '.' })
+	if len(parts) < 2 || len(parts) > 3 {
+		return false, nil
+	}
+	nums := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return false, nil
+		}
+		nums[i] = n
+	}
+	if len(parts[0]) == 4 {
+		if len(nums) != 3 {
+			return true, fmt.Errorf("dates look like 2026-10-17")
+		}
+		return true, p.setDayOfMonth(nums[2], time.Month(nums[1]), nums[0])
+	}
+	year := p.now.Year()
+	if len(nums) == 3 {
+		year = nums[2]
+		if year < 100 {
+			year += 2000
+		}
+	}
+	day, month := nums[0], nums[1]
+	if !p.order.dayFirst(p.now.Location()) {
+		day, month = month, day
+	}
+	if month > 12 && day <= 12 {
+		day, month = month, day
+	}
+	return true, p.setDayOfMonth(day, time.Month(month), year)
+}
+
+// monthDay reads what follows a month name: "oct 17", "oct 17 2026".
+func (p *parser) monthDay(month time.Month) error {
+	day, err := strconv.Atoi(strings.TrimRight(p.peek(0), "stndrh"))
+	if err != nil || day > 31 {
+		// "oct" or "oct 2026" mean the first of the month.
+		return p.setDayOfMonth(1, month, p.year())
+	}
+	p.pos++
+	return p.setDayOfMonth(day, month, p.year())
+}
+
+// year consumes a four digit year if one comes next, or returns the
+// current year.
+func (p *parser) year() int {
+	if w := p.peek(0); len(w) == 4 {
+		if y, err := strconv.Atoi(w); err == nil {
+			p.pos++
+			return y
+		}
+	}
+	return p.now.Year()
+}
+
+func (p *parser) setDayOfMonth(day int, month time.Month, year int) error {
+	if month < time.January || month > time.December {
+		return fmt.Errorf("there is no month %d", month)
+	}
+	t := time.Date(year, month, day, 0, 0, 0, 0, p.now.Location())
+	if t.Day() != day {
+		return fmt.Errorf("%s has no day %d", month, day)
+	}
+	return p.setDate(t)
+}
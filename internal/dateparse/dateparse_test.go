@@ -0,0 +1,171 @@
+package dateparse
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func loadZone(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("no zoneinfo: %v", err)
+	}
+	return loc
+}
+
+// TestParseWithOrder resolves input against Friday 2026-10-16 14:20. want
+// is "2006-01-02" for a bare day and "2006-01-02 15:04" with a time.
+func TestParseWithOrder(t *testing.T) {
+	london := loadZone(t, "Europe/London")
+	newYork := loadZone(t, "America/New_York")
+	chicago := loadZone(t, "America/Chicago")
+	indianapolis := loadZone(t, "America/Indiana/Indianapolis")
+	honolulu := loadZone(t, "Pacific/Honolulu")
+	usPacific := loadZone(t, "US/Pacific")
+	saoPaulo := loadZone(t, "America/Sao_Paulo")
+	mexicoCity := loadZone(t, "America/Mexico_City")
+	bogota := loadZone(t, "America/Bogota")
+	buenosAires := loadZone(t, "America/Argentina/Buenos_Aires")
+	tests := []struct {
+		in      string
+		loc     *time.Location
+		order   Order
+		want    string
+		wantErr bool
+	}{
+		// The forms dateHint lists.
+		{in: "tomorrow 3pm", want: "2026-10-17 15:00"},
+		{in: "next tue 10:30", want: "2026-10-20 10:30"},
+		{in: "in 2 weeks", want: "2026-10-30"},
+		{in: "fri", want: "2026-10-16"},
+		{in: "+3d", want: "2026-10-19"},
+		{in: "2026-10-20", want: "2026-10-20"},
+
+		{in: "next fri", want: "2026-10-23"},
+		{in: "last mon", want: "2026-10-12"},
+		{in: "yesterday 9am", want: "2026-10-15 09:00"},
+		{in: "3 days ago", want: "2026-10-13"},
+		{in: "in an hour", want: "2026-10-16 15:20"},
+		{in: "now", want: "2026-10-16 14:20"},
+		{in: "+2h", want: "2026-10-16 16:20"},
+		{in: "noon", want: "2026-10-16 12:00"},
+		{in: "at 5", want: "2026-10-16 05:00"},
+		{in: "3 pm", want: "2026-10-16 15:00"},
+		{in: "oct 17", want: "2026-10-17"},
+		{in: "Oct 17, 2027", want: "2027-10-17"},
+		{in: "17 october 2027", want: "2027-10-17"},
+		{in: "2026-10-20 09:15", want: "2026-10-20 09:15"},
+		{in: "2026/10/20", want: "2026-10-20"},
+		{in: "2026-10-20T09:15:00Z", want: "2026-10-20 10:15"},
+
+		// Offsets apply to the date however they are ordered.
+		{in: "fri +2d", want: "2026-10-18"},
+		{in: "+2d fri", want: "2026-10-18"},
+		{in: "tomorrow +1w", want: "2026-10-24"},
+		{in: "+1d +1d", want: "2026-10-18"},
+
+		// A single dot splits hours from minutes.
+		{in: "10.30", want: "2026-10-16 10:30"},
+		{in: "tomorrow 9.15", want: "2026-10-17 09:15"},
+		{in: "3.30pm", want: "2026-10-16 15:30"},
+		{in: "17.10.2026", want: "2026-10-17"},
+
+		// Numeric dates follow the order, or the zone when it is auto.
+		{in: "03/04/2026", loc: london, want: "2026-04-03"},
+		{in: "03/04/2026", loc: newYork, want: "2026-03-04"},
+		{in: "03/04/2026", loc: newYork, order: OrderDayFirst, want: "2026-04-03"},
+		{in: "03/04/2026", loc: london, order: OrderMonthFirst, want: "2026-03-04"},
+		{in: "04/05/26", want: "2026-05-04"},
+		{in: "10/17", loc: london, want: "2026-10-17"},
+		{in: "17/10", loc: newYork, want: "2026-10-17"},
+		{in: "17/10/2026", order: OrderMonthFirst, want: "2026-10-17"},
+		{in: "03/04/2026", loc: saoPaulo, want: "2026-04-03"},
+		{in: "03/04/2026", loc: mexicoCity, want: "2026-04-03"},
+		{in: "03/04/2026", loc: bogota, want: "2026-04-03"},
+		{in: "03/04/2026", loc: buenosAires, want: "2026-04-03"},
+		{in: "03/04/2026", loc: chicago, want: "2026-03-04"},
+		{in: "03/04/2026", loc: indianapolis, want: "2026-03-04"},
+		{in: "03/04/2026", loc: honolulu, want: "2026-03-04"},
+		{in: "03/04/2026", loc: usPacific, want: "2026-03-04"},
+
+		{in: "someday", wantErr: true},
+		{in: "2026-02-30", wantErr: true},
+		{in: "31/02/2026", wantErr: true},
+		{in: "13/13/2026", wantErr: true},
+		{in: "25:00", wantErr: true},
+		{in: "13pm", wantErr: true},
+		{in: "10.3", wantErr: true},
+		{in: "31.12", wantErr: true},
+		{in: "tomorrow yesterday", wantErr: true},
+		{in: "3pm 4pm", wantErr: true},
+		{in: "in x days", wantErr: true},
+		{in: "+3x", wantErr: true},
+		{in: "next blue", wantErr: true},
+	}
+	for _, tt := range tests {
+		loc := tt.loc
+		if loc == nil {
+			loc = london
+		}
+		now := time.Date(2026, 10, 16, 14, 20, 0, 0, loc)
+		got, err := ParseWithOrder(tt.in, now, tt.order)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%q in %s = %s, want an error", tt.in, loc, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q in %s: %v", tt.in, loc, err)
+			continue
+		}
+		layout := "2006-01-02"
+		if got.HasTime {
+			layout = "2006-01-02 15:04"
+		}
+		if s := got.Time.Format(layout); s != tt.want {
+			t.Errorf("%q in %s = %s, want %s", tt.in, loc, s, tt.want)
+		}
+		if got.Time.Location() != loc {
+			t.Errorf("%q in %s: result in %s", tt.in, loc, got.Time.Location())
+		}
+	}
+}
+
+func TestParseEmpty(t *testing.T) {
+	if _, err := Parse("  ", time.Now()); !errors.Is(err, ErrEmpty) {
+		t.Errorf("blank input: err = %v, want ErrEmpty", err)
+	}
+}
+
+func TestOrderNamed(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Order
+		wantErr bool
+	}{
+		{in: "", want: OrderAuto},
+		{in: "auto", want: OrderAuto},
+		{in: "DMY", want: OrderDayFirst},
+		{in: "mdy", want: OrderMonthFirst},
+		{in: "month first", want: OrderMonthFirst},
+		{in: "ymd", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := OrderNamed(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("OrderNamed(%q) = %s, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("OrderNamed(%q) = %s, %v; want %s", tt.in, got, err, tt.want)
+		}
+		if again, _ := OrderNamed(got.String()); again != got {
+			t.Errorf("%s does not round-trip through its name", got)
+		}
+	}
+}
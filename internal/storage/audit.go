@@ -0,0 +1,372 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AuditEntry is one recorded change to a stored record.
+type AuditEntry struct {
+	ID       int64
+	Entity   string
+	EntityID int64
+	// AccountID is the account the change belongs to, if any.
+	AccountID   sql.NullInt64
+	AccountName string
+	// Action is create, update, delete, restore, purge, tag or untag.
+	Action    string
+	Changes   []AuditChange
+	Actor     string
+	CreatedAt time.Time
+}
+
+// AuditChange is the before and after value of one field. Before is empty
+// for creations and After is empty for deletions.
+type AuditChange struct {
+	Field  string
+	Before string
+	After  string
+}
+
+// AuditFilter narrows ListAuditLog. Zero fields match everything.
+type AuditFilter struct {
+	AccountID int64
+	// Actor matches case-insensitively by prefix.
+	Actor string
+	Since time.Time
+	Until time.Time
+	Limit int
+}
+
+// auditTable describes a table whose rows are audited field by field.
+type auditTable struct {
+	table  string
+	entity string
+	// account is the column holding the related account ID, if any.
+	account string
+}
+
+var auditTables = []auditTable{
+	{table: "accounts", entity: "account", account: "id"},
+	{table: "contacts", entity: "contact", account: "account_id"},
+	{table: "notes", entity: "note", account: "account_id"},
+	{table: "events", entity: "event", account: "account_id"},
+	{table: "tasks", entity: "task", account: "account_id"},
+	{table: "deals", entity: "deal", account: "account_id"},
+	{table: "custom_fields", entity: "field"},
+}
+
+// auditSkipColumns are bookkeeping columns left out of change sets.
+// deleted_at is reported through the delete and restore actions instead.
+var auditSkipColumns = map[string]bool{"id": true, "creator": true, "created_at": true, "deleted_at": true, "trash_batch": true}
+
+const (
+	auditActorExpr = `COALESCE((SELECT name FROM audit_actor WHERE id = 1), '')`
+	auditNowExpr   = `strftime('%Y-%m-%dT%H:%M:%SZ', 'now')`
+	auditInsert    = `INSERT INTO audit_log (entity, entity_id, account_id, action, changes, actor, created_at)`
+)
+
+// SetActor sets the name recorded with every change made from now on.
+func (s *Store) SetActor(ctx context.Context, name string) error {
+	if _, err := s.db.ExecContext(ctx, `INSERT INTO audit_actor (id, name) VALUES (1, ?)
+        ON CONFLICT(id) DO UPDATE SET name = excluded.name`, strings.TrimSpace(name)); err != nil {
+		return fmt.Errorf("set audit actor: %w", err)
+	}
+	return nil
+}
+
+// ensureAuditTriggers (re)creates the triggers that write audit_log. Like
+// the search index they live outside the numbered migrations: columns are
+// read from the live schema on every start, so fields added by later
+// migrations are audited without touching the triggers.
+func (s *Store) ensureAuditTriggers(ctx context.Context) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin audit triggers: %w", err)
+	}
+	if err := createAuditTriggers(ctx, tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit audit triggers: %w", err)
+	}
+	return nil
+}
+
+func createAuditTriggers(ctx context.Context, tx *sql.Tx) error {
+	var stmts []string
+	for _, t := range auditTables {
+		columns, softDelete, err := auditColumns(ctx, tx, t.table)
+		if err != nil {
+			return err
+		}
+		stmts = append(stmts, t.statements(columns, softDelete)...)
+	}
+	stmts = append(stmts, auditLinkStatements()...)
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("create audit trigger: %w", err)
+		}
+	}
+	return nil
+}
+
+// dropAuditTriggers removes the triggers createAuditTriggers adds, leaving
+// the ones that keep audit_log append-only in place.
+func dropAuditTriggers(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, `SELECT name FROM sqlite_master
+        WHERE type = 'trigger' AND name LIKE 'audit\_%' ESCAPE '\' AND tbl_name != 'audit_log'`)
+	if err != nil {
+		return fmt.Errorf("list audit triggers: %w", err)
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan audit trigger: %w", err)
+		}
+		names = append(names, name)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if _, err := tx.ExecContext(ctx, `DROP TRIGGER IF EXISTS `+name); err != nil {
+			return fmt.Errorf("drop audit trigger: %w", err)
+		}
+	}
+	return nil
+}
+
+// auditColumns lists a table's audited columns and whether it soft-deletes.
+func auditColumns(ctx context.Context, tx *sql.Tx, table string) ([]string, bool, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT name FROM pragma_table_info(?) ORDER BY cid`, table)
+	if err != nil {
+		return nil, false, fmt.Errorf("read %s columns: %w", table, err)
+	}
+	defer rows.Close()
+	var columns []string
+	softDelete := false
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, false, fmt.Errorf("scan %s column: %w", table, err)
+		}
+		if name == "deleted_at" {
+			softDelete = true
+		}
+		if !auditSkipColumns[name] {
+			columns = append(columns, name)
+		}
+	}
+	return columns, softDelete, rows.Err()
+}
+
+func (t auditTable) statements(columns []string, softDelete bool) []string {
+	// fields selects one row per column so the triggers can keep only the
+	// ones that changed and fold them into a JSON object.
+	fields := func(before, after string) string {
+		parts := make([]string, len(columns))
+		for i, c := range columns {
+			b, a := "NULL", "NULL"
+			if before != "" {
+				b = before + "." + c
+			}
+			if after != "" {
+				a = after + "." + c
+			}
+			parts[i] = fmt.Sprintf(`SELECT '%s' AS field, %s AS before, %s AS after`, c, b, a)
+		}
+		return strings.Join(parts, " UNION ALL ")
+	}
+	account := func(row string) string {
+		if t.account == "" {
+			return "NULL"
+		}
+		return row + "." + t.account
+	}
+	updateAction, purgeAction := `'update'`, `'delete'`
+	changed := `COUNT(*) > 0`
+	if softDelete {
+		updateAction = `CASE WHEN old.deleted_at IS NULL AND new.deleted_at IS NOT NULL THEN 'delete'
+                WHEN old.deleted_at IS NOT NULL AND new.deleted_at IS NULL THEN 'restore' ELSE 'update' END`
+		purgeAction = `'purge'`
+		changed += ` OR old.deleted_at IS NOT new.deleted_at`
+	}
+	name := "audit_" + t.table
+	insert := fmt.Sprintf(`%s SELECT '%s', new.id, %s, 'create', json_group_object(field, json_object('after', after)), %s, %s
+            FROM (%s) WHERE after IS NOT NULL;`, auditInsert, t.entity, account("new"), auditActorExpr, auditNowExpr, fields("", "new"))
+	update := fmt.Sprintf(`%s SELECT '%s', new.id, %s, %s, json_group_object(field, json_object('before', before, 'after', after)), %s, %s
+            FROM (%s) WHERE before IS NOT after HAVING %s;`, auditInsert, t.entity, account("new"), updateAction, auditActorExpr, auditNowExpr, fields("old", "new"), changed)
+	remove := fmt.Sprintf(`%s SELECT '%s', old.id, %s, %s, json_group_object(field, json_object('before', before)), %s, %s
+            FROM (%s) WHERE before IS NOT NULL;`, auditInsert, t.entity, account("old"), purgeAction, auditActorExpr, auditNowExpr, fields("old", ""))
+	return []string{
+		`DROP TRIGGER IF EXISTS ` + name + `_ai`,
+		`DROP TRIGGER IF EXISTS ` + name + `_au`,
+		`DROP TRIGGER IF EXISTS ` + name + `_ad`,
+		fmt.Sprintf(`CREATE TRIGGER %s_ai AFTER INSERT ON %s BEGIN %s END`, name, t.table, insert),
+		fmt.Sprintf(`CREATE TRIGGER %s_au AFTER UPDATE ON %s BEGIN %s END`, name, t.table, update),
+		fmt.Sprintf(`CREATE TRIGGER %s_ad AFTER DELETE ON %s BEGIN %s END`, name, t.table, remove),
+	}
+}
+
+// auditLinkStatements audits tags and custom field values as changes to
+// the account they belong to, and attendees and occurrence exceptions as
+// changes to their event.
+func auditLinkStatements() []string {
+	tagName := func(row string) string {
+		return fmt.Sprintf(`COALESCE((SELECT name FROM tags WHERE id = %s.tag_id), 'tag #' || %s.tag_id)`, row, row)
+	}
+	fieldName := func(row string) string {
+		return fmt.Sprintf(`COALESCE((SELECT name FROM custom_fields WHERE id = %s.field_id), 'field #' || %s.field_id)`, row, row)
+	}
+	entry := func(row, action, changes string) string {
+		return fmt.Sprintf(`%s VALUES ('account', %s.account_id, %s.account_id, '%s', %s, %s, %s);`,
+			auditInsert, row, row, action, changes, auditActorExpr, auditNowExpr)
+	}
+	occurrence := func(row string) string {
+		return fmt.Sprintf(`'occurrence ' || %s.occurrence`, row)
+	}
+	exception := func(row string) string {
+		return fmt.Sprintf(`CASE WHEN %[1]s.cancelled THEN 'cancelled'
+            ELSE COALESCE(NULLIF(trim(COALESCE(%[1]s.title, '') || ' ' || COALESCE(%[1]s.event_time, '')), ''), 'edited') END`, row)
+	}
+	contactName := func(row string) string {
+		return fmt.Sprintf(`COALESCE((SELECT name FROM contacts WHERE id = %s.contact_id), 'contact #' || %s.contact_id)`, row, row)
+	}
+	eventEntry := func(row, changes string) string {
+		return fmt.Sprintf(`%s VALUES ('event', %s.event_id, (SELECT account_id FROM events WHERE id = %s.event_id), 'update', %s, %s, %s);`,
+			auditInsert, row, row, changes, auditActorExpr, auditNowExpr)
+	}
+	return []string{
+		`DROP TRIGGER IF EXISTS audit_account_tags_ai`,
+		`DROP TRIGGER IF EXISTS audit_account_tags_ad`,
+		`DROP TRIGGER IF EXISTS audit_account_field_values_ai`,
+		`DROP TRIGGER IF EXISTS audit_account_field_values_au`,
+		`DROP TRIGGER IF EXISTS audit_account_field_values_ad`,
+		`DROP TRIGGER IF EXISTS audit_event_exceptions_ai`,
+		`DROP TRIGGER IF EXISTS audit_event_exceptions_au`,
+		`DROP TRIGGER IF EXISTS audit_event_exceptions_ad`,
+		`DROP TRIGGER IF EXISTS audit_event_attendees_ai`,
+		`DROP TRIGGER IF EXISTS audit_event_attendees_ad`,
+		`CREATE TRIGGER audit_account_tags_ai AFTER INSERT ON account_tags BEGIN ` +
+			entry("new", "tag", `json_object('tag', json_object('after', `+tagName("new")+`))`) + ` END`,
+		`CREATE TRIGGER audit_account_tags_ad AFTER DELETE ON account_tags BEGIN ` +
+			entry("old", "untag", `json_object('tag', json_object('before', `+tagName("old")+`))`) + ` END`,
+		`CREATE TRIGGER audit_account_field_values_ai AFTER INSERT ON account_field_values BEGIN ` +
+			entry("new", "update", `json_object(`+fieldName("new")+`, json_object('after', new.value))`) + ` END`,
+		`CREATE TRIGGER audit_account_field_values_au AFTER UPDATE ON account_field_values WHEN old.value IS NOT new.value BEGIN ` +
+			entry("new", "update", `json_object(`+fieldName("new")+`, json_object('before', old.value, 'after', new.value))`) + ` END`,
+		`CREATE TRIGGER audit_account_field_values_ad AFTER DELETE ON account_field_values BEGIN ` +
+			entry("old", "update", `json_object(`+fieldName("old")+`, json_object('before', old.value))`) + ` END`,
+		`CREATE TRIGGER audit_event_exceptions_ai AFTER INSERT ON event_exceptions BEGIN ` +
+			eventEntry("new", `json_object(`+occurrence("new")+`, json_object('after', `+exception("new")+`))`) + ` END`,
+		`CREATE TRIGGER audit_event_exceptions_au AFTER UPDATE ON event_exceptions BEGIN ` +
+			eventEntry("new", `json_object(`+occurrence("new")+`, json_object('before', `+exception("old")+`, 'after', `+exception("new")+`))`) + ` END`,
+		`CREATE TRIGGER audit_event_exceptions_ad AFTER DELETE ON event_exceptions BEGIN ` +
+			eventEntry("old", `json_object(`+occurrence("old")+`, json_object('before', `+exception("old")+`))`) + ` END`,
+		`CREATE TRIGGER audit_event_attendees_ai AFTER INSERT ON event_attendees BEGIN ` +
+			eventEntry("new", `json_object('attendee', json_object('after', `+contactName("new")+`))`) + ` END`,
+		`CREATE TRIGGER audit_event_attendees_ad AFTER DELETE ON event_attendees BEGIN ` +
+			eventEntry("old", `json_object('attendee', json_object('before', `+contactName("old")+`))`) + ` END`,
+	}
+}
+
+// ListAuditLog returns audit entries matching the filter, newest first.
+func (s *Store) ListAuditLog(ctx context.Context, f AuditFilter) ([]AuditEntry, error) {
+	where := []string{"1 = 1"}
+	var args []any
+	if f.AccountID != 0 {
+		// The audit log cannot be rewritten, so accounts merged into this
+		// one keep their own ID there.
+		where = append(where, "(l.account_id = ? OR l.account_id IN (SELECT merged_id FROM account_merges WHERE account_id = ?))")
+		args = append(args, f.AccountID, f.AccountID)
+	}
+	if actor := strings.TrimSpace(f.Actor); actor != "" {
+		where = append(where, "lower(l.actor) LIKE ?")
+		args = append(args, strings.ToLower(actor)+"%")
+	}
+	if !f.Since.IsZero() {
+		where = append(where, "l.created_at >= ?")
+		args = append(args, f.Since.UTC().Format(time.RFC3339))
+	}
+	if !f.Until.IsZero() {
+		where = append(where, "l.created_at < ?")
+		args = append(args, f.Until.UTC().Format(time.RFC3339))
+	}
+	limit := f.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	args = append(args, limit)
+	rows, err := s.db.QueryContext(ctx, `SELECT l.id, l.entity, l.entity_id, l.account_id,
+            COALESCE(a.name, (SELECT m.merged_name FROM account_merges m WHERE m.merged_id = l.account_id)),
+            l.action, l.changes, l.actor, l.created_at
+        FROM audit_log l LEFT JOIN accounts a ON a.id = l.account_id
+        WHERE `+strings.Join(where, " AND ")+` ORDER BY l.id DESC LIMIT ?`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []AuditEntry
+	for rows.Next() {
+		var e AuditEntry
+		var accountName sql.NullString
+		var changes, created string
+		if err := rows.Scan(&e.ID, &e.Entity, &e.EntityID, &e.AccountID, &accountName, &e.Action, &changes, &e.Actor, &created); err != nil {
+			return nil, fmt.Errorf("scan audit entry: %w", err)
+		}
+		e.AccountName = nullStringToString(accountName)
+		if e.Changes, err = decodeAuditChanges(changes); err != nil {
+			return nil, err
+		}
+		if t, err := time.Parse(time.RFC3339, created); err == nil {
+			e.CreatedAt = t
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// decodeAuditChanges turns a stored change set into changes sorted by field.
+func decodeAuditChanges(raw string) ([]AuditChange, error) {
+	var decoded map[string]struct {
+		Before any `json:"before"`
+		After  any `json:"after"`
+	}
+	if err := json.Unmarshal([]byte(raw), &decoded); err != nil {
+		return nil, fmt.Errorf("decode audit changes: %w", err)
+	}
+	changes := make([]AuditChange, 0, len(decoded))
+	for field, c := range decoded {
+		changes = append(changes, AuditChange{Field: field, Before: auditValue(c.Before), After: auditValue(c.After)})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
+
+func auditValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
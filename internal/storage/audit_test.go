@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAuditTriggers(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	if err := s.SetActor(ctx, " Alice "); err != nil {
+		t.Fatalf("set actor: %v", err)
+	}
+	acme := Account{Name: "Acme", Phone: "+15555550100", Creator: "tester", CreatedAt: time.Now().UTC()}
+	if err := s.CreateAccount(ctx, &acme); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	acme.Phone = "+15555550199"
+	if err := s.UpdateAccount(ctx, &acme); err != nil {
+		t.Fatalf("update account: %v", err)
+	}
+	if err := s.SetActor(ctx, "Bob"); err != nil {
+		t.Fatalf("set actor: %v", err)
+	}
+	if err := s.AddAccountTags(ctx, acme.ID, "vip"); err != nil {
+		t.Fatalf("tag account: %v", err)
+	}
+	if _, err := s.DeleteAccount(ctx, acme.ID, false); err != nil {
+		t.Fatalf("delete account: %v", err)
+	}
+	if err := s.Restore(ctx, TrashItem{Type: "account", ID: acme.ID}); err != nil {
+		t.Fatalf("restore account: %v", err)
+	}
+
+	entries, err := s.ListAuditLog(ctx, AuditFilter{AccountID: acme.ID})
+	if err != nil {
+		t.Fatalf("list audit log: %v", err)
+	}
+	var actions, actors []string
+	for _, e := range entries {
+		actions = append(actions, e.Action)
+		actors = append(actors, e.Actor)
+	}
+	if want := []string{"restore", "delete", "tag", "update", "create"}; !reflect.DeepEqual(actions, want) {
+		t.Fatalf("audit actions = %v, want %v", actions, want)
+	}
+	if want := []string{"Bob", "Bob", "Bob", "Alice", "Alice"}; !reflect.DeepEqual(actors, want) {
+		t.Errorf("audit actors = %v, want %v", actors, want)
+	}
+	update := entries[3]
+	if want := []AuditChange{{Field: "phone", Before: "+15555550100", After: "+15555550199"}}; !reflect.DeepEqual(update.Changes, want) {
+		t.Errorf("update changes = %+v, want %+v", update.Changes, want)
+	}
+	if tag := entries[2]; len(tag.Changes) != 1 || tag.Changes[0].After != "vip" || tag.AccountName != "Acme" {
+		t.Errorf("tag entry = %+v, want vip added to Acme", tag)
+	}
+	if deleted := entries[1]; len(deleted.Changes) != 0 {
+		t.Errorf("delete entry lists changes %+v, want none", deleted.Changes)
+	}
+
+	if _, err := s.db.ExecContext(ctx, `UPDATE audit_log SET actor = 'mallory'`); err == nil {
+		t.Errorf("audit log rows could be rewritten")
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM audit_log`); err == nil {
+		t.Errorf("audit log rows could be deleted")
+	}
+}
+
+func TestListAuditLogFilters(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	s.SetActor(ctx, "Alice")
+	acme := createTestAccount(t, s, "Acme")
+	s.SetActor(ctx, "Bob")
+	beta := createTestAccount(t, s, "Beta")
+
+	count := func(f AuditFilter) int {
+		t.Helper()
+		entries, err := s.ListAuditLog(ctx, f)
+		if err != nil {
+			t.Fatalf("list audit log: %v", err)
+		}
+		return len(entries)
+	}
+	now := time.Now()
+	tests := []struct {
+		name   string
+		filter AuditFilter
+		want   int
+	}{
+		{"everything", AuditFilter{}, 2},
+		{"actor prefix", AuditFilter{Actor: "al"}, 1},
+		{"unknown actor", AuditFilter{Actor: "carol"}, 0},
+		{"account", AuditFilter{AccountID: beta.ID}, 1},
+		{"since yesterday", AuditFilter{Since: now.AddDate(0, 0, -1)}, 2},
+		{"since tomorrow", AuditFilter{Since: now.AddDate(0, 0, 1)}, 0},
+		{"until yesterday", AuditFilter{Until: now.AddDate(0, 0, -1)}, 0},
+		{"limit", AuditFilter{Limit: 1}, 1},
+	}
+	for _, tt := range tests {
+		if got := count(tt.filter); got != tt.want {
+			t.Errorf("%s: %d entries, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	if err := s.MergeAccounts(ctx, acme.ID, beta.ID, "Bob"); err != nil {
+		t.Fatalf("merge accounts: %v", err)
+	}
+	entries, err := s.ListAuditLog(ctx, AuditFilter{AccountID: acme.ID, Actor: "bob"})
+	if err != nil {
+		t.Fatalf("list audit log: %v", err)
+	}
+	found := false
+	for _, e := range entries {
+		if e.EntityID == beta.ID && e.Action == "create" {
+			found = e.AccountName == "Beta"
+		}
+	}
+	if !found {
+		t.Errorf("history of Acme after the merge = %+v, want Beta's creation under its old name", entries)
+	}
+}
@@ -0,0 +1,556 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// BackupFormat and BackupVersion identify a JSON backup. Bump BackupVersion
+// when the layout of the file changes; schema changes are covered by the
+// Schema field instead.
+const (
+	BackupFormat  = "crmterm-backup"
+	BackupVersion = 1
+)
+
+// Backup is a portable dump of the database. Rows keep their IDs and the
+// columns that refer to other rows, so a restore can rebuild the links.
+type Backup struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+	// Schema is the migration version of the database that was dumped.
+	Schema     int             `json:"schema"`
+	ExportedAt string          `json:"exported_at"`
+	Config     json.RawMessage `json:"config,omitempty"`
+	Tables     []BackupTable   `json:"tables"`
+}
+
+// BackupTable holds every row of one table, keyed by column name.
+type BackupTable struct {
+	Name string           `json:"name"`
+	Rows []map[string]any `json:"rows"`
+}
+
+// RestoreResult counts what ImportJSON wrote, by table.
+type RestoreResult struct {
+	Inserted map[string]int
+	// Matched counts rows that were already stored and reused instead of
+	// inserted: accounts, tags and fields by name, other rows when every
+	// column matches.
+	Matched map[string]int
+	// Skipped counts rows whose parent was missing from the backup.
+	Skipped int
+	// Config is the configuration saved with the backup, if any.
+	Config json.RawMessage
+}
+
+// backupTable describes how a table is dumped and restored.
+type backupTable struct {
+	table string
+	// refs maps columns holding another row's ID to that row's table.
+	refs map[string]string
+	// unique is a column that identifies a row across databases; a row
+	// whose value already exists is mapped onto the existing one.
+	unique string
+	// history tables record past changes. Their rows are kept even when a
+	// referenced row is gone.
+	history bool
+}
+
+// backupTables lists the dumped tables with parents before children, the
+// order they must be restored in.
+var backupTables = []backupTable{
+	{table: "accounts", unique: "name", refs: map[string]string{"parent_id": "accounts"}},
+	{table: "tags", unique: "name"},
+	{table: "custom_fields", unique: "name"},
+	{table: "contacts", refs: map[string]string{"account_id": "accounts"}},
+	{table: "notes", refs: map[string]string{"account_id": "accounts"}},
+	{table: "events", refs: map[string]string{"account_id": "accounts"}},
+	{table: "tasks", refs: map[string]string{"account_id": "accounts"}},
+	{table: "deals", refs: map[string]string{"account_id": "accounts"}},
+	{table: "deal_stage_changes", refs: map[string]string{"deal_id": "deals"}},
+	{table: "account_tags", refs: map[string]string{"account_id": "accounts", "tag_id": "tags"}},
+	{table: "account_field_values", refs: map[string]string{"account_id": "accounts", "field_id": "custom_fields"}},
+	{table: "account_status_changes", refs: map[string]string{"account_id": "accounts"}},
+	{table: "account_merges", refs: map[string]string{"account_id": "accounts", "merged_id": "accounts"}, history: true},
+	{table: "event_exceptions", refs: map[string]string{"event_id": "events"}},
+	{table: "event_attendees", refs: map[string]string{"event_id": "events", "contact_id": "contacts"}},
+	{table: "audit_log", refs: map[string]string{"account_id": "accounts"}, history: true},
+}
+
+// ExportJSON writes every table to w as an indented JSON Backup, together
+// with config (marshalled as is; nil leaves it out). It returns the number
+// of rows written per table.
+func (s *Store) ExportJSON(ctx context.Context, w io.Writer, config any) (map[string]int, error) {
+	schema, err := s.SchemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	backup := Backup{
+		Format:     BackupFormat,
+		Version:    BackupVersion,
+		Schema:     schema,
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	if config != nil {
+		if backup.Config, err = json.Marshal(config); err != nil {
+			return nil, fmt.Errorf("marshal config: %w", err)
+		}
+	}
+	// One transaction gives a consistent view across tables.
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin backup: %w", err)
+	}
+	defer tx.Rollback()
+	counts := map[string]int{}
+	for _, t := range backupTables {
+		rows, err := dumpTable(ctx, tx, t.table)
+		if err != nil {
+			return nil, err
+		}
+		backup.Tables = append(backup.Tables, BackupTable{Name: t.table, Rows: rows})
+		counts[t.table] = len(rows)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(backup); err != nil {
+		return nil, fmt.Errorf("write backup: %w", err)
+	}
+	return counts, nil
+}
+
+func dumpTable(ctx context.Context, q queryer, table string) ([]map[string]any, error) {
+	rows, err := q.QueryContext(ctx, `SELECT * FROM `+table+` ORDER BY rowid`)
+	if err != nil {
+		return nil, fmt.Errorf("dump %s: %w", table, err)
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("dump %s columns: %w", table, err)
+	}
+	dump := []map[string]any{}
+	for rows.Next() {
+		values := make([]any, len(columns))
+		ptrs := make([]any, len(values))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("dump %s row: %w", table, err)
+		}
+		row := make(map[string]any, len(columns))
+		for i, c := range columns {
+			if b, ok := values[i].([]byte); ok {
+				values[i] = string(b)
+			}
+			row[c] = values[i]
+		}
+		dump = append(dump, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("dump %s rows: %w", table, err)
+	}
+	return dump, nil
+}
+
+// ImportJSON restores a Backup written by ExportJSON. Into an empty table
+// rows keep their IDs; into one that already has rows they get new IDs and
+// every reference to them is rewritten. Accounts, tags and custom fields
+// that already exist under the same name are reused, as are rows identical
+// to a stored one, so restoring a backup twice adds nothing. A backup from
+// an older schema is first run through the migrations it missed, and one
+// with columns the schema does not know is refused. It runs in one
+// transaction, and the audit triggers are paused for it so the backup's
+// own audit log is restored instead of a row per insert.
+func (s *Store) ImportJSON(ctx context.Context, r io.Reader) (RestoreResult, error) {
+	result := RestoreResult{Inserted: map[string]int{}, Matched: map[string]int{}}
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var backup Backup
+	if err := dec.Decode(&backup); err != nil {
+		return result, fmt.Errorf("read backup: %w", err)
+	}
+	if backup.Format != BackupFormat {
+		return result, fmt.Errorf("not a crmterm backup")
+	}
+	if backup.Version > BackupVersion {
+		return result, fmt.Errorf("backup format v%d is newer than this build supports (v%d)", backup.Version, BackupVersion)
+	}
+	if latest := latestSchemaVersion(); backup.Schema > latest {
+		return result, fmt.Errorf("%w (backup v%d, build supports v%d)", ErrSchemaTooNew, backup.Schema, latest)
+	}
+	tables := map[string][]map[string]any{}
+	for _, t := range backup.Tables {
+		tables[t.Name] = t.Rows
+	}
+	known := map[string]bool{}
+	for _, t := range backupTables {
+		known[t.table] = true
+	}
+	for name := range tables {
+		if !known[name] {
+			return result, fmt.Errorf("backup has unknown table %q", name)
+		}
+	}
+	if backup.Schema < latestSchemaVersion() {
+		upgraded, err := upgradeBackup(ctx, backup)
+		if err != nil {
+			return result, err
+		}
+		tables = map[string][]map[string]any{}
+		for _, t := range upgraded.Tables {
+			tables[t.Name] = t.Rows
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, fmt.Errorf("begin restore: %w", err)
+	}
+	if err := dropAuditTriggers(ctx, tx); err != nil {
+		tx.Rollback()
+		return result, err
+	}
+	rs := &restorer{tx: tx, ids: map[string]map[int64]int64{}, kept: map[string]bool{}, result: &result}
+	for _, t := range backupTables {
+		if err := rs.restoreTable(ctx, t, tables[t.table]); err != nil {
+			tx.Rollback()
+			return result, err
+		}
+	}
+	if err := createAuditTriggers(ctx, tx); err != nil {
+		tx.Rollback()
+		return result, err
+	}
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("commit restore: %w", err)
+	}
+	result.Config = backup.Config
+	return result, nil
+}
+
+// upgradeBackup carries a backup from an older schema through the
+// migrations it missed. The rows are loaded into a scratch in-memory
+// database migrated to the backup's schema, the later migrations run there,
+// and the tables are dumped again, so data a migration moved (such as
+// accounts.decision_maker into contacts) comes along instead of being left
+// behind by the restore.
+func upgradeBackup(ctx context.Context, backup Backup) (Backup, error) {
+	db, err := sql.Open(driverName, ":memory:")
+	if err != nil {
+		return backup, fmt.Errorf("open upgrade database: %w", err)
+	}
+	defer db.Close()
+	// Every connection to :memory: gets a database of its own.
+	db.SetMaxOpenConns(1)
+	scratch := &Store{db: db}
+	if err := scratch.ensureMigrationsTable(ctx); err != nil {
+		return backup, err
+	}
+	var later []migration
+	for _, m := range migrations {
+		if m.version > backup.Schema {
+			later = append(later, m)
+			continue
+		}
+		if err := scratch.applyMigration(ctx, m); err != nil {
+			return backup, err
+		}
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return backup, fmt.Errorf("begin upgrade: %w", err)
+	}
+	for _, t := range backup.Tables {
+		columns, err := tableColumns(ctx, tx, t.Name)
+		if err != nil {
+			tx.Rollback()
+			return backup, err
+		}
+		if len(columns) == 0 && len(t.Rows) > 0 {
+			tx.Rollback()
+			return backup, fmt.Errorf("backup table %s is not in schema v%d", t.Name, backup.Schema)
+		}
+		for _, row := range t.Rows {
+			names := make([]string, 0, len(row))
+			args := make([]any, 0, len(row))
+			for column, value := range row {
+				if _, ok := columns[column]; !ok {
+					tx.Rollback()
+					return backup, fmt.Errorf("backup column %s.%s is not in schema v%d", t.Name, column, backup.Schema)
+				}
+				value, err := backupValue(value)
+				if err != nil {
+					tx.Rollback()
+					return backup, fmt.Errorf("upgrade %s.%s: %w", t.Name, column, err)
+				}
+				names = append(names, column)
+				args = append(args, value)
+			}
+			marks := strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", ")
+			if _, err := tx.ExecContext(ctx, `INSERT INTO `+t.Name+` (`+strings.Join(names, ", ")+`) VALUES (`+marks+`)`, args...); err != nil {
+				tx.Rollback()
+				return backup, fmt.Errorf("upgrade %s: %w", t.Name, err)
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return backup, fmt.Errorf("commit upgrade: %w", err)
+	}
+	for _, m := range later {
+		if err := scratch.applyMigration(ctx, m); err != nil {
+			return backup, fmt.Errorf("upgrade backup from v%d: %w", backup.Schema, err)
+		}
+	}
+
+	upgraded := backup
+	upgraded.Schema = latestSchemaVersion()
+	upgraded.Tables = nil
+	for _, t := range backupTables {
+		rows, err := dumpTable(ctx, db, t.table)
+		if err != nil {
+			return backup, err
+		}
+		upgraded.Tables = append(upgraded.Tables, BackupTable{Name: t.table, Rows: rows})
+	}
+	return upgraded, nil
+}
+
+// restorer carries the ID mappings built up while restoring a backup.
+type restorer struct {
+	tx *sql.Tx
+	// ids maps a table's backup IDs to the IDs the rows got.
+	ids map[string]map[int64]int64
+	// kept marks tables restored with their original IDs, which happens
+	// when the table was empty.
+	kept   map[string]bool
+	result *RestoreResult
+}
+
+// mapID translates a backup ID of table into the restored row's ID.
+func (rs *restorer) mapID(table string, id int64) (int64, bool) {
+	mapped, ok := rs.ids[table][id]
+	return mapped, ok
+}
+
+// tableColumns reads a table's live columns and which of them are NOT NULL.
+func tableColumns(ctx context.Context, tx *sql.Tx, table string) (map[string]bool, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT name, "notnull" FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return nil, fmt.Errorf("read %s columns: %w", table, err)
+	}
+	defer rows.Close()
+	columns := map[string]bool{}
+	for rows.Next() {
+		var name string
+		var notNull bool
+		if err := rows.Scan(&name, &notNull); err != nil {
+			return nil, fmt.Errorf("scan %s column: %w", table, err)
+		}
+		columns[name] = notNull
+	}
+	return columns, rows.Err()
+}
+
+func (rs *restorer) restoreTable(ctx context.Context, t backupTable, rows []map[string]any) error {
+	columns, err := tableColumns(ctx, rs.tx, t.table)
+	if err != nil {
+		return err
+	}
+	_, hasID := columns["id"]
+	var existing int
+	if err := rs.tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+t.table).Scan(&existing); err != nil {
+		return fmt.Errorf("count %s: %w", t.table, err)
+	}
+	rs.kept[t.table] = hasID && existing == 0
+	rs.ids[t.table] = map[int64]int64{}
+	// parents holds self references, set once every row of the table exists.
+	type parentLink struct {
+		id, parent int64
+		column     string
+	}
+	var parents []parentLink
+
+next:
+	for _, row := range rows {
+		var oldID int64
+		if hasID {
+			if oldID, err = backupInt(row["id"]); err != nil {
+				return fmt.Errorf("restore %s: id: %w", t.table, err)
+			}
+			if t.unique != "" {
+				var id int64
+				err := rs.tx.QueryRowContext(ctx, `SELECT id FROM `+t.table+` WHERE `+t.unique+` = ?`, row[t.unique]).Scan(&id)
+				if err == nil {
+					rs.ids[t.table][oldID] = id
+					rs.result.Matched[t.table]++
+					continue
+				}
+				if err != sql.ErrNoRows {
+					return fmt.Errorf("match %s: %w", t.table, err)
+				}
+			}
+		}
+		var names []string
+		var args []any
+		var self []parentLink
+		for column, value := range row {
+			notNull, ok := columns[column]
+			if !ok {
+				// Backups from older schemas are upgraded first, so this
+				// is a column no build of ours wrote.
+				return fmt.Errorf("restore %s: backup column %q is not in this database", t.table, column)
+			}
+			if column == "id" && !rs.kept[t.table] {
+				continue
+			}
+			value, err := backupValue(value)
+			if err != nil {
+				return fmt.Errorf("restore %s.%s: %w", t.table, column, err)
+			}
+			if ref, ok := t.refs[column]; ok && value != nil {
+				old, err := backupInt(value)
+				if err != nil {
+					return fmt.Errorf("restore %s.%s: %w", t.table, column, err)
+				}
+				switch mapped, found := rs.mapID(ref, old); {
+				case ref == t.table:
+					self = append(self, parentLink{parent: old, column: column})
+					value = nil
+				case found:
+					value = mapped
+				case t.history && (notNull || rs.kept[ref]):
+					// The row this history refers to is gone. Keep its old
+					// ID, which cannot point at another row when IDs were
+					// kept or the column may not be blank.
+				case !notNull:
+					value = nil
+				default:
+					rs.result.Skipped++
+					continue next
+				}
+			}
+			if t.table == "audit_log" && column == "entity_id" {
+				value = rs.auditEntityID(row, value)
+			}
+			names = append(names, column)
+			args = append(args, value)
+		}
+		if hasID && !rs.kept[t.table] {
+			// A row identical to one already stored is the same record,
+			// typically from restoring a backup twice.
+			id, found, err := rs.findSame(ctx, t.table, names, args)
+			if err != nil {
+				return err
+			}
+			if found {
+				rs.ids[t.table][oldID] = id
+				rs.result.Matched[t.table]++
+				continue
+			}
+		}
+		verb := "INSERT"
+		if !hasID {
+			verb = "INSERT OR IGNORE"
+		}
+		marks := strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", ")
+		res, err := rs.tx.ExecContext(ctx, verb+` INTO `+t.table+` (`+strings.Join(names, ", ")+`) VALUES (`+marks+`)`, args...)
+		if err != nil {
+			return fmt.Errorf("restore %s: %w", t.table, err)
+		}
+		rs.result.Inserted[t.table]++
+		if !hasID {
+			continue
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("restore %s id: %w", t.table, err)
+		}
+		rs.ids[t.table][oldID] = id
+		for _, link := range self {
+			link.id = id
+			parents = append(parents, link)
+		}
+	}
+	for _, link := range parents {
+		parent, ok := rs.mapID(t.table, link.parent)
+		if !ok {
+			continue
+		}
+		if _, err := rs.tx.ExecContext(ctx, `UPDATE `+t.table+` SET `+link.column+` = ? WHERE id = ?`, parent, link.id); err != nil {
+			return fmt.Errorf("restore %s.%s: %w", t.table, link.column, err)
+		}
+	}
+	return nil
+}
+
+// findSame looks for a row of table holding exactly the given values,
+// NULLs included.
+func (rs *restorer) findSame(ctx context.Context, table string, names []string, args []any) (int64, bool, error) {
+	terms := make([]string, len(names))
+	for i, name := range names {
+		terms[i] = name + ` IS ?`
+	}
+	var id int64
+	err := rs.tx.QueryRowContext(ctx, `SELECT id FROM `+table+` WHERE `+strings.Join(terms, ` AND `)+` LIMIT 1`, args...).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("match %s: %w", table, err)
+	}
+	return id, true, nil
+}
+
+// auditEntityID rewrites an audit entry's entity_id to the restored row of
+// the table its entity names. Entries about rows that are gone keep the
+// old ID.
+func (rs *restorer) auditEntityID(row map[string]any, value any) any {
+	entity, _ := row["entity"].(string)
+	old, err := backupInt(value)
+	if err != nil {
+		return value
+	}
+	for _, t := range auditTables {
+		if t.entity != entity {
+			continue
+		}
+		if id, ok := rs.mapID(t.table, old); ok {
+			return id
+		}
+	}
+	return value
+}
+
+// backupValue converts a decoded JSON value, or one dumped by an upgrade,
+// into a column value.
+func backupValue(v any) (any, error) {
+	switch v := v.(type) {
+	case nil, string, bool, int64, float64:
+		return v, nil
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n, nil
+		}
+		return v.Float64()
+	}
+	return nil, fmt.Errorf("unexpected value %v", v)
+}
+
+func backupInt(v any) (int64, error) {
+	switch v := v.(type) {
+	case int64:
+		return v, nil
+	case json.Number:
+		return v.Int64()
+	}
+	return 0, fmt.Errorf("expected an integer, got %v", v)
+}
@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestBackupRoundTrip(t *testing.T) {
+	src := openTestStore(t)
+	ctx := context.Background()
+	account := createTestAccount(t, src, "Acme")
+	c := Contact{AccountID: account.ID, Name: "Jane", Primary: true, Creator: "tester"}
+	if err := src.CreateContact(ctx, &c); err != nil {
+		t.Fatalf("create contact: %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := src.ExportJSON(ctx, &buf, nil); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	data := buf.Bytes()
+
+	dst := openTestStore(t)
+	res, err := dst.ImportJSON(ctx, bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if res.Inserted["accounts"] != 1 || res.Inserted["contacts"] != 1 {
+		t.Errorf("inserted %v, want one account and one contact", res.Inserted)
+	}
+	restored, err := dst.AccountByName(ctx, "Acme")
+	if err != nil {
+		t.Fatalf("find account: %v", err)
+	}
+	contacts, err := dst.ListContacts(ctx, restored.ID)
+	if err != nil {
+		t.Fatalf("list contacts: %v", err)
+	}
+	if len(contacts) != 1 || contacts[0].Name != "Jane" || !contacts[0].Primary {
+		t.Errorf("contacts = %+v, want the primary contact Jane", contacts)
+	}
+
+	again, err := dst.ImportJSON(ctx, bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("restore again: %v", err)
+	}
+	if len(again.Inserted) != 0 {
+		t.Errorf("second restore inserted %v, want nothing", again.Inserted)
+	}
+}
+
+// TestRestoreUpgradesOldBackup restores a backup taken at schema 1, when
+// accounts still had a decision_maker column; migration 2 turned that into
+// a primary contact, and the restore has to do the same.
+func TestRestoreUpgradesOldBackup(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	backup := Backup{
+		Format:  BackupFormat,
+		Version: BackupVersion,
+		Schema:  1,
+		Tables: []BackupTable{
+			{Name: "accounts", Rows: []map[string]any{
+				{"id": 7, "name": "Acme", "phone": nil, "address": nil, "email": "hi@acme.test",
+					"decision_maker": "Jeff", "creator": "me", "created_at": "2024-01-01T00:00:00Z"},
+			}},
+			{Name: "notes", Rows: []map[string]any{
+				{"id": 3, "content": "Met Jeff", "account_id": 7, "creator": "me", "created_at": "2024-01-02T00:00:00Z"},
+			}},
+		},
+	}
+	data, err := json.Marshal(backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ImportJSON(ctx, bytes.NewReader(data)); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+
+	account, err := s.AccountByName(ctx, "Acme")
+	if err != nil {
+		t.Fatalf("find account: %v", err)
+	}
+	if account.Email != "hi@acme.test" {
+		t.Errorf("email = %q, want it restored", account.Email)
+	}
+	contacts, err := s.ListContacts(ctx, account.ID)
+	if err != nil {
+		t.Fatalf("list contacts: %v", err)
+	}
+	if len(contacts) != 1 || contacts[0].Name != "Jeff" || !contacts[0].Primary || contacts[0].Role != primaryContactRole {
+		t.Errorf("contacts = %+v, want Jeff as the primary decision maker", contacts)
+	}
+	if account.DecisionMaker != "Jeff" {
+		t.Errorf("decision maker = %q, want Jeff", account.DecisionMaker)
+	}
+	var content string
+	if err := s.db.QueryRowContext(ctx, `SELECT content FROM notes WHERE account_id = ?`, account.ID).Scan(&content); err != nil {
+		t.Fatalf("note of the restored account: %v", err)
+	}
+	if content != "Met Jeff" {
+		t.Errorf("note = %q, want the backup's note", content)
+	}
+}
+
+func TestRestoreRejectsUnknownColumn(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	backup := Backup{
+		Format:  BackupFormat,
+		Version: BackupVersion,
+		Schema:  1,
+		Tables: []BackupTable{
+			{Name: "accounts", Rows: []map[string]any{
+				{"id": 1, "name": "Acme", "fax": "555-0100", "creator": "me", "created_at": "2024-01-01T00:00:00Z"},
+			}},
+		},
+	}
+	data, err := json.Marshal(backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.ImportJSON(ctx, bytes.NewReader(data))
+	if err == nil || !strings.Contains(err.Error(), "fax") {
+		t.Fatalf("restore = %v, want an error naming the fax column", err)
+	}
+	if accounts, _ := s.ListAccounts(ctx); len(accounts) != 0 {
+		t.Errorf("restore that failed left accounts behind: %+v", accounts)
+	}
+
+	backup.Schema = latestSchemaVersion()
+	if data, err = json.Marshal(backup); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ImportJSON(ctx, bytes.NewReader(data)); err == nil || !strings.Contains(err.Error(), "fax") {
+		t.Fatalf("restore at the current schema = %v, want an error naming the fax column", err)
+	}
+}
@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Contact is a person associated with an account.
+type Contact struct {
+	ID        int64
+	AccountID int64
+	Name      string
+	Title     string
+	Email     string
+	Phone     string
+	Role      string
+	Primary   bool
+	Creator   string
+	CreatedAt time.Time
+}
+
+// primaryContactRole labels contacts created from the account decision maker.
+const primaryContactRole = "Decision maker"
+
+const contactColumns = `id, account_id, name, title, email, phone, role, is_primary, creator, created_at`
+
+// ListContacts returns an account's contacts, primary first.
+func (s *Store) ListContacts(ctx context.Context, accountID int64) ([]Contact, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+contactColumns+` FROM contacts WHERE account_id = ? ORDER BY is_primary DESC, name COLLATE NOCASE`, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("query contacts: %w", err)
+	}
+	defer rows.Close()
+
+	var contacts []Contact
+	for rows.Next() {
+		c, err := scanContact(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan contact: %w", err)
+		}
+		contacts = append(contacts, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return contacts, nil
+}
+
+// ContactByID retrieves a single contact.
+func (s *Store) ContactByID(ctx context.Context, id int64) (*Contact, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+contactColumns+` FROM contacts WHERE id = ?`, id)
+	c, err := scanContact(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get contact: %w", err)
+	}
+	return &c, nil
+}
+
+// CreateContact inserts a contact. Marking it primary demotes any other
+// primary contact on the same account.
+func (s *Store) CreateContact(ctx context.Context, c *Contact) error {
+	if c == nil {
+		return fmt.Errorf("nil contact")
+	}
+	if strings.TrimSpace(c.Name) == "" {
+		return fmt.Errorf("contact name required")
+	}
+	if c.AccountID == 0 {
+		return fmt.Errorf("contact account required")
+	}
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = time.Now().UTC()
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin insert contact: %w", err)
+	}
+	res, err := tx.ExecContext(ctx, `INSERT INTO contacts (account_id, name, title, email, phone, role, is_primary, creator, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		c.AccountID, strings.TrimSpace(c.Name), nullString(c.Title), nullString(c.Email), nullString(c.Phone), nullString(c.Role), c.Primary, c.Creator, c.CreatedAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("insert contact: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("contact id: %w", err)
+	}
+	if c.Primary {
+		if err := demoteOtherPrimaries(ctx, tx, c.AccountID, id); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit contact: %w", err)
+	}
+	c.ID = id
+	return nil
+}
+
+// UpdateContact persists changes to an existing contact.
+func (s *Store) UpdateContact(ctx context.Context, c *Contact) error {
+	if c == nil {
+		return fmt.Errorf("nil contact")
+	}
+	if strings.TrimSpace(c.Name) == "" {
+		return fmt.Errorf("contact name required")
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin update contact: %w", err)
+	}
+	res, err := tx.ExecContext(ctx, `UPDATE contacts SET name = ?, title = ?, email = ?, phone = ?, role = ?, is_primary = ? WHERE id = ?`,
+		strings.TrimSpace(c.Name), nullString(c.Title), nullString(c.Email), nullString(c.Phone), nullString(c.Role), c.Primary, c.ID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("update contact: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		tx.Rollback()
+		return ErrNotFound
+	}
+	if c.Primary {
+		if err := demoteOtherPrimaries(ctx, tx, c.AccountID, c.ID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit contact: %w", err)
+	}
+	return nil
+}
+
+// DeleteContact removes a contact.
+func (s *Store) DeleteContact(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM contacts WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete contact: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func demoteOtherPrimaries(ctx context.Context, tx *sql.Tx, accountID, keepID int64) error {
+	if _, err := tx.ExecContext(ctx, `UPDATE contacts SET is_primary = 0 WHERE account_id = ? AND id != ?`, accountID, keepID); err != nil {
+		return fmt.Errorf("demote primary contacts: %w", err)
+	}
+	return nil
+}
+
+// setPrimaryContactName keeps the account's primary contact in step with the
+// legacy decision-maker field. Blank names leave contacts untouched.
+func setPrimaryContactName(ctx context.Context, tx *sql.Tx, accountID int64, name, creator string, createdAt time.Time) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil
+	}
+	var id int64
+	err := tx.QueryRowContext(ctx, `SELECT id FROM contacts WHERE account_id = ? AND is_primary = 1 ORDER BY id LIMIT 1`, accountID).Scan(&id)
+	switch {
+	case err == nil:
+		if _, err := tx.ExecContext(ctx, `UPDATE contacts SET name = ? WHERE id = ?`, name, id); err != nil {
+			return fmt.Errorf("update primary contact: %w", err)
+		}
+	case errors.Is(err, sql.ErrNoRows):
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO contacts (account_id, name, role, is_primary, creator, created_at) VALUES (?, ?, ?, 1, ?, ?)`,
+			accountID, name, primaryContactRole, creator, createdAt.UTC().Format(time.RFC3339)); err != nil {
+			return fmt.Errorf("insert primary contact: %w", err)
+		}
+	default:
+		return fmt.Errorf("find primary contact: %w", err)
+	}
+	return nil
+}
+
+func scanContact(rs rowScanner) (Contact, error) {
+	var c Contact
+	var title, email, phone, role sql.NullString
+	var created string
+	if err := rs.Scan(&c.ID, &c.AccountID, &c.Name, &title, &email, &phone, &role, &c.Primary, &c.Creator, &created); err != nil {
+		return Contact{}, err
+	}
+	c.Title = nullStringToString(title)
+	c.Email = nullStringToString(email)
+	c.Phone = nullStringToString(phone)
+	c.Role = nullStringToString(role)
+	if t, err := time.Parse(time.RFC3339, created); err == nil {
+		c.CreatedAt = t
+	}
+	return c, nil
+}
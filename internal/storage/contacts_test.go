@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+func TestContactSnapshotRestoresDeletion(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	account := createTestAccount(t, s, "Acme")
+	c := Contact{AccountID: account.ID, Name: "Jane", Email: "jane@acme.test", Creator: "tester"}
+	if err := s.CreateContact(ctx, &c); err != nil {
+		t.Fatalf("create contact: %v", err)
+	}
+	e := Event{Title: "Kickoff", AccountID: sql.NullInt64{Int64: account.ID, Valid: true}, Creator: "tester"}
+	if err := s.CreateEvent(ctx, &e); err != nil {
+		t.Fatalf("create event: %v", err)
+	}
+	if err := s.SetEventAttendees(ctx, e.ID, []int64{c.ID}); err != nil {
+		t.Fatalf("set attendees: %v", err)
+	}
+
+	snap, err := s.SnapshotRecord(ctx, "contact", c.ID)
+	if err != nil {
+		t.Fatalf("snapshot contact: %v", err)
+	}
+	if err := s.DeleteContact(ctx, c.ID); err != nil {
+		t.Fatalf("delete contact: %v", err)
+	}
+	if err := s.RestoreSnapshot(ctx, snap); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+
+	got, err := s.ContactByID(ctx, c.ID)
+	if err != nil {
+		t.Fatalf("contact after undo: %v", err)
+	}
+	if got.Email != c.Email {
+		t.Errorf("email = %q, want %q", got.Email, c.Email)
+	}
+	var attendees int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM event_attendees WHERE event_id = ? AND contact_id = ?`, e.ID, c.ID).Scan(&attendees); err != nil {
+		t.Fatalf("count attendees: %v", err)
+	}
+	if attendees != 1 {
+		t.Errorf("attendance rows = %d, want 1", attendees)
+	}
+}
+
+func TestContactSnapshotRestoresDemotedPrimary(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	account := createTestAccount(t, s, "Acme")
+	first := Contact{AccountID: account.ID, Name: "Jane", Primary: true, Creator: "tester"}
+	second := Contact{AccountID: account.ID, Name: "Raj", Creator: "tester"}
+	for _, c := range []*Contact{&first, &second} {
+		if err := s.CreateContact(ctx, c); err != nil {
+			t.Fatalf("create contact %s: %v", c.Name, err)
+		}
+	}
+
+	snap, err := s.SnapshotRecord(ctx, "contact", second.ID)
+	if err != nil {
+		t.Fatalf("snapshot contact: %v", err)
+	}
+	second.Primary = true
+	second.Title = "CTO"
+	if err := s.UpdateContact(ctx, &second); err != nil {
+		t.Fatalf("update contact: %v", err)
+	}
+	if got, _ := s.ContactByID(ctx, first.ID); got == nil || got.Primary {
+		t.Fatalf("first contact still primary after update")
+	}
+	if err := s.RestoreSnapshot(ctx, snap); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+
+	contacts, err := s.ListContacts(ctx, account.ID)
+	if err != nil {
+		t.Fatalf("list contacts: %v", err)
+	}
+	if len(contacts) != 2 {
+		t.Fatalf("got %d contacts, want 2", len(contacts))
+	}
+	if contacts[0].ID != first.ID || !contacts[0].Primary || contacts[1].Primary {
+		t.Errorf("primary not restored: %+v", contacts)
+	}
+	if contacts[1].Title != "" {
+		t.Errorf("title = %q, want it cleared by undo", contacts[1].Title)
+	}
+}
@@ -0,0 +1,390 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultCurrency is used when a deal does not specify one.
+const DefaultCurrency = "USD"
+
+// Deal is a sale in progress against an account.
+type Deal struct {
+	ID            int64
+	AccountID     int64
+	Title         string
+	AmountCents   int64
+	Currency      string
+	Stage         string
+	Probability   int
+	ExpectedClose time.Time
+	Owner         string
+	Creator       string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	AccountName   string
+}
+
+// DealStageChange records a deal moving between pipeline stages.
+type DealStageChange struct {
+	ID        int64
+	DealID    int64
+	FromStage string
+	ToStage   string
+	Actor     string
+	ChangedAt time.Time
+}
+
+// StageGroup is a pipeline column: every deal currently in one stage.
+type StageGroup struct {
+	Stage  string
+	Deals  []Deal
+	Totals map[string]int64
+}
+
+const dealColumns = `d.id, d.account_id, d.title, d.amount_cents, d.currency, d.stage, d.probability, d.expected_close, d.owner, d.creator, d.created_at, d.updated_at, a.name`
+
+// ListDeals returns every deal ordered by expected close date.
+func (s *Store) ListDeals(ctx context.Context) ([]Deal, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+dealColumns+` FROM deals d
+        JOIN accounts a ON a.id = d.account_id
+        WHERE a.deleted_at IS NULL
+        ORDER BY COALESCE(d.expected_close, '9999') ASC, d.title COLLATE NOCASE`)
+	if err != nil {
+		return nil, fmt.Errorf("query deals: %w", err)
+	}
+	defer rows.Close()
+
+	var deals []Deal
+	for rows.Next() {
+		d, err := scanDeal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan deal: %w", err)
+		}
+		deals = append(deals, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return deals, nil
+}
+
+// DealByID retrieves a single deal.
+func (s *Store) DealByID(ctx context.Context, id int64) (*Deal, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+dealColumns+` FROM deals d JOIN accounts a ON a.id = d.account_id WHERE d.id = ?`, id)
+	d, err := scanDeal(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get deal: %w", err)
+	}
+	return &d, nil
+}
+
+// CreateDeal inserts a deal and records its opening stage.
+func (s *Store) CreateDeal(ctx context.Context, d *Deal) error {
+	if d == nil {
+		return fmt.Errorf("nil deal")
+	}
+	if strings.TrimSpace(d.Title) == "" {
+		return fmt.Errorf("deal title required")
+	}
+	if d.AccountID == 0 {
+		return fmt.Errorf("deal account required")
+	}
+	if strings.TrimSpace(d.Stage) == "" {
+		return fmt.Errorf("deal stage required")
+	}
+	if d.Probability < 0 || d.Probability > 100 {
+		return fmt.Errorf("probability must be between 0 and 100")
+	}
+	if strings.TrimSpace(d.Currency) == "" {
+		d.Currency = DefaultCurrency
+	}
+	d.Currency = strings.ToUpper(strings.TrimSpace(d.Currency))
+	d.Stage = strings.TrimSpace(d.Stage)
+	if d.CreatedAt.IsZero() {
+		d.CreatedAt = time.Now().UTC()
+	}
+	d.UpdatedAt = d.CreatedAt
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin insert deal: %w", err)
+	}
+	res, err := tx.ExecContext(ctx, `INSERT INTO deals (account_id, title, amount_cents, currency, stage, probability, expected_close, owner, creator, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		d.AccountID, strings.TrimSpace(d.Title), d.AmountCents, d.Currency, d.Stage, d.Probability, nullDate(d.ExpectedClose), nullString(d.Owner), d.Creator,
+		d.CreatedAt.UTC().Format(time.RFC3339), d.UpdatedAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("insert deal: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("deal id: %w", err)
+	}
+	if err := recordStageChange(ctx, tx, id, "", d.Stage, d.Creator, d.CreatedAt); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit deal: %w", err)
+	}
+	d.ID = id
+	return nil
+}
+
+// UpdateDeal persists edits to a deal. A stage change is recorded against
+// actor so it shows up in the activity stream.
+func (s *Store) UpdateDeal(ctx context.Context, d *Deal, actor string) error {
+	if d == nil {
+		return fmt.Errorf("nil deal")
+	}
+	if strings.TrimSpace(d.Title) == "" {
+		return fmt.Errorf("deal title required")
+	}
+	if d.Probability < 0 || d.Probability > 100 {
+		return fmt.Errorf("probability must be between 0 and 100")
+	}
+	if strings.TrimSpace(d.Currency) == "" {
+		d.Currency = DefaultCurrency
+	}
+	d.Currency = strings.ToUpper(strings.TrimSpace(d.Currency))
+	d.Stage = strings.TrimSpace(d.Stage)
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin update deal: %w", err)
+	}
+	var previous string
+	if err := tx.QueryRowContext(ctx, `SELECT stage FROM deals WHERE id = ?`, d.ID).Scan(&previous); err != nil {
+		tx.Rollback()
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("get deal stage: %w", err)
+	}
+	now := time.Now().UTC()
+	_, err = tx.ExecContext(ctx, `UPDATE deals SET account_id = ?, title = ?, amount_cents = ?, currency = ?, stage = ?, probability = ?, expected_close = ?, owner = ?, updated_at = ? WHERE id = ?`,
+		d.AccountID, strings.TrimSpace(d.Title), d.AmountCents, d.Currency, d.Stage, d.Probability, nullDate(d.ExpectedClose), nullString(d.Owner), now.Format(time.RFC3339), d.ID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("update deal: %w", err)
+	}
+	if previous != d.Stage {
+		if err := recordStageChange(ctx, tx, d.ID, previous, d.Stage, actor, now); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit deal: %w", err)
+	}
+	d.UpdatedAt = now
+	return nil
+}
+
+// MoveDealStage changes only the stage of a deal.
+func (s *Store) MoveDealStage(ctx context.Context, id int64, stage, actor string) error {
+	stage = strings.TrimSpace(stage)
+	if stage == "" {
+		return fmt.Errorf("deal stage required")
+	}
+	deal, err := s.DealByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if deal.Stage == stage {
+		return nil
+	}
+	deal.Stage = stage
+	return s.UpdateDeal(ctx, deal, actor)
+}
+
+// DeleteDeal removes a deal and its stage history.
+func (s *Store) DeleteDeal(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM deals WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete deal: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListDealStageChanges returns a deal's stage history, oldest first.
+func (s *Store) ListDealStageChanges(ctx context.Context, dealID int64) ([]DealStageChange, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, deal_id, from_stage, to_stage, actor, changed_at FROM deal_stage_changes WHERE deal_id = ? ORDER BY changed_at ASC, id ASC`, dealID)
+	if err != nil {
+		return nil, fmt.Errorf("query stage changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []DealStageChange
+	for rows.Next() {
+		var c DealStageChange
+		var from sql.NullString
+		var changed string
+		if err := rows.Scan(&c.ID, &c.DealID, &from, &c.ToStage, &c.Actor, &changed); err != nil {
+			return nil, fmt.Errorf("scan stage change: %w", err)
+		}
+		c.FromStage = nullStringToString(from)
+		if t, err := time.Parse(time.RFC3339, changed); err == nil {
+			c.ChangedAt = t
+		}
+		changes = append(changes, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func recordStageChange(ctx context.Context, tx *sql.Tx, dealID int64, from, to, actor string, at time.Time) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO deal_stage_changes (deal_id, from_stage, to_stage, actor, changed_at) VALUES (?, ?, ?, ?, ?)`,
+		dealID, nullString(from), to, actor, at.UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("record stage change: %w", err)
+	}
+	return nil
+}
+
+func scanDeal(rs rowScanner) (Deal, error) {
+	var d Deal
+	var expected, owner sql.NullString
+	var created, updated string
+	if err := rs.Scan(&d.ID, &d.AccountID, &d.Title, &d.AmountCents, &d.Currency, &d.Stage, &d.Probability, &expected, &owner, &d.Creator, &created, &updated, &d.AccountName); err != nil {
+		return Deal{}, err
+	}
+	d.Owner = nullStringToString(owner)
+	if expected.Valid {
+		if t, err := time.Parse("2006-01-02", expected.String); err == nil {
+			d.ExpectedClose = t
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, created); err == nil {
+		d.CreatedAt = t
+	}
+	if t, err := time.Parse(time.RFC3339, updated); err == nil {
+		d.UpdatedAt = t
+	}
+	return d, nil
+}
+
+func nullDate(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.Format("2006-01-02")
+}
+
+// GroupDeals buckets deals into the given stage order. Deals whose stage is
+// not listed are appended in extra groups so nothing silently disappears.
+func GroupDeals(deals []Deal, stages []string) []StageGroup {
+	groups := make([]StageGroup, 0, len(stages))
+	index := map[string]int{}
+	for _, stage := range stages {
+		index[strings.ToLower(stage)] = len(groups)
+		groups = append(groups, StageGroup{Stage: stage, Totals: map[string]int64{}})
+	}
+	for _, d := range deals {
+		key := strings.ToLower(d.Stage)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, StageGroup{Stage: d.Stage, Totals: map[string]int64{}})
+		}
+		groups[i].Deals = append(groups[i].Deals, d)
+		groups[i].Totals[d.Currency] += d.AmountCents
+	}
+	return groups
+}
+
+// ParseAmount converts user input such as "12,500" or "99.95" into cents.
+func ParseAmount(value string) (int64, error) {
+	cleaned := strings.NewReplacer(",", "", "$", "", "€", "", "£", "", " ", "").Replace(strings.TrimSpace(value))
+	if cleaned == "" {
+		return 0, nil
+	}
+	whole, frac := cleaned, ""
+	if i := strings.IndexByte(cleaned, '.'); i >= 0 {
+		whole, frac = cleaned[:i], cleaned[i+1:]
+	}
+	if len(frac) > 2 {
+		return 0, fmt.Errorf("amount has more than two decimal places")
+	}
+	for len(frac) < 2 {
+		frac += "0"
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	// ParseInt alone would take signs, so "5.-1" came out as 4.90.
+	if !isDigits(whole) || !isDigits(frac) {
+		return 0, fmt.Errorf("invalid amount")
+	}
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount")
+	}
+	cents, err := strconv.ParseInt(frac, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount")
+	}
+	return units*100 + cents, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatAmount renders cents with thousands separators and a currency code.
+func FormatAmount(cents int64, currency string) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	whole := strconv.FormatInt(cents/100, 10)
+	var grouped strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(r)
+	}
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	return fmt.Sprintf("%s%s %s.%02d", sign, currency, grouped.String(), cents%100)
+}
+
+// FormatTotals renders per-currency sums in a stable order.
+func FormatTotals(totals map[string]int64) string {
+	if len(totals) == 0 {
+		return FormatAmount(0, DefaultCurrency)
+	}
+	currencies := make([]string, 0, len(totals))
+	for c := range totals {
+		currencies = append(currencies, c)
+	}
+	sort.Strings(currencies)
+	parts := make([]string, 0, len(currencies))
+	for _, c := range currencies {
+		parts = append(parts, FormatAmount(totals[c], c))
+	}
+	return strings.Join(parts, " + ")
+}
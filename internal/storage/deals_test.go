@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{in: "", want: 0},
+		{in: "12,500", want: 1250000},
+		{in: "99.95", want: 9995},
+		{in: "$ 1,234.5", want: 123450},
+		{in: "€7", want: 700},
+		{in: ".5", want: 50},
+		{in: "5.", want: 500},
+		{in: "5.-1", wantErr: true},
+		{in: "5.+1", wantErr: true},
+		{in: "-5", wantErr: true},
+		{in: "-0.5", wantErr: true},
+		{in: "+5", wantErr: true},
+		{in: "1.234", wantErr: true},
+		{in: "1.2.3", wantErr: true},
+		{in: "abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAmount(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseAmount(%q) = %d, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseAmount(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseAmount(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateDealIgnoresStagePadding(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	account := createTestAccount(t, s, "Acme")
+	d := Deal{AccountID: account.ID, Title: "Renewal", Stage: "Proposal", Creator: "tester"}
+	if err := s.CreateDeal(ctx, &d); err != nil {
+		t.Fatalf("create deal: %v", err)
+	}
+
+	d.Stage = "  Proposal "
+	d.Probability = 60
+	if err := s.UpdateDeal(ctx, &d, "tester"); err != nil {
+		t.Fatalf("update deal: %v", err)
+	}
+	changes, err := s.ListDealStageChanges(ctx, d.ID)
+	if err != nil {
+		t.Fatalf("list stage changes: %v", err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("got %d stage changes, want only the creation: %+v", len(changes), changes)
+	}
+
+	d.Stage = " Won "
+	if err := s.UpdateDeal(ctx, &d, "tester"); err != nil {
+		t.Fatalf("update deal: %v", err)
+	}
+	changes, err = s.ListDealStageChanges(ctx, d.ID)
+	if err != nil {
+		t.Fatalf("list stage changes: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("got %d stage changes, want 2", len(changes))
+	}
+	for _, c := range changes {
+		if c.ToStage == "Won" && c.FromStage == "Proposal" {
+			return
+		}
+	}
+	t.Errorf("no trimmed Proposal -> Won change in %+v", changes)
+}
@@ -0,0 +1,301 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// DuplicateThreshold is the lowest score FindDuplicateAccounts reports.
+const DuplicateThreshold = 40
+
+// Duplicate signals and the points each contributes to a pair's score.
+const (
+	dupScoreSameName    = 50
+	dupScoreSimilarName = 35
+	dupScorePhone       = 30
+	dupScoreDomain      = 20
+	dupScoreAddress     = 15
+)
+
+// ErrMergeSelf indicates an attempt to merge an account into itself.
+var ErrMergeSelf = errors.New("cannot merge an account into itself")
+
+// DuplicatePair is two accounts that probably describe the same company.
+// A is the older of the two, which is usually the one to keep.
+type DuplicatePair struct {
+	A, B  Account
+	Score int
+	// Reasons lists the signals that matched, e.g. "same phone".
+	Reasons []string
+}
+
+// legalSuffixes are company-name words ignored when comparing names.
+var legalSuffixes = map[string]bool{
+	"inc": true, "incorporated": true, "llc": true, "ltd": true, "limited": true,
+	"co": true, "corp": true, "corporation": true, "company": true, "plc": true,
+	"gmbh": true, "ag": true, "sa": true, "bv": true, "the": true,
+}
+
+// freeMailDomains are shared by unrelated people, so a match means nothing.
+var freeMailDomains = map[string]bool{
+	"gmail.com": true, "googlemail.com": true, "outlook.com": true, "hotmail.com": true,
+	"live.com": true, "yahoo.com": true, "icloud.com": true, "me.com": true,
+	"aol.com": true, "proton.me": true, "protonmail.com": true,
+}
+
+// ScoreDuplicate rates how likely two accounts are the same company on a
+// 0–100 scale from their names, email domains, phone numbers and addresses.
+func ScoreDuplicate(a, b Account) (int, []string) {
+	score := 0
+	var reasons []string
+	nameA, nameB := normalizeAccountName(a.Name), normalizeAccountName(b.Name)
+	if nameA != "" && nameA == nameB {
+		score += dupScoreSameName
+		reasons = append(reasons, "same name")
+	} else if similarity(nameA, nameB) >= 0.85 {
+		score += dupScoreSimilarName
+		reasons = append(reasons, "similar name")
+	}
+	if pa, pb := phoneDigits(a.Phone), phoneDigits(b.Phone); pa != "" && pa == pb {
+		score += dupScorePhone
+		reasons = append(reasons, "same phone")
+	}
+	if da, db := emailDomain(a.Email), emailDomain(b.Email); da != "" && da == db {
+		score += dupScoreDomain
+		reasons = append(reasons, "same email domain")
+	}
+	if overlap := wordOverlap(a.Address, b.Address); overlap >= 0.6 {
+		score += int(dupScoreAddress*overlap + 0.5)
+		reasons = append(reasons, "similar address")
+	}
+	if score > 100 {
+		score = 100
+	}
+	return score, reasons
+}
+
+// FindDuplicateAccounts compares every pair of live accounts and returns
+// those scoring at least DuplicateThreshold, most likely first.
+func (s *Store) FindDuplicateAccounts(ctx context.Context) ([]DuplicatePair, error) {
+	accounts, err := s.ListAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(accounts, func(i, j int) bool {
+		return accounts[i].CreatedAt.Before(accounts[j].CreatedAt)
+	})
+	var pairs []DuplicatePair
+	for i := range accounts {
+		for j := i + 1; j < len(accounts); j++ {
+			score, reasons := ScoreDuplicate(accounts[i], accounts[j])
+			if score < DuplicateThreshold {
+				continue
+			}
+			pairs = append(pairs, DuplicatePair{A: accounts[i], B: accounts[j], Score: score, Reasons: reasons})
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].Score > pairs[j].Score
+	})
+	return pairs, nil
+}
+
+// MergeAccounts folds dropID into keepID. Notes, events, tasks, contacts,
+// deals, tags, custom field values, subsidiaries and status history move to
+// the surviving account, blank fields on it are filled from the dropped one,
+// and the dropped account is removed. The merge is recorded in keep's
+// activity, which also makes the dropped account's audit entries part of
+// keep's history. Both accounts must be live; a missing or trashed one
+// returns ErrNotFound.
+func (s *Store) MergeAccounts(ctx context.Context, keepID, dropID int64, actor string) error {
+	if keepID == dropID {
+		return ErrMergeSelf
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin merge accounts: %w", err)
+	}
+	var dropName string
+	var dropParent sql.NullInt64
+	if err := tx.QueryRowContext(ctx, `SELECT name, parent_id FROM accounts WHERE id = ? AND deleted_at IS NULL`, dropID).Scan(&dropName, &dropParent); err != nil {
+		tx.Rollback()
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("get merged account: %w", err)
+	}
+	var keepLive int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM accounts WHERE id = ? AND deleted_at IS NULL`, keepID).Scan(&keepLive); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("get kept account: %w", err)
+	}
+	if keepLive == 0 {
+		tx.Rollback()
+		return ErrNotFound
+	}
+	var hasPrimary int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM contacts WHERE account_id = ? AND is_primary = 1`, keepID).Scan(&hasPrimary); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("check primary contact: %w", err)
+	}
+	// When keep sits anywhere under drop it takes over drop's place in the
+	// hierarchy, so moving drop's subsidiaries cannot create a cycle.
+	keepUnderDrop := false
+	if err := checkParent(ctx, tx, dropID, keepID); err != nil {
+		if !errors.Is(err, ErrAccountCycle) {
+			tx.Rollback()
+			return err
+		}
+		keepUnderDrop = true
+	}
+	stmts := []struct {
+		what  string
+		query string
+		args  []any
+	}{
+		{"fill account", `UPDATE accounts SET
+                phone = COALESCE(NULLIF(phone, ''), (SELECT phone FROM accounts WHERE id = ?)),
+                address = COALESCE(NULLIF(address, ''), (SELECT address FROM accounts WHERE id = ?)),
+                email = COALESCE(NULLIF(email, ''), (SELECT email FROM accounts WHERE id = ?)),
+                status = COALESCE(NULLIF(status, ''), (SELECT status FROM accounts WHERE id = ?)),
+                parent_id = CASE WHEN ? THEN ? ELSE parent_id END
+            WHERE id = ?`, []any{dropID, dropID, dropID, dropID, keepUnderDrop, nullInt64(dropParent), keepID}},
+		{"move notes", `UPDATE notes SET account_id = ? WHERE account_id = ?`, []any{keepID, dropID}},
+		{"move events", `UPDATE events SET account_id = ? WHERE account_id = ?`, []any{keepID, dropID}},
+		{"move tasks", `UPDATE tasks SET account_id = ? WHERE account_id = ?`, []any{keepID, dropID}},
+		{"move deals", `UPDATE deals SET account_id = ? WHERE account_id = ?`, []any{keepID, dropID}},
+		{"move contacts", `UPDATE contacts SET account_id = ?, is_primary = CASE WHEN ? THEN 0 ELSE is_primary END WHERE account_id = ?`, []any{keepID, hasPrimary > 0, dropID}},
+		{"move tags", `INSERT OR IGNORE INTO account_tags (account_id, tag_id) SELECT ?, tag_id FROM account_tags WHERE account_id = ?`, []any{keepID, dropID}},
+		{"move field values", `INSERT OR IGNORE INTO account_field_values (account_id, field_id, value) SELECT ?, field_id, value FROM account_field_values WHERE account_id = ?`, []any{keepID, dropID}},
+		{"move subsidiaries", `UPDATE accounts SET parent_id = ? WHERE parent_id = ? AND id != ?`, []any{keepID, dropID, keepID}},
+		{"move status history", `UPDATE account_status_changes SET account_id = ? WHERE account_id = ?`, []any{keepID, dropID}},
+		{"move merge history", `UPDATE account_merges SET account_id = ? WHERE account_id = ?`, []any{keepID, dropID}},
+		{"record merge", `INSERT INTO account_merges (account_id, merged_id, merged_name, actor, merged_at) VALUES (?, ?, ?, ?, ?)`,
+			[]any{keepID, dropID, dropName, actor, time.Now().UTC().Format(time.RFC3339)}},
+		{"delete merged account", `DELETE FROM accounts WHERE id = ?`, []any{dropID}},
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt.query, stmt.args...); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("%s: %w", stmt.what, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit merge accounts: %w", err)
+	}
+	return nil
+}
+
+// normalizeAccountName lowercases a company name and drops punctuation,
+// spacing and legal suffixes, so "Place 1 Inc." and "Place1" compare equal.
+func normalizeAccountName(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var kept []string
+	for _, w := range words {
+		if !legalSuffixes[w] {
+			kept = append(kept, w)
+		}
+	}
+	if len(kept) == 0 {
+		kept = words
+	}
+	return strings.Join(kept, "")
+}
+
+// phoneDigits returns the last ten digits of a phone number, ignoring
+// formatting and country prefixes. Numbers too short to be meaningful
+// return "".
+func phoneDigits(phone string) string {
+	var digits []rune
+	for _, r := range phone {
+		if unicode.IsDigit(r) {
+			digits = append(digits, r)
+		}
+	}
+	if len(digits) < 7 {
+		return ""
+	}
+	if len(digits) > 10 {
+		digits = digits[len(digits)-10:]
+	}
+	return string(digits)
+}
+
+// emailDomain returns the lowercased domain of an email address, or "" for
+// free mail providers.
+func emailDomain(email string) string {
+	_, domain, ok := strings.Cut(strings.TrimSpace(email), "@")
+	domain = strings.ToLower(domain)
+	if !ok || domain == "" || freeMailDomains[domain] {
+		return ""
+	}
+	return domain
+}
+
+// wordOverlap is the Jaccard similarity of two strings' word sets.
+func wordOverlap(a, b string) float64 {
+	split := func(s string) map[string]bool {
+		set := map[string]bool{}
+		for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			set[w] = true
+		}
+		return set
+	}
+	wa, wb := split(a), split(b)
+	if len(wa) == 0 || len(wb) == 0 {
+		return 0
+	}
+	shared := 0
+	for w := range wa {
+		if wb[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(wa)+len(wb)-shared)
+}
+
+// similarity is one minus the edit distance between a and b relative to
+// the longer string.
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 0
+	}
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
+			}
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
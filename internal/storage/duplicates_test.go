@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestMergeAccountsKeepsHistory(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	keep := createTestAccount(t, s, "Acme Inc")
+	drop := createTestAccount(t, s, "ACME")
+	if err := s.SetAccountStatus(ctx, drop.ID, "Prospect", "tester"); err != nil {
+		t.Fatalf("set status: %v", err)
+	}
+	note := Note{Content: "Met at the fair", AccountID: sql.NullInt64{Int64: drop.ID, Valid: true}, Creator: "tester"}
+	if err := s.CreateNote(ctx, &note); err != nil {
+		t.Fatalf("create note: %v", err)
+	}
+	dropChanges, err := s.ListAccountStatusChanges(ctx, drop.ID)
+	if err != nil {
+		t.Fatalf("list status changes: %v", err)
+	}
+	if len(dropChanges) == 0 {
+		t.Fatalf("no status changes recorded before the merge")
+	}
+
+	if err := s.MergeAccounts(ctx, keep.ID, drop.ID, "tester"); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+
+	changes, err := s.ListAccountStatusChanges(ctx, keep.ID)
+	if err != nil {
+		t.Fatalf("list status changes: %v", err)
+	}
+	if len(changes) != len(dropChanges) {
+		t.Errorf("keep has %d status changes, want the %d of the merged account", len(changes), len(dropChanges))
+	}
+	moved, err := s.NoteByID(ctx, note.ID)
+	if err != nil {
+		t.Fatalf("note after merge: %v", err)
+	}
+	if moved.AccountID.Int64 != keep.ID {
+		t.Errorf("note account = %d, want %d", moved.AccountID.Int64, keep.ID)
+	}
+
+	entries, err := s.ListAuditLog(ctx, AuditFilter{AccountID: keep.ID})
+	if err != nil {
+		t.Fatalf("audit log: %v", err)
+	}
+	var fromDrop int
+	for _, e := range entries {
+		if e.AccountID.Int64 != drop.ID {
+			continue
+		}
+		fromDrop++
+		if e.AccountName != drop.Name {
+			t.Errorf("entry %d names %q, want the merged account %q", e.ID, e.AccountName, drop.Name)
+		}
+	}
+	if fromDrop == 0 {
+		t.Errorf("keep's history has none of the merged account's audit entries: %+v", entries)
+	}
+}
+
+func TestMergeAccountsChecksKeep(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	keep := createTestAccount(t, s, "Acme Inc")
+	drop := createTestAccount(t, s, "ACME")
+	if err := s.MergeAccounts(ctx, drop.ID, drop.ID, "tester"); !errors.Is(err, ErrMergeSelf) {
+		t.Errorf("merge into itself: err = %v, want ErrMergeSelf", err)
+	}
+	if err := s.MergeAccounts(ctx, drop.ID+100, drop.ID, "tester"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("merge into a missing account: err = %v, want ErrNotFound", err)
+	}
+	if _, err := s.DeleteAccount(ctx, keep.ID, false); err != nil {
+		t.Fatalf("delete account: %v", err)
+	}
+	if err := s.MergeAccounts(ctx, keep.ID, drop.ID, "tester"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("merge into a trashed account: err = %v, want ErrNotFound", err)
+	}
+	if _, err := s.AccountByID(ctx, drop.ID); err != nil {
+		t.Errorf("refused merge removed the dropped account: %v", err)
+	}
+}
+
+func TestSnapshotMergeUndoesMerge(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	keep := createTestAccount(t, s, "Acme Inc")
+	drop := createTestAccount(t, s, "ACME")
+	for _, c := range []Contact{
+		{AccountID: keep.ID, Name: "Ann", Primary: true, Creator: "tester"},
+		{AccountID: drop.ID, Name: "Bob", Primary: true, Creator: "tester"},
+	} {
+		if err := s.CreateContact(ctx, &c); err != nil {
+			t.Fatalf("create contact: %v", err)
+		}
+	}
+	if err := s.AddAccountTags(ctx, drop.ID, "vip"); err != nil {
+		t.Fatalf("tag account: %v", err)
+	}
+	note := Note{Content: "Met at the fair", AccountID: sql.NullInt64{Int64: drop.ID, Valid: true}, Creator: "tester"}
+	if err := s.CreateNote(ctx, &note); err != nil {
+		t.Fatalf("create note: %v", err)
+	}
+
+	snap, err := s.SnapshotMerge(ctx, keep.ID, drop.ID)
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if err := s.MergeAccounts(ctx, keep.ID, drop.ID, "tester"); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if err := s.RestoreSnapshot(ctx, snap); err != nil {
+		t.Fatalf("undo merge: %v", err)
+	}
+
+	restored, err := s.AccountByID(ctx, drop.ID)
+	if err != nil {
+		t.Fatalf("merged account after undo: %v", err)
+	}
+	if restored.Name != drop.Name {
+		t.Errorf("name = %q, want %q", restored.Name, drop.Name)
+	}
+	for _, tt := range []struct {
+		id   int64
+		want string
+	}{{keep.ID, "Ann"}, {drop.ID, "Bob"}} {
+		contacts, err := s.ListContacts(ctx, tt.id)
+		if err != nil {
+			t.Fatalf("list contacts: %v", err)
+		}
+		if len(contacts) != 1 || contacts[0].Name != tt.want || !contacts[0].Primary {
+			t.Errorf("account %d contacts = %+v, want %s as the primary", tt.id, contacts, tt.want)
+		}
+	}
+	moved, err := s.NoteByID(ctx, note.ID)
+	if err != nil {
+		t.Fatalf("note after undo: %v", err)
+	}
+	if moved.AccountID.Int64 != drop.ID {
+		t.Errorf("note account = %d, want it back on %d", moved.AccountID.Int64, drop.ID)
+	}
+	tagged, err := s.ListAccountsByTag(ctx, "tag:vip")
+	if err != nil {
+		t.Fatalf("accounts by tag: %v", err)
+	}
+	if len(tagged) != 1 || tagged[0].ID != drop.ID {
+		t.Errorf("vip accounts = %+v, want only the restored account", tagged)
+	}
+	var merges int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM account_merges`).Scan(&merges); err != nil {
+		t.Fatalf("count merges: %v", err)
+	}
+	if merges != 0 {
+		t.Errorf("%d merges recorded after undo, want none", merges)
+	}
+}
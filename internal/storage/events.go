@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Attendee is a contact invited to an event.
+type Attendee struct {
+	ContactID int64
+	Name      string
+	Email     string
+}
+
+// conflictHorizon is how far ahead EventConflicts compares the occurrences
+// of recurring events.
+const conflictHorizon = 365 * 24 * time.Hour
+
+// End returns when the event finishes. Events without a duration end when
+// they start.
+func (e Event) End() time.Time {
+	return e.EventTime.Add(e.Duration)
+}
+
+// Overlaps reports whether two events by the same creator are scheduled at
+// the same time. Events without a duration occupy their start minute.
+func (e Event) Overlaps(o Event) bool {
+	if !strings.EqualFold(strings.TrimSpace(e.Creator), strings.TrimSpace(o.Creator)) {
+		return false
+	}
+	end := func(ev Event) time.Time {
+		if ev.Duration <= 0 {
+			return ev.EventTime.Add(time.Minute)
+		}
+		return ev.End()
+	}
+	return e.EventTime.Before(end(o)) && o.EventTime.Before(end(e))
+}
+
+// EventConflicts returns the scheduled events by e's creator that overlap
+// e, or any of its occurrences in the coming year when it repeats. e itself
+// is ignored, so it may already be saved.
+func (s *Store) EventConflicts(ctx context.Context, e Event) ([]Event, error) {
+	events, err := s.ListEvents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	loc := e.EventTime.Location()
+	from := e.EventTime.Add(-24 * time.Hour)
+	to := e.EventTime.Add(conflictHorizon)
+	if _, ok := e.Rule(); !ok {
+		to = e.End().Add(24 * time.Hour)
+	}
+	mine := e.Occurrences(from, to, loc)
+	var others []Event
+	for _, o := range events {
+		if o.ID != e.ID || e.ID == 0 {
+			others = append(others, o.Occurrences(from, to, loc)...)
+		}
+	}
+	var conflicts []Event
+	seen := map[int64]bool{}
+	for _, occ := range mine {
+		for _, o := range others {
+			if !seen[o.ID] && occ.Overlaps(o) {
+				seen[o.ID] = true
+				conflicts = append(conflicts, o)
+			}
+		}
+	}
+	return conflicts, nil
+}
+
+// SetEventAttendees replaces the contacts attending an event.
+func (s *Store) SetEventAttendees(ctx context.Context, eventID int64, contactIDs []int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin set attendees: %w", err)
+	}
+	keep := map[int64]bool{}
+	for _, id := range contactIDs {
+		keep[id] = true
+	}
+	rows, err := tx.QueryContext(ctx, `SELECT contact_id FROM event_attendees WHERE event_id = ?`, eventID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("query attendees: %w", err)
+	}
+	var drop []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return fmt.Errorf("scan attendee: %w", err)
+		}
+		if !keep[id] {
+			drop = append(drop, id)
+		}
+	}
+	rows.Close()
+	// Only the difference is written so the audit log shows who was added
+	// or removed rather than the whole list every time.
+	for _, id := range drop {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM event_attendees WHERE event_id = ? AND contact_id = ?`, eventID, id); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("remove attendee: %w", err)
+		}
+	}
+	for _, id := range contactIDs {
+		if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO event_attendees (event_id, contact_id) VALUES (?, ?)`, eventID, id); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("add attendee: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit set attendees: %w", err)
+	}
+	return nil
+}
+
+// loadEventAttendees fills in the attendees of the events.
+func (s *Store) loadEventAttendees(ctx context.Context, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	index := make(map[int64]int, len(events))
+	for i, e := range events {
+		index[e.ID] = i
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT ea.event_id, c.id, c.name, c.email
+        FROM event_attendees ea JOIN contacts c ON c.id = ea.contact_id
+        ORDER BY c.name COLLATE NOCASE`)
+	if err != nil {
+		return fmt.Errorf("query event attendees: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var eventID int64
+		var a Attendee
+		var email sql.NullString
+		if err := rows.Scan(&eventID, &a.ContactID, &a.Name, &email); err != nil {
+			return fmt.Errorf("scan event attendee: %w", err)
+		}
+		a.Email = nullStringToString(email)
+		if i, ok := index[eventID]; ok {
+			events[i].Attendees = append(events[i].Attendees, a)
+		}
+	}
+	return rows.Err()
+}
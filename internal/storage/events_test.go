@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestEventOverlaps(t *testing.T) {
+	at := func(hour, minute int) time.Time { return time.Date(2026, 5, 4, hour, minute, 0, 0, time.UTC) }
+	meeting := Event{EventTime: at(10, 0), Duration: time.Hour, Creator: "alice"}
+	tests := []struct {
+		name  string
+		other Event
+		want  bool
+	}{
+		{"same slot", Event{EventTime: at(10, 0), Duration: time.Hour, Creator: "alice"}, true},
+		{"starts inside", Event{EventTime: at(10, 30), Duration: time.Hour, Creator: "Alice "}, true},
+		{"back to back", Event{EventTime: at(11, 0), Duration: time.Hour, Creator: "alice"}, false},
+		{"ends at start", Event{EventTime: at(9, 0), Duration: time.Hour, Creator: "alice"}, false},
+		{"no duration inside", Event{EventTime: at(10, 59), Creator: "alice"}, true},
+		{"no duration at end", Event{EventTime: at(11, 0), Creator: "alice"}, false},
+		{"someone else", Event{EventTime: at(10, 0), Duration: time.Hour, Creator: "bob"}, false},
+	}
+	for _, tt := range tests {
+		if got := meeting.Overlaps(tt.other); got != tt.want {
+			t.Errorf("%s: Overlaps = %v, want %v", tt.name, got, tt.want)
+		}
+		if got := tt.other.Overlaps(meeting); got != tt.want {
+			t.Errorf("%s: reversed Overlaps = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if end := meeting.End(); !end.Equal(at(11, 0)) {
+		t.Errorf("End = %v, want 11:00", end)
+	}
+}
+
+func TestEventConflicts(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	monday := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	save := func(e Event) Event {
+		t.Helper()
+		if err := s.CreateEvent(ctx, &e); err != nil {
+			t.Fatalf("create event %q: %v", e.Title, err)
+		}
+		return e
+	}
+	standup := save(Event{Title: "Standup", EventTime: monday, Duration: 30 * time.Minute, Recurrence: "FREQ=WEEKLY", Creator: "alice"})
+	save(Event{Title: "Bob's call", EventTime: monday.AddDate(0, 0, 7), Duration: time.Hour, Creator: "bob"})
+	review := save(Event{Title: "Review", EventTime: monday.Add(2 * time.Hour), Duration: time.Hour, Creator: "alice"})
+
+	conflicts, err := s.EventConflicts(ctx, Event{Title: "Demo", EventTime: monday.AddDate(0, 0, 14).Add(15 * time.Minute), Duration: time.Hour, Creator: "alice"})
+	if err != nil {
+		t.Fatalf("event conflicts: %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].ID != standup.ID {
+		t.Errorf("conflicts of a demo during a later standup = %+v, want the standup", conflicts)
+	}
+	if conflicts, _ := s.EventConflicts(ctx, review); len(conflicts) != 0 {
+		t.Errorf("a saved event conflicts with %+v, want it to ignore itself", conflicts)
+	}
+	weekly := Event{Title: "Planning", EventTime: monday.AddDate(0, 0, -7).Add(2 * time.Hour), Duration: 30 * time.Minute, Recurrence: "FREQ=WEEKLY", Creator: "alice"}
+	if conflicts, _ := s.EventConflicts(ctx, weekly); len(conflicts) != 1 || conflicts[0].ID != review.ID {
+		t.Errorf("conflicts of a weekly planning = %+v, want the review in its second week", conflicts)
+	}
+}
+
+func TestEventDetailsAndAttendees(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	acme := createTestAccount(t, s, "Acme")
+	var ids []int64
+	for _, name := range []string{"Carol", "Ann", "Bob"} {
+		c := Contact{AccountID: acme.ID, Name: name, Email: name + "@acme.test", Creator: "tester"}
+		if err := s.CreateContact(ctx, &c); err != nil {
+			t.Fatalf("create contact: %v", err)
+		}
+		ids = append(ids, c.ID)
+	}
+	e := Event{
+		Title:     "Kickoff",
+		EventTime: time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC),
+		Duration:  90 * time.Minute,
+		Location:  "https://meet.example/kickoff",
+		AccountID: sql.NullInt64{Int64: acme.ID, Valid: true},
+		Creator:   "tester",
+	}
+	if err := s.CreateEvent(ctx, &e); err != nil {
+		t.Fatalf("create event: %v", err)
+	}
+	attendees := func() []string {
+		t.Helper()
+		got, err := s.EventByID(ctx, e.ID)
+		if err != nil {
+			t.Fatalf("load event: %v", err)
+		}
+		if got.Duration != e.Duration || got.Location != e.Location {
+			t.Errorf("loaded event runs %v at %q, want %v at %q", got.Duration, got.Location, e.Duration, e.Location)
+		}
+		var names []string
+		for _, a := range got.Attendees {
+			names = append(names, a.Name)
+		}
+		return names
+	}
+
+	if err := s.SetEventAttendees(ctx, e.ID, ids); err != nil {
+		t.Fatalf("set attendees: %v", err)
+	}
+	if got := attendees(); len(got) != 3 || got[0] != "Ann" || got[2] != "Carol" {
+		t.Errorf("attendees = %v, want Ann, Bob and Carol", got)
+	}
+	if err := s.SetEventAttendees(ctx, e.ID, ids[2:]); err != nil {
+		t.Fatalf("replace attendees: %v", err)
+	}
+	if got := attendees(); len(got) != 1 || got[0] != "Bob" {
+		t.Errorf("attendees after replacing = %v, want only Bob", got)
+	}
+	if err := s.SetEventAttendees(ctx, e.ID, nil); err != nil {
+		t.Fatalf("clear attendees: %v", err)
+	}
+	if got := attendees(); len(got) != 0 {
+		t.Errorf("attendees after clearing = %v, want none", got)
+	}
+}
@@ -0,0 +1,261 @@
+package storage
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExportFilter scopes a CSV export. The zero value exports everything.
+type ExportFilter struct {
+	// Since and Until bound the export by creation time for accounts and
+	// notes and by start time for events. Until is exclusive; zero leaves
+	// that side open.
+	Since time.Time
+	Until time.Time
+	// AccountIDs limits the export to these accounts and the notes and
+	// events linked to them. Nil means every account, plus notes and events
+	// with no account.
+	AccountIDs []int64
+}
+
+func (f ExportFilter) inRange(t time.Time) bool {
+	if !f.Since.IsZero() && t.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !t.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// accountSet returns the AccountIDs as a lookup, or nil when unscoped.
+func (f ExportFilter) accountSet() map[int64]bool {
+	if f.AccountIDs == nil {
+		return nil
+	}
+	set := make(map[int64]bool, len(f.AccountIDs))
+	for _, id := range f.AccountIDs {
+		set[id] = true
+	}
+	return set
+}
+
+// accountExportHeader lists the built-in account columns in the order they
+// are written. Every name is one ImportAccountsCSV recognises.
+var accountExportHeader = []string{"Account Name", "Phone", "Address", "Email", "Decision Maker", "Tags", "Status", "Creator", "Created At"}
+
+// ExportAccountsCSV writes accounts matching filter to w, followed by a
+// column per custom field, so the file can be read back by
+// ImportAccountsCSV. Times are written in loc. It returns the number of
+// accounts written.
+func (s *Store) ExportAccountsCSV(ctx context.Context, w io.Writer, filter ExportFilter, loc *time.Location) (int, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	fields, err := s.ListCustomFields(ctx)
+	if err != nil {
+		return 0, err
+	}
+	values, err := s.allFieldValues(ctx)
+	if err != nil {
+		return 0, err
+	}
+	header := append([]string{}, accountExportHeader...)
+	for _, f := range fields {
+		header = append(header, f.Name)
+	}
+	out := csv.NewWriter(w)
+	if err := out.Write(header); err != nil {
+		return 0, fmt.Errorf("write header: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+accountColumns+` FROM accounts a WHERE a.deleted_at IS NULL ORDER BY a.name COLLATE NOCASE`)
+	if err != nil {
+		return 0, fmt.Errorf("query accounts: %w", err)
+	}
+	defer rows.Close()
+
+	scope := filter.accountSet()
+	count := 0
+	for rows.Next() {
+		a, err := scanAccount(rows)
+		if err != nil {
+			return count, fmt.Errorf("scan account: %w", err)
+		}
+		if scope != nil && !scope[a.ID] {
+			continue
+		}
+		if !filter.inRange(a.CreatedAt) {
+			continue
+		}
+		record := []string{a.Name, a.Phone, a.Address, a.Email, a.DecisionMaker,
+			strings.Join(a.Tags, ";"), a.Status, a.Creator, a.CreatedAt.In(loc).Format(time.RFC3339)}
+		for _, f := range fields {
+			record = append(record, values[a.ID][f.ID])
+		}
+		if err := out.Write(record); err != nil {
+			return count, fmt.Errorf("write account: %w", err)
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, fmt.Errorf("accounts rows: %w", err)
+	}
+	out.Flush()
+	if err := out.Error(); err != nil {
+		return count, fmt.Errorf("write csv: %w", err)
+	}
+	return count, nil
+}
+
+// allFieldValues loads every custom field value keyed by account and field.
+func (s *Store) allFieldValues(ctx context.Context) (map[int64]map[int64]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT account_id, field_id, value FROM account_field_values`)
+	if err != nil {
+		return nil, fmt.Errorf("query field values: %w", err)
+	}
+	defer rows.Close()
+
+	values := map[int64]map[int64]string{}
+	for rows.Next() {
+		var accountID, fieldID int64
+		var value string
+		if err := rows.Scan(&accountID, &fieldID, &value); err != nil {
+			return nil, fmt.Errorf("scan field value: %w", err)
+		}
+		if values[accountID] == nil {
+			values[accountID] = map[int64]string{}
+		}
+		values[accountID][fieldID] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// ExportNotesCSV writes notes matching filter to w, oldest first, and
+// returns how many were written.
+func (s *Store) ExportNotesCSV(ctx context.Context, w io.Writer, filter ExportFilter, loc *time.Location) (int, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"ID", "Account", "Content", "Creator", "Created At"}); err != nil {
+		return 0, fmt.Errorf("write header: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT n.id, n.content, n.account_id, n.creator, n.created_at, a.name
+        FROM notes n
+        LEFT JOIN accounts a ON a.id = n.account_id AND a.deleted_at IS NULL
+        WHERE n.deleted_at IS NULL
+        ORDER BY n.created_at, n.id`)
+	if err != nil {
+		return 0, fmt.Errorf("query notes: %w", err)
+	}
+	defer rows.Close()
+
+	scope := filter.accountSet()
+	count := 0
+	for rows.Next() {
+		var n Note
+		var created string
+		if err := rows.Scan(&n.ID, &n.Content, &n.AccountID, &n.Creator, &created, &n.AccountName); err != nil {
+			return count, fmt.Errorf("scan note: %w", err)
+		}
+		if t, err := time.Parse(time.RFC3339, created); err == nil {
+			n.CreatedAt = t
+		}
+		if scope != nil && (!n.AccountID.Valid || !scope[n.AccountID.Int64]) {
+			continue
+		}
+		if !filter.inRange(n.CreatedAt) {
+			continue
+		}
+		record := []string{strconv.FormatInt(n.ID, 10), n.AccountName.String, n.Content, n.Creator, n.CreatedAt.In(loc).Format(time.RFC3339)}
+		if err := out.Write(record); err != nil {
+			return count, fmt.Errorf("write note: %w", err)
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, fmt.Errorf("notes rows: %w", err)
+	}
+	out.Flush()
+	if err := out.Error(); err != nil {
+		return count, fmt.Errorf("write csv: %w", err)
+	}
+	return count, nil
+}
+
+// ExportEventsCSV writes events matching filter to w in start order and
+// returns how many were written. A recurring event is written once with its
+// rule in the Repeats column, and is included when any of its occurrences
+// falls inside the date range.
+func (s *Store) ExportEventsCSV(ctx context.Context, w io.Writer, filter ExportFilter, loc *time.Location) (int, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	events, err := s.ListEvents(ctx)
+	if err != nil {
+		return 0, err
+	}
+	out := csv.NewWriter(w)
+	header := []string{"ID", "Account", "Title", "Details", "Start", "End", "Duration Minutes", "Location", "Attendees", "Repeats", "Creator", "Created At"}
+	if err := out.Write(header); err != nil {
+		return 0, fmt.Errorf("write header: %w", err)
+	}
+
+	scope := filter.accountSet()
+	count := 0
+	for _, e := range events {
+		if scope != nil && (!e.AccountID.Valid || !scope[e.AccountID.Int64]) {
+			continue
+		}
+		if !eventInRange(e, filter, loc) {
+			continue
+		}
+		var end, minutes string
+		if e.Duration > 0 {
+			end = e.End().In(loc).Format(time.RFC3339)
+			minutes = strconv.Itoa(int(e.Duration / time.Minute))
+		}
+		names := make([]string, len(e.Attendees))
+		for i, a := range e.Attendees {
+			names[i] = a.Name
+		}
+		record := []string{strconv.FormatInt(e.ID, 10), e.AccountName.String, e.Title, e.Details,
+			e.EventTime.In(loc).Format(time.RFC3339), end, minutes, e.Location,
+			strings.Join(names, ";"), e.Recurrence, e.Creator, e.CreatedAt.In(loc).Format(time.RFC3339)}
+		if err := out.Write(record); err != nil {
+			return count, fmt.Errorf("write event: %w", err)
+		}
+		count++
+	}
+	out.Flush()
+	if err := out.Error(); err != nil {
+		return count, fmt.Errorf("write csv: %w", err)
+	}
+	return count, nil
+}
+
+// eventInRange reports whether an event, or for a series any occurrence of
+// it, starts inside the filter's date range.
+func eventInRange(e Event, filter ExportFilter, loc *time.Location) bool {
+	if filter.inRange(e.EventTime) {
+		return true
+	}
+	if e.Recurrence == "" || (!filter.Until.IsZero() && !e.EventTime.Before(filter.Until)) {
+		return false
+	}
+	from, to := filter.Since, filter.Until
+	if to.IsZero() {
+		to = from.Add(conflictHorizon)
+	}
+	return len(e.Occurrences(from, to, loc)) > 0
+}
@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestExportAccountsRoundTrip exports accounts and imports the file into
+// an empty store, which must end up with the same accounts.
+func TestExportAccountsRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2026, 2, 3, 14, 5, 0, 0, time.UTC)
+	src := openTestStore(t)
+	region := CustomField{Name: "Region", Type: FieldText}
+	if err := src.CreateCustomField(ctx, &region); err != nil {
+		t.Fatalf("create field: %v", err)
+	}
+	acme := Account{Name: "Acme", Phone: "+15555550100", Address: "1 Main St, Springfield", Email: "sales@acme.test",
+		DecisionMaker: "Ann Lee", Status: "Customer", Creator: "alice", CreatedAt: created}
+	if err := src.CreateAccount(ctx, &acme); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	if err := src.AddAccountTags(ctx, acme.ID, "vip", "emea"); err != nil {
+		t.Fatalf("tag account: %v", err)
+	}
+	if err := src.SetAccountFieldValues(ctx, acme.ID, map[int64]string{region.ID: "North, East"}); err != nil {
+		t.Fatalf("set field: %v", err)
+	}
+	createTestAccount(t, src, "Beta")
+
+	var buf bytes.Buffer
+	n, err := src.ExportAccountsCSV(ctx, &buf, ExportFilter{}, time.UTC)
+	if err != nil || n != 2 {
+		t.Fatalf("export = %d, %v; want 2 accounts", n, err)
+	}
+
+	dst := openTestStore(t)
+	copyField := CustomField{Name: "Region", Type: FieldText}
+	if err := dst.CreateCustomField(ctx, &copyField); err != nil {
+		t.Fatalf("create field: %v", err)
+	}
+	res, err := dst.ImportAccountsCSV(ctx, bytes.NewReader(buf.Bytes()), ImportOptions{Creator: "importer"})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if res.Created != 2 || len(res.Errors) != 0 {
+		t.Fatalf("import created %d accounts with errors %v, want 2 and none", res.Created, res.Errors)
+	}
+	got, err := dst.AccountByName(ctx, "Acme")
+	if err != nil {
+		t.Fatalf("find account: %v", err)
+	}
+	if got.Phone != acme.Phone || got.Address != acme.Address || got.Email != acme.Email ||
+		got.DecisionMaker != acme.DecisionMaker || got.Status != acme.Status || got.Creator != "alice" || !got.CreatedAt.Equal(created) {
+		t.Errorf("imported account = %+v, want it to match %+v", got, acme)
+	}
+	if strings.Join(got.Tags, ";") != "emea;vip" {
+		t.Errorf("imported tags = %v, want emea and vip", got.Tags)
+	}
+	values, err := dst.AccountFieldValues(ctx, got.ID)
+	if err != nil {
+		t.Fatalf("field values: %v", err)
+	}
+	if values[copyField.ID] != "North, East" {
+		t.Errorf("imported region = %q, want %q", values[copyField.ID], "North, East")
+	}
+}
+
+func TestExportFilters(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 9, 0, 0, 0, time.UTC) }
+	acme := createTestAccount(t, s, "Acme")
+	beta := createTestAccount(t, s, "Beta")
+	link := func(a Account) sql.NullInt64 { return sql.NullInt64{Int64: a.ID, Valid: true} }
+	for _, n := range []Note{
+		{Content: "early", AccountID: link(acme), CreatedAt: day(1)},
+		{Content: "inside", AccountID: link(acme), CreatedAt: day(10)},
+		{Content: "beta", AccountID: link(beta), CreatedAt: day(10)},
+		{Content: "loose", CreatedAt: day(10)},
+		{Content: "late", AccountID: link(acme), CreatedAt: day(20)},
+	} {
+		n.Creator = "tester"
+		if err := s.CreateNote(ctx, &n); err != nil {
+			t.Fatalf("create note: %v", err)
+		}
+	}
+	for _, e := range []Event{
+		{Title: "weekly", EventTime: day(2), Recurrence: "FREQ=WEEKLY", AccountID: link(acme)},
+		{Title: "one-off", EventTime: day(3), AccountID: link(acme)},
+		{Title: "review", EventTime: day(11), Duration: 45 * time.Minute, AccountID: link(beta)},
+	} {
+		e.Creator = "tester"
+		if err := s.CreateEvent(ctx, &e); err != nil {
+			t.Fatalf("create event: %v", err)
+		}
+	}
+
+	column := func(export func(context.Context, *bytes.Buffer) (int, error), col int) []string {
+		t.Helper()
+		var buf bytes.Buffer
+		n, err := export(ctx, &buf)
+		if err != nil {
+			t.Fatalf("export: %v", err)
+		}
+		records, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("read export: %v", err)
+		}
+		if n != len(records)-1 {
+			t.Errorf("export reported %d rows and wrote %d", n, len(records)-1)
+		}
+		var out []string
+		for _, r := range records[1:] {
+			out = append(out, r[col])
+		}
+		return out
+	}
+	notes := func(f ExportFilter) string {
+		return strings.Join(column(func(ctx context.Context, b *bytes.Buffer) (int, error) { return s.ExportNotesCSV(ctx, b, f, time.UTC) }, 2), ",")
+	}
+	events := func(f ExportFilter) string {
+		return strings.Join(column(func(ctx context.Context, b *bytes.Buffer) (int, error) { return s.ExportEventsCSV(ctx, b, f, time.UTC) }, 2), ",")
+	}
+	week := ExportFilter{Since: day(8), Until: day(15)}
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"all notes", notes(ExportFilter{}), "early,inside,beta,loose,late"},
+		{"notes in range", notes(week), "inside,beta,loose"},
+		{"notes of acme", notes(ExportFilter{AccountIDs: []int64{acme.ID}}), "early,inside,late"},
+		{"notes of no account", notes(ExportFilter{AccountIDs: []int64{}}), ""},
+		{"events in range", events(week), "weekly,review"},
+		{"events of acme before the series", events(ExportFilter{Until: day(2), AccountIDs: []int64{acme.ID}}), ""},
+		{"events of beta", events(ExportFilter{AccountIDs: []int64{beta.ID}}), "review"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := s.ExportEventsCSV(ctx, &buf, ExportFilter{AccountIDs: []int64{beta.ID}}, time.UTC); err != nil {
+		t.Fatalf("export events: %v", err)
+	}
+	records, _ := csv.NewReader(&buf).ReadAll()
+	if got := records[1]; got[5] != "2026-03-11T09:45:00Z" || got[6] != "45" {
+		t.Errorf("review ends %q after %q minutes, want 09:45 after 45", got[5], got[6])
+	}
+}
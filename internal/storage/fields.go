@@ -0,0 +1,285 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"crmterm/internal/dateparse"
+)
+
+// FieldType is the kind of value a custom field holds.
+type FieldType string
+
+const (
+	FieldText   FieldType = "text"
+	FieldNumber FieldType = "number"
+	FieldDate   FieldType = "date"
+	FieldBool   FieldType = "bool"
+	FieldEnum   FieldType = "enum"
+)
+
+// FieldTypes lists every supported custom field type.
+var FieldTypes = []FieldType{FieldText, FieldNumber, FieldDate, FieldBool, FieldEnum}
+
+// fieldDateLayout is how date values are stored.
+const fieldDateLayout = "2006-01-02"
+
+// ErrFieldExists indicates a duplicate custom field name.
+var ErrFieldExists = errors.New("custom field already exists")
+
+// CustomField defines an extra attribute tracked on every account, such as
+// a contract number or renewal date.
+type CustomField struct {
+	ID   int64
+	Name string
+	Type FieldType
+	// Options holds the allowed values of an enum field.
+	Options  []string
+	Position int
+}
+
+// ParseFieldType matches a type name, accepting a few common aliases.
+func ParseFieldType(value string) (FieldType, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "text", "string":
+		return FieldText, nil
+	case "number", "num", "int", "decimal":
+		return FieldNumber, nil
+	case "date":
+		return FieldDate, nil
+	case "bool", "boolean", "yes/no", "checkbox":
+		return FieldBool, nil
+	case "enum", "choice", "select":
+		return FieldEnum, nil
+	}
+	return "", fmt.Errorf("unknown field type %q", value)
+}
+
+// Normalize validates value against the field's type and returns it in
+// stored form. An empty value means "not set" and is always accepted.
+func (f CustomField) Normalize(value string) (string, error) {
+	return f.NormalizeAt(value, time.Now(), dateparse.OrderAuto)
+}
+
+// NormalizeAt is Normalize with dates read by dateparse relative to now,
+// with numeric dates such as "03/04/2026" read in order. Forms and imports
+// go through here so they accept the same dates.
+func (f CustomField) NormalizeAt(value string, now time.Time, order dateparse.Order) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	switch f.Type {
+	case FieldNumber:
+		n, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", ""), 64)
+		if err != nil {
+			return "", fmt.Errorf("%s must be a number", f.Name)
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	case FieldDate:
+		r, err := dateparse.ParseWithOrder(value, now, order)
+		if err != nil {
+			return "", fmt.Errorf("%s must be a date like 2025-03-31: %v", f.Name, err)
+		}
+		return r.Time.Format(fieldDateLayout), nil
+	case FieldBool:
+		switch strings.ToLower(value) {
+		case "y", "yes", "true", "1", "on":
+			return "true", nil
+		case "n", "no", "false", "0", "off":
+			return "false", nil
+		}
+		return "", fmt.Errorf("%s must be yes or no", f.Name)
+	case FieldEnum:
+		for _, option := range f.Options {
+			if strings.EqualFold(option, value) {
+				return option, nil
+			}
+		}
+		return "", fmt.Errorf("%s must be one of: %s", f.Name, strings.Join(f.Options, ", "))
+	}
+	return value, nil
+}
+
+// DateValue parses a stored date value.
+func (f CustomField) DateValue(value string) (time.Time, bool) {
+	t, err := time.Parse(fieldDateLayout, value)
+	return t, err == nil
+}
+
+func (f CustomField) validate() error {
+	if strings.TrimSpace(f.Name) == "" {
+		return fmt.Errorf("field name required")
+	}
+	if _, err := ParseFieldType(string(f.Type)); err != nil {
+		return err
+	}
+	if f.Type == FieldEnum && len(f.Options) == 0 {
+		return fmt.Errorf("enum field %s needs at least one option", f.Name)
+	}
+	for _, option := range f.Options {
+		if strings.Contains(option, ";") {
+			return fmt.Errorf("option %q cannot contain ';'", option)
+		}
+	}
+	return nil
+}
+
+// ListCustomFields returns the field definitions in display order.
+func (s *Store) ListCustomFields(ctx context.Context) ([]CustomField, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, name, type, options, position FROM custom_fields ORDER BY position, id`)
+	if err != nil {
+		return nil, fmt.Errorf("query custom fields: %w", err)
+	}
+	defer rows.Close()
+
+	var fields []CustomField
+	for rows.Next() {
+		var f CustomField
+		var options sql.NullString
+		if err := rows.Scan(&f.ID, &f.Name, &f.Type, &options, &f.Position); err != nil {
+			return nil, fmt.Errorf("scan custom field: %w", err)
+		}
+		if options.String != "" {
+			f.Options = strings.Split(options.String, ";")
+		}
+		fields = append(fields, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// CreateCustomField adds a field definition after the existing ones.
+func (s *Store) CreateCustomField(ctx context.Context, f *CustomField) error {
+	if f == nil {
+		return fmt.Errorf("nil custom field")
+	}
+	f.Name = strings.TrimSpace(f.Name)
+	if err := f.validate(); err != nil {
+		return err
+	}
+	res, err := s.db.ExecContext(ctx, `INSERT INTO custom_fields (name, type, options, position, created_at)
+        VALUES (?, ?, ?, (SELECT COALESCE(MAX(position), 0) + 1 FROM custom_fields), ?)`,
+		f.Name, string(f.Type), nullString(strings.Join(f.Options, ";")), time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		if isUniqueConstraint(err) {
+			return ErrFieldExists
+		}
+		return fmt.Errorf("insert custom field: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("custom field id: %w", err)
+	}
+	f.ID = id
+	return s.db.QueryRowContext(ctx, `SELECT position FROM custom_fields WHERE id = ?`, id).Scan(&f.Position)
+}
+
+// UpdateCustomField renames a field or changes its enum options. The type
+// is fixed once created so stored values stay valid.
+func (s *Store) UpdateCustomField(ctx context.Context, f *CustomField) error {
+	if f == nil {
+		return fmt.Errorf("nil custom field")
+	}
+	f.Name = strings.TrimSpace(f.Name)
+	if err := f.validate(); err != nil {
+		return err
+	}
+	res, err := s.db.ExecContext(ctx, `UPDATE custom_fields SET name = ?, options = ? WHERE id = ?`,
+		f.Name, nullString(strings.Join(f.Options, ";")), f.ID)
+	if err != nil {
+		if isUniqueConstraint(err) {
+			return ErrFieldExists
+		}
+		return fmt.Errorf("update custom field: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteCustomField removes a field definition along with every account's
+// value for it.
+func (s *Store) DeleteCustomField(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM custom_fields WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete custom field: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// AccountFieldValues returns an account's custom field values keyed by
+// field ID. Unset fields are absent.
+func (s *Store) AccountFieldValues(ctx context.Context, accountID int64) (map[int64]string, error) {
+	return accountFieldValues(ctx, s.db, accountID)
+}
+
+func accountFieldValues(ctx context.Context, q queryer, accountID int64) (map[int64]string, error) {
+	rows, err := q.QueryContext(ctx, `SELECT field_id, value FROM account_field_values WHERE account_id = ?`, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("query field values: %w", err)
+	}
+	defer rows.Close()
+
+	values := map[int64]string{}
+	for rows.Next() {
+		var id int64
+		var value string
+		if err := rows.Scan(&id, &value); err != nil {
+			return nil, fmt.Errorf("scan field value: %w", err)
+		}
+		values[id] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// SetAccountFieldValues stores custom field values for an account. Values
+// should already be normalized; an empty value clears the field. Fields
+// missing from values are left unchanged.
+func (s *Store) SetAccountFieldValues(ctx context.Context, accountID int64, values map[int64]string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin field values: %w", err)
+	}
+	if err := setAccountFieldValues(ctx, tx, accountID, values); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit field values: %w", err)
+	}
+	return nil
+}
+
+func setAccountFieldValues(ctx context.Context, tx *sql.Tx, accountID int64, values map[int64]string) error {
+	for fieldID, value := range values {
+		var err error
+		if value == "" {
+			_, err = tx.ExecContext(ctx, `DELETE FROM account_field_values WHERE account_id = ? AND field_id = ?`, accountID, fieldID)
+		} else {
+			_, err = tx.ExecContext(ctx, `INSERT INTO account_field_values (account_id, field_id, value) VALUES (?, ?, ?)
+                ON CONFLICT(account_id, field_id) DO UPDATE SET value = excluded.value`, accountID, fieldID, value)
+		}
+		if err != nil {
+			return fmt.Errorf("set field value: %w", err)
+		}
+	}
+	return nil
+}
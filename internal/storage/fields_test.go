@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"crmterm/internal/dateparse"
+)
+
+func TestCustomFieldNormalize(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skipf("no zoneinfo: %v", err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no zoneinfo: %v", err)
+	}
+	number := CustomField{Name: "Seats", Type: FieldNumber}
+	date := CustomField{Name: "Renewal", Type: FieldDate}
+	flag := CustomField{Name: "Partner", Type: FieldBool}
+	tier := CustomField{Name: "Tier", Type: FieldEnum, Options: []string{"Gold", "Silver"}}
+	tests := []struct {
+		field   CustomField
+		in      string
+		loc     *time.Location
+		order   dateparse.Order
+		want    string
+		wantErr bool
+	}{
+		{field: number, in: "1,250.50", want: "1250.5"},
+		{field: number, in: "lots", wantErr: true},
+		{field: flag, in: "Yes", want: "true"},
+		{field: flag, in: "off", want: "false"},
+		{field: flag, in: "maybe", wantErr: true},
+		{field: tier, in: "gold", want: "Gold"},
+		{field: tier, in: "Bronze", wantErr: true},
+		{field: date, in: "", want: ""},
+		{field: date, in: "2026-03-31", want: "2026-03-31"},
+		{field: date, in: "2026/03/31", want: "2026-03-31"},
+		{field: date, in: "Mar 31 2026", want: "2026-03-31"},
+		{field: date, in: "Mar 31, 2026", want: "2026-03-31"},
+		{field: date, in: "tomorrow", want: "2026-01-16"},
+		{field: date, in: "03/04/2026", loc: london, want: "2026-04-03"},
+		{field: date, in: "03/04/2026", loc: newYork, want: "2026-03-04"},
+		{field: date, in: "31/03/2026", loc: newYork, want: "2026-03-31"},
+		{field: date, in: "03/04/2026", loc: newYork, order: dateparse.OrderDayFirst, want: "2026-04-03"},
+		{field: date, in: "03/04/2026", loc: london, order: dateparse.OrderMonthFirst, want: "2026-03-04"},
+		{field: date, in: "someday", wantErr: true},
+		{field: date, in: "2026-02-30", wantErr: true},
+	}
+	for _, tt := range tests {
+		loc := tt.loc
+		if loc == nil {
+			loc = time.UTC
+		}
+		now := time.Date(2026, 1, 15, 12, 0, 0, 0, loc)
+		got, err := tt.field.NormalizeAt(tt.in, now, tt.order)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s %q in %s = %q, want an error", tt.field.Type, tt.in, loc, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s %q in %s: %v", tt.field.Type, tt.in, loc, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s %q in %s = %q, want %q", tt.field.Type, tt.in, loc, got, tt.want)
+		}
+	}
+}
+
+// TestImportCustomDateMatchesForm checks that a CSV import reads numeric
+// dates through dateparse in the import's location, as the form does.
+func TestImportCustomDateMatchesForm(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no zoneinfo: %v", err)
+	}
+	s := openTestStore(t)
+	ctx := context.Background()
+	field := CustomField{Name: "Renewal", Type: FieldDate}
+	if err := s.CreateCustomField(ctx, &field); err != nil {
+		t.Fatalf("create field: %v", err)
+	}
+	csv := "name,renewal\nAcme,03/04/2026\n"
+	res, err := s.ImportAccountsCSV(ctx, strings.NewReader(csv), ImportOptions{Creator: "tester", Location: newYork})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if res.Created != 1 {
+		t.Fatalf("created %d accounts, want 1: %+v", res.Created, res.Rows)
+	}
+	account, err := s.AccountByName(ctx, "Acme")
+	if err != nil {
+		t.Fatalf("find account: %v", err)
+	}
+	values, err := s.AccountFieldValues(ctx, account.ID)
+	if err != nil {
+		t.Fatalf("field values: %v", err)
+	}
+	if got := values[field.ID]; got != "2026-03-04" {
+		t.Errorf("imported renewal = %q, want month first as in New York", got)
+	}
+}
@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrAccountCycle indicates a parent assignment that would make an account
+// its own ancestor.
+var ErrAccountCycle = errors.New("account cannot be its own ancestor")
+
+// ListChildAccounts returns the live accounts directly under parentID,
+// alphabetically.
+func (s *Store) ListChildAccounts(ctx context.Context, parentID int64) ([]Account, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+accountColumns+` FROM accounts a WHERE a.parent_id = ? AND a.deleted_at IS NULL ORDER BY a.name COLLATE NOCASE`, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("query child accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var accounts []Account
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan account: %w", err)
+		}
+		accounts = append(accounts, account)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// checkParent verifies that parentID exists and is neither accountID nor
+// one of its descendants.
+func checkParent(ctx context.Context, tx *sql.Tx, accountID, parentID int64) error {
+	if accountID == parentID {
+		return ErrAccountCycle
+	}
+	var exists int
+	if err := tx.QueryRowContext(ctx, `SELECT 1 FROM accounts WHERE id = ? AND deleted_at IS NULL`, parentID).Scan(&exists); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("parent account: %w", ErrNotFound)
+		}
+		return fmt.Errorf("get parent account: %w", err)
+	}
+	var cycle int
+	err := tx.QueryRowContext(ctx, `WITH RECURSIVE descendants(id) AS (
+            SELECT id FROM accounts WHERE parent_id = ?
+            UNION
+            SELECT a.id FROM accounts a JOIN descendants d ON a.parent_id = d.id
+        )
+        SELECT COUNT(*) FROM descendants WHERE id = ?`, accountID, parentID).Scan(&cycle)
+	if err != nil {
+		return fmt.Errorf("check account hierarchy: %w", err)
+	}
+	if cycle > 0 {
+		return ErrAccountCycle
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+// createChildAccount saves an account under parentID.
+func createChildAccount(t *testing.T, s *Store, name string, parentID int64) Account {
+	t.Helper()
+	a := Account{Name: name, ParentID: sql.NullInt64{Int64: parentID, Valid: true}, Creator: "tester", CreatedAt: time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)}
+	if err := s.CreateAccount(context.Background(), &a); err != nil {
+		t.Fatalf("create account %q: %v", name, err)
+	}
+	return a
+}
+
+func TestAccountHierarchy(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	group := createTestAccount(t, s, "Group")
+	east := createChildAccount(t, s, "East", group.ID)
+	boston := createChildAccount(t, s, "Boston", east.ID)
+	createChildAccount(t, s, "Atlanta", group.ID)
+
+	children, err := s.ListChildAccounts(ctx, group.ID)
+	if err != nil {
+		t.Fatalf("list children: %v", err)
+	}
+	if len(children) != 2 || children[0].Name != "Atlanta" || children[1].Name != "East" {
+		t.Errorf("children of Group = %+v, want Atlanta and East", children)
+	}
+	got, err := s.AccountByID(ctx, east.ID)
+	if err != nil {
+		t.Fatalf("load East: %v", err)
+	}
+	if got.ParentName != "Group" {
+		t.Errorf("East parent name = %q, want Group", got.ParentName)
+	}
+
+	tests := []struct {
+		name   string
+		parent int64
+		want   error
+	}{
+		{"own parent", group.ID, ErrAccountCycle},
+		{"child as parent", east.ID, ErrAccountCycle},
+		{"grandchild as parent", boston.ID, ErrAccountCycle},
+		{"missing parent", 999, ErrNotFound},
+	}
+	for _, tt := range tests {
+		update := group
+		update.ParentID = sql.NullInt64{Int64: tt.parent, Valid: true}
+		if err := s.UpdateAccount(ctx, &update); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	if _, err := s.DeleteAccount(ctx, group.ID, false); err != nil {
+		t.Fatalf("delete Group: %v", err)
+	}
+	orphan := createTestAccount(t, s, "Orphan")
+	orphan.ParentID = sql.NullInt64{Int64: group.ID, Valid: true}
+	if err := s.UpdateAccount(ctx, &orphan); !errors.Is(err, ErrNotFound) {
+		t.Errorf("trashed parent: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestAccountActivityRollup(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	group := createTestAccount(t, s, "Group")
+	east := createChildAccount(t, s, "East", group.ID)
+	boston := createChildAccount(t, s, "Boston", east.ID)
+	west := createChildAccount(t, s, "West", group.ID)
+	for _, a := range []Account{group, east, boston, west} {
+		note := Note{Content: "call " + a.Name, AccountID: sql.NullInt64{Int64: a.ID, Valid: true}, Creator: "tester"}
+		if err := s.CreateNote(ctx, &note); err != nil {
+			t.Fatalf("create note: %v", err)
+		}
+	}
+	if _, err := s.DeleteAccount(ctx, west.ID, true); err != nil {
+		t.Fatalf("delete West: %v", err)
+	}
+
+	notes := func(rollup bool) map[string]int64 {
+		t.Helper()
+		activity, err := s.ListAccountActivity(ctx, group.ID, 50, rollup)
+		if err != nil {
+			t.Fatalf("list activity: %v", err)
+		}
+		got := map[string]int64{}
+		for _, a := range activity {
+			if a.Type == "note" {
+				got[a.Title] = a.AccountID
+			}
+		}
+		return got
+	}
+	if got := notes(false); len(got) != 1 || got["call Group"] != group.ID {
+		t.Errorf("activity without rollup = %v, want only Group's note", got)
+	}
+	got := notes(true)
+	if len(got) != 3 || got["call East"] != east.ID || got["call Boston"] != boston.ID {
+		t.Errorf("rolled up activity = %v, want notes of Group, East and Boston", got)
+	}
+	if _, ok := got["call West"]; ok {
+		t.Errorf("rolled up activity includes the trashed subsidiary West")
+	}
+}
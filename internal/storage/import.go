@@ -0,0 +1,665 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"crmterm/internal/dateparse"
+	"crmterm/internal/validate"
+)
+
+// ImportTargets lists the built-in attributes a CSV column can fill, in the
+// order the import preview shows them. Custom fields are targeted as
+// "field:<id>".
+var ImportTargets = []string{"name", "phone", "address", "email", "decision_maker", "tags", "status", "creator", "created_at", "note"}
+
+// fieldTargetPrefix marks an import target that is a custom field.
+const fieldTargetPrefix = "field:"
+
+// Row outcomes reported in ImportRow.Action.
+const (
+	ImportCreated   = "created"
+	ImportUpdated   = "updated"
+	ImportUnchanged = "unchanged"
+	ImportSkipped   = "skipped"
+)
+
+// Import strategies decide what happens to a row that matches an account
+// already in the database.
+const (
+	// ImportSkipExisting leaves the account alone and skips the row.
+	ImportSkipExisting = "skip"
+	// ImportFillBlanks copies values into fields the account has left
+	// empty.
+	ImportFillBlanks = "fill"
+	// ImportOverwrite replaces the account's values with the row's.
+	ImportOverwrite = "overwrite"
+	// ImportCopy creates a new account named "Name (2)", "Name (3)" and
+	// so on.
+	ImportCopy = "copy"
+)
+
+// ImportStrategies lists the import strategies in the order they are
+// offered.
+var ImportStrategies = []string{ImportSkipExisting, ImportFillBlanks, ImportOverwrite, ImportCopy}
+
+// Keys rows are matched to existing accounts by.
+const (
+	ImportMatchName  = "name"
+	ImportMatchEmail = "email"
+)
+
+// ErrImportNoName is returned when no column maps to the account name.
+var ErrImportNoName = errors.New("csv missing 'name' column")
+
+// ImportOptions controls ImportAccountsCSV.
+type ImportOptions struct {
+	// Creator is recorded on rows without a creator column.
+	Creator string
+	// Location reads timestamps that carry no zone.
+	Location *time.Location
+	// DryRun runs the whole import and rolls it back, so the result says
+	// what would happen without writing anything.
+	DryRun bool
+	// Columns overrides the detected target of a column, by index. An
+	// empty target ignores the column.
+	Columns map[int]string
+	// Strategy is one of ImportStrategies; empty means ImportSkipExisting.
+	Strategy string
+	// MatchBy is ImportMatchName (the default) or ImportMatchEmail. Rows
+	// matched by email fall back to the name when the email is blank or
+	// unknown, since account names are unique.
+	MatchBy string
+	// PhoneCountry is the ISO 3166 country phone numbers without a
+	// country code are read in, e.g. "US".
+	PhoneCountry string
+	// DateOrder reads numeric dates in custom date columns.
+	DateOrder dateparse.Order
+	// RejectInvalid skips rows whose phone number or email address does
+	// not validate, instead of importing them as written with a warning.
+	RejectInvalid bool
+}
+
+// ImportColumn is how one CSV column is read.
+type ImportColumn struct {
+	Index  int
+	Header string
+	// Target is one of ImportTargets or "field:<id>"; empty when the
+	// column is ignored.
+	Target string
+}
+
+// ImportRow is the outcome of one CSV row.
+type ImportRow struct {
+	// Line is the row's line in the file; the header is line 1.
+	Line   int
+	Name   string
+	Action string
+	// Changed names the fields an update filled in or replaced.
+	Changed []string
+	// Problems lists what went wrong. A created row can still have
+	// problems, such as a tag or field value that was left out.
+	Problems []string
+}
+
+// ImportResult summarizes a CSV import operation.
+type ImportResult struct {
+	Created   int
+	Updated   int
+	Unchanged int
+	Skipped   int
+	Errors    []string
+	Notes     int
+	Columns   []ImportColumn
+	Rows      []ImportRow
+}
+
+// ImportTarget resolves a column header, or a name typed to remap one, to
+// the target it fills. It returns "" when nothing matches.
+func ImportTarget(name string, fields []CustomField) string {
+	key := normalizeHeader(name)
+	switch key {
+	case "name", "accountname":
+		return "name"
+	case "phone", "phonenumber":
+		return "phone"
+	case "address":
+		return "address"
+	case "email", "mail", "emailaddress":
+		return "email"
+	case "decisionmaker", "dm":
+		return "decision_maker"
+	case "creator":
+		return "creator"
+	case "createdat", "created", "createddate", "createdtime":
+		return "created_at"
+	case "note", "notes":
+		return "note"
+	case "tags", "tag", "labels":
+		return "tags"
+	case "status", "stage", "lifecycle":
+		return "status"
+	}
+	for _, f := range fields {
+		if key != "" && normalizeHeader(f.Name) == key {
+			return fieldTargetPrefix + strconv.FormatInt(f.ID, 10)
+		}
+	}
+	return ""
+}
+
+// ImportTargetField returns the custom field a "field:<id>" target names.
+func ImportTargetField(target string, fields []CustomField) (CustomField, bool) {
+	if !strings.HasPrefix(target, fieldTargetPrefix) {
+		return CustomField{}, false
+	}
+	id, err := strconv.ParseInt(strings.TrimPrefix(target, fieldTargetPrefix), 10, 64)
+	if err != nil {
+		return CustomField{}, false
+	}
+	for _, f := range fields {
+		if f.ID == id {
+			return f, true
+		}
+	}
+	return CustomField{}, false
+}
+
+// ImportAccountsCSV ingests accounts from a CSV reader. Every row is
+// written in one transaction; a row that fails is rolled back on its own
+// and reported in Rows. When no column holds the account name it returns
+// ErrImportNoName along with the detected Columns, so the caller can remap
+// them and try again.
+func (s *Store) ImportAccountsCSV(ctx context.Context, r io.Reader, opts ImportOptions) (ImportResult, error) {
+	result := ImportResult{}
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return result, fmt.Errorf("read header: %w", err)
+	}
+	fields, err := s.ListCustomFields(ctx)
+	if err != nil {
+		return result, err
+	}
+	index := map[string]int{}
+	custom := map[int]CustomField{}
+	for i, h := range header {
+		target := ImportTarget(h, fields)
+		if mapped, ok := opts.Columns[i]; ok {
+			target = mapped
+		}
+		if f, ok := ImportTargetField(target, fields); ok {
+			custom[i] = f
+		} else if target != "" {
+			index[target] = i
+		}
+		result.Columns = append(result.Columns, ImportColumn{Index: i, Header: h, Target: target})
+	}
+	if _, ok := index["name"]; !ok {
+		return result, ErrImportNoName
+	}
+	loc := opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	strategy := opts.Strategy
+	switch strategy {
+	case "":
+		strategy = ImportSkipExisting
+	case ImportSkipExisting, ImportFillBlanks, ImportOverwrite, ImportCopy:
+	default:
+		return result, fmt.Errorf("unknown import strategy %q", opts.Strategy)
+	}
+	matchBy := opts.MatchBy
+	switch matchBy {
+	case "":
+		matchBy = ImportMatchName
+	case ImportMatchName, ImportMatchEmail:
+	default:
+		return result, fmt.Errorf("cannot match accounts by %q", opts.MatchBy)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, fmt.Errorf("begin import: %w", err)
+	}
+	im := &accountImport{tx: tx, index: index, custom: custom, creator: opts.Creator, loc: loc, dateOrder: opts.DateOrder, strategy: strategy, matchBy: matchBy,
+		country: opts.PhoneCountry, strict: opts.RejectInvalid, result: &result}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var row ImportRow
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				row.Line = parseErr.StartLine
+				err = parseErr.Err
+			}
+			row.Action = ImportSkipped
+			row.Problems = []string{err.Error()}
+		} else {
+			row.Line, _ = reader.FieldPos(0)
+			if err := im.importRow(ctx, &row, record); err != nil {
+				tx.Rollback()
+				return result, err
+			}
+		}
+		switch row.Action {
+		case ImportCreated:
+			result.Created++
+		case ImportUpdated:
+			result.Updated++
+		case ImportUnchanged:
+			result.Unchanged++
+		case ImportSkipped:
+			result.Skipped++
+		}
+		for _, problem := range row.Problems {
+			result.Errors = append(result.Errors, fmt.Sprintf("row %d: %s", row.Line, problem))
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	if opts.DryRun {
+		tx.Rollback()
+		return result, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("commit import: %w", err)
+	}
+	return result, nil
+}
+
+// accountImport is the state of one ImportAccountsCSV run.
+type accountImport struct {
+	tx *sql.Tx
+	// index maps built-in targets to their column.
+	index map[string]int
+	// custom maps column positions to the custom field they fill.
+	custom    map[int]CustomField
+	creator   string
+	loc       *time.Location
+	dateOrder dateparse.Order
+	strategy  string
+	matchBy   string
+	country   string
+	strict    bool
+	result    *ImportResult
+}
+
+// importRecord is a CSV row read into the values it imports.
+type importRecord struct {
+	account Account
+	tags    []string
+	// values holds the custom field values, already normalized.
+	values map[int64]string
+	note   string
+}
+
+// importRow writes one CSV record inside a savepoint, so a row that fails
+// half way leaves nothing behind. Problems with the row are recorded in
+// row; the returned error means the import as a whole cannot go on.
+func (im *accountImport) importRow(ctx context.Context, row *ImportRow, record []string) error {
+	rec, ok := im.read(row, record)
+	if !ok {
+		return nil
+	}
+	tx := im.tx
+	if _, err := tx.ExecContext(ctx, `SAVEPOINT import_row`); err != nil {
+		return fmt.Errorf("import row %d: %w", row.Line, err)
+	}
+	existing, err := im.match(ctx, &rec.account)
+	if err != nil {
+		return fmt.Errorf("import row %d: %w", row.Line, err)
+	}
+	trashed := ""
+	if existing == nil {
+		if trashed, err = im.trashedName(ctx, rec.account.Name); err != nil {
+			return fmt.Errorf("import row %d: %w", row.Line, err)
+		}
+	}
+	switch {
+	case existing == nil && trashed == "":
+		im.create(ctx, row, rec)
+	case im.strategy == ImportCopy:
+		name, err := copyName(ctx, tx, rec.account.Name)
+		if err != nil {
+			return fmt.Errorf("import row %d: %w", row.Line, err)
+		}
+		rec.account.Name = name
+		row.Name = name
+		im.create(ctx, row, rec)
+	case trashed != "":
+		// Names stay unique across the trash, so the row cannot be
+		// created, and updating a deleted account would hide the change.
+		row.Action = ImportSkipped
+		row.Problems = append(row.Problems, fmt.Sprintf("matches account '%s' in the trash; restore it first or import with mode copy", trashed))
+	case im.strategy == ImportFillBlanks, im.strategy == ImportOverwrite:
+		if err := im.update(ctx, row, existing, rec); err != nil {
+			row.Action = ImportSkipped
+			row.Changed = nil
+			row.Problems = append(row.Problems, err.Error())
+		}
+	default:
+		row.Action = ImportSkipped
+		row.Problems = append(row.Problems, fmt.Sprintf("duplicate account '%s'", existing.Name))
+	}
+	if row.Action == ImportSkipped {
+		if _, err := tx.ExecContext(ctx, `ROLLBACK TO import_row`); err != nil {
+			return fmt.Errorf("import row %d: %w", row.Line, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `RELEASE import_row`); err != nil {
+		return fmt.Errorf("import row %d: %w", row.Line, err)
+	}
+	return nil
+}
+
+// read picks a record's values out of its columns. It reports false when
+// the row cannot be imported at all.
+func (im *accountImport) read(row *ImportRow, record []string) (*importRecord, bool) {
+	value := func(key string) string {
+		if idx, ok := im.index[key]; ok && idx < len(record) {
+			return strings.TrimSpace(record[idx])
+		}
+		return ""
+	}
+	row.Name = value("name")
+	if row.Name == "" {
+		row.Action = ImportSkipped
+		row.Problems = append(row.Problems, "account name required")
+		return nil, false
+	}
+	rec := &importRecord{
+		account: Account{
+			Name:          row.Name,
+			Phone:         value("phone"),
+			Address:       value("address"),
+			Email:         value("email"),
+			DecisionMaker: value("decision_maker"),
+			Status:        value("status"),
+			Creator:       value("creator"),
+		},
+		tags:   splitTagList(value("tags")),
+		values: map[int64]string{},
+		note:   value("note"),
+	}
+	account := &rec.account
+	contact, warnings := validate.CheckContact(validate.Contact{Phone: account.Phone, Email: account.Email, Address: account.Address}, im.country)
+	account.Phone, account.Email, account.Address = contact.Phone, contact.Email, contact.Address
+	for _, w := range warnings {
+		row.Problems = append(row.Problems, w.String())
+	}
+	if im.strict && len(warnings) > 0 {
+		row.Action = ImportSkipped
+		return nil, false
+	}
+	if account.Creator == "" {
+		account.Creator = im.creator
+	}
+	if account.Creator == "" {
+		account.Creator = "Import"
+	}
+	if stamp := value("created_at"); stamp != "" {
+		if parsed, ok := parseImportTime(stamp, im.loc); ok {
+			account.CreatedAt = parsed
+		} else {
+			row.Problems = append(row.Problems, fmt.Sprintf("unreadable created date '%s'; using now", stamp))
+		}
+	}
+	if account.CreatedAt.IsZero() {
+		account.CreatedAt = time.Now().In(im.loc)
+	}
+	for idx, field := range im.custom {
+		if idx >= len(record) {
+			continue
+		}
+		v, err := field.NormalizeAt(record[idx], time.Now().In(im.loc), im.dateOrder)
+		if err != nil {
+			row.Problems = append(row.Problems, err.Error())
+			continue
+		}
+		if v != "" {
+			rec.values[field.ID] = v
+		}
+	}
+	return rec, true
+}
+
+// match finds the existing account a row refers to, or nil when the row is
+// a new account.
+func (im *accountImport) match(ctx context.Context, a *Account) (*Account, error) {
+	if im.matchBy == ImportMatchEmail && a.Email != "" {
+		row := im.tx.QueryRowContext(ctx, `SELECT `+accountColumns+` FROM accounts a WHERE a.deleted_at IS NULL AND lower(a.email) = lower(?) ORDER BY a.id LIMIT 1`, a.Email)
+		existing, err := scanAccount(row)
+		if err == nil {
+			return &existing, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("match account: %w", err)
+		}
+	}
+	row := im.tx.QueryRowContext(ctx, `SELECT `+accountColumns+` FROM accounts a WHERE a.deleted_at IS NULL AND lower(a.name) = lower(?)`, a.Name)
+	existing, err := scanAccount(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("match account: %w", err)
+	}
+	return &existing, nil
+}
+
+// trashedName returns the name of the trashed account holding name, or ""
+// when there is none.
+func (im *accountImport) trashedName(ctx context.Context, name string) (string, error) {
+	var trashed string
+	err := im.tx.QueryRowContext(ctx, `SELECT name FROM accounts WHERE deleted_at IS NOT NULL AND lower(name) = lower(?)`, name).Scan(&trashed)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("match trashed account: %w", err)
+	}
+	return trashed, nil
+}
+
+// create inserts a record as a new account.
+func (im *accountImport) create(ctx context.Context, row *ImportRow, rec *importRecord) {
+	tx := im.tx
+	id, err := insertAccount(ctx, tx, &rec.account)
+	if err != nil {
+		row.Action = ImportSkipped
+		if errors.Is(err, ErrAccountExists) {
+			row.Problems = append(row.Problems, fmt.Sprintf("duplicate account '%s'", rec.account.Name))
+		} else {
+			row.Problems = append(row.Problems, err.Error())
+		}
+		return
+	}
+	row.Action = ImportCreated
+	if len(rec.tags) > 0 {
+		if err := addAccountTags(ctx, tx, id, rec.tags...); err != nil {
+			row.Problems = append(row.Problems, fmt.Sprintf("tags error: %v", err))
+		}
+	}
+	if err := setAccountFieldValues(ctx, tx, id, rec.values); err != nil {
+		row.Problems = append(row.Problems, fmt.Sprintf("custom fields error: %v", err))
+	}
+	if rec.note != "" {
+		im.addNote(ctx, row, id, rec)
+	}
+}
+
+// update applies a record to the account it matched, following the import
+// strategy. Blank cells never clear a value, tags are only ever added, and
+// a note is added unless the account already has one with the same text.
+// The creator and creation date are left as they were.
+func (im *accountImport) update(ctx context.Context, row *ImportRow, existing *Account, rec *importRecord) error {
+	tx := im.tx
+	merge := func(field string, current *string, incoming string) bool {
+		if incoming == "" || incoming == *current {
+			return false
+		}
+		if im.strategy == ImportFillBlanks && *current != "" {
+			return false
+		}
+		*current = incoming
+		row.Changed = append(row.Changed, field)
+		return true
+	}
+	in := rec.account
+	if strings.EqualFold(in.Name, existing.Name) {
+		// Names differing only in case are the same account; keep the
+		// spelling already saved.
+		in.Name = existing.Name
+	}
+	updated := *existing
+	row.Name = existing.Name
+	columns := []struct {
+		field    string
+		current  *string
+		incoming string
+	}{
+		{"name", &updated.Name, in.Name},
+		{"phone", &updated.Phone, in.Phone},
+		{"address", &updated.Address, in.Address},
+		{"email", &updated.Email, in.Email},
+	}
+	changed := false
+	for _, c := range columns {
+		if merge(c.field, c.current, c.incoming) {
+			changed = true
+		}
+	}
+	if changed {
+		_, err := tx.ExecContext(ctx, `UPDATE accounts SET name = ?, phone = ?, address = ?, email = ? WHERE id = ?`,
+			updated.Name, nullString(updated.Phone), nullString(updated.Address), nullString(updated.Email), existing.ID)
+		if err != nil {
+			if isUniqueConstraint(err) {
+				return fmt.Errorf("cannot rename '%s': duplicate account '%s'", existing.Name, updated.Name)
+			}
+			return fmt.Errorf("update account: %w", err)
+		}
+		row.Name = updated.Name
+	}
+	now := time.Now()
+	if merge("status", &updated.Status, in.Status) {
+		if _, err := tx.ExecContext(ctx, `UPDATE accounts SET status = ? WHERE id = ?`, updated.Status, existing.ID); err != nil {
+			return fmt.Errorf("update account status: %w", err)
+		}
+		from := existing.Status
+		if from == "" {
+			from = "none"
+		}
+		if err := recordStatusChange(ctx, tx, existing.ID, from, updated.Status, in.Creator, now); err != nil {
+			return err
+		}
+	}
+	if merge("decision maker", &updated.DecisionMaker, in.DecisionMaker) {
+		if err := setPrimaryContactName(ctx, tx, existing.ID, updated.DecisionMaker, in.Creator, now); err != nil {
+			return err
+		}
+	}
+
+	have := map[string]bool{}
+	for _, tag := range existing.Tags {
+		have[strings.ToLower(tag)] = true
+	}
+	var tags []string
+	for _, tag := range rec.tags {
+		if norm, err := NormalizeTag(tag); err != nil || !have[norm] {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > 0 {
+		if err := addAccountTags(ctx, tx, existing.ID, tags...); err != nil {
+			row.Problems = append(row.Problems, fmt.Sprintf("tags error: %v", err))
+		} else {
+			row.Changed = append(row.Changed, "tags")
+		}
+	}
+
+	if len(rec.values) > 0 {
+		current, err := accountFieldValues(ctx, tx, existing.ID)
+		if err != nil {
+			return err
+		}
+		values := map[int64]string{}
+		for _, field := range im.custom {
+			v, ok := rec.values[field.ID]
+			if !ok {
+				continue
+			}
+			cur := current[field.ID]
+			if merge(field.Name, &cur, v) {
+				values[field.ID] = cur
+			}
+		}
+		if err := setAccountFieldValues(ctx, tx, existing.ID, values); err != nil {
+			row.Problems = append(row.Problems, fmt.Sprintf("custom fields error: %v", err))
+		}
+	}
+
+	if rec.note != "" {
+		var dup int
+		err := tx.QueryRowContext(ctx, `SELECT 1 FROM notes WHERE account_id = ? AND content = ? AND deleted_at IS NULL LIMIT 1`, existing.ID, rec.note).Scan(&dup)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			if im.addNote(ctx, row, existing.ID, rec) {
+				row.Changed = append(row.Changed, "note")
+			}
+		case err != nil:
+			return fmt.Errorf("find note: %w", err)
+		}
+	}
+
+	row.Action = ImportUnchanged
+	if len(row.Changed) > 0 {
+		row.Action = ImportUpdated
+	}
+	return nil
+}
+
+// addNote links the record's note to an account and reports whether it was
+// written.
+func (im *accountImport) addNote(ctx context.Context, row *ImportRow, accountID int64, rec *importRecord) bool {
+	note := Note{
+		Content:   rec.note,
+		AccountID: sql.NullInt64{Int64: accountID, Valid: true},
+		Creator:   rec.account.Creator,
+		CreatedAt: rec.account.CreatedAt,
+	}
+	if err := insertNote(ctx, im.tx, &note); err != nil {
+		row.Problems = append(row.Problems, fmt.Sprintf("note error: %v", err))
+		return false
+	}
+	im.result.Notes++
+	return true
+}
+
+// copyName returns the first of "name (2)", "name (3)" and so on that no
+// account uses, trashed accounts included.
+func copyName(ctx context.Context, tx *sql.Tx, name string) (string, error) {
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)", name, n)
+		var taken int
+		err := tx.QueryRowContext(ctx, `SELECT 1 FROM accounts WHERE lower(name) = lower(?) LIMIT 1`, candidate).Scan(&taken)
+		if errors.Is(err, sql.ErrNoRows) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("find free name: %w", err)
+		}
+	}
+}
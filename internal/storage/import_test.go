@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestImportDryRunAndMapping(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	csv := "Company,Tel,Favourite colour\nAcme,555-555-5555,blue\n,555-555-0000,red\n"
+	res, err := s.ImportAccountsCSV(ctx, strings.NewReader(csv), ImportOptions{Creator: "tester", PhoneCountry: "US", DryRun: true})
+	if !errors.Is(err, ErrImportNoName) {
+		t.Fatalf("import without a name column: err = %v, want ErrImportNoName", err)
+	}
+	if len(res.Columns) != 3 || res.Columns[0].Target != "" {
+		t.Fatalf("detected columns = %+v, want three unmapped", res.Columns)
+	}
+
+	opts := ImportOptions{Creator: "tester", PhoneCountry: "US", DryRun: true, Columns: map[int]string{0: "name", 1: "phone"}}
+	res, err = s.ImportAccountsCSV(ctx, strings.NewReader(csv), opts)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if res.Created != 1 || res.Skipped != 1 {
+		t.Errorf("dry run created %d and skipped %d, want 1 and 1: %+v", res.Created, res.Skipped, res.Rows)
+	}
+	if len(res.Rows) != 2 || res.Rows[1].Line != 3 || len(res.Rows[1].Problems) == 0 {
+		t.Errorf("rows = %+v, want line 3 skipped with a problem", res.Rows)
+	}
+	if accounts, _ := s.ListAccounts(ctx); len(accounts) != 0 {
+		t.Fatalf("dry run wrote accounts: %+v", accounts)
+	}
+
+	opts.DryRun = false
+	if _, err := s.ImportAccountsCSV(ctx, strings.NewReader(csv), opts); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	account, err := s.AccountByName(ctx, "Acme")
+	if err != nil {
+		t.Fatalf("find account: %v", err)
+	}
+	if account.Phone != "+15555555555" {
+		t.Errorf("phone = %q, want it in E.164 form", account.Phone)
+	}
+}
+
+func TestImportStrategies(t *testing.T) {
+	csv := "name,phone,email\nacme,555-555-5555,sales@acme.test\n"
+	tests := []struct {
+		strategy string
+		action   string
+		name     string
+		phone    string
+		email    string
+	}{
+		{strategy: ImportSkipExisting, action: ImportSkipped, name: "Acme", email: "info@acme.test"},
+		{strategy: ImportFillBlanks, action: ImportUpdated, name: "Acme", phone: "+15555555555", email: "info@acme.test"},
+		{strategy: ImportOverwrite, action: ImportUpdated, name: "Acme", phone: "+15555555555", email: "sales@acme.test"},
+		{strategy: ImportCopy, action: ImportCreated, name: "acme (2)", phone: "+15555555555", email: "sales@acme.test"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			s := openTestStore(t)
+			ctx := context.Background()
+			existing := Account{Name: "Acme", Email: "info@acme.test", Creator: "tester"}
+			if err := s.CreateAccount(ctx, &existing); err != nil {
+				t.Fatalf("create account: %v", err)
+			}
+			res, err := s.ImportAccountsCSV(ctx, strings.NewReader(csv), ImportOptions{Creator: "tester", PhoneCountry: "US", Strategy: tt.strategy})
+			if err != nil {
+				t.Fatalf("import: %v", err)
+			}
+			if len(res.Rows) != 1 || res.Rows[0].Action != tt.action {
+				t.Fatalf("rows = %+v, want one %s", res.Rows, tt.action)
+			}
+			got, err := s.AccountByName(ctx, tt.name)
+			if err != nil {
+				t.Fatalf("find %q: %v", tt.name, err)
+			}
+			if got.Phone != tt.phone || got.Email != tt.email {
+				t.Errorf("%s has phone %q and email %q, want %q and %q", got.Name, got.Phone, got.Email, tt.phone, tt.email)
+			}
+		})
+	}
+}
+
+func TestImportMatchesByEmail(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	existing := Account{Name: "Acme Inc", Email: "info@acme.test", Creator: "tester"}
+	if err := s.CreateAccount(ctx, &existing); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	csv := "name,email,phone\nACME,INFO@acme.test,555-555-5555\n"
+	res, err := s.ImportAccountsCSV(ctx, strings.NewReader(csv), ImportOptions{Creator: "tester", PhoneCountry: "US",
+		Strategy: ImportFillBlanks, MatchBy: ImportMatchEmail})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if res.Updated != 1 || res.Created != 0 {
+		t.Fatalf("updated %d and created %d, want the account matched by email: %+v", res.Updated, res.Created, res.Rows)
+	}
+	got, err := s.AccountByID(ctx, existing.ID)
+	if err != nil {
+		t.Fatalf("load account: %v", err)
+	}
+	if got.Name != "Acme Inc" || got.Phone != "+15555555555" {
+		t.Errorf("account = %q with phone %q, want the name kept and the phone filled", got.Name, got.Phone)
+	}
+}
+
+func TestImportReportsTrashedMatch(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	account := createTestAccount(t, s, "Acme")
+	if _, err := s.DeleteAccount(ctx, account.ID, false); err != nil {
+		t.Fatalf("delete account: %v", err)
+	}
+	csv := "name,phone\nACME,555-555-5555\n"
+	for _, strategy := range []string{ImportSkipExisting, ImportFillBlanks, ImportOverwrite} {
+		res, err := s.ImportAccountsCSV(ctx, strings.NewReader(csv), ImportOptions{Creator: "tester", PhoneCountry: "US", Strategy: strategy, DryRun: true})
+		if err != nil {
+			t.Fatalf("%s: import: %v", strategy, err)
+		}
+		if res.Skipped != 1 || len(res.Rows[0].Problems) != 1 || !strings.Contains(res.Rows[0].Problems[0], "in the trash") {
+			t.Errorf("%s: rows = %+v, want the row skipped as matching a trashed account", strategy, res.Rows)
+		}
+	}
+
+	res, err := s.ImportAccountsCSV(ctx, strings.NewReader(csv), ImportOptions{Creator: "tester", PhoneCountry: "US", Strategy: ImportCopy})
+	if err != nil {
+		t.Fatalf("copy: import: %v", err)
+	}
+	if res.Created != 1 || res.Rows[0].Name != "ACME (2)" {
+		t.Errorf("copy: rows = %+v, want ACME (2) created", res.Rows)
+	}
+}
+
+func TestImportTarget(t *testing.T) {
+	fields := []CustomField{{ID: 7, Name: "Renewal Date"}}
+	tests := []struct{ in, want string }{
+		{"Account Name", "name"},
+		{"E-mail Address", "email"},
+		{"DM", "decision_maker"},
+		{"Created", "created_at"},
+		{"Labels", "tags"},
+		{"Lifecycle", "status"},
+		{"renewal_date", "field:7"},
+		{"Favourite colour", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := ImportTarget(tt.in, fields); got != tt.want {
+			t.Errorf("ImportTarget(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+	if f, ok := ImportTargetField("field:7", fields); !ok || f.Name != "Renewal Date" {
+		t.Errorf("ImportTargetField(field:7) = %+v, %v; want Renewal Date", f, ok)
+	}
+	if _, ok := ImportTargetField("phone", fields); ok {
+		t.Errorf("ImportTargetField(phone) found a field")
+	}
+}
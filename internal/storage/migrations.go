@@ -0,0 +1,430 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrSchemaTooNew indicates the database was migrated by a newer build.
+var ErrSchemaTooNew = errors.New("database schema is newer than this build")
+
+// migration is a single numbered schema change. Versions must be strictly
+// increasing and never reused once released.
+type migration struct {
+	version int
+	name    string
+	stmts   []string
+}
+
+// migrations lists every schema change in the order it must be applied.
+// Append new entries; never edit one that has shipped.
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "initial schema",
+		stmts: []string{
+			`CREATE TABLE IF NOT EXISTS accounts (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            name TEXT NOT NULL UNIQUE,
+            phone TEXT,
+            address TEXT,
+            email TEXT,
+            decision_maker TEXT,
+            creator TEXT NOT NULL,
+            created_at TEXT NOT NULL
+        );`,
+			`CREATE TABLE IF NOT EXISTS notes (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            content TEXT NOT NULL,
+            account_id INTEGER,
+            creator TEXT NOT NULL,
+            created_at TEXT NOT NULL,
+            FOREIGN KEY(account_id) REFERENCES accounts(id) ON DELETE SET NULL
+        );`,
+			`CREATE TABLE IF NOT EXISTS events (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            title TEXT NOT NULL,
+            details TEXT,
+            event_time TEXT NOT NULL,
+            account_id INTEGER,
+            creator TEXT NOT NULL,
+            created_at TEXT NOT NULL,
+            FOREIGN KEY(account_id) REFERENCES accounts(id) ON DELETE SET NULL
+        );`,
+		},
+	},
+	{
+		version: 2,
+		name:    "contacts",
+		stmts: []string{
+			`CREATE TABLE contacts (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            account_id INTEGER NOT NULL,
+            name TEXT NOT NULL,
+            title TEXT,
+            email TEXT,
+            phone TEXT,
+            role TEXT,
+            is_primary INTEGER NOT NULL DEFAULT 0,
+            creator TEXT NOT NULL,
+            created_at TEXT NOT NULL,
+            FOREIGN KEY(account_id) REFERENCES accounts(id) ON DELETE CASCADE
+        );`,
+			`CREATE INDEX idx_contacts_account ON contacts(account_id);`,
+			`INSERT INTO contacts (account_id, name, role, is_primary, creator, created_at)
+            SELECT id, trim(decision_maker), 'Decision maker', 1, creator, created_at
+            FROM accounts WHERE trim(COALESCE(decision_maker, '')) != '';`,
+			`ALTER TABLE accounts DROP COLUMN decision_maker;`,
+		},
+	},
+	{
+		version: 3,
+		name:    "deals",
+		stmts: []string{
+			`CREATE TABLE deals (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            account_id INTEGER NOT NULL,
+            title TEXT NOT NULL,
+            amount_cents INTEGER NOT NULL DEFAULT 0,
+            currency TEXT NOT NULL DEFAULT 'USD',
+            stage TEXT NOT NULL,
+            probability INTEGER NOT NULL DEFAULT 0,
+            expected_close TEXT,
+            owner TEXT,
+            creator TEXT NOT NULL,
+            created_at TEXT NOT NULL,
+            updated_at TEXT NOT NULL,
+            FOREIGN KEY(account_id) REFERENCES accounts(id) ON DELETE CASCADE
+        );`,
+			`CREATE INDEX idx_deals_account ON deals(account_id);`,
+			`CREATE TABLE deal_stage_changes (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            deal_id INTEGER NOT NULL,
+            from_stage TEXT,
+            to_stage TEXT NOT NULL,
+            actor TEXT NOT NULL,
+            changed_at TEXT NOT NULL,
+            FOREIGN KEY(deal_id) REFERENCES deals(id) ON DELETE CASCADE
+        );`,
+			`CREATE INDEX idx_deal_stage_changes_deal ON deal_stage_changes(deal_id);`,
+		},
+	},
+	{
+		version: 4,
+		name:    "tasks",
+		stmts: []string{
+			`CREATE TABLE tasks (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            title TEXT NOT NULL,
+            details TEXT,
+            due_at TEXT,
+            priority INTEGER NOT NULL DEFAULT 1,
+            done_at TEXT,
+            account_id INTEGER,
+            creator TEXT NOT NULL,
+            created_at TEXT NOT NULL,
+            FOREIGN KEY(account_id) REFERENCES accounts(id) ON DELETE SET NULL
+        );`,
+			`CREATE INDEX idx_tasks_due ON tasks(done_at, due_at);`,
+		},
+	},
+	{
+		version: 5,
+		name:    "soft delete",
+		stmts: []string{
+			`ALTER TABLE accounts ADD COLUMN deleted_at TEXT;`,
+			`ALTER TABLE notes ADD COLUMN deleted_at TEXT;`,
+			`ALTER TABLE events ADD COLUMN deleted_at TEXT;`,
+			`ALTER TABLE tasks ADD COLUMN deleted_at TEXT;`,
+		},
+	},
+	{
+		version: 6,
+		name:    "tags",
+		stmts: []string{
+			`CREATE TABLE tags (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+            created_at TEXT NOT NULL
+        );`,
+			`CREATE TABLE account_tags (
+            account_id INTEGER NOT NULL,
+            tag_id INTEGER NOT NULL,
+            PRIMARY KEY(account_id, tag_id),
+            FOREIGN KEY(account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+            FOREIGN KEY(tag_id) REFERENCES tags(id) ON DELETE CASCADE
+        );`,
+			`CREATE INDEX idx_account_tags_tag ON account_tags(tag_id);`,
+		},
+	},
+	{
+		version: 7,
+		name:    "custom fields",
+		stmts: []string{
+			`CREATE TABLE custom_fields (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+            type TEXT NOT NULL,
+            options TEXT,
+            position INTEGER NOT NULL,
+            created_at TEXT NOT NULL
+        );`,
+			`CREATE TABLE account_field_values (
+            account_id INTEGER NOT NULL,
+            field_id INTEGER NOT NULL,
+            value TEXT NOT NULL,
+            PRIMARY KEY(account_id, field_id),
+            FOREIGN KEY(account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+            FOREIGN KEY(field_id) REFERENCES custom_fields(id) ON DELETE CASCADE
+        );`,
+		},
+	},
+	{
+		version: 8,
+		name:    "account status",
+		stmts: []string{
+			`ALTER TABLE accounts ADD COLUMN status TEXT;`,
+			`CREATE TABLE account_status_changes (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            account_id INTEGER NOT NULL,
+            from_status TEXT,
+            to_status TEXT NOT NULL,
+            actor TEXT NOT NULL,
+            changed_at TEXT NOT NULL,
+            FOREIGN KEY(account_id) REFERENCES accounts(id) ON DELETE CASCADE
+        );`,
+			`CREATE INDEX idx_account_status_changes_account ON account_status_changes(account_id);`,
+		},
+	},
+	{
+		version: 9,
+		name:    "account hierarchy",
+		stmts: []string{
+			`ALTER TABLE accounts ADD COLUMN parent_id INTEGER REFERENCES accounts(id) ON DELETE SET NULL;`,
+			`CREATE INDEX idx_accounts_parent ON accounts(parent_id);`,
+		},
+	},
+	{
+		version: 10,
+		name:    "account merges",
+		stmts: []string{
+			`CREATE TABLE account_merges (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            account_id INTEGER NOT NULL,
+            merged_id INTEGER NOT NULL,
+            merged_name TEXT NOT NULL,
+            actor TEXT NOT NULL,
+            merged_at TEXT NOT NULL,
+            FOREIGN KEY(account_id) REFERENCES accounts(id) ON DELETE CASCADE
+        );`,
+			`CREATE INDEX idx_account_merges_account ON account_merges(account_id);`,
+		},
+	},
+	{
+		version: 11,
+		name:    "audit log",
+		stmts: []string{
+			`CREATE TABLE audit_log (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            entity TEXT NOT NULL,
+            entity_id INTEGER NOT NULL,
+            account_id INTEGER,
+            action TEXT NOT NULL,
+            changes TEXT NOT NULL DEFAULT '{}',
+            actor TEXT NOT NULL DEFAULT '',
+            created_at TEXT NOT NULL
+        );`,
+			`CREATE INDEX idx_audit_log_account ON audit_log(account_id, id);`,
+			`CREATE INDEX idx_audit_log_created ON audit_log(created_at);`,
+			`CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log BEGIN SELECT RAISE(ABORT, 'audit log is append-only'); END;`,
+			`CREATE TRIGGER audit_log_no_delete BEFORE DELETE ON audit_log BEGIN SELECT RAISE(ABORT, 'audit log is append-only'); END;`,
+			`CREATE TABLE audit_actor (
+            id INTEGER PRIMARY KEY CHECK (id = 1),
+            name TEXT NOT NULL
+        );`,
+		},
+	},
+	{
+		version: 12,
+		name:    "recurring events",
+		stmts: []string{
+			`ALTER TABLE events ADD COLUMN recurrence TEXT;`,
+			`CREATE TABLE event_exceptions (
+            event_id INTEGER NOT NULL,
+            occurrence TEXT NOT NULL,
+            cancelled INTEGER NOT NULL DEFAULT 0,
+            title TEXT,
+            details TEXT,
+            event_time TEXT,
+            PRIMARY KEY(event_id, occurrence),
+            FOREIGN KEY(event_id) REFERENCES events(id) ON DELETE CASCADE
+        );`,
+		},
+	},
+	{
+		version: 13,
+		name:    "event details",
+		stmts: []string{
+			`ALTER TABLE events ADD COLUMN duration_minutes INTEGER NOT NULL DEFAULT 0;`,
+			`ALTER TABLE events ADD COLUMN location TEXT;`,
+			`CREATE TABLE event_attendees (
+            event_id INTEGER NOT NULL,
+            contact_id INTEGER NOT NULL,
+            PRIMARY KEY(event_id, contact_id),
+            FOREIGN KEY(event_id) REFERENCES events(id) ON DELETE CASCADE,
+            FOREIGN KEY(contact_id) REFERENCES contacts(id) ON DELETE CASCADE
+        );`,
+			`CREATE INDEX idx_event_attendees_contact ON event_attendees(contact_id);`,
+		},
+	},
+	{
+		version: 14,
+		name:    "trash batches",
+		stmts: []string{
+			`ALTER TABLE accounts ADD COLUMN trash_batch TEXT;`,
+			`ALTER TABLE notes ADD COLUMN trash_batch TEXT;`,
+			`ALTER TABLE events ADD COLUMN trash_batch TEXT;`,
+			`ALTER TABLE tasks ADD COLUMN trash_batch TEXT;`,
+			// Rows already in the trash were tied to their account by an
+			// equal deleted_at; turn that into a batch per account.
+			`UPDATE accounts SET trash_batch = 'account-' || id WHERE deleted_at IS NOT NULL;`,
+			`UPDATE notes SET trash_batch = 'account-' || account_id WHERE deleted_at IS NOT NULL
+            AND deleted_at = (SELECT a.deleted_at FROM accounts a WHERE a.id = notes.account_id);`,
+			`UPDATE events SET trash_batch = 'account-' || account_id WHERE deleted_at IS NOT NULL
+            AND deleted_at = (SELECT a.deleted_at FROM accounts a WHERE a.id = events.account_id);`,
+			`UPDATE tasks SET trash_batch = 'account-' || account_id WHERE deleted_at IS NOT NULL
+            AND deleted_at = (SELECT a.deleted_at FROM accounts a WHERE a.id = tasks.account_id);`,
+		},
+	},
+	{
+		version: 15,
+		name:    "event time zones",
+		stmts: []string{
+			// Existing series stay blank and keep repeating in the zone
+			// they are shown in, as before.
+			`ALTER TABLE events ADD COLUMN time_zone TEXT;`,
+		},
+	},
+}
+
+// latestSchemaVersion reports the highest migration known to this build.
+func latestSchemaVersion() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].version
+}
+
+// SchemaVersion returns the most recently applied migration version.
+func (s *Store) SchemaVersion(ctx context.Context) (int, error) {
+	var version int
+	if err := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// ensureMigrationsTable creates the table recording applied migrations.
+func (s *Store) ensureMigrationsTable(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+            version INTEGER PRIMARY KEY,
+            name TEXT NOT NULL,
+            applied_at TEXT NOT NULL
+        );`); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) migrate(ctx context.Context) error {
+	if err := s.ensureMigrationsTable(ctx); err != nil {
+		return err
+	}
+
+	current, err := s.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	latest := latestSchemaVersion()
+	if current > latest {
+		return fmt.Errorf("%w (database v%d, build supports v%d)", ErrSchemaTooNew, current, latest)
+	}
+
+	var pending []migration
+	for _, m := range migrations {
+		if m.version > current {
+			pending = append(pending, m)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	hasData, err := s.hasUserTables(ctx)
+	if err != nil {
+		return err
+	}
+	if hasData {
+		if _, err := s.backup(ctx, current); err != nil {
+			return err
+		}
+	}
+
+	for _, m := range pending {
+		if err := s.applyMigration(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) applyMigration(ctx context.Context, m migration) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin migration %d: %w", m.version, err)
+	}
+	for _, stmt := range m.stmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d (%s): %w", m.version, m.name, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+		m.version, m.name, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("record migration %d: %w", m.version, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit migration %d: %w", m.version, err)
+	}
+	return nil
+}
+
+// hasUserTables reports whether the database holds anything worth backing up.
+func (s *Store) hasUserTables(ctx context.Context) (bool, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master
+        WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name != 'schema_migrations'`).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("inspect schema: %w", err)
+	}
+	return count > 0, nil
+}
+
+// backup writes a consistent copy of the database next to the original file
+// and returns its path.
+func (s *Store) backup(ctx context.Context, version int) (string, error) {
+	if s.path == "" {
+		return "", nil
+	}
+	stamp := time.Now().UTC().Format("20060102T150405")
+	dest := fmt.Sprintf("%s.v%d-%s.bak", s.path, version, stamp)
+	quoted := "'" + strings.ReplaceAll(dest, "'", "''") + "'"
+	if _, err := s.db.ExecContext(ctx, "VACUUM INTO "+quoted); err != nil {
+		return "", fmt.Errorf("backup database: %w", err)
+	}
+	return dest, nil
+}
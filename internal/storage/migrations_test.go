@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrateFreshDatabase(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	version, err := s.SchemaVersion(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != latestSchemaVersion() {
+		t.Fatalf("schema version = %d, want %d", version, latestSchemaVersion())
+	}
+	var applied int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations`).Scan(&applied); err != nil {
+		t.Fatal(err)
+	}
+	if applied != len(migrations) {
+		t.Fatalf("%d migrations recorded, want %d", applied, len(migrations))
+	}
+	if backups, _ := filepath.Glob(s.path + ".v*.bak"); len(backups) != 0 {
+		t.Fatalf("fresh database was backed up: %v", backups)
+	}
+}
+
+func TestMigrateBaselineSchema(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	path := filepath.Join(dir, "crmterm", "crmterm.db")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// A database written before migrations existed: the baseline tables
+	// and no schema_migrations.
+	db, err := sql.Open(driverName, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range migrations[0].stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, row := range [][]string{{"Acme", " Jeff "}, {"Beta", "  "}, {"Gamma", ""}} {
+		if _, err := db.Exec(`INSERT INTO accounts (name, decision_maker, creator, created_at) VALUES (?, ?, 'me', '2024-01-01T00:00:00Z')`, row[0], row[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.Exec(`INSERT INTO notes (content, account_id, creator, created_at) VALUES ('hello', 1, 'me', '2024-01-02T00:00:00Z')`); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	ctx := context.Background()
+	s, err := Open(ctx)
+	if err != nil {
+		t.Fatalf("open baseline database: %v", err)
+	}
+	defer s.Close()
+	if version, _ := s.SchemaVersion(ctx); version != latestSchemaVersion() {
+		t.Fatalf("schema version = %d, want %d", version, latestSchemaVersion())
+	}
+	backups, _ := filepath.Glob(path + ".v0-*.bak")
+	if len(backups) != 1 {
+		t.Fatalf("want one v0 backup, got %v", backups)
+	}
+
+	acme, err := s.AccountByName(ctx, "Acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acme.DecisionMaker != "Jeff" {
+		t.Errorf("decision maker = %q, want Jeff", acme.DecisionMaker)
+	}
+	contacts, err := s.ListContacts(ctx, acme.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contacts) != 1 || contacts[0].Name != "Jeff" || !contacts[0].Primary || contacts[0].Role != primaryContactRole {
+		t.Fatalf("contacts = %+v, want one primary 'Jeff'", contacts)
+	}
+	for _, name := range []string{"Beta", "Gamma"} {
+		a, err := s.AccountByName(ctx, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if contacts, _ := s.ListContacts(ctx, a.ID); len(contacts) != 0 {
+			t.Errorf("%s: blank decision maker became contacts %+v", name, contacts)
+		}
+	}
+	var columns []string
+	rows, err := s.db.QueryContext(ctx, `SELECT name FROM pragma_table_info('accounts')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+		var name string
+		rows.Scan(&name)
+		columns = append(columns, name)
+	}
+	rows.Close()
+	if strings.Contains(strings.Join(columns, ","), "decision_maker") {
+		t.Errorf("accounts still has decision_maker: %v", columns)
+	}
+	var notes int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM notes WHERE account_id = ? AND deleted_at IS NULL`, acme.ID).Scan(&notes); err != nil {
+		t.Fatal(err)
+	}
+	if notes != 1 {
+		t.Errorf("%d notes on Acme, want the baseline note", notes)
+	}
+}
+
+func TestMigrateRejectsNewerSchema(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	if _, err := s.db.ExecContext(ctx, `INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, 'future', '2030-01-01T00:00:00Z')`, latestSchemaVersion()+1); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+	if _, err := Open(ctx); !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("open = %v, want ErrSchemaTooNew", err)
+	}
+}
@@ -0,0 +1,503 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence frequencies, named as in RFC 5545 RRULEs.
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+	FreqYearly  = "YEARLY"
+)
+
+// EventLookBack and EventLookAhead are the days around today in which
+// SplitEvents lists the occurrences of recurring events.
+const (
+	EventLookBack  = 30
+	EventLookAhead = 90
+)
+
+// maxOccurrenceScan bounds how many slots a rule is stepped through when
+// expanding, so a daily rule decades in the past stays cheap.
+const maxOccurrenceScan = 20000
+
+// Recurrence is the repeat rule of an event, a subset of an RFC 5545 RRULE:
+// FREQ, INTERVAL, BYDAY, UNTIL and COUNT. Occurrences keep the wall-clock
+// time of the first one in its time zone, so a weekly 09:00 meeting stays at
+// 09:00 across daylight saving changes.
+type Recurrence struct {
+	Freq     string
+	Interval int
+	// ByDay limits daily rules to these weekdays and lists the days a
+	// weekly rule falls on. Empty means the weekday of the first occurrence.
+	ByDay []time.Weekday
+	// Until is the last moment an occurrence may start; zero for no end.
+	Until time.Time
+	// Count caps the number of occurrences; zero for no cap.
+	Count int
+}
+
+var rruleDays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// ParseRecurrence reads an RRULE such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH".
+// A leading "RRULE:" is allowed.
+func ParseRecurrence(rule string) (Recurrence, error) {
+	var r Recurrence
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	for _, part := range strings.Split(rule, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return Recurrence{}, fmt.Errorf("invalid recurrence part %q", part)
+		}
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.Freq = strings.ToUpper(value)
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return Recurrence{}, fmt.Errorf("invalid recurrence interval %q", value)
+			}
+			r.Interval = n
+		case "BYDAY":
+			for _, day := range strings.Split(strings.ToUpper(value), ",") {
+				idx := -1
+				for i, name := range rruleDays {
+					if day == name {
+						idx = i
+					}
+				}
+				if idx < 0 {
+					return Recurrence{}, fmt.Errorf("invalid recurrence weekday %q", day)
+				}
+				r.ByDay = append(r.ByDay, time.Weekday(idx))
+			}
+		case "UNTIL":
+			t, err := time.Parse("20060102T150405Z", value)
+			if err != nil {
+				if t, err = time.Parse("20060102", value); err != nil {
+					return Recurrence{}, fmt.Errorf("invalid recurrence end %q", value)
+				}
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			r.Until = t
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return Recurrence{}, fmt.Errorf("invalid recurrence count %q", value)
+			}
+			r.Count = n
+		default:
+			return Recurrence{}, fmt.Errorf("unsupported recurrence part %q", key)
+		}
+	}
+	if r.Interval == 0 {
+		r.Interval = 1
+	}
+	return r, r.validate()
+}
+
+func (r Recurrence) validate() error {
+	switch r.Freq {
+	case FreqDaily, FreqWeekly:
+	case FreqMonthly, FreqYearly:
+		if len(r.ByDay) > 0 {
+			return fmt.Errorf("weekdays only apply to daily and weekly recurrences")
+		}
+	case "":
+		return fmt.Errorf("recurrence frequency required")
+	default:
+		return fmt.Errorf("unsupported recurrence frequency %q", r.Freq)
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return fmt.Errorf("a recurrence ends either by count or by date, not both")
+	}
+	return nil
+}
+
+// String formats the rule as an RRULE.
+func (r Recurrence) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			days[i] = rruleDays[d]
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Between returns the start times of the occurrences of a series starting
+// at start that fall in [from, to), in start's time zone.
+func (r Recurrence) Between(start, from, to time.Time) []time.Time {
+	interval := r.Interval
+	if interval <= 0 {
+		interval = 1
+	}
+	days := map[time.Weekday]bool{}
+	for _, d := range r.ByDay {
+		days[d] = true
+	}
+	if len(days) == 0 && r.Freq == FreqWeekly {
+		days[start.Weekday()] = true
+	}
+	var slots []time.Time
+	n := 0
+	// emit reports whether stepping should go on after candidate t.
+	emit := func(t time.Time) bool {
+		if t.Before(start) {
+			return true
+		}
+		if !r.Until.IsZero() && t.After(r.Until) || !t.Before(to) {
+			return false
+		}
+		n++
+		if !t.Before(from) {
+			slots = append(slots, t)
+		}
+		return r.Count == 0 || n < r.Count
+	}
+	y, m, d := start.Date()
+	hh, mm, ss := start.Clock()
+	loc := start.Location()
+	for step := 0; step < maxOccurrenceScan; step++ {
+		k := step * interval
+		switch r.Freq {
+		case FreqDaily:
+			t := time.Date(y, m, d+k, hh, mm, ss, 0, loc)
+			if len(days) > 0 && !days[t.Weekday()] {
+				if t.Before(to) {
+					continue
+				}
+				return slots
+			}
+			if !emit(t) {
+				return slots
+			}
+		case FreqWeekly:
+			// Weeks start on Monday, as in the RRULE default.
+			monday := d - (int(start.Weekday())+6)%7
+			for offset := 0; offset < 7; offset++ {
+				t := time.Date(y, m, monday+7*k+offset, hh, mm, ss, 0, loc)
+				if days[t.Weekday()] && !emit(t) {
+					return slots
+				}
+			}
+		case FreqMonthly, FreqYearly:
+			month, year := m+time.Month(k), y
+			if r.Freq == FreqYearly {
+				month, year = m, y+k
+			}
+			t := time.Date(year, month, d, hh, mm, ss, 0, loc)
+			// Months without the start's day (the 31st, Feb 29) are skipped.
+			if t.Day() != d {
+				if t.Before(to) {
+					continue
+				}
+				return slots
+			}
+			if !emit(t) {
+				return slots
+			}
+		default:
+			return slots
+		}
+	}
+	return slots
+}
+
+// EventException changes or cancels one occurrence of a recurring event.
+// Blank fields keep the series' values.
+type EventException struct {
+	// Occurrence is the start the occurrence had under the series' rule.
+	Occurrence time.Time
+	Cancelled  bool
+	Title      string
+	Details    string
+	EventTime  time.Time
+}
+
+// Rule parses the event's recurrence; ok is false for one-off events.
+func (e Event) Rule() (Recurrence, bool) {
+	if e.Recurrence == "" {
+		return Recurrence{}, false
+	}
+	r, err := ParseRecurrence(e.Recurrence)
+	return r, err == nil
+}
+
+// Occurrences expands a recurring event into one Event per occurrence
+// starting in [from, to), with exceptions applied and cancelled occurrences
+// left out. Slots are computed in the series' own zone and returned in loc,
+// so the same occurrence, and the exception keyed to it, turns up whatever
+// zone the events are shown in. loc stands in for the series' zone only
+// for events saved without one. A one-off event is returned as is.
+func (e Event) Occurrences(from, to time.Time, loc *time.Location) []Event {
+	rule, ok := e.Rule()
+	if !ok {
+		return []Event{e}
+	}
+	exceptions := make(map[int64]EventException, len(e.Exceptions))
+	for _, ex := range e.Exceptions {
+		exceptions[ex.Occurrence.Unix()] = ex
+	}
+	var out []Event
+	for _, slot := range rule.Between(e.EventTime.In(e.zone(loc)), from, to) {
+		occ := e
+		occ.Occurrence = slot.In(loc)
+		occ.EventTime = occ.Occurrence
+		occ.Exceptions = nil
+		if ex, ok := exceptions[slot.Unix()]; ok {
+			if ex.Cancelled {
+				continue
+			}
+			if ex.Title != "" {
+				occ.Title = ex.Title
+			}
+			if ex.Details != "" {
+				occ.Details = ex.Details
+			}
+			if !ex.EventTime.IsZero() {
+				occ.EventTime = ex.EventTime
+			}
+		}
+		out = append(out, occ)
+	}
+	return out
+}
+
+// zone is the location the series repeats in, or fallback when the event
+// has no known zone of its own.
+func (e Event) zone(fallback *time.Location) *time.Location {
+	if e.TimeZone != "" {
+		if loc, err := time.LoadLocation(e.TimeZone); err == nil {
+			return loc
+		}
+	}
+	return fallback
+}
+
+// defaultTimeZone takes the zone of EventTime as the series' zone when none
+// is set, and checks that a set one is known.
+func (e *Event) defaultTimeZone() error {
+	if e.TimeZone == "" {
+		if name := e.EventTime.Location().String(); name != "Local" {
+			e.TimeZone = name
+		}
+		return nil
+	}
+	if _, err := time.LoadLocation(e.TimeZone); err != nil {
+		return fmt.Errorf("unknown event time zone %q", e.TimeZone)
+	}
+	return nil
+}
+
+// ExpandEvents replaces every recurring event with its occurrences in
+// [from, to). One-off events are kept whatever their time.
+func ExpandEvents(events []Event, from, to time.Time) []Event {
+	var out []Event
+	for _, e := range events {
+		out = append(out, e.Occurrences(from, to, from.Location())...)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].EventTime.Before(out[j].EventTime) })
+	return out
+}
+
+// SetEventOccurrence changes a single occurrence of a recurring event,
+// leaving the rest of the series alone. Blank title and details keep the
+// series' values.
+func (s *Store) SetEventOccurrence(ctx context.Context, eventID int64, ex EventException) error {
+	if ex.Occurrence.IsZero() {
+		return fmt.Errorf("occurrence required")
+	}
+	if err := saveEventException(ctx, s.db, eventID, ex); err != nil {
+		if strings.Contains(err.Error(), "FOREIGN KEY") {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// saveEventException inserts or replaces the exception for one occurrence.
+func saveEventException(ctx context.Context, db execer, eventID int64, ex EventException) error {
+	var eventTime interface{}
+	if !ex.EventTime.IsZero() {
+		eventTime = ex.EventTime.UTC().Format(time.RFC3339)
+	}
+	_, err := db.ExecContext(ctx, `INSERT INTO event_exceptions (event_id, occurrence, cancelled, title, details, event_time) VALUES (?, ?, ?, ?, ?, ?)
+        ON CONFLICT(event_id, occurrence) DO UPDATE SET cancelled = excluded.cancelled, title = excluded.title,
+            details = excluded.details, event_time = excluded.event_time`,
+		eventID, ex.Occurrence.UTC().Format(time.RFC3339), ex.Cancelled, nullString(ex.Title), nullString(ex.Details), eventTime)
+	if err != nil {
+		return fmt.Errorf("save event occurrence: %w", err)
+	}
+	return nil
+}
+
+// CancelEventOccurrence drops a single occurrence from a recurring event.
+func (s *Store) CancelEventOccurrence(ctx context.Context, eventID int64, occurrence time.Time) error {
+	return s.SetEventOccurrence(ctx, eventID, EventException{Occurrence: occurrence, Cancelled: true})
+}
+
+// moveEventExceptions carries a series' exceptions over an edit of its
+// start, rule or zone. While the rule repeats the same way an exception
+// moves with the start, keeping its distance from the first occurrence in
+// days; when the pattern changes it stays on its date. Either way it takes
+// the new time of day, and it is dropped when the new series has no
+// occurrence there. A series that stops repeating loses all of them.
+func moveEventExceptions(ctx context.Context, tx *sql.Tx, eventID int64, before, after Event) error {
+	newRule, ok := after.Rule()
+	if !ok {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM event_exceptions WHERE event_id = ?`, eventID); err != nil {
+			return fmt.Errorf("drop event exceptions: %w", err)
+		}
+		return nil
+	}
+	oldRule, _ := before.Rule()
+	if before.EventTime.Equal(after.EventTime) && before.TimeZone == after.TimeZone && oldRule.String() == newRule.String() {
+		return nil
+	}
+	rows, err := tx.QueryContext(ctx, `SELECT event_id, occurrence, cancelled, title, details, event_time FROM event_exceptions WHERE event_id = ? ORDER BY occurrence`, eventID)
+	if err != nil {
+		return fmt.Errorf("query event exceptions: %w", err)
+	}
+	var exceptions []EventException
+	for rows.Next() {
+		_, ex, err := scanEventException(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		exceptions = append(exceptions, ex)
+	}
+	if err := rows.Close(); err != nil {
+		return fmt.Errorf("read event exceptions: %w", err)
+	}
+	if len(exceptions) == 0 {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM event_exceptions WHERE event_id = ?`, eventID); err != nil {
+		return fmt.Errorf("drop event exceptions: %w", err)
+	}
+
+	newZone := after.zone(after.EventTime.Location())
+	oldZone := before.zone(newZone)
+	oldStart, newStart := before.EventTime.In(oldZone), after.EventTime.In(newZone)
+	pattern := func(r Recurrence) string {
+		return Recurrence{Freq: r.Freq, Interval: r.Interval, ByDay: r.ByDay}.String()
+	}
+	days := 0
+	if pattern(oldRule) == pattern(newRule) {
+		days = int(calendarDate(newStart).Sub(calendarDate(oldStart)).Hours() / 24)
+	}
+	hh, mm, ss := newStart.Clock()
+	kept := map[int64]bool{}
+	for _, ex := range exceptions {
+		y, m, d := ex.Occurrence.In(oldZone).Date()
+		target := time.Date(y, m, d+days, hh, mm, ss, 0, newZone)
+		if kept[target.Unix()] || len(newRule.Between(newStart, target, target.Add(time.Second))) == 0 {
+			continue
+		}
+		kept[target.Unix()] = true
+		if !ex.EventTime.IsZero() {
+			ex.EventTime = ex.EventTime.Add(target.Sub(ex.Occurrence))
+		}
+		ex.Occurrence = target
+		if err := saveEventException(ctx, tx, eventID, ex); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// calendarDate is t's date in its own zone as midnight UTC, so dates in
+// different zones can be counted apart in whole days.
+func calendarDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// loadEventExceptions attaches stored exceptions to the recurring events.
+func (s *Store) loadEventExceptions(ctx context.Context, events []Event) error {
+	index := map[int64]int{}
+	var ids []any
+	for i, e := range events {
+		if e.Recurrence != "" {
+			index[e.ID] = i
+			ids = append(ids, e.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	rows, err := s.db.QueryContext(ctx, `SELECT event_id, occurrence, cancelled, title, details, event_time FROM event_exceptions
+        WHERE event_id IN (`+placeholders+`) ORDER BY occurrence`, ids...)
+	if err != nil {
+		return fmt.Errorf("query event exceptions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		eventID, ex, err := scanEventException(rows)
+		if err != nil {
+			return err
+		}
+		i := index[eventID]
+		events[i].Exceptions = append(events[i].Exceptions, ex)
+	}
+	return rows.Err()
+}
+
+// scanEventException reads a row of event_id, occurrence, cancelled,
+// title, details and event_time.
+func scanEventException(row rowScanner) (int64, EventException, error) {
+	var eventID int64
+	var ex EventException
+	var occurrence string
+	var title, details, eventTime sql.NullString
+	if err := row.Scan(&eventID, &occurrence, &ex.Cancelled, &title, &details, &eventTime); err != nil {
+		return 0, EventException{}, fmt.Errorf("scan event exception: %w", err)
+	}
+	if t, err := time.Parse(time.RFC3339, occurrence); err == nil {
+		ex.Occurrence = t
+	}
+	if t, err := time.Parse(time.RFC3339, eventTime.String); err == nil {
+		ex.EventTime = t
+	}
+	ex.Title = nullStringToString(title)
+	ex.Details = nullStringToString(details)
+	return eventID, ex, nil
+}
+
+// normalizeRecurrence validates a stored rule and rewrites it canonically.
+func normalizeRecurrence(rule string) (interface{}, error) {
+	if strings.TrimSpace(rule) == "" {
+		return nil, nil
+	}
+	r, err := ParseRecurrence(rule)
+	if err != nil {
+		return nil, err
+	}
+	return r.String(), nil
+}
@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func loadZone(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("no zoneinfo: %v", err)
+	}
+	return loc
+}
+
+// TestOccurrencesKeepSeriesZone expands a New York series across the March
+// daylight saving change in several display zones; every occurrence stays
+// at 09:00 New York time and the cancelled one stays cancelled.
+func TestOccurrencesKeepSeriesZone(t *testing.T) {
+	newYork := loadZone(t, "America/New_York")
+	s := openTestStore(t)
+	ctx := context.Background()
+	e := Event{Title: "Standup", EventTime: time.Date(2026, 3, 2, 9, 0, 0, 0, newYork), Recurrence: "FREQ=WEEKLY", Creator: "tester"}
+	if err := s.CreateEvent(ctx, &e); err != nil {
+		t.Fatalf("create event: %v", err)
+	}
+	if e.TimeZone != "America/New_York" {
+		t.Fatalf("time zone = %q, want the zone of the start", e.TimeZone)
+	}
+	cancelled := time.Date(2026, 3, 9, 9, 0, 0, 0, newYork)
+	if err := s.CancelEventOccurrence(ctx, e.ID, cancelled); err != nil {
+		t.Fatalf("cancel occurrence: %v", err)
+	}
+	series, err := s.EventByID(ctx, e.ID)
+	if err != nil {
+		t.Fatalf("load event: %v", err)
+	}
+
+	for _, name := range []string{"America/New_York", "UTC", "Europe/London", "Asia/Tokyo"} {
+		loc := loadZone(t, name)
+		from := time.Date(2026, 3, 1, 0, 0, 0, 0, loc)
+		occurrences := series.Occurrences(from, from.AddDate(0, 0, 28), loc)
+		var days []int
+		for _, occ := range occurrences {
+			local := occ.EventTime.In(newYork)
+			if local.Hour() != 9 || local.Minute() != 0 {
+				t.Errorf("in %s: occurrence at %s, want 09:00 New York", name, local)
+			}
+			if occ.EventTime.Location() != loc {
+				t.Errorf("in %s: occurrence returned in %s", name, occ.EventTime.Location())
+			}
+			days = append(days, local.Day())
+		}
+		if len(days) != 3 || days[0] != 2 || days[1] != 16 || days[2] != 23 {
+			t.Errorf("in %s: occurrences on March %v, want 2, 16 and 23", name, days)
+		}
+	}
+}
+
+func TestUpdateEventMovesExceptions(t *testing.T) {
+	newYork := loadZone(t, "America/New_York")
+	london := loadZone(t, "Europe/London")
+	s := openTestStore(t)
+	ctx := context.Background()
+	e := Event{Title: "Standup", EventTime: time.Date(2026, 3, 2, 9, 0, 0, 0, newYork), Recurrence: "FREQ=WEEKLY", Creator: "tester"}
+	other := Event{Title: "Review", EventTime: time.Date(2026, 3, 3, 14, 0, 0, 0, newYork), Recurrence: "FREQ=WEEKLY", Creator: "tester"}
+	for _, ev := range []*Event{&e, &other} {
+		if err := s.CreateEvent(ctx, ev); err != nil {
+			t.Fatalf("create event: %v", err)
+		}
+	}
+	if err := s.CancelEventOccurrence(ctx, e.ID, time.Date(2026, 3, 9, 9, 0, 0, 0, newYork)); err != nil {
+		t.Fatalf("cancel occurrence: %v", err)
+	}
+	moved := EventException{Occurrence: time.Date(2026, 3, 16, 9, 0, 0, 0, newYork), Title: "Planning",
+		EventTime: time.Date(2026, 3, 16, 11, 0, 0, 0, newYork)}
+	if err := s.SetEventOccurrence(ctx, e.ID, moved); err != nil {
+		t.Fatalf("move occurrence: %v", err)
+	}
+	if loaded, err := s.EventByID(ctx, other.ID); err != nil || len(loaded.Exceptions) != 0 {
+		t.Fatalf("other series exceptions = %+v (%v), want none", loaded, err)
+	}
+
+	// check lists the exceptions as "day hh:mm" in zone, with the moved
+	// occurrence's new start after an arrow.
+	check := func(step string, zone *time.Location, want ...string) {
+		t.Helper()
+		loaded, err := s.EventByID(ctx, e.ID)
+		if err != nil {
+			t.Fatalf("%s: load event: %v", step, err)
+		}
+		var got []string
+		for _, ex := range loaded.Exceptions {
+			line := ex.Occurrence.In(zone).Format("02 15:04")
+			if !ex.EventTime.IsZero() {
+				line += " -> " + ex.EventTime.In(zone).Format("02 15:04")
+			}
+			got = append(got, line)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("%s: exceptions %v, want %v", step, got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s: exceptions %v, want %v", step, got, want)
+				break
+			}
+		}
+	}
+
+	e.EventTime = time.Date(2026, 3, 2, 10, 0, 0, 0, newYork)
+	if err := s.UpdateEvent(ctx, &e); err != nil {
+		t.Fatalf("update start: %v", err)
+	}
+	check("later start", newYork, "09 10:00", "16 10:00 -> 16 12:00")
+
+	e.EventTime = time.Date(2026, 3, 3, 10, 0, 0, 0, newYork)
+	if err := s.UpdateEvent(ctx, &e); err != nil {
+		t.Fatalf("update day: %v", err)
+	}
+	check("next weekday", newYork, "10 10:00", "17 10:00 -> 17 12:00")
+
+	e.TimeZone = "Europe/London"
+	e.EventTime = time.Date(2026, 3, 3, 10, 0, 0, 0, london)
+	if err := s.UpdateEvent(ctx, &e); err != nil {
+		t.Fatalf("update zone: %v", err)
+	}
+	check("London", london, "10 10:00", "17 10:00 -> 17 12:00")
+
+	e.Recurrence = "FREQ=WEEKLY;INTERVAL=2"
+	if err := s.UpdateEvent(ctx, &e); err != nil {
+		t.Fatalf("update rule: %v", err)
+	}
+	check("fortnightly", london, "17 10:00 -> 17 12:00")
+
+	e.Recurrence = ""
+	if err := s.UpdateEvent(ctx, &e); err != nil {
+		t.Fatalf("stop repeating: %v", err)
+	}
+	var left int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM event_exceptions WHERE event_id = ?`, e.ID).Scan(&left); err != nil {
+		t.Fatalf("count exceptions: %v", err)
+	}
+	if left != 0 {
+		t.Errorf("one-off event kept %d exceptions", left)
+	}
+	if loaded, err := s.EventByID(ctx, other.ID); err != nil || len(loaded.Exceptions) != 0 {
+		t.Errorf("other series exceptions = %+v (%v), want none", loaded, err)
+	}
+}
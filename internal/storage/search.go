@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Snippet markers wrap the matched terms in SearchResult.Snippet.
+const (
+	SnippetOpen  = "\x02"
+	SnippetClose = "\x03"
+)
+
+// SearchResult is one ranked hit from Search.
+type SearchResult struct {
+	Type        string
+	ID          int64
+	Title       string
+	Snippet     string
+	AccountID   sql.NullInt64
+	AccountName string
+	Rank        float64
+}
+
+// searchIndex describes an FTS5 table that mirrors columns of a content table.
+type searchIndex struct {
+	table   string
+	fts     string
+	columns []string
+}
+
+const searchLimit = 50
+
+var searchIndexes = []searchIndex{
+	{table: "accounts", fts: "accounts_fts", columns: []string{"name", "email", "phone", "address"}},
+	{table: "notes", fts: "notes_fts", columns: []string{"content"}},
+	{table: "events", fts: "events_fts", columns: []string{"title", "details"}},
+}
+
+// ensureSearchIndex creates the full-text index when SQLite was built with
+// FTS5 (the sqlite_fts5 build tag). It lives outside the numbered migrations
+// because the same database may be opened by builds with and without FTS5:
+// without it the sync triggers are dropped so writes keep working, and the
+// index is rebuilt the next time an FTS5 build recreates them.
+func (s *Store) ensureSearchIndex(ctx context.Context) error {
+	var enabled int
+	if err := s.db.QueryRowContext(ctx, `SELECT sqlite_compileoption_used('ENABLE_FTS5')`).Scan(&enabled); err != nil {
+		return fmt.Errorf("check fts5: %w", err)
+	}
+	s.fts = enabled == 1
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin search index: %w", err)
+	}
+	for _, idx := range searchIndexes {
+		triggers := []string{idx.fts + "_ai", idx.fts + "_ad", idx.fts + "_au"}
+		if !s.fts {
+			for _, name := range triggers {
+				if _, err := tx.ExecContext(ctx, `DROP TRIGGER IF EXISTS `+name); err != nil {
+					tx.Rollback()
+					return fmt.Errorf("drop search trigger: %w", err)
+				}
+			}
+			continue
+		}
+		var existing int
+		if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name IN (?, ?, ?)`,
+			triggers[0], triggers[1], triggers[2]).Scan(&existing); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("check search triggers: %w", err)
+		}
+		for _, stmt := range idx.statements() {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("create search index %s: %w", idx.fts, err)
+			}
+		}
+		if existing < len(triggers) {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO `+idx.fts+`(`+idx.fts+`) VALUES ('rebuild')`); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("rebuild search index %s: %w", idx.fts, err)
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit search index: %w", err)
+	}
+	return nil
+}
+
+func (idx searchIndex) statements() []string {
+	cols := strings.Join(idx.columns, ", ")
+	values := func(prefix string) string {
+		parts := make([]string, len(idx.columns))
+		for i, c := range idx.columns {
+			parts[i] = prefix + "." + c
+		}
+		return strings.Join(parts, ", ")
+	}
+	insert := fmt.Sprintf(`INSERT INTO %s(rowid, %s) VALUES (new.id, %s);`, idx.fts, cols, values("new"))
+	remove := fmt.Sprintf(`INSERT INTO %s(%s, rowid, %s) VALUES ('delete', old.id, %s);`, idx.fts, idx.fts, cols, values("old"))
+	return []string{
+		fmt.Sprintf(`CREATE VIRTUAL TABLE IF NOT EXISTS %s USING fts5(%s, content='%s', content_rowid='id', tokenize='unicode61')`, idx.fts, cols, idx.table),
+		fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %s_ai AFTER INSERT ON %s BEGIN %s END`, idx.fts, idx.table, insert),
+		fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %s_ad AFTER DELETE ON %s BEGIN %s END`, idx.fts, idx.table, remove),
+		fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %s_au AFTER UPDATE ON %s BEGIN %s %s END`, idx.fts, idx.table, remove, insert),
+	}
+}
+
+// SearchEnabled reports whether Search uses the FTS5 index. When false it
+// falls back to substring matching.
+func (s *Store) SearchEnabled() bool {
+	return s.fts
+}
+
+// ftsQuery turns free text into an FTS5 query that matches every word as a
+// prefix, so "renew mar" finds "renewal in March".
+func ftsQuery(query string) string {
+	var terms []string
+	for _, word := range strings.Fields(query) {
+		word = strings.ReplaceAll(word, `"`, "")
+		if word == "" {
+			continue
+		}
+		terms = append(terms, `"`+word+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
+
+// Search finds accounts, notes and events matching the query, best matches
+// first, returning at most searchLimit results.
+func (s *Store) Search(ctx context.Context, query string) ([]SearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, nil
+	}
+	limit := searchLimit
+	var rows *sql.Rows
+	var err error
+	if s.fts {
+		match := ftsQuery(query)
+		if match == "" {
+			return nil, nil
+		}
+		rows, err = s.db.QueryContext(ctx, `SELECT type, id, title, snippet, account_id, account_name, rank FROM (
+            SELECT 'account' AS type, a.id, a.name AS title,
+                snippet(accounts_fts, -1, ?, ?, '…', 10) AS snippet,
+                a.id AS account_id, a.name AS account_name,
+                bm25(accounts_fts, 10.0, 5.0, 5.0, 1.0) AS rank
+                FROM accounts_fts JOIN accounts a ON a.id = accounts_fts.rowid
+                WHERE accounts_fts MATCH ? AND a.deleted_at IS NULL
+            UNION ALL
+            SELECT 'note' AS type, n.id, substr(n.content, 1, 60) AS title,
+                snippet(notes_fts, 0, ?, ?, '…', 12) AS snippet,
+                acc.id AS account_id, acc.name AS account_name,
+                bm25(notes_fts) AS rank
+                FROM notes_fts JOIN notes n ON n.id = notes_fts.rowid
+                LEFT JOIN accounts acc ON acc.id = n.account_id AND acc.deleted_at IS NULL
+                WHERE notes_fts MATCH ? AND n.deleted_at IS NULL
+            UNION ALL
+            SELECT 'event' AS type, e.id, e.title,
+                snippet(events_fts, -1, ?, ?, '…', 12) AS snippet,
+                acc.id AS account_id, acc.name AS account_name,
+                bm25(events_fts, 5.0, 1.0) AS rank
+                FROM events_fts JOIN events e ON e.id = events_fts.rowid
+                LEFT JOIN accounts acc ON acc.id = e.account_id AND acc.deleted_at IS NULL
+                WHERE events_fts MATCH ? AND e.deleted_at IS NULL
+        ) ORDER BY rank LIMIT ?`,
+			SnippetOpen, SnippetClose, match,
+			SnippetOpen, SnippetClose, match,
+			SnippetOpen, SnippetClose, match,
+			limit)
+	} else {
+		words := strings.Fields(strings.ToLower(query))
+		accountWhere, accountArgs := likeAll(`lower(a.name || ' ' || COALESCE(a.email, '') || ' ' || COALESCE(a.phone, '') || ' ' || COALESCE(a.address, ''))`, words)
+		noteWhere, noteArgs := likeAll(`lower(n.content)`, words)
+		eventWhere, eventArgs := likeAll(`lower(e.title || ' ' || COALESCE(e.details, ''))`, words)
+		args := append(append(append(accountArgs, noteArgs...), eventArgs...), limit)
+		rows, err = s.db.QueryContext(ctx, `SELECT type, id, title, snippet, account_id, account_name, rank FROM (
+            SELECT 'account' AS type, a.id, a.name AS title,
+                COALESCE(a.email, a.phone, a.address, '') AS snippet,
+                a.id AS account_id, a.name AS account_name, 0 AS rank
+                FROM accounts a
+                WHERE a.deleted_at IS NULL AND `+accountWhere+`
+            UNION ALL
+            SELECT 'note' AS type, n.id, substr(n.content, 1, 60) AS title, substr(n.content, 1, 120) AS snippet,
+                acc.id AS account_id, acc.name AS account_name, 1 AS rank
+                FROM notes n
+                LEFT JOIN accounts acc ON acc.id = n.account_id AND acc.deleted_at IS NULL
+                WHERE n.deleted_at IS NULL AND `+noteWhere+`
+            UNION ALL
+            SELECT 'event' AS type, e.id, e.title, substr(COALESCE(e.details, ''), 1, 120) AS snippet,
+                acc.id AS account_id, acc.name AS account_name, 2 AS rank
+                FROM events e
+                LEFT JOIN accounts acc ON acc.id = e.account_id AND acc.deleted_at IS NULL
+                WHERE e.deleted_at IS NULL AND `+eventWhere+`
+        ) ORDER BY rank, title COLLATE NOCASE LIMIT ?`, args...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var r SearchResult
+		var snippet, accountName sql.NullString
+		if err := rows.Scan(&r.Type, &r.ID, &r.Title, &snippet, &r.AccountID, &accountName, &r.Rank); err != nil {
+			return nil, fmt.Errorf("scan search result: %w", err)
+		}
+		r.Snippet = nullStringToString(snippet)
+		r.AccountName = nullStringToString(accountName)
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// likeAll builds a condition requiring every word to appear in expr.
+func likeAll(expr string, words []string) (string, []interface{}) {
+	conds := make([]string, len(words))
+	args := make([]interface{}, len(words))
+	for i, w := range words {
+		conds[i] = expr + " LIKE ?"
+		args[i] = "%" + w + "%"
+	}
+	return "(" + strings.Join(conds, " AND ") + ")", args
+}
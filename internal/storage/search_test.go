@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+// TestSearch runs in whichever mode the build supports; `make test` covers
+// FTS5 and plain `go test` the substring fallback.
+func TestSearch(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	t.Logf("full-text search enabled: %v", s.SearchEnabled())
+	account := createTestAccount(t, s, "Acme Rockets")
+	note := Note{Content: "Discussed the launch window", AccountID: sql.NullInt64{Int64: account.ID, Valid: true}, Creator: "tester"}
+	if err := s.CreateNote(ctx, &note); err != nil {
+		t.Fatalf("create note: %v", err)
+	}
+	trashed := Note{Content: "Launch postponed", Creator: "tester"}
+	if err := s.CreateNote(ctx, &trashed); err != nil {
+		t.Fatalf("create note: %v", err)
+	}
+	if err := s.DeleteNote(ctx, trashed.ID); err != nil {
+		t.Fatalf("delete note: %v", err)
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"rockets", []string{"account"}},
+		{"launch", []string{"note"}},
+		{"acme launch", nil},
+		{"nothing here", nil},
+	}
+	for _, tt := range tests {
+		results, err := s.Search(ctx, tt.query)
+		if err != nil {
+			t.Fatalf("search %q: %v", tt.query, err)
+		}
+		var got []string
+		for _, r := range results {
+			got = append(got, r.Type)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("search %q = %v, want %v", tt.query, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("search %q = %v, want %v", tt.query, got, tt.want)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AccountStatusChange records an account moving through its lifecycle.
+type AccountStatusChange struct {
+	ID         int64
+	AccountID  int64
+	FromStatus string
+	ToStatus   string
+	Actor      string
+	ChangedAt  time.Time
+}
+
+// SetAccountStatus moves an account to a new status and records the
+// transition. Setting the current status again is a no-op.
+func (s *Store) SetAccountStatus(ctx context.Context, id int64, status, actor string) error {
+	status = strings.TrimSpace(status)
+	if status == "" {
+		return fmt.Errorf("account status required")
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin status change: %w", err)
+	}
+	var current sql.NullString
+	if err := tx.QueryRowContext(ctx, `SELECT status FROM accounts WHERE id = ? AND deleted_at IS NULL`, id).Scan(&current); err != nil {
+		tx.Rollback()
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("get account status: %w", err)
+	}
+	if current.String == status {
+		tx.Rollback()
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE accounts SET status = ? WHERE id = ?`, status, id); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("update account status: %w", err)
+	}
+	// An account without a status gets "none" as its starting point so the
+	// move still shows up in the activity stream.
+	from := current.String
+	if from == "" {
+		from = "none"
+	}
+	if err := recordStatusChange(ctx, tx, id, from, status, actor, time.Now()); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit status change: %w", err)
+	}
+	return nil
+}
+
+// ListAccountStatusChanges returns an account's status history, oldest first.
+func (s *Store) ListAccountStatusChanges(ctx context.Context, accountID int64) ([]AccountStatusChange, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, account_id, from_status, to_status, actor, changed_at FROM account_status_changes WHERE account_id = ? ORDER BY changed_at ASC, id ASC`, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("query status changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []AccountStatusChange
+	for rows.Next() {
+		var c AccountStatusChange
+		var from sql.NullString
+		var changed string
+		if err := rows.Scan(&c.ID, &c.AccountID, &from, &c.ToStatus, &c.Actor, &changed); err != nil {
+			return nil, fmt.Errorf("scan status change: %w", err)
+		}
+		c.FromStatus = nullStringToString(from)
+		if t, err := time.Parse(time.RFC3339, changed); err == nil {
+			c.ChangedAt = t
+		}
+		changes = append(changes, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// CountAccountsByStatus counts live accounts per status. Accounts without a
+// status are counted under the empty string.
+func (s *Store) CountAccountsByStatus(ctx context.Context) (map[string]int, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT COALESCE(status, ''), COUNT(*) FROM accounts WHERE deleted_at IS NULL GROUP BY COALESCE(status, '')`)
+	if err != nil {
+		return nil, fmt.Errorf("count accounts by status: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var status string
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, fmt.Errorf("scan status count: %w", err)
+		}
+		counts[status] += n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+func recordStatusChange(ctx context.Context, tx *sql.Tx, accountID int64, from, to, actor string, at time.Time) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO account_status_changes (account_id, from_status, to_status, actor, changed_at) VALUES (?, ?, ?, ?, ?)`,
+		accountID, nullString(from), to, actor, at.UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("record status change: %w", err)
+	}
+	return nil
+}
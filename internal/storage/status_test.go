@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSetAccountStatus(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	acme := Account{Name: "Acme", Status: "Lead", Creator: "tester", CreatedAt: time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)}
+	if err := s.CreateAccount(ctx, &acme); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	beta := createTestAccount(t, s, "Beta")
+
+	if err := s.SetAccountStatus(ctx, acme.ID, " Customer ", "alice"); err != nil {
+		t.Fatalf("set status: %v", err)
+	}
+	if err := s.SetAccountStatus(ctx, acme.ID, "Customer", "bob"); err != nil {
+		t.Fatalf("set the same status again: %v", err)
+	}
+	if err := s.SetAccountStatus(ctx, beta.ID, "Prospect", "alice"); err != nil {
+		t.Fatalf("set status without a previous one: %v", err)
+	}
+	if err := s.SetAccountStatus(ctx, acme.ID, "  ", "alice"); err == nil {
+		t.Errorf("blank status accepted")
+	}
+	if err := s.SetAccountStatus(ctx, beta.ID+100, "Lead", "alice"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing account: err = %v, want ErrNotFound", err)
+	}
+
+	changes, err := s.ListAccountStatusChanges(ctx, acme.ID)
+	if err != nil {
+		t.Fatalf("list changes: %v", err)
+	}
+	type move struct{ from, to, actor string }
+	var got []move
+	for _, c := range changes {
+		got = append(got, move{c.FromStatus, c.ToStatus, c.Actor})
+	}
+	want := []move{{"", "Lead", "tester"}, {"Lead", "Customer", "alice"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("acme status history = %+v, want %+v", got, want)
+	}
+	changes, _ = s.ListAccountStatusChanges(ctx, beta.ID)
+	if len(changes) != 1 || changes[0].FromStatus != "none" || changes[0].ToStatus != "Prospect" {
+		t.Errorf("beta status history = %+v, want one move from none to Prospect", changes)
+	}
+
+	createTestAccount(t, s, "Gamma")
+	counts, err := s.CountAccountsByStatus(ctx)
+	if err != nil {
+		t.Fatalf("count by status: %v", err)
+	}
+	if want := map[string]int{"Customer": 1, "Prospect": 1, "": 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("status counts = %v, want %v", counts, want)
+	}
+}
@@ -3,10 +3,8 @@ package storage
 import (
 	"context"
 	"database/sql"
-	"encoding/csv"
 	"errors"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"sort"
@@ -20,22 +18,42 @@ const (
 	driverName = "sqlite3"
 )
 
+// accountColumns is the select list understood by scanAccount. Queries must
+// alias the accounts table as "a".
+const accountColumns = `a.id, a.name, a.phone, a.address, a.email,
+        (SELECT c.name FROM contacts c WHERE c.account_id = a.id AND c.is_primary = 1 ORDER BY c.id LIMIT 1),
+        (SELECT group_concat(t.name, ';') FROM account_tags at JOIN tags t ON t.id = at.tag_id WHERE at.account_id = a.id),
+        a.status, a.parent_id,
+        (SELECT p.name FROM accounts p WHERE p.id = a.parent_id AND p.deleted_at IS NULL),
+        a.creator, a.created_at`
+
 // Store wraps the SQLite database and exposes higher-level helpers.
 type Store struct {
 	db   *sql.DB
 	path string
+	fts  bool
 }
 
 // Account represents a customer account.
 type Account struct {
-	ID            int64
-	Name          string
-	Phone         string
-	Address       string
-	Email         string
+	ID      int64
+	Name    string
+	Phone   string
+	Address string
+	Email   string
+	// DecisionMaker mirrors the name of the account's primary contact.
 	DecisionMaker string
-	Creator       string
-	CreatedAt     time.Time
+	// Tags holds the account's tag names in alphabetical order.
+	Tags []string
+	// Status is the account's lifecycle stage; empty until one is set.
+	Status string
+	// ParentID links a branch or subsidiary to its parent account.
+	ParentID sql.NullInt64
+	// ParentName is the parent's name, empty when the parent is unset or in
+	// the trash.
+	ParentName string
+	Creator    string
+	CreatedAt  time.Time
 }
 
 // Note represents a free-form note tied to an optional account.
@@ -50,14 +68,31 @@ type Note struct {
 
 // Event holds scheduled interactions tied to an optional account.
 type Event struct {
-	ID          int64
-	Title       string
-	Details     string
-	EventTime   time.Time
+	ID        int64
+	Title     string
+	Details   string
+	EventTime time.Time
+	// Duration is how long the event runs; zero when no end was given.
+	Duration time.Duration
+	// Location is a place or meeting link.
+	Location    string
 	AccountID   sql.NullInt64
 	Creator     string
 	CreatedAt   time.Time
 	AccountName sql.NullString
+	Attendees   []Attendee
+	// Recurrence is the RRULE of a repeating event, empty for one-off
+	// events. EventTime is then the start of the first occurrence.
+	Recurrence string
+	// TimeZone is the IANA zone a series repeats in, so its occurrences keep
+	// their wall-clock time there whichever zone they are shown in. Blank
+	// for events saved before zones were recorded; those repeat in the zone
+	// they are shown in.
+	TimeZone   string
+	Exceptions []EventException
+	// Occurrence is set on events expanded from a series to the start the
+	// occurrence has under the rule, before any exception moved it.
+	Occurrence time.Time
 }
 
 // Activity is a combined stream of user actions for dashboards.
@@ -67,14 +102,9 @@ type Activity struct {
 	Title     string
 	Details   string
 	CreatedAt time.Time
-}
-
-// ImportResult summarizes a CSV import operation.
-type ImportResult struct {
-	Created int
-	Skipped int
-	Errors  []string
-	Notes   int
+	// AccountID is the account the entry belongs to. Only
+	// ListAccountActivity fills it.
+	AccountID int64
 }
 
 // CleanupResult summarises data deletion counts.
@@ -82,11 +112,17 @@ type CleanupResult struct {
 	Accounts int64
 	Notes    int64
 	Events   int64
+	Tasks    int64
+	// Undo puts the deleted records back when restored.
+	Undo *Snapshot
 }
 
 var (
 	// ErrAccountExists indicates a duplicate account name.
 	ErrAccountExists = errors.New("account already exists")
+	// ErrAccountTrashed indicates the name belongs to an account in the
+	// trash, which still holds it until restored or purged.
+	ErrAccountTrashed = errors.New("account is in the trash")
 	// ErrNotFound indicates the requested record does not exist.
 	ErrNotFound = errors.New("record not found")
 )
@@ -98,20 +134,26 @@ func Open(ctx context.Context) (*Store, error) {
 		return nil, err
 	}
 
-	db, err := sql.Open(driverName, path)
+	// Foreign keys are enabled through the DSN so every pooled connection
+	// enforces them, not just the first one.
+	db, err := sql.Open(driverName, path+"?_foreign_keys=on")
 	if err != nil {
 		return nil, fmt.Errorf("open sqlite: %w", err)
 	}
-	if _, err := db.ExecContext(ctx, "PRAGMA foreign_keys = ON"); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("enable foreign keys: %w", err)
-	}
 
 	store := &Store{db: db, path: path}
 	if err := store.migrate(ctx); err != nil {
 		db.Close()
 		return nil, err
 	}
+	if err := store.ensureSearchIndex(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := store.ensureAuditTriggers(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
 	return store, nil
 }
 
@@ -138,57 +180,9 @@ func resolveDBPath() (string, error) {
 	return filepath.Join(dir, "crmterm.db"), nil
 }
 
-func (s *Store) migrate(ctx context.Context) error {
-	stmts := []string{
-		`CREATE TABLE IF NOT EXISTS accounts (
-            id INTEGER PRIMARY KEY AUTOINCREMENT,
-            name TEXT NOT NULL UNIQUE,
-            phone TEXT,
-            address TEXT,
-            email TEXT,
-            decision_maker TEXT,
-            creator TEXT NOT NULL,
-            created_at TEXT NOT NULL
-        );`,
-		`CREATE TABLE IF NOT EXISTS notes (
-            id INTEGER PRIMARY KEY AUTOINCREMENT,
-            content TEXT NOT NULL,
-            account_id INTEGER,
-            creator TEXT NOT NULL,
-            created_at TEXT NOT NULL,
-            FOREIGN KEY(account_id) REFERENCES accounts(id) ON DELETE SET NULL
-        );`,
-		`CREATE TABLE IF NOT EXISTS events (
-            id INTEGER PRIMARY KEY AUTOINCREMENT,
-            title TEXT NOT NULL,
-            details TEXT,
-            event_time TEXT NOT NULL,
-            account_id INTEGER,
-            creator TEXT NOT NULL,
-            created_at TEXT NOT NULL,
-            FOREIGN KEY(account_id) REFERENCES accounts(id) ON DELETE SET NULL
-        );`,
-	}
-
-	tx, err := s.db.BeginTx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("begin migrations: %w", err)
-	}
-	for _, stmt := range stmts {
-		if _, err := tx.ExecContext(ctx, stmt); err != nil {
-			tx.Rollback()
-			return fmt.Errorf("migrate: %w", err)
-		}
-	}
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit migrations: %w", err)
-	}
-	return nil
-}
-
 // ListAccounts loads all accounts ordered alphabetically.
 func (s *Store) ListAccounts(ctx context.Context) ([]Account, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT id, name, phone, address, email, decision_maker, creator, created_at FROM accounts ORDER BY name COLLATE NOCASE`)
+	rows, err := s.db.QueryContext(ctx, `SELECT `+accountColumns+` FROM accounts a WHERE a.deleted_at IS NULL ORDER BY a.name COLLATE NOCASE`)
 	if err != nil {
 		return nil, fmt.Errorf("query accounts: %w", err)
 	}
@@ -215,7 +209,7 @@ func (s *Store) SearchAccounts(ctx context.Context, term string) ([]Account, err
 		return s.ListAccounts(ctx)
 	}
 	like := fmt.Sprintf("%%%s%%", strings.ToLower(term))
-	rows, err := s.db.QueryContext(ctx, `SELECT id, name, phone, address, email, decision_maker, creator, created_at FROM accounts WHERE lower(name) LIKE ? ORDER BY name COLLATE NOCASE`, like)
+	rows, err := s.db.QueryContext(ctx, `SELECT `+accountColumns+` FROM accounts a WHERE a.deleted_at IS NULL AND lower(a.name) LIKE ? ORDER BY a.name COLLATE NOCASE`, like)
 	if err != nil {
 		return nil, fmt.Errorf("search accounts: %w", err)
 	}
@@ -235,43 +229,134 @@ func (s *Store) SearchAccounts(ctx context.Context, term string) ([]Account, err
 	return accounts, nil
 }
 
-// CreateAccount inserts a new account enforcing uniqueness.
+// CreateAccount inserts a new account enforcing uniqueness. A non-empty
+// DecisionMaker becomes the account's primary contact.
 func (s *Store) CreateAccount(ctx context.Context, a *Account) error {
 	if strings.TrimSpace(a.Name) == "" {
 		return fmt.Errorf("account name required")
 	}
-	now := time.Now().UTC()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin insert account: %w", err)
+	}
+	id, err := insertAccount(ctx, tx, a)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit account: %w", err)
+	}
+	a.ID = id
+	return nil
+}
+
+// insertAccount is CreateAccount within tx. It returns the new ID without
+// setting a.ID, so a rolled back insert leaves the account unchanged.
+func insertAccount(ctx context.Context, tx *sql.Tx, a *Account) (int64, error) {
 	if a.CreatedAt.IsZero() {
-		a.CreatedAt = now
+		a.CreatedAt = time.Now().UTC()
 	}
-	res, err := s.db.ExecContext(ctx, `INSERT INTO accounts (name, phone, address, email, decision_maker, creator, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
-		strings.TrimSpace(a.Name), nullString(a.Phone), nullString(a.Address), nullString(a.Email), nullString(a.DecisionMaker), a.Creator, a.CreatedAt.UTC().Format(time.RFC3339))
+	if a.ParentID.Valid {
+		if err := checkParent(ctx, tx, 0, a.ParentID.Int64); err != nil {
+			return 0, err
+		}
+	}
+	a.Status = strings.TrimSpace(a.Status)
+	res, err := tx.ExecContext(ctx, `INSERT INTO accounts (name, phone, address, email, status, parent_id, creator, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		strings.TrimSpace(a.Name), nullString(a.Phone), nullString(a.Address), nullString(a.Email), nullString(a.Status), nullInt64(a.ParentID), a.Creator, a.CreatedAt.UTC().Format(time.RFC3339))
 	if err != nil {
 		if isUniqueConstraint(err) {
-			return ErrAccountExists
+			return 0, nameTakenError(ctx, tx, a.Name)
 		}
-		return fmt.Errorf("insert account: %w", err)
+		return 0, fmt.Errorf("insert account: %w", err)
 	}
 	id, err := res.LastInsertId()
-	if err == nil {
-		a.ID = id
+	if err != nil {
+		return 0, fmt.Errorf("account id: %w", err)
 	}
-	return nil
+	if err := setPrimaryContactName(ctx, tx, id, a.DecisionMaker, a.Creator, a.CreatedAt); err != nil {
+		return 0, err
+	}
+	if a.Status != "" {
+		if err := recordStatusChange(ctx, tx, id, "", a.Status, a.Creator, a.CreatedAt); err != nil {
+			return 0, err
+		}
+	}
+	return id, nil
 }
 
 // CreateNote persists a new note.
 func (s *Store) CreateNote(ctx context.Context, n *Note) error {
+	return insertNote(ctx, s.db, n)
+}
+
+func insertNote(ctx context.Context, ex execer, n *Note) error {
 	if strings.TrimSpace(n.Content) == "" {
 		return fmt.Errorf("note content required")
 	}
 	if n.CreatedAt.IsZero() {
 		n.CreatedAt = time.Now().UTC()
 	}
-	_, err := s.db.ExecContext(ctx, `INSERT INTO notes (content, account_id, creator, created_at) VALUES (?, ?, ?, ?)`,
+	res, err := ex.ExecContext(ctx, `INSERT INTO notes (content, account_id, creator, created_at) VALUES (?, ?, ?, ?)`,
 		n.Content, nullInt64(n.AccountID), n.Creator, n.CreatedAt.UTC().Format(time.RFC3339))
 	if err != nil {
 		return fmt.Errorf("insert note: %w", err)
 	}
+	if id, err := res.LastInsertId(); err == nil {
+		n.ID = id
+	}
+	return nil
+}
+
+// NoteByID retrieves a single note.
+func (s *Store) NoteByID(ctx context.Context, id int64) (*Note, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT n.id, n.content, n.account_id, n.creator, n.created_at, a.name
+        FROM notes n
+        LEFT JOIN accounts a ON a.id = n.account_id AND a.deleted_at IS NULL
+        WHERE n.id = ? AND n.deleted_at IS NULL`, id)
+	var n Note
+	var created string
+	if err := row.Scan(&n.ID, &n.Content, &n.AccountID, &n.Creator, &created, &n.AccountName); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get note: %w", err)
+	}
+	if t, err := time.Parse(time.RFC3339, created); err == nil {
+		n.CreatedAt = t
+	}
+	return &n, nil
+}
+
+// UpdateNote persists edits to a note's content and account link.
+func (s *Store) UpdateNote(ctx context.Context, n *Note) error {
+	if n == nil {
+		return fmt.Errorf("nil note")
+	}
+	if strings.TrimSpace(n.Content) == "" {
+		return fmt.Errorf("note content required")
+	}
+	res, err := s.db.ExecContext(ctx, `UPDATE notes SET content = ?, account_id = ? WHERE id = ? AND deleted_at IS NULL`,
+		n.Content, nullInt64(n.AccountID), n.ID)
+	if err != nil {
+		return fmt.Errorf("update note: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteNote moves a note to the trash.
+func (s *Store) DeleteNote(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE notes SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`, trashStamp(), id)
+	if err != nil {
+		return fmt.Errorf("delete note: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
 	return nil
 }
 
@@ -286,17 +371,133 @@ func (s *Store) CreateEvent(ctx context.Context, e *Event) error {
 	if e.CreatedAt.IsZero() {
 		e.CreatedAt = time.Now().UTC()
 	}
-	_, err := s.db.ExecContext(ctx, `INSERT INTO events (title, details, event_time, account_id, creator, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
-		e.Title, nullString(e.Details), e.EventTime.UTC().Format(time.RFC3339), nullInt64(e.AccountID), e.Creator, e.CreatedAt.UTC().Format(time.RFC3339))
+	rule, err := normalizeRecurrence(e.Recurrence)
+	if err != nil {
+		return err
+	}
+	if err := e.defaultTimeZone(); err != nil {
+		return err
+	}
+	res, err := s.db.ExecContext(ctx, `INSERT INTO events (title, details, event_time, duration_minutes, location, recurrence, time_zone, account_id, creator, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.Title, nullString(e.Details), e.EventTime.UTC().Format(time.RFC3339), int64(e.Duration/time.Minute), nullString(e.Location), rule,
+		nullString(e.TimeZone), nullInt64(e.AccountID), e.Creator, e.CreatedAt.UTC().Format(time.RFC3339))
 	if err != nil {
 		return fmt.Errorf("insert event: %w", err)
 	}
+	if id, err := res.LastInsertId(); err == nil {
+		e.ID = id
+	}
+	return nil
+}
+
+// EventByID retrieves a single event.
+func (s *Store) EventByID(ctx context.Context, id int64) (*Event, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT e.id, e.title, e.details, e.event_time, e.duration_minutes, e.location, e.recurrence, e.time_zone, e.account_id, e.creator, e.created_at, a.name
+        FROM events e
+        LEFT JOIN accounts a ON a.id = e.account_id AND a.deleted_at IS NULL
+        WHERE e.id = ? AND e.deleted_at IS NULL`, id)
+	var e Event
+	var details, location, recurrence, timeZone sql.NullString
+	var eventTime, created string
+	var minutes int64
+	if err := row.Scan(&e.ID, &e.Title, &details, &eventTime, &minutes, &location, &recurrence, &timeZone, &e.AccountID, &e.Creator, &created, &e.AccountName); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get event: %w", err)
+	}
+	e.Details = nullStringToString(details)
+	e.Duration = time.Duration(minutes) * time.Minute
+	e.Location = nullStringToString(location)
+	e.Recurrence = nullStringToString(recurrence)
+	e.TimeZone = nullStringToString(timeZone)
+	if t, err := time.Parse(time.RFC3339, eventTime); err == nil {
+		e.EventTime = t
+	}
+	if t, err := time.Parse(time.RFC3339, created); err == nil {
+		e.CreatedAt = t
+	}
+	events := []Event{e}
+	if err := s.loadEventExceptions(ctx, events); err != nil {
+		return nil, err
+	}
+	if err := s.loadEventAttendees(ctx, events); err != nil {
+		return nil, err
+	}
+	return &events[0], nil
+}
+
+// UpdateEvent persists edits to an event. When the start, rule or zone of a
+// series changes, its exceptions follow the series or are dropped; see
+// moveEventExceptions.
+func (s *Store) UpdateEvent(ctx context.Context, e *Event) error {
+	if e == nil {
+		return fmt.Errorf("nil event")
+	}
+	if strings.TrimSpace(e.Title) == "" {
+		return fmt.Errorf("event title required")
+	}
+	if e.EventTime.IsZero() {
+		return fmt.Errorf("event time required")
+	}
+	rule, err := normalizeRecurrence(e.Recurrence)
+	if err != nil {
+		return err
+	}
+	if err := e.defaultTimeZone(); err != nil {
+		return err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin update event: %w", err)
+	}
+	var before Event
+	var eventTime string
+	var recurrence, timeZone sql.NullString
+	err = tx.QueryRowContext(ctx, `SELECT event_time, recurrence, time_zone FROM events WHERE id = ? AND deleted_at IS NULL`, e.ID).
+		Scan(&eventTime, &recurrence, &timeZone)
+	if errors.Is(err, sql.ErrNoRows) {
+		tx.Rollback()
+		return ErrNotFound
+	}
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("load event: %w", err)
+	}
+	before.EventTime, _ = time.Parse(time.RFC3339, eventTime)
+	before.Recurrence = nullStringToString(recurrence)
+	before.TimeZone = nullStringToString(timeZone)
+	if _, err := tx.ExecContext(ctx, `UPDATE events SET title = ?, details = ?, event_time = ?, duration_minutes = ?, location = ?, recurrence = ?, time_zone = ?, account_id = ? WHERE id = ?`,
+		e.Title, nullString(e.Details), e.EventTime.UTC().Format(time.RFC3339), int64(e.Duration/time.Minute), nullString(e.Location), rule,
+		nullString(e.TimeZone), nullInt64(e.AccountID), e.ID); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("update event: %w", err)
+	}
+	if err := moveEventExceptions(ctx, tx, e.ID, before, *e); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit update event: %w", err)
+	}
+	return nil
+}
+
+// DeleteEvent moves an event to the trash.
+func (s *Store) DeleteEvent(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE events SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`, trashStamp(), id)
+	if err != nil {
+		return fmt.Errorf("delete event: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
 	return nil
 }
 
 // AccountByName retrieves an account by case-insensitive name.
 func (s *Store) AccountByName(ctx context.Context, name string) (*Account, error) {
-	row := s.db.QueryRowContext(ctx, `SELECT id, name, phone, address, email, decision_maker, creator, created_at FROM accounts WHERE lower(name) = lower(?)`, strings.TrimSpace(name))
+	row := s.db.QueryRowContext(ctx, `SELECT `+accountColumns+` FROM accounts a WHERE a.deleted_at IS NULL AND lower(a.name) = lower(?)`, strings.TrimSpace(name))
 	account, err := scanAccount(row)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -309,7 +510,7 @@ func (s *Store) AccountByName(ctx context.Context, name string) (*Account, error
 
 // AccountByID retrieves an account by its identifier.
 func (s *Store) AccountByID(ctx context.Context, id int64) (*Account, error) {
-	row := s.db.QueryRowContext(ctx, `SELECT id, name, phone, address, email, decision_maker, creator, created_at FROM accounts WHERE id = ?`, id)
+	row := s.db.QueryRowContext(ctx, `SELECT `+accountColumns+` FROM accounts a WHERE a.id = ? AND a.deleted_at IS NULL`, id)
 	account, err := scanAccount(row)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -320,7 +521,8 @@ func (s *Store) AccountByID(ctx context.Context, id int64) (*Account, error) {
 	return &account, nil
 }
 
-// UpdateAccount persists changes to an existing account.
+// UpdateAccount persists changes to an existing account. A non-empty
+// DecisionMaker renames the primary contact, creating one if needed.
 func (s *Store) UpdateAccount(ctx context.Context, a *Account) error {
 	if a == nil {
 		return fmt.Errorf("nil account")
@@ -328,22 +530,108 @@ func (s *Store) UpdateAccount(ctx context.Context, a *Account) error {
 	if strings.TrimSpace(a.Name) == "" {
 		return fmt.Errorf("account name required")
 	}
-	_, err := s.db.ExecContext(ctx, `UPDATE accounts SET name = ?, phone = ?, address = ?, email = ?, decision_maker = ? WHERE id = ?`,
-		strings.TrimSpace(a.Name), nullString(a.Phone), nullString(a.Address), nullString(a.Email), nullString(a.DecisionMaker), a.ID)
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin update account: %w", err)
+	}
+	if a.ParentID.Valid {
+		if err := checkParent(ctx, tx, a.ID, a.ParentID.Int64); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	res, err := tx.ExecContext(ctx, `UPDATE accounts SET name = ?, phone = ?, address = ?, email = ?, parent_id = ? WHERE id = ? AND deleted_at IS NULL`,
+		strings.TrimSpace(a.Name), nullString(a.Phone), nullString(a.Address), nullString(a.Email), nullInt64(a.ParentID), a.ID)
 	if err != nil {
 		if isUniqueConstraint(err) {
-			return ErrAccountExists
+			err = nameTakenError(ctx, tx, a.Name)
+			tx.Rollback()
+			return err
 		}
+		tx.Rollback()
 		return fmt.Errorf("update account: %w", err)
 	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		tx.Rollback()
+		return ErrNotFound
+	}
+	if err := setPrimaryContactName(ctx, tx, a.ID, a.DecisionMaker, a.Creator, time.Now()); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit account: %w", err)
+	}
 	return nil
 }
 
+// DeleteAccount moves an account to the trash along with its contacts and
+// deals, which stay attached to it. With cascade, its notes, events and tasks
+// are trashed too and come back when the account is restored; otherwise they
+// are kept and unlinked from the account.
+func (s *Store) DeleteAccount(ctx context.Context, id int64, cascade bool) (CleanupResult, error) {
+	var result CleanupResult
+	stamp, batch := trashStamp(), newTrashBatch()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, fmt.Errorf("begin delete account: %w", err)
+	}
+	scopes := []snapshotScope{{table: "accounts", where: "id = ?", args: []any{id}}}
+	for _, table := range []string{"notes", "events", "tasks"} {
+		scopes = append(scopes, snapshotScope{table: table, where: "account_id = ? AND deleted_at IS NULL", args: []any{id}})
+	}
+	undo, err := captureSnapshot(ctx, tx, scopes)
+	if err != nil {
+		tx.Rollback()
+		return result, err
+	}
+	children := []struct {
+		table string
+		count *int64
+	}{
+		{"notes", &result.Notes},
+		{"events", &result.Events},
+		{"tasks", &result.Tasks},
+	}
+	for _, child := range children {
+		query := `UPDATE ` + child.table + ` SET account_id = NULL WHERE account_id = ? AND deleted_at IS NULL`
+		args := []interface{}{id}
+		if cascade {
+			query = `UPDATE ` + child.table + ` SET deleted_at = ?, trash_batch = ? WHERE account_id = ? AND deleted_at IS NULL`
+			args = []interface{}{stamp, batch, id}
+		}
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			tx.Rollback()
+			return CleanupResult{}, fmt.Errorf("detach %s: %w", child.table, err)
+		}
+		if cascade {
+			*child.count, _ = res.RowsAffected()
+		}
+	}
+	res, err := tx.ExecContext(ctx, `UPDATE accounts SET deleted_at = ?, trash_batch = ? WHERE id = ? AND deleted_at IS NULL`, stamp, batch, id)
+	if err != nil {
+		tx.Rollback()
+		return CleanupResult{}, fmt.Errorf("delete account: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		tx.Rollback()
+		return CleanupResult{}, ErrNotFound
+	}
+	result.Accounts = 1
+	result.Undo = undo
+	if err := tx.Commit(); err != nil {
+		return CleanupResult{}, fmt.Errorf("commit delete account: %w", err)
+	}
+	return result, nil
+}
+
 // ListEvents fetches events sorted by event_time ascending.
 func (s *Store) ListEvents(ctx context.Context) ([]Event, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT e.id, e.title, e.details, e.event_time, e.account_id, e.creator, e.created_at, a.name
+	rows, err := s.db.QueryContext(ctx, `SELECT e.id, e.title, e.details, e.event_time, e.duration_minutes, e.location, e.recurrence, e.time_zone, e.account_id, e.creator, e.created_at, a.name
         FROM events e
-        LEFT JOIN accounts a ON a.id = e.account_id
+        LEFT JOIN accounts a ON a.id = e.account_id AND a.deleted_at IS NULL
+        WHERE e.deleted_at IS NULL
         ORDER BY e.event_time ASC`)
 	if err != nil {
 		return nil, fmt.Errorf("query events: %w", err)
@@ -355,10 +643,16 @@ func (s *Store) ListEvents(ctx context.Context) ([]Event, error) {
 		var e Event
 		var eventTime, created string
 		var accountID sql.NullInt64
-		var accountName sql.NullString
-		if err := rows.Scan(&e.ID, &e.Title, &e.Details, &eventTime, &accountID, &e.Creator, &created, &accountName); err != nil {
+		var details, location, accountName, recurrence, timeZone sql.NullString
+		var minutes int64
+		if err := rows.Scan(&e.ID, &e.Title, &details, &eventTime, &minutes, &location, &recurrence, &timeZone, &accountID, &e.Creator, &created, &accountName); err != nil {
 			return nil, fmt.Errorf("scan event: %w", err)
 		}
+		e.Details = nullStringToString(details)
+		e.Duration = time.Duration(minutes) * time.Minute
+		e.Location = nullStringToString(location)
+		e.Recurrence = nullStringToString(recurrence)
+		e.TimeZone = nullStringToString(timeZone)
 		if t, err := time.Parse(time.RFC3339, eventTime); err == nil {
 			e.EventTime = t
 		}
@@ -372,6 +666,12 @@ func (s *Store) ListEvents(ctx context.Context) ([]Event, error) {
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	if err := s.loadEventExceptions(ctx, events); err != nil {
+		return nil, err
+	}
+	if err := s.loadEventAttendees(ctx, events); err != nil {
+		return nil, err
+	}
 	return events, nil
 }
 
@@ -381,11 +681,28 @@ func (s *Store) ListActivities(ctx context.Context, limit int) ([]Activity, erro
 		limit = 20
 	}
 	rows, err := s.db.QueryContext(ctx, `SELECT type, id, title, details, created_at FROM (
-            SELECT 'account' AS type, id, name AS title, phone AS details, created_at FROM accounts
+            SELECT 'account' AS type, id, name AS title, phone AS details, created_at FROM accounts WHERE deleted_at IS NULL
             UNION ALL
-            SELECT 'note' AS type, id, substr(content, 1, 80) AS title, '' AS details, created_at FROM notes
+            SELECT 'note' AS type, id, substr(content, 1, 80) AS title, '' AS details, created_at FROM notes WHERE deleted_at IS NULL
             UNION ALL
-            SELECT 'event' AS type, id, title, substr(details, 1, 80) AS details, created_at FROM events
+            SELECT 'event' AS type, id, title, substr(details, 1, 80) AS details, created_at FROM events WHERE deleted_at IS NULL
+            UNION ALL
+            SELECT 'deal' AS type, id, title, stage AS details, created_at FROM deals
+                WHERE account_id IN (SELECT id FROM accounts WHERE deleted_at IS NULL)
+            UNION ALL
+            SELECT 'task' AS type, id, title, substr(details, 1, 80) AS details, created_at FROM tasks WHERE deleted_at IS NULL
+            UNION ALL
+            SELECT 'stage' AS type, sc.deal_id AS id, d.title || ' → ' || sc.to_stage AS title, 'from ' || sc.from_stage || ' by ' || sc.actor AS details, sc.changed_at AS created_at
+                FROM deal_stage_changes sc JOIN deals d ON d.id = sc.deal_id
+                WHERE sc.from_stage IS NOT NULL AND d.account_id IN (SELECT id FROM accounts WHERE deleted_at IS NULL)
+            UNION ALL
+            SELECT 'status' AS type, sc.account_id AS id, a.name || ' → ' || sc.to_status AS title, 'from ' || sc.from_status || ' by ' || sc.actor AS details, sc.changed_at AS created_at
+                FROM account_status_changes sc JOIN accounts a ON a.id = sc.account_id
+                WHERE sc.from_status IS NOT NULL AND a.deleted_at IS NULL
+            UNION ALL
+            SELECT 'merge' AS type, m.account_id AS id, a.name || ' ← ' || m.merged_name AS title, 'merged by ' || m.actor AS details, m.merged_at AS created_at
+                FROM account_merges m JOIN accounts a ON a.id = m.account_id
+                WHERE a.deleted_at IS NULL
         ) ORDER BY created_at DESC LIMIT ?`, limit)
 	if err != nil {
 		return nil, fmt.Errorf("query activities: %w", err)
@@ -412,18 +729,39 @@ func (s *Store) ListActivities(ctx context.Context, limit int) ([]Activity, erro
 	return activities, nil
 }
 
-// ListAccountActivity returns activity related to a specific account.
-func (s *Store) ListAccountActivity(ctx context.Context, accountID int64, limit int) ([]Activity, error) {
+// ListAccountActivity returns activity related to a specific account. With
+// rollup set it also includes the activity of every live descendant.
+func (s *Store) ListAccountActivity(ctx context.Context, accountID int64, limit int, rollup bool) ([]Activity, error) {
 	if limit <= 0 {
 		limit = 20
 	}
-	rows, err := s.db.QueryContext(ctx, `SELECT type, id, title, details, created_at FROM (
-            SELECT 'account' AS type, id, name AS title, phone AS details, created_at FROM accounts WHERE id = ?
+	rows, err := s.db.QueryContext(ctx, `WITH RECURSIVE scope(id) AS (
+            SELECT ?
+            UNION
+            SELECT a.id FROM accounts a JOIN scope ON a.parent_id = scope.id WHERE ? AND a.deleted_at IS NULL
+        )
+        SELECT type, id, title, details, created_at, account_id FROM (
+            SELECT 'account' AS type, id, name AS title, phone AS details, created_at, id AS account_id FROM accounts WHERE id IN scope AND deleted_at IS NULL
+            UNION ALL
+            SELECT 'note' AS type, id, substr(content, 1, 80) AS title, '' AS details, created_at, account_id FROM notes WHERE account_id IN scope AND deleted_at IS NULL
             UNION ALL
-            SELECT 'note' AS type, id, substr(content, 1, 80) AS title, '' AS details, created_at FROM notes WHERE account_id = ?
+            SELECT 'event' AS type, id, title, substr(details, 1, 80) AS details, created_at, account_id FROM events WHERE account_id IN scope AND deleted_at IS NULL
             UNION ALL
-            SELECT 'event' AS type, id, title, substr(details, 1, 80) AS details, created_at FROM events WHERE account_id = ?
-        ) ORDER BY created_at DESC LIMIT ?`, accountID, accountID, accountID, limit)
+            SELECT 'contact' AS type, id, name AS title, COALESCE(title, role) AS details, created_at, account_id FROM contacts WHERE account_id IN scope
+            UNION ALL
+            SELECT 'deal' AS type, id, title, stage AS details, created_at, account_id FROM deals WHERE account_id IN scope
+            UNION ALL
+            SELECT 'task' AS type, id, title, substr(details, 1, 80) AS details, created_at, account_id FROM tasks WHERE account_id IN scope AND deleted_at IS NULL
+            UNION ALL
+            SELECT 'stage' AS type, sc.deal_id AS id, d.title || ' → ' || sc.to_stage AS title, 'from ' || sc.from_stage || ' by ' || sc.actor AS details, sc.changed_at AS created_at, d.account_id
+                FROM deal_stage_changes sc JOIN deals d ON d.id = sc.deal_id WHERE sc.from_stage IS NOT NULL AND d.account_id IN scope
+            UNION ALL
+            SELECT 'status' AS type, account_id AS id, 'Status → ' || to_status AS title, 'from ' || from_status || ' by ' || actor AS details, changed_at AS created_at, account_id
+                FROM account_status_changes WHERE from_status IS NOT NULL AND account_id IN scope
+            UNION ALL
+            SELECT 'merge' AS type, account_id AS id, 'Merged ' || merged_name AS title, 'by ' || actor AS details, merged_at AS created_at, account_id
+                FROM account_merges WHERE account_id IN scope
+        ) ORDER BY created_at DESC LIMIT ?`, accountID, rollup, limit)
 	if err != nil {
 		return nil, fmt.Errorf("query account activity: %w", err)
 	}
@@ -434,7 +772,7 @@ func (s *Store) ListAccountActivity(ctx context.Context, accountID int64, limit
 		var a Activity
 		var created string
 		var details sql.NullString
-		if err := rows.Scan(&a.Type, &a.ID, &a.Title, &details, &created); err != nil {
+		if err := rows.Scan(&a.Type, &a.ID, &a.Title, &details, &created, &a.AccountID); err != nil {
 			return nil, fmt.Errorf("scan activity: %w", err)
 		}
 		a.Details = nullStringToString(details)
@@ -449,160 +787,32 @@ func (s *Store) ListAccountActivity(ctx context.Context, accountID int64, limit
 	return activities, nil
 }
 
-// ImportAccountsCSV ingests accounts from a CSV reader.
-func (s *Store) ImportAccountsCSV(ctx context.Context, r io.Reader, defaultCreator string, loc *time.Location) (ImportResult, error) {
-	result := ImportResult{}
-	reader := csv.NewReader(r)
-	reader.TrimLeadingSpace = true
-	reader.FieldsPerRecord = -1
-	header, err := reader.Read()
-	if err != nil {
-		return result, fmt.Errorf("read header: %w", err)
-	}
-	index := map[string]int{}
-	for i, h := range header {
-		key := normalizeHeader(h)
-		if key == "" {
-			continue
-		}
-		switch key {
-		case "accountname":
-			key = "name"
-		case "phonenumber":
-			key = "phone"
-		case "decisionmaker", "dm":
-			key = "decision_maker"
-		case "created", "createddate", "createdtime":
-			key = "created_at"
-		case "notes":
-			key = "note"
-		case "mail", "emailaddress":
-			key = "email"
-		}
-		index[key] = i
-	}
-	nameIdx, ok := index["name"]
-	if !ok {
-		return result, fmt.Errorf("csv missing 'name' column")
-	}
-	locUsed := loc
-	if locUsed == nil {
-		locUsed = time.Local
-	}
-	row := 1
-	for {
-		record, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			result.Errors = append(result.Errors, fmt.Sprintf("row %d: %v", row+1, err))
-			continue
-		}
-		row++
-		if nameIdx >= len(record) {
-			result.Errors = append(result.Errors, fmt.Sprintf("row %d: missing name field", row))
-			result.Skipped++
-			continue
-		}
-		name := strings.TrimSpace(record[nameIdx])
-		if name == "" {
-			result.Errors = append(result.Errors, fmt.Sprintf("row %d: account name required", row))
-			result.Skipped++
-			continue
-		}
-		account := Account{Name: name}
-		if idx, ok := index["phone"]; ok && idx < len(record) {
-			account.Phone = strings.TrimSpace(record[idx])
-		}
-		if idx, ok := index["address"]; ok && idx < len(record) {
-			account.Address = strings.TrimSpace(record[idx])
-		}
-		if idx, ok := index["email"]; ok && idx < len(record) {
-			account.Email = strings.TrimSpace(record[idx])
-		}
-		if idx, ok := index["decision_maker"]; ok && idx < len(record) {
-			account.DecisionMaker = strings.TrimSpace(record[idx])
-		}
-		creator := defaultCreator
-		if idx, ok := index["creator"]; ok && idx < len(record) {
-			val := strings.TrimSpace(record[idx])
-			if val != "" {
-				creator = val
-			}
-		}
-		if creator == "" {
-			creator = "Import"
-		}
-		account.Creator = creator
-		if idx, ok := index["created_at"]; ok && idx < len(record) {
-			stamp := strings.TrimSpace(record[idx])
-			if stamp != "" {
-				if parsed, ok := parseImportTime(stamp, locUsed); ok {
-					account.CreatedAt = parsed
-				}
-			}
-		}
-		if account.CreatedAt.IsZero() {
-			account.CreatedAt = time.Now().In(locUsed)
-		}
-		if err := s.CreateAccount(ctx, &account); err != nil {
-			if errors.Is(err, ErrAccountExists) {
-				result.Skipped++
-				result.Errors = append(result.Errors, fmt.Sprintf("row %d: duplicate account '%s'", row, account.Name))
-				continue
-			}
-			result.Errors = append(result.Errors, fmt.Sprintf("row %d: %v", row, err))
-			result.Skipped++
-			continue
-		}
-		result.Created++
-		if idx, ok := index["note"]; ok && idx < len(record) {
-			note := strings.TrimSpace(record[idx])
-			if note != "" {
-				accountNote := Note{
-					Content:   note,
-					AccountID: sql.NullInt64{Int64: account.ID, Valid: true},
-					Creator:   creator,
-					CreatedAt: account.CreatedAt,
-				}
-				if err := s.CreateNote(ctx, &accountNote); err != nil {
-					result.Errors = append(result.Errors, fmt.Sprintf("row %d: note error: %v", row, err))
-				} else {
-					result.Notes++
-				}
-			}
-		}
-	}
-	return result, nil
-}
-
-// DeleteBefore removes accounts, notes, and events created before the cutoff.
+// DeleteBefore moves accounts, notes, and events created before the cutoff to the trash.
 func (s *Store) DeleteBefore(ctx context.Context, cutoff time.Time) (CleanupResult, error) {
 	if cutoff.IsZero() {
 		return CleanupResult{}, nil
 	}
 	args := cutoff.UTC().Format(time.RFC3339)
 	return s.deleteByQueries(ctx,
-		deleteQuery{"notes", "DELETE FROM notes WHERE created_at < ?", args},
-		deleteQuery{"events", "DELETE FROM events WHERE created_at < ?", args},
-		deleteQuery{"accounts", "DELETE FROM accounts WHERE created_at < ?", args},
+		deleteQuery{"notes", "created_at < ?", args},
+		deleteQuery{"events", "created_at < ?", args},
+		deleteQuery{"accounts", "created_at < ?", args},
 	)
 }
 
-// DeleteOldest removes the oldest N accounts, notes, and events.
+// DeleteOldest moves the oldest N accounts, notes, and events to the trash.
 func (s *Store) DeleteOldest(ctx context.Context, limit int) (CleanupResult, error) {
 	if limit <= 0 {
 		return CleanupResult{}, nil
 	}
 	return s.deleteByQueries(ctx,
-		deleteQuery{"notes", "DELETE FROM notes WHERE id IN (SELECT id FROM notes ORDER BY created_at ASC LIMIT ?)", limit},
-		deleteQuery{"events", "DELETE FROM events WHERE id IN (SELECT id FROM events ORDER BY created_at ASC LIMIT ?)", limit},
-		deleteQuery{"accounts", "DELETE FROM accounts WHERE id IN (SELECT id FROM accounts ORDER BY created_at ASC LIMIT ?)", limit},
+		deleteQuery{"notes", "id IN (SELECT id FROM notes WHERE deleted_at IS NULL ORDER BY created_at ASC LIMIT ?)", limit},
+		deleteQuery{"events", "id IN (SELECT id FROM events WHERE deleted_at IS NULL ORDER BY created_at ASC LIMIT ?)", limit},
+		deleteQuery{"accounts", "id IN (SELECT id FROM accounts WHERE deleted_at IS NULL ORDER BY created_at ASC LIMIT ?)", limit},
 	)
 }
 
-// DeleteRange removes entries between start and end (inclusive on start, exclusive on end if provided).
+// DeleteRange moves entries between start and end (inclusive on start, exclusive on end if provided) to the trash.
 func (s *Store) DeleteRange(ctx context.Context, start, end time.Time) (CleanupResult, error) {
 	if start.IsZero() && end.IsZero() {
 		return CleanupResult{}, nil
@@ -623,36 +833,50 @@ func (s *Store) DeleteRange(ctx context.Context, start, end time.Time) (CleanupR
 	eventsArgs := append([]interface{}(nil), args...)
 	accountsArgs := append([]interface{}(nil), args...)
 	return s.deleteByQueries(ctx,
-		deleteQuery{"notes", fmt.Sprintf("DELETE FROM notes WHERE %s", where), notesArgs},
-		deleteQuery{"events", fmt.Sprintf("DELETE FROM events WHERE %s", where), eventsArgs},
-		deleteQuery{"accounts", fmt.Sprintf("DELETE FROM accounts WHERE %s", where), accountsArgs},
+		deleteQuery{"notes", where, notesArgs},
+		deleteQuery{"events", where, eventsArgs},
+		deleteQuery{"accounts", where, accountsArgs},
 	)
 }
 
+// deleteQuery selects rows of one table to move to the trash. where is
+// applied on top of "deleted_at IS NULL".
 type deleteQuery struct {
 	entity string
-	query  string
+	where  string
 	args   interface{}
 }
 
 func (s *Store) deleteByQueries(ctx context.Context, queries ...deleteQuery) (CleanupResult, error) {
 	var result CleanupResult
-	for _, q := range queries {
-		var res sql.Result
-		var err error
-		switch args := q.args.(type) {
+	stamp, batch := trashStamp(), newTrashBatch()
+	scopes := make([]snapshotScope, len(queries))
+	for i, q := range queries {
+		var args []interface{}
+		switch extra := q.args.(type) {
 		case []interface{}:
-			res, err = s.db.ExecContext(ctx, q.query, args...)
-		case interface{}:
-			res, err = s.db.ExecContext(ctx, q.query, args)
+			args = extra
+		case nil:
 		default:
-			res, err = s.db.ExecContext(ctx, q.query)
+			args = []interface{}{extra}
 		}
+		scopes[i] = snapshotScope{table: q.entity, where: "deleted_at IS NULL AND (" + q.where + ")", args: args}
+	}
+	// Capture everything before the first update, as the limits in
+	// DeleteOldest would otherwise pick the next-oldest rows.
+	undo, err := captureSnapshot(ctx, s.db, scopes)
+	if err != nil {
+		return result, err
+	}
+	result.Undo = undo
+	for _, sc := range scopes {
+		query := fmt.Sprintf("UPDATE %s SET deleted_at = ?, trash_batch = ? WHERE %s", sc.table, sc.where)
+		res, err := s.db.ExecContext(ctx, query, append([]interface{}{stamp, batch}, sc.args...)...)
 		if err != nil {
-			return result, fmt.Errorf("delete %s: %w", q.entity, err)
+			return result, fmt.Errorf("delete %s: %w", sc.table, err)
 		}
 		af, _ := res.RowsAffected()
-		switch q.entity {
+		switch sc.table {
 		case "accounts":
 			result.Accounts += af
 		case "notes":
@@ -668,17 +892,25 @@ type rowScanner interface {
 	Scan(dest ...any) error
 }
 
+// execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
 func scanAccount(rs rowScanner) (Account, error) {
 	var a Account
-	var phone, address, email, decision sql.NullString
+	var phone, address, email, decision, tags, status, parent sql.NullString
 	var created string
-	if err := rs.Scan(&a.ID, &a.Name, &phone, &address, &email, &decision, &a.Creator, &created); err != nil {
+	if err := rs.Scan(&a.ID, &a.Name, &phone, &address, &email, &decision, &tags, &status, &a.ParentID, &parent, &a.Creator, &created); err != nil {
 		return Account{}, err
 	}
 	a.Phone = nullStringToString(phone)
 	a.Address = nullStringToString(address)
 	a.Email = nullStringToString(email)
 	a.DecisionMaker = nullStringToString(decision)
+	a.Tags = splitTagList(tags.String)
+	a.Status = nullStringToString(status)
+	a.ParentName = nullStringToString(parent)
 	if created != "" {
 		if t, err := time.Parse(time.RFC3339, created); err == nil {
 			a.CreatedAt = t
@@ -732,6 +964,19 @@ func nullInt64(v sql.NullInt64) interface{} {
 	return v.Int64
 }
 
+// nameTakenError explains why an account could not take name: another
+// live account has it, or a trashed one does.
+func nameTakenError(ctx context.Context, tx *sql.Tx, name string) error {
+	var trashed int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM accounts WHERE name = ? AND deleted_at IS NOT NULL`, strings.TrimSpace(name)).Scan(&trashed); err != nil {
+		return fmt.Errorf("check account name: %w", err)
+	}
+	if trashed > 0 {
+		return ErrAccountTrashed
+	}
+	return ErrAccountExists
+}
+
 func isUniqueConstraint(err error) bool {
 	if err == nil {
 		return false
@@ -739,13 +984,15 @@ func isUniqueConstraint(err error) bool {
 	return strings.Contains(strings.ToLower(err.Error()), "unique")
 }
 
-// SplitEvents groups events relative to a reference time.
+// SplitEvents groups events relative to a reference time. Recurring events
+// contribute their occurrences from EventLookBack before now to
+// EventLookAhead after it.
 func SplitEvents(events []Event, now time.Time) (today, upcoming, past []Event) {
 	loc := now.Location()
 	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
 	endOfDay := startOfDay.Add(24 * time.Hour)
 
-	for _, e := range events {
+	for _, e := range ExpandEvents(events, startOfDay.AddDate(0, 0, -EventLookBack), endOfDay.AddDate(0, 0, EventLookAhead)) {
 		t := e.EventTime.In(loc)
 		switch {
 		case !t.Before(startOfDay) && t.Before(endOfDay):
@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// openTestStore opens a fresh database under a temporary config directory.
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	s, err := Open(context.Background())
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// createTestAccount saves an account with the given name and fails the
+// test if it cannot.
+func createTestAccount(t *testing.T, s *Store, name string) Account {
+	t.Helper()
+	a := Account{Name: name, Creator: "tester", CreatedAt: time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)}
+	if err := s.CreateAccount(context.Background(), &a); err != nil {
+		t.Fatalf("create account %q: %v", name, err)
+	}
+	return a
+}
@@ -0,0 +1,253 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Tag is a label used to group accounts, e.g. an industry, region or tier.
+type Tag struct {
+	ID   int64
+	Name string
+	// Accounts counts the live accounts carrying the tag.
+	Accounts int
+}
+
+// TagFilter selects accounts by tag: every Include tag must be present and
+// no Exclude tag may be.
+type TagFilter struct {
+	Include []string
+	Exclude []string
+}
+
+// tagPrefix introduces a tag term in a filter expression.
+const tagPrefix = "tag:"
+
+// NormalizeTag lowercases a tag name and joins its words with dashes.
+// Separators used by filters and CSV lists are rejected.
+func NormalizeTag(name string) (string, error) {
+	tag := strings.ToLower(strings.Join(strings.Fields(name), "-"))
+	if tag == "" {
+		return "", fmt.Errorf("tag name required")
+	}
+	if strings.ContainsAny(tag, ";,:") || strings.HasPrefix(tag, "-") {
+		return "", fmt.Errorf("invalid tag %q", name)
+	}
+	return tag, nil
+}
+
+// ParseTagFilter pulls `tag:name` and `-tag:name` terms out of expr and
+// returns them with the remaining words, so callers can mix tag filters
+// with free-text search. Empty terms such as a half-typed "tag:" are
+// dropped.
+func ParseTagFilter(expr string) (TagFilter, string) {
+	var filter TagFilter
+	var rest []string
+	for _, word := range strings.Fields(expr) {
+		lower := strings.ToLower(word)
+		switch {
+		case strings.HasPrefix(lower, "-"+tagPrefix):
+			if tag, err := NormalizeTag(lower[len(tagPrefix)+1:]); err == nil {
+				filter.Exclude = append(filter.Exclude, tag)
+			}
+		case strings.HasPrefix(lower, tagPrefix):
+			if tag, err := NormalizeTag(lower[len(tagPrefix):]); err == nil {
+				filter.Include = append(filter.Include, tag)
+			}
+		default:
+			rest = append(rest, word)
+		}
+	}
+	return filter, strings.Join(rest, " ")
+}
+
+// IsZero reports whether the filter has no terms.
+func (f TagFilter) IsZero() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0
+}
+
+// Matches reports whether an account with the given tags passes the filter.
+func (f TagFilter) Matches(tags []string) bool {
+	has := func(tag string) bool {
+		for _, t := range tags {
+			if strings.EqualFold(t, tag) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, tag := range f.Include {
+		if !has(tag) {
+			return false
+		}
+	}
+	for _, tag := range f.Exclude {
+		if has(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// ListTags returns every tag with its account count, alphabetically.
+func (s *Store) ListTags(ctx context.Context) ([]Tag, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT t.id, t.name,
+            (SELECT COUNT(*) FROM account_tags at JOIN accounts a ON a.id = at.account_id
+             WHERE at.tag_id = t.id AND a.deleted_at IS NULL)
+        FROM tags t ORDER BY t.name`)
+	if err != nil {
+		return nil, fmt.Errorf("query tags: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []Tag
+	for rows.Next() {
+		var t Tag
+		if err := rows.Scan(&t.ID, &t.Name, &t.Accounts); err != nil {
+			return nil, fmt.Errorf("scan tag: %w", err)
+		}
+		tags = append(tags, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// AddAccountTags attaches tags to an account, creating any that do not
+// exist yet. Tags the account already has are left alone.
+func (s *Store) AddAccountTags(ctx context.Context, accountID int64, names ...string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tag account: %w", err)
+	}
+	if err := addAccountTags(ctx, tx, accountID, names...); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tags: %w", err)
+	}
+	return nil
+}
+
+func addAccountTags(ctx context.Context, tx *sql.Tx, accountID int64, names ...string) error {
+	var exists int
+	if err := tx.QueryRowContext(ctx, `SELECT 1 FROM accounts WHERE id = ? AND deleted_at IS NULL`, accountID).Scan(&exists); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("get account: %w", err)
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, name := range names {
+		tag, err := NormalizeTag(name)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO tags (name, created_at) VALUES (?, ?) ON CONFLICT(name) DO NOTHING`, tag, now); err != nil {
+			return fmt.Errorf("insert tag: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO account_tags (account_id, tag_id) SELECT ?, id FROM tags WHERE name = ?`, accountID, tag); err != nil {
+			return fmt.Errorf("tag account: %w", err)
+		}
+	}
+	return nil
+}
+
+// RemoveAccountTags detaches tags from an account. Tags no account uses any
+// more are deleted. ErrNotFound means the account had none of them.
+func (s *Store) RemoveAccountTags(ctx context.Context, accountID int64, names ...string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin untag account: %w", err)
+	}
+	var removed int64
+	for _, name := range names {
+		tag, err := NormalizeTag(name)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		res, err := tx.ExecContext(ctx, `DELETE FROM account_tags WHERE account_id = ? AND tag_id = (SELECT id FROM tags WHERE name = ?)`, accountID, tag)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("untag account: %w", err)
+		}
+		n, _ := res.RowsAffected()
+		removed += n
+	}
+	if removed == 0 {
+		tx.Rollback()
+		return ErrNotFound
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tags WHERE id NOT IN (SELECT tag_id FROM account_tags)`); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("delete unused tags: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit untag: %w", err)
+	}
+	return nil
+}
+
+// ListAccountsByTag returns the accounts matching a tag expression such as
+// `tag:enterprise -tag:churned`, ordered alphabetically.
+func (s *Store) ListAccountsByTag(ctx context.Context, expr string) ([]Account, error) {
+	filter, rest := ParseTagFilter(expr)
+	if rest != "" {
+		return nil, fmt.Errorf("unsupported tag filter term %q", rest)
+	}
+	where := []string{"a.deleted_at IS NULL"}
+	var args []any
+	const hasTag = `EXISTS (SELECT 1 FROM account_tags at JOIN tags t ON t.id = at.tag_id WHERE at.account_id = a.id AND t.name = ?)`
+	for _, tag := range filter.Include {
+		where = append(where, hasTag)
+		args = append(args, tag)
+	}
+	for _, tag := range filter.Exclude {
+		where = append(where, "NOT "+hasTag)
+		args = append(args, tag)
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT `+accountColumns+` FROM accounts a WHERE `+strings.Join(where, " AND ")+` ORDER BY a.name COLLATE NOCASE`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query accounts by tag: %w", err)
+	}
+	defer rows.Close()
+
+	var accounts []Account
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan account: %w", err)
+		}
+		accounts = append(accounts, account)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// splitTagList splits a semicolon-separated tag list, dropping blanks and
+// duplicates, and sorts the result.
+func splitTagList(value string) []string {
+	var tags []string
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, ";") {
+		tag := strings.TrimSpace(part)
+		key := strings.ToLower(tag)
+		if tag == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "VIP", want: "vip"},
+		{in: "  North   America ", want: "north-america"},
+		{in: "", wantErr: true},
+		{in: "a;b", wantErr: true},
+		{in: "a,b", wantErr: true},
+		{in: "tag:x", wantErr: true},
+		{in: "-churned", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeTag(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("NormalizeTag(%q) = %q, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("NormalizeTag(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseTagFilter(t *testing.T) {
+	filter, rest := ParseTagFilter("acme TAG:Enterprise -tag:churned tag: west")
+	want := TagFilter{Include: []string{"enterprise"}, Exclude: []string{"churned"}}
+	if !reflect.DeepEqual(filter, want) || rest != "acme west" {
+		t.Errorf("ParseTagFilter = %+v, %q; want %+v, %q", filter, rest, want, "acme west")
+	}
+	if f, _ := ParseTagFilter("acme"); !f.IsZero() {
+		t.Errorf("filter without tag terms = %+v, want zero", f)
+	}
+
+	tests := []struct {
+		tags []string
+		want bool
+	}{
+		{[]string{"enterprise"}, true},
+		{[]string{"Enterprise", "emea"}, true},
+		{[]string{"enterprise", "churned"}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := filter.Matches(tt.tags); got != tt.want {
+			t.Errorf("Matches(%v) = %v, want %v", tt.tags, got, tt.want)
+		}
+	}
+}
+
+func TestAccountTags(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	acme := createTestAccount(t, s, "Acme")
+	beta := createTestAccount(t, s, "Beta")
+	if err := s.AddAccountTags(ctx, acme.ID, "Enterprise", "EMEA", "enterprise"); err != nil {
+		t.Fatalf("tag acme: %v", err)
+	}
+	if err := s.AddAccountTags(ctx, beta.ID, "enterprise", "churned"); err != nil {
+		t.Fatalf("tag beta: %v", err)
+	}
+	if err := s.AddAccountTags(ctx, beta.ID, "bad;tag"); err == nil {
+		t.Errorf("added a tag with a separator in it")
+	}
+
+	tags, err := s.ListTags(ctx)
+	if err != nil {
+		t.Fatalf("list tags: %v", err)
+	}
+	counts := map[string]int{}
+	for _, tag := range tags {
+		counts[tag.Name] = tag.Accounts
+	}
+	if want := map[string]int{"churned": 1, "emea": 1, "enterprise": 2}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("tag counts = %v, want %v", counts, want)
+	}
+
+	names := func(expr string) []string {
+		t.Helper()
+		accounts, err := s.ListAccountsByTag(ctx, expr)
+		if err != nil {
+			t.Fatalf("accounts by %q: %v", expr, err)
+		}
+		var out []string
+		for _, a := range accounts {
+			out = append(out, a.Name)
+		}
+		return out
+	}
+	if got := names("tag:enterprise"); !reflect.DeepEqual(got, []string{"Acme", "Beta"}) {
+		t.Errorf("tag:enterprise = %v", got)
+	}
+	if got := names("tag:enterprise -tag:churned"); !reflect.DeepEqual(got, []string{"Acme"}) {
+		t.Errorf("tag:enterprise -tag:churned = %v", got)
+	}
+	if _, err := s.ListAccountsByTag(ctx, "tag:vip acme"); err == nil {
+		t.Errorf("free text in a tag expression gave no error")
+	}
+
+	if err := s.RemoveAccountTags(ctx, beta.ID, "churned"); err != nil {
+		t.Fatalf("untag beta: %v", err)
+	}
+	if err := s.RemoveAccountTags(ctx, beta.ID, "churned"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("removing a missing tag: err = %v, want ErrNotFound", err)
+	}
+	tags, _ = s.ListTags(ctx)
+	for _, tag := range tags {
+		if tag.Name == "churned" {
+			t.Errorf("unused tag churned was kept")
+		}
+	}
+	accounts, err := s.ListAccounts(ctx)
+	if err != nil {
+		t.Fatalf("list accounts: %v", err)
+	}
+	for _, a := range accounts {
+		if a.Name == "Acme" && !reflect.DeepEqual(a.Tags, []string{"emea", "enterprise"}) {
+			t.Errorf("Acme tags = %v, want emea and enterprise", a.Tags)
+		}
+	}
+}
+
+func TestImportTagsColumn(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	csv := "Name,Tags\nAcme,VIP; emea ;;vip\n"
+	if _, err := s.ImportAccountsCSV(ctx, strings.NewReader(csv), ImportOptions{Creator: "tester", PhoneCountry: "US"}); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	tagged, err := s.ListAccountsByTag(ctx, "tag:vip tag:emea")
+	if err != nil {
+		t.Fatalf("accounts by tag: %v", err)
+	}
+	if len(tagged) != 1 || tagged[0].Name != "Acme" {
+		t.Errorf("accounts tagged vip and emea = %+v, want Acme", tagged)
+	}
+}
+
+func TestSplitTagList(t *testing.T) {
+	got := splitTagList(" vip; EMEA;; Vip ;enterprise ")
+	if want := []string{"EMEA", "enterprise", "vip"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("splitTagList = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Task priorities, ordered so higher values sort first.
+const (
+	PriorityLow    = 0
+	PriorityNormal = 1
+	PriorityHigh   = 2
+)
+
+// Task is a to-do with an optional due date, tied to an optional account.
+type Task struct {
+	ID          int64
+	Title       string
+	Details     string
+	DueAt       time.Time
+	Priority    int
+	DoneAt      time.Time
+	AccountID   sql.NullInt64
+	Creator     string
+	CreatedAt   time.Time
+	AccountName sql.NullString
+}
+
+// Done reports whether the task has been completed.
+func (t Task) Done() bool {
+	return !t.DoneAt.IsZero()
+}
+
+// PriorityLabel names a task priority for display.
+func PriorityLabel(p int) string {
+	switch {
+	case p >= PriorityHigh:
+		return "high"
+	case p <= PriorityLow:
+		return "low"
+	default:
+		return "normal"
+	}
+}
+
+const taskColumns = `t.id, t.title, t.details, t.due_at, t.priority, t.done_at, t.account_id, t.creator, t.created_at, a.name`
+
+// ListTasks returns tasks ordered by due date, optionally including completed ones.
+func (s *Store) ListTasks(ctx context.Context, includeDone bool) ([]Task, error) {
+	where := "WHERE t.deleted_at IS NULL AND t.done_at IS NULL"
+	if includeDone {
+		where = "WHERE t.deleted_at IS NULL"
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT `+taskColumns+` FROM tasks t
+        LEFT JOIN accounts a ON a.id = t.account_id AND a.deleted_at IS NULL
+        `+where+`
+        ORDER BY t.due_at IS NULL, t.due_at ASC, t.priority DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("query tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []Task
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan task: %w", err)
+		}
+		tasks = append(tasks, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// TaskByID retrieves a single task.
+func (s *Store) TaskByID(ctx context.Context, id int64) (*Task, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+taskColumns+` FROM tasks t LEFT JOIN accounts a ON a.id = t.account_id AND a.deleted_at IS NULL WHERE t.id = ? AND t.deleted_at IS NULL`, id)
+	t, err := scanTask(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get task: %w", err)
+	}
+	return &t, nil
+}
+
+// CreateTask persists a new task.
+func (s *Store) CreateTask(ctx context.Context, t *Task) error {
+	if t == nil {
+		return fmt.Errorf("nil task")
+	}
+	if strings.TrimSpace(t.Title) == "" {
+		return fmt.Errorf("task title required")
+	}
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now().UTC()
+	}
+	res, err := s.db.ExecContext(ctx, `INSERT INTO tasks (title, details, due_at, priority, done_at, account_id, creator, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		strings.TrimSpace(t.Title), nullString(t.Details), nullTime(t.DueAt), t.Priority, nullTime(t.DoneAt), nullInt64(t.AccountID), t.Creator, t.CreatedAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("insert task: %w", err)
+	}
+	if id, err := res.LastInsertId(); err == nil {
+		t.ID = id
+	}
+	return nil
+}
+
+// UpdateTask persists edits to a task.
+func (s *Store) UpdateTask(ctx context.Context, t *Task) error {
+	if t == nil {
+		return fmt.Errorf("nil task")
+	}
+	if strings.TrimSpace(t.Title) == "" {
+		return fmt.Errorf("task title required")
+	}
+	res, err := s.db.ExecContext(ctx, `UPDATE tasks SET title = ?, details = ?, due_at = ?, priority = ?, done_at = ?, account_id = ? WHERE id = ? AND deleted_at IS NULL`,
+		strings.TrimSpace(t.Title), nullString(t.Details), nullTime(t.DueAt), t.Priority, nullTime(t.DoneAt), nullInt64(t.AccountID), t.ID)
+	if err != nil {
+		return fmt.Errorf("update task: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// CompleteTask marks a task done at the given time; a zero time reopens it.
+func (s *Store) CompleteTask(ctx context.Context, id int64, at time.Time) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE tasks SET done_at = ? WHERE id = ? AND deleted_at IS NULL`, nullTime(at), id)
+	if err != nil {
+		return fmt.Errorf("complete task: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteTask moves a task to the trash.
+func (s *Store) DeleteTask(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE tasks SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`, trashStamp(), id)
+	if err != nil {
+		return fmt.Errorf("delete task: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func scanTask(rs rowScanner) (Task, error) {
+	var t Task
+	var details, due, done, accountName sql.NullString
+	var created string
+	if err := rs.Scan(&t.ID, &t.Title, &details, &due, &t.Priority, &done, &t.AccountID, &t.Creator, &created, &accountName); err != nil {
+		return Task{}, err
+	}
+	t.Details = nullStringToString(details)
+	t.AccountName = accountName
+	if due.Valid {
+		if parsed, err := time.Parse(time.RFC3339, due.String); err == nil {
+			t.DueAt = parsed
+		}
+	}
+	if done.Valid {
+		if parsed, err := time.Parse(time.RFC3339, done.String); err == nil {
+			t.DoneAt = parsed
+		}
+	}
+	if parsed, err := time.Parse(time.RFC3339, created); err == nil {
+		t.CreatedAt = parsed
+	}
+	return t, nil
+}
+
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// SplitTasks groups open tasks relative to a reference time the same way
+// SplitEvents groups events. Tasks without a due date count as upcoming.
+func SplitTasks(tasks []Task, now time.Time) (overdue, today, upcoming []Task) {
+	loc := now.Location()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	endOfDay := startOfDay.Add(24 * time.Hour)
+
+	for _, t := range tasks {
+		if t.Done() {
+			continue
+		}
+		due := t.DueAt.In(loc)
+		switch {
+		case t.DueAt.IsZero():
+			upcoming = append(upcoming, t)
+		case due.Before(startOfDay):
+			overdue = append(overdue, t)
+		case due.Before(endOfDay):
+			today = append(today, t)
+		default:
+			upcoming = append(upcoming, t)
+		}
+	}
+
+	byDue := func(list []Task) func(i, j int) bool {
+		return func(i, j int) bool {
+			a, b := list[i], list[j]
+			if a.DueAt.IsZero() != b.DueAt.IsZero() {
+				return b.DueAt.IsZero()
+			}
+			if !a.DueAt.Equal(b.DueAt) {
+				return a.DueAt.Before(b.DueAt)
+			}
+			return a.Priority > b.Priority
+		}
+	}
+	sort.SliceStable(overdue, byDue(overdue))
+	sort.SliceStable(today, byDue(today))
+	sort.SliceStable(upcoming, byDue(upcoming))
+
+	return overdue, today, upcoming
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTaskLifecycle(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	account := createTestAccount(t, s, "Acme")
+	due := time.Date(2026, 10, 20, 17, 0, 0, 0, time.UTC)
+	task := Task{Title: " Send proposal ", DueAt: due, Priority: PriorityHigh,
+		AccountID: sql.NullInt64{Int64: account.ID, Valid: true}, Creator: "tester"}
+	if err := s.CreateTask(ctx, &task); err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if err := s.CreateTask(ctx, &Task{Title: "  ", Creator: "tester"}); err == nil {
+		t.Errorf("created a task without a title")
+	}
+
+	got, err := s.TaskByID(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("load task: %v", err)
+	}
+	if got.Title != "Send proposal" || !got.DueAt.Equal(due) || got.Priority != PriorityHigh || got.AccountName.String != "Acme" {
+		t.Errorf("task = %+v, want the trimmed title, due date, priority and account", got)
+	}
+
+	if err := s.CompleteTask(ctx, task.ID, due); err != nil {
+		t.Fatalf("complete task: %v", err)
+	}
+	if open, _ := s.ListTasks(ctx, false); len(open) != 0 {
+		t.Errorf("open tasks = %+v, want the completed one left out", open)
+	}
+	all, err := s.ListTasks(ctx, true)
+	if err != nil {
+		t.Fatalf("list tasks: %v", err)
+	}
+	if len(all) != 1 || !all[0].Done() {
+		t.Errorf("all tasks = %+v, want the completed one", all)
+	}
+	if err := s.CompleteTask(ctx, task.ID, time.Time{}); err != nil {
+		t.Fatalf("reopen task: %v", err)
+	}
+	if open, _ := s.ListTasks(ctx, false); len(open) != 1 {
+		t.Errorf("open tasks = %+v, want the reopened one", open)
+	}
+
+	if err := s.DeleteTask(ctx, task.ID); err != nil {
+		t.Fatalf("delete task: %v", err)
+	}
+	if _, err := s.TaskByID(ctx, task.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("trashed task: err = %v, want ErrNotFound", err)
+	}
+	if err := s.CompleteTask(ctx, task.ID, due); !errors.Is(err, ErrNotFound) {
+		t.Errorf("complete trashed task: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSplitTasks(t *testing.T) {
+	now := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)
+	at := func(day, hour int) time.Time { return time.Date(2026, 10, day, hour, 0, 0, 0, time.UTC) }
+	tasks := []Task{
+		{Title: "later", DueAt: at(20, 9)},
+		{Title: "undated"},
+		{Title: "yesterday", DueAt: at(15, 9)},
+		{Title: "tonight", DueAt: at(16, 23)},
+		{Title: "this morning", DueAt: at(16, 8), Priority: PriorityLow},
+		{Title: "this morning urgent", DueAt: at(16, 8), Priority: PriorityHigh},
+		{Title: "done", DueAt: at(15, 9), DoneAt: at(15, 10)},
+		{Title: "tomorrow", DueAt: at(17, 0)},
+	}
+	overdue, today, upcoming := SplitTasks(tasks, now)
+	titles := func(list []Task) []string {
+		var out []string
+		for _, t := range list {
+			out = append(out, t.Title)
+		}
+		return out
+	}
+	for _, tt := range []struct {
+		name string
+		got  []Task
+		want []string
+	}{
+		{"overdue", overdue, []string{"yesterday"}},
+		{"today", today, []string{"this morning urgent", "this morning", "tonight"}},
+		{"upcoming", upcoming, []string{"tomorrow", "later", "undated"}},
+	} {
+		got := titles(tt.got)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestPriorityLabel(t *testing.T) {
+	tests := []struct {
+		in   int
+		want string
+	}{
+		{PriorityLow, "low"},
+		{PriorityNormal, "normal"},
+		{PriorityHigh, "high"},
+		{5, "high"},
+		{-1, "low"},
+	}
+	for _, tt := range tests {
+		if got := PriorityLabel(tt.in); got != tt.want {
+			t.Errorf("PriorityLabel(%d) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
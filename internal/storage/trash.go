@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// TrashItem is a soft-deleted record waiting to be restored or purged.
+type TrashItem struct {
+	Type      string
+	ID        int64
+	Title     string
+	DeletedAt time.Time
+}
+
+// trashTables maps trash item types to their tables. Children are listed
+// before accounts so purges never leave rows pointing at a missing account.
+var trashTables = []struct {
+	kind  string
+	table string
+}{
+	{"note", "notes"},
+	{"event", "events"},
+	{"task", "tasks"},
+	{"account", "accounts"},
+}
+
+func trashTable(kind string) (string, error) {
+	for _, t := range trashTables {
+		if t.kind == kind {
+			return t.table, nil
+		}
+	}
+	return "", fmt.Errorf("unknown trash type %q", kind)
+}
+
+// trashStamp is the deleted_at value for rows trashed now.
+func trashStamp() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+// newTrashBatch returns the trash_batch value shared by everything one
+// operation trashes, so an account is restored or purged together with
+// the rows that went with it. Stamps alone cannot tell apart two deletes
+// in the same second.
+func newTrashBatch() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// ListTrash returns soft-deleted records, most recently deleted first.
+func (s *Store) ListTrash(ctx context.Context) ([]TrashItem, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT type, id, title, deleted_at FROM (
+            SELECT 'account' AS type, id, name AS title, deleted_at FROM accounts WHERE deleted_at IS NOT NULL
+            UNION ALL
+            SELECT 'note' AS type, id, substr(content, 1, 80) AS title, deleted_at FROM notes WHERE deleted_at IS NOT NULL
+            UNION ALL
+            SELECT 'event' AS type, id, title, deleted_at FROM events WHERE deleted_at IS NOT NULL
+            UNION ALL
+            SELECT 'task' AS type, id, title, deleted_at FROM tasks WHERE deleted_at IS NOT NULL
+        ) ORDER BY deleted_at DESC, type, id`)
+	if err != nil {
+		return nil, fmt.Errorf("query trash: %w", err)
+	}
+	defer rows.Close()
+
+	var items []TrashItem
+	for rows.Next() {
+		var item TrashItem
+		var deleted string
+		if err := rows.Scan(&item.Type, &item.ID, &item.Title, &deleted); err != nil {
+			return nil, fmt.Errorf("scan trash: %w", err)
+		}
+		if t, err := time.Parse(time.RFC3339, deleted); err == nil {
+			item.DeletedAt = t
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Restore brings a trashed record back. Restoring an account also restores
+// the notes, events and tasks that were trashed along with it.
+func (s *Store) Restore(ctx context.Context, item TrashItem) error {
+	table, err := trashTable(item.Type)
+	if err != nil {
+		return err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin restore: %w", err)
+	}
+	var stamp, batch sql.NullString
+	if err := tx.QueryRowContext(ctx, `SELECT deleted_at, trash_batch FROM `+table+` WHERE id = ?`, item.ID).Scan(&stamp, &batch); err != nil || !stamp.Valid {
+		tx.Rollback()
+		if err == nil || errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("restore %s: %w", item.Type, err)
+	}
+	if item.Type == "account" && batch.Valid {
+		for _, child := range trashTables {
+			if child.kind == "account" {
+				continue
+			}
+			if _, err := tx.ExecContext(ctx, `UPDATE `+child.table+` SET deleted_at = NULL, trash_batch = NULL WHERE account_id = ? AND trash_batch = ?`, item.ID, batch.String); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("restore %s: %w", child.table, err)
+			}
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE `+table+` SET deleted_at = NULL, trash_batch = NULL WHERE id = ?`, item.ID); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("restore %s: %w", item.Type, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit restore: %w", err)
+	}
+	return nil
+}
+
+// Purge permanently deletes a trashed record. Purging an account also purges
+// whatever was trashed with it, plus its contacts and deals.
+func (s *Store) Purge(ctx context.Context, item TrashItem) error {
+	table, err := trashTable(item.Type)
+	if err != nil {
+		return err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin purge: %w", err)
+	}
+	if item.Type == "account" {
+		for _, child := range trashTables {
+			if child.kind == "account" {
+				continue
+			}
+			if _, err := tx.ExecContext(ctx, `DELETE FROM `+child.table+` WHERE account_id = ? AND deleted_at IS NOT NULL
+                AND trash_batch = (SELECT trash_batch FROM accounts WHERE id = ? AND deleted_at IS NOT NULL)`, item.ID, item.ID); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("purge %s: %w", child.table, err)
+			}
+		}
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE id = ? AND deleted_at IS NOT NULL`, item.ID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("purge %s: %w", item.Type, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		tx.Rollback()
+		return ErrNotFound
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit purge: %w", err)
+	}
+	return nil
+}
+
+// PurgeTrash permanently deletes everything trashed before the cutoff.
+func (s *Store) PurgeTrash(ctx context.Context, before time.Time) (CleanupResult, error) {
+	var result CleanupResult
+	if before.IsZero() {
+		return result, nil
+	}
+	cutoff := before.UTC().Format(time.RFC3339)
+	for _, t := range trashTables {
+		res, err := s.db.ExecContext(ctx, `DELETE FROM `+t.table+` WHERE deleted_at IS NOT NULL AND deleted_at < ?`, cutoff)
+		if err != nil {
+			return result, fmt.Errorf("purge %s: %w", t.table, err)
+		}
+		n, _ := res.RowsAffected()
+		switch t.kind {
+		case "account":
+			result.Accounts = n
+		case "note":
+			result.Notes = n
+		case "event":
+			result.Events = n
+		case "task":
+			result.Tasks = n
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,199 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestUpdatesSkipTrashedRecords(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	account := createTestAccount(t, s, "Acme")
+	note := Note{Content: "Call back", Creator: "tester"}
+	if err := s.CreateNote(ctx, &note); err != nil {
+		t.Fatalf("create note: %v", err)
+	}
+	event := Event{Title: "Demo", EventTime: time.Date(2026, 3, 1, 15, 0, 0, 0, time.UTC), Creator: "tester"}
+	if err := s.CreateEvent(ctx, &event); err != nil {
+		t.Fatalf("create event: %v", err)
+	}
+	task := Task{Title: "Send quote", Creator: "tester"}
+	if err := s.CreateTask(ctx, &task); err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if err := s.DeleteNote(ctx, note.ID); err != nil {
+		t.Fatalf("delete note: %v", err)
+	}
+	if err := s.DeleteEvent(ctx, event.ID); err != nil {
+		t.Fatalf("delete event: %v", err)
+	}
+	if err := s.DeleteTask(ctx, task.ID); err != nil {
+		t.Fatalf("delete task: %v", err)
+	}
+	if _, err := s.DeleteAccount(ctx, account.ID, false); err != nil {
+		t.Fatalf("delete account: %v", err)
+	}
+
+	note.Content = "Edited"
+	event.Title = "Edited"
+	task.Title = "Edited"
+	account.Name = "Edited"
+	checks := []struct {
+		name string
+		err  error
+	}{
+		{"UpdateNote", s.UpdateNote(ctx, &note)},
+		{"UpdateEvent", s.UpdateEvent(ctx, &event)},
+		{"UpdateTask", s.UpdateTask(ctx, &task)},
+		{"CompleteTask", s.CompleteTask(ctx, task.ID, time.Now())},
+		{"UpdateAccount", s.UpdateAccount(ctx, &account)},
+	}
+	for _, c := range checks {
+		if !errors.Is(c.err, ErrNotFound) {
+			t.Errorf("%s on a trashed record: err = %v, want ErrNotFound", c.name, c.err)
+		}
+	}
+
+	var content string
+	if err := s.db.QueryRowContext(ctx, `SELECT content FROM notes WHERE id = ?`, note.ID).Scan(&content); err != nil {
+		t.Fatalf("read trashed note: %v", err)
+	}
+	if content != "Call back" {
+		t.Errorf("trashed note content = %q, want it unchanged", content)
+	}
+}
+
+func TestRestoreAccountBringsBackOnlyItsBatch(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	account := createTestAccount(t, s, "Acme")
+	accountID := sql.NullInt64{Int64: account.ID, Valid: true}
+	alone := Note{Content: "Deleted on its own", AccountID: accountID, Creator: "tester"}
+	along := Note{Content: "Deleted with the account", AccountID: accountID, Creator: "tester"}
+	for _, n := range []*Note{&alone, &along} {
+		if err := s.CreateNote(ctx, n); err != nil {
+			t.Fatalf("create note: %v", err)
+		}
+	}
+	if err := s.DeleteNote(ctx, alone.ID); err != nil {
+		t.Fatalf("delete note: %v", err)
+	}
+	res, err := s.DeleteAccount(ctx, account.ID, true)
+	if err != nil {
+		t.Fatalf("delete account: %v", err)
+	}
+	if res.Notes != 1 {
+		t.Fatalf("cascade trashed %d notes, want 1", res.Notes)
+	}
+	// Two deletes within one second share a stamp; only the batch tells
+	// them apart.
+	if _, err := s.db.ExecContext(ctx, `UPDATE notes SET deleted_at = (SELECT deleted_at FROM accounts WHERE id = ?) WHERE id = ?`, account.ID, alone.ID); err != nil {
+		t.Fatalf("align stamps: %v", err)
+	}
+
+	if err := s.Restore(ctx, TrashItem{Type: "account", ID: account.ID}); err != nil {
+		t.Fatalf("restore account: %v", err)
+	}
+	if _, err := s.NoteByID(ctx, along.ID); err != nil {
+		t.Errorf("note trashed with the account not restored: %v", err)
+	}
+	if _, err := s.NoteByID(ctx, alone.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("separately trashed note: err = %v, want it still in the trash", err)
+	}
+
+	if _, err := s.DeleteAccount(ctx, account.ID, true); err != nil {
+		t.Fatalf("delete account again: %v", err)
+	}
+	if err := s.Purge(ctx, TrashItem{Type: "account", ID: account.ID}); err != nil {
+		t.Fatalf("purge account: %v", err)
+	}
+	items, err := s.ListTrash(ctx)
+	if err != nil {
+		t.Fatalf("list trash: %v", err)
+	}
+	if len(items) != 1 || items[0].Type != "note" || items[0].ID != alone.ID {
+		t.Errorf("trash after purge = %+v, want only the separately trashed note", items)
+	}
+}
+
+func TestTrashedAccountHoldsItsName(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	trashed := createTestAccount(t, s, "Acme")
+	if _, err := s.DeleteAccount(ctx, trashed.ID, false); err != nil {
+		t.Fatalf("delete account: %v", err)
+	}
+	if err := s.CreateAccount(ctx, &Account{Name: "Acme", Creator: "tester"}); !errors.Is(err, ErrAccountTrashed) {
+		t.Errorf("create with a trashed name: err = %v, want ErrAccountTrashed", err)
+	}
+	other := createTestAccount(t, s, "Beta")
+	other.Name = "Acme"
+	if err := s.UpdateAccount(ctx, &other); !errors.Is(err, ErrAccountTrashed) {
+		t.Errorf("rename to a trashed name: err = %v, want ErrAccountTrashed", err)
+	}
+	other.Name = "Gamma"
+	if err := s.UpdateAccount(ctx, &other); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if err := s.CreateAccount(ctx, &Account{Name: "Gamma", Creator: "tester"}); !errors.Is(err, ErrAccountExists) {
+		t.Errorf("create with a live name: err = %v, want ErrAccountExists", err)
+	}
+}
+
+func TestPurgeTrashBeforeCutoff(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	old := createTestAccount(t, s, "Old")
+	recent := createTestAccount(t, s, "Recent")
+	live := createTestAccount(t, s, "Live")
+	for _, a := range []Account{old, recent} {
+		n := Note{Content: "about " + a.Name, AccountID: sql.NullInt64{Int64: a.ID, Valid: true}, Creator: "tester"}
+		if err := s.CreateNote(ctx, &n); err != nil {
+			t.Fatalf("create note: %v", err)
+		}
+		if _, err := s.DeleteAccount(ctx, a.ID, true); err != nil {
+			t.Fatalf("delete account: %v", err)
+		}
+	}
+	backdate := time.Now().AddDate(0, 0, -40).UTC().Format(time.RFC3339)
+	for _, stmt := range []string{
+		`UPDATE accounts SET deleted_at = ? WHERE id = ?`,
+		`UPDATE notes SET deleted_at = ? WHERE account_id = ?`,
+	} {
+		if _, err := s.db.ExecContext(ctx, stmt, backdate, old.ID); err != nil {
+			t.Fatalf("backdate trash: %v", err)
+		}
+	}
+
+	if res, err := s.PurgeTrash(ctx, time.Time{}); err != nil || res.Accounts+res.Notes != 0 {
+		t.Errorf("purge without a cutoff = %+v, %v; want nothing purged", res, err)
+	}
+	res, err := s.PurgeTrash(ctx, time.Now().AddDate(0, 0, -30))
+	if err != nil {
+		t.Fatalf("purge trash: %v", err)
+	}
+	if res.Accounts != 1 || res.Notes != 1 {
+		t.Errorf("purged %d accounts and %d notes, want 1 and 1", res.Accounts, res.Notes)
+	}
+	items, err := s.ListTrash(ctx)
+	if err != nil {
+		t.Fatalf("list trash: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("trash after purge = %+v, want Recent and its note", items)
+	}
+	for _, item := range items {
+		if item.Type == "account" && item.ID != recent.ID {
+			t.Errorf("trash still holds account %d, want only Recent", item.ID)
+		}
+	}
+	if _, err := s.AccountByID(ctx, live.ID); err != nil {
+		t.Errorf("live account touched by the purge: %v", err)
+	}
+	if err := s.Purge(ctx, TrashItem{Type: "account", ID: live.ID}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("purging a live account: err = %v, want ErrNotFound", err)
+	}
+}
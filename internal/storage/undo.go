@@ -0,0 +1,325 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Snapshot is a saved copy of the rows an operation is about to change.
+// Restoring it puts those rows back exactly as they were, so the UI can
+// offer undo without every operation needing a hand-written inverse.
+type Snapshot struct {
+	scopes []snapshotScope
+}
+
+// snapshotScope is the set of rows of one table selected by where.
+type snapshotScope struct {
+	table string
+	where string
+	args  []any
+	// exact scopes also delete rows that appeared after the snapshot, so
+	// undoing a creation removes the new row.
+	exact bool
+	// unique, when set, restores rows by this column instead of by id: a
+	// row whose value is stored again, possibly under a new id, is reused.
+	unique string
+	// refs maps columns holding another scope's ids to that scope's table,
+	// so they follow rows that unique restored under a new id.
+	refs    map[string]string
+	columns []string
+	rows    [][]any
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// accountScopes covers an account with its contacts, tags, custom field
+// values and status history. Parents come before children so restores
+// satisfy foreign keys.
+func accountScopes(id int64) []snapshotScope {
+	return []snapshotScope{
+		{table: "accounts", where: "id = ?", args: []any{id}, exact: true},
+		// Unused tags are deleted, and one of the same name may have been
+		// created since, so tags come back by name.
+		{table: "tags", where: "id IN (SELECT tag_id FROM account_tags WHERE account_id = ?)", args: []any{id}, unique: "name"},
+		{table: "contacts", where: "account_id = ?", args: []any{id}, exact: true},
+		{table: "account_tags", where: "account_id = ?", args: []any{id}, exact: true, refs: map[string]string{"tag_id": "tags"}},
+		{table: "account_field_values", where: "account_id = ?", args: []any{id}, exact: true},
+		{table: "account_status_changes", where: "account_id = ?", args: []any{id}, exact: true},
+	}
+}
+
+// mergeScopes covers everything MergeAccounts touches: both accounts with
+// their contacts, tags and field values, and the rows it moves from drop to
+// keep. Moved rows are upserted back, which points them at drop again.
+func mergeScopes(keepID, dropID int64) []snapshotScope {
+	both := []any{keepID, dropID}
+	drop := []any{dropID}
+	return []snapshotScope{
+		{table: "accounts", where: "id IN (?, ?)", args: both, exact: true},
+		{table: "accounts", where: "parent_id = ?", args: drop},
+		{table: "tags", where: "id IN (SELECT tag_id FROM account_tags WHERE account_id IN (?, ?))", args: both, unique: "name"},
+		{table: "contacts", where: "account_id IN (?, ?)", args: both, exact: true},
+		{table: "account_tags", where: "account_id IN (?, ?)", args: both, exact: true, refs: map[string]string{"tag_id": "tags"}},
+		{table: "account_field_values", where: "account_id IN (?, ?)", args: both, exact: true},
+		{table: "notes", where: "account_id = ?", args: drop},
+		{table: "events", where: "account_id = ?", args: drop},
+		{table: "tasks", where: "account_id = ?", args: drop},
+		{table: "deals", where: "account_id = ?", args: drop},
+		{table: "account_status_changes", where: "account_id = ?", args: drop},
+		{table: "account_merges", where: "account_id IN (?, ?)", args: both, exact: true},
+	}
+}
+
+func recordScopes(kind string, id int64) ([]snapshotScope, error) {
+	if kind == "deal" {
+		return []snapshotScope{
+			{table: "deals", where: "id = ?", args: []any{id}, exact: true},
+			{table: "deal_stage_changes", where: "deal_id = ?", args: []any{id}, exact: true},
+		}, nil
+	}
+	if kind == "contact" {
+		// Contacts are deleted outright, taking their attendance with them.
+		// The account's primary contact is kept too, since marking this one
+		// primary demotes it.
+		return []snapshotScope{
+			{table: "contacts", where: "id = ?", args: []any{id}, exact: true},
+			{table: "contacts", where: "is_primary = 1 AND id != ? AND account_id = (SELECT account_id FROM contacts WHERE id = ?)", args: []any{id, id}},
+			{table: "event_attendees", where: "contact_id = ?", args: []any{id}, exact: true},
+		}, nil
+	}
+	table, err := trashTable(kind)
+	if err != nil {
+		return nil, err
+	}
+	switch table {
+	case "accounts":
+		return accountScopes(id), nil
+	case "events":
+		return []snapshotScope{
+			{table: "events", where: "id = ?", args: []any{id}, exact: true},
+			{table: "event_exceptions", where: "event_id = ?", args: []any{id}, exact: true},
+			{table: "event_attendees", where: "event_id = ?", args: []any{id}, exact: true},
+		}, nil
+	}
+	return []snapshotScope{{table: table, where: "id = ?", args: []any{id}, exact: true}}, nil
+}
+
+// SnapshotRecord captures a note, event, task, contact, deal or account
+// (with everything hanging off it) before it is edited or deleted.
+func (s *Store) SnapshotRecord(ctx context.Context, kind string, id int64) (*Snapshot, error) {
+	scopes, err := recordScopes(kind, id)
+	if err != nil {
+		return nil, err
+	}
+	return captureSnapshot(ctx, s.db, scopes)
+}
+
+// SnapshotTrashItem captures a trashed record before it is restored. For an
+// account that includes the notes, events and tasks in the trash with it.
+func (s *Store) SnapshotTrashItem(ctx context.Context, item TrashItem) (*Snapshot, error) {
+	scopes, err := recordScopes(item.Type, item.ID)
+	if err != nil {
+		return nil, err
+	}
+	if item.Type == "account" {
+		for _, table := range []string{"notes", "events", "tasks"} {
+			scopes = append(scopes, snapshotScope{table: table, where: "account_id = ? AND deleted_at IS NOT NULL", args: []any{item.ID}})
+		}
+	}
+	return captureSnapshot(ctx, s.db, scopes)
+}
+
+// SnapshotMerge captures two accounts before MergeAccounts folds dropID
+// into keepID, so restoring it splits them apart again.
+func (s *Store) SnapshotMerge(ctx context.Context, keepID, dropID int64) (*Snapshot, error) {
+	return captureSnapshot(ctx, s.db, mergeScopes(keepID, dropID))
+}
+
+// CreatedRecord returns the snapshot of a record as it was before it was
+// created: restoring it deletes the record again.
+func CreatedRecord(kind string, id int64) (*Snapshot, error) {
+	scopes, err := recordScopes(kind, id)
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{scopes: scopes}, nil
+}
+
+func captureSnapshot(ctx context.Context, q queryer, scopes []snapshotScope) (*Snapshot, error) {
+	for i := range scopes {
+		sc := &scopes[i]
+		rows, err := q.QueryContext(ctx, `SELECT * FROM `+sc.table+` WHERE `+sc.where, sc.args...)
+		if err != nil {
+			return nil, fmt.Errorf("snapshot %s: %w", sc.table, err)
+		}
+		sc.columns, err = rows.Columns()
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("snapshot %s columns: %w", sc.table, err)
+		}
+		for rows.Next() {
+			values := make([]any, len(sc.columns))
+			ptrs := make([]any, len(values))
+			for j := range values {
+				ptrs[j] = &values[j]
+			}
+			if err := rows.Scan(ptrs...); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("snapshot %s row: %w", sc.table, err)
+			}
+			sc.rows = append(sc.rows, values)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &Snapshot{scopes: scopes}, nil
+}
+
+// RestoreSnapshot writes the captured rows back, undoing whatever changed
+// them since. Rows are upserted by primary key so restoring an account does
+// not cascade through its contacts and deals.
+func (s *Store) RestoreSnapshot(ctx context.Context, snap *Snapshot) error {
+	if snap == nil {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin restore: %w", err)
+	}
+	// Children first, so removing a created account does not trip over
+	// link rows restored a moment earlier.
+	for i := len(snap.scopes) - 1; i >= 0; i-- {
+		sc := snap.scopes[i]
+		if !sc.exact {
+			continue
+		}
+		query, args := sc.removeExtras()
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("restore %s: %w", sc.table, err)
+		}
+	}
+	// ids maps the captured ids of unique scopes to the ids restored.
+	ids := map[string]map[any]any{}
+	for _, sc := range snap.scopes {
+		if len(sc.rows) == 0 {
+			continue
+		}
+		if sc.unique != "" {
+			mapped, err := sc.restoreUnique(ctx, tx)
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+			ids[sc.table] = mapped
+			continue
+		}
+		query := sc.upsert()
+		for _, row := range sc.rows {
+			row = append([]any(nil), row...)
+			for i, c := range sc.columns {
+				if id, ok := ids[sc.refs[c]][row[i]]; ok {
+					row[i] = id
+				}
+			}
+			if _, err := tx.ExecContext(ctx, query, row...); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("restore %s: %w", sc.table, err)
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit restore: %w", err)
+	}
+	return nil
+}
+
+// idColumn reports the position of the id column, or -1 for link tables
+// keyed by their foreign keys.
+func (sc snapshotScope) idColumn() int {
+	for i, c := range sc.columns {
+		if c == "id" {
+			return i
+		}
+	}
+	return -1
+}
+
+// removeExtras returns the delete that drops rows in the scope which were
+// not captured. Link tables are cleared and reinserted in full.
+func (sc snapshotScope) removeExtras() (string, []any) {
+	query := `DELETE FROM ` + sc.table + ` WHERE (` + sc.where + `)`
+	args := append([]any(nil), sc.args...)
+	idx := sc.idColumn()
+	if idx < 0 && len(sc.columns) > 0 || len(sc.rows) == 0 {
+		return query, args
+	}
+	marks := make([]string, len(sc.rows))
+	for i, row := range sc.rows {
+		marks[i] = "?"
+		args = append(args, row[idx])
+	}
+	return query + ` AND id NOT IN (` + strings.Join(marks, ", ") + `)`, args
+}
+
+// restoreUnique inserts the rows whose unique value is no longer stored,
+// leaves the others as they are, and maps each captured id to the id the
+// row has now.
+func (sc snapshotScope) restoreUnique(ctx context.Context, tx *sql.Tx) (map[any]any, error) {
+	idx, key := sc.idColumn(), -1
+	var names []string
+	for i, c := range sc.columns {
+		if c == sc.unique {
+			key = i
+		}
+		if i != idx {
+			names = append(names, c)
+		}
+	}
+	if idx < 0 || key < 0 {
+		return nil, fmt.Errorf("restore %s: no id or %s column", sc.table, sc.unique)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", ")
+	insert := `INSERT INTO ` + sc.table + ` (` + strings.Join(names, ", ") + `) VALUES (` + placeholders + `)
+        ON CONFLICT(` + sc.unique + `) DO NOTHING`
+	mapped := map[any]any{}
+	for _, row := range sc.rows {
+		args := make([]any, 0, len(names))
+		for i, v := range row {
+			if i != idx {
+				args = append(args, v)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, insert, args...); err != nil {
+			return nil, fmt.Errorf("restore %s: %w", sc.table, err)
+		}
+		var id int64
+		if err := tx.QueryRowContext(ctx, `SELECT id FROM `+sc.table+` WHERE `+sc.unique+` = ?`, row[key]).Scan(&id); err != nil {
+			return nil, fmt.Errorf("restore %s: %w", sc.table, err)
+		}
+		mapped[row[idx]] = id
+	}
+	return mapped, nil
+}
+
+func (sc snapshotScope) upsert() string {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(sc.columns)), ", ")
+	query := `INSERT INTO ` + sc.table + ` (` + strings.Join(sc.columns, ", ") + `) VALUES (` + placeholders + `)`
+	if sc.idColumn() < 0 {
+		return `INSERT OR IGNORE` + strings.TrimPrefix(query, `INSERT`)
+	}
+	sets := make([]string, 0, len(sc.columns))
+	for _, c := range sc.columns {
+		if c != "id" {
+			sets = append(sets, c+" = excluded."+c)
+		}
+	}
+	return query + ` ON CONFLICT(id) DO UPDATE SET ` + strings.Join(sets, ", ")
+}
@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+func TestRestoreSnapshotReusesRecreatedTag(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	acme := createTestAccount(t, s, "Acme")
+	beta := createTestAccount(t, s, "Beta")
+	if err := s.AddAccountTags(ctx, acme.ID, "vip", "emea"); err != nil {
+		t.Fatalf("tag acme: %v", err)
+	}
+	snap, err := s.SnapshotRecord(ctx, "account", acme.ID)
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	// Removing the last use deletes the tag; tagging another account
+	// creates it again under a new id.
+	if err := s.RemoveAccountTags(ctx, acme.ID, "vip"); err != nil {
+		t.Fatalf("untag acme: %v", err)
+	}
+	if err := s.AddAccountTags(ctx, beta.ID, "VIP"); err != nil {
+		t.Fatalf("tag beta: %v", err)
+	}
+
+	if err := s.RestoreSnapshot(ctx, snap); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+
+	tagged, err := s.ListAccountsByTag(ctx, "tag:vip")
+	if err != nil {
+		t.Fatalf("accounts by tag: %v", err)
+	}
+	if len(tagged) != 2 {
+		t.Errorf("%d accounts tagged vip after undo, want Acme and Beta: %+v", len(tagged), tagged)
+	}
+	var tags int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM tags`).Scan(&tags); err != nil {
+		t.Fatalf("count tags: %v", err)
+	}
+	if tags != 2 {
+		t.Errorf("%d tags stored, want vip and emea once each", tags)
+	}
+}
+
+func TestRestoreSnapshotUndoesCreation(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	account := createTestAccount(t, s, "Acme")
+	note := Note{Content: "Hello", Creator: "tester"}
+	if err := s.CreateNote(ctx, &note); err != nil {
+		t.Fatalf("create note: %v", err)
+	}
+	for _, rec := range []struct {
+		kind string
+		id   int64
+	}{{"note", note.ID}, {"account", account.ID}} {
+		snap, err := CreatedRecord(rec.kind, rec.id)
+		if err != nil {
+			t.Fatalf("created record %s: %v", rec.kind, err)
+		}
+		if err := s.RestoreSnapshot(ctx, snap); err != nil {
+			t.Fatalf("undo %s: %v", rec.kind, err)
+		}
+	}
+	if _, err := s.NoteByID(ctx, note.ID); err == nil {
+		t.Errorf("note still there after undoing its creation")
+	}
+	if _, err := s.AccountByName(ctx, "Acme"); err == nil {
+		t.Errorf("account still there after undoing its creation")
+	}
+}
+
+func TestRestoreSnapshotUndoesStageAndStatus(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	account := createTestAccount(t, s, "Acme")
+	d := Deal{AccountID: account.ID, Title: "Renewal", Stage: "Proposal", Creator: "tester"}
+	if err := s.CreateDeal(ctx, &d); err != nil {
+		t.Fatalf("create deal: %v", err)
+	}
+
+	dealSnap, err := s.SnapshotRecord(ctx, "deal", d.ID)
+	if err != nil {
+		t.Fatalf("snapshot deal: %v", err)
+	}
+	if err := s.MoveDealStage(ctx, d.ID, "Won", "tester"); err != nil {
+		t.Fatalf("move deal: %v", err)
+	}
+	accountSnap, err := s.SnapshotRecord(ctx, "account", account.ID)
+	if err != nil {
+		t.Fatalf("snapshot account: %v", err)
+	}
+	if err := s.SetAccountStatus(ctx, account.ID, "Customer", "tester"); err != nil {
+		t.Fatalf("set status: %v", err)
+	}
+
+	if err := s.RestoreSnapshot(ctx, accountSnap); err != nil {
+		t.Fatalf("undo status: %v", err)
+	}
+	if err := s.RestoreSnapshot(ctx, dealSnap); err != nil {
+		t.Fatalf("undo move: %v", err)
+	}
+
+	got, err := s.DealByID(ctx, d.ID)
+	if err != nil {
+		t.Fatalf("load deal: %v", err)
+	}
+	if got.Stage != "Proposal" {
+		t.Errorf("stage = %q after undo, want Proposal", got.Stage)
+	}
+	if changes, _ := s.ListDealStageChanges(ctx, d.ID); len(changes) != 1 {
+		t.Errorf("%d stage changes after undo, want only the creation: %+v", len(changes), changes)
+	}
+	restored, err := s.AccountByID(ctx, account.ID)
+	if err != nil {
+		t.Fatalf("load account: %v", err)
+	}
+	if restored.Status != account.Status {
+		t.Errorf("status = %q after undo, want %q", restored.Status, account.Status)
+	}
+	if changes, _ := s.ListAccountStatusChanges(ctx, account.ID); len(changes) != 0 {
+		t.Errorf("%d status changes after undo, want none: %+v", len(changes), changes)
+	}
+}
+
+func TestSnapshotTrashItemUndoesRestore(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	account := createTestAccount(t, s, "Acme")
+	note := Note{Content: "Hello", AccountID: sql.NullInt64{Int64: account.ID, Valid: true}, Creator: "tester"}
+	if err := s.CreateNote(ctx, &note); err != nil {
+		t.Fatalf("create note: %v", err)
+	}
+	if _, err := s.DeleteAccount(ctx, account.ID, true); err != nil {
+		t.Fatalf("delete account: %v", err)
+	}
+	item := TrashItem{Type: "account", ID: account.ID, Title: account.Name}
+	snap, err := s.SnapshotTrashItem(ctx, item)
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if err := s.Restore(ctx, item); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if err := s.RestoreSnapshot(ctx, snap); err != nil {
+		t.Fatalf("undo restore: %v", err)
+	}
+	trash, err := s.ListTrash(ctx)
+	if err != nil {
+		t.Fatalf("list trash: %v", err)
+	}
+	if len(trash) != 2 {
+		t.Fatalf("trash = %+v, want the account and its note back in it", trash)
+	}
+	if err := s.Restore(ctx, item); err != nil {
+		t.Fatalf("restore again: %v", err)
+	}
+	if _, err := s.NoteByID(ctx, note.ID); err != nil {
+		t.Errorf("note did not come back with its account: %v", err)
+	}
+}
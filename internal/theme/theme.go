@@ -14,9 +14,12 @@ type Theme struct {
 	Danger    lipgloss.Style
 	Faint     lipgloss.Style
 	Highlight lipgloss.Style
+	Tag       lipgloss.Style
 	Border    lipgloss.Style
 	HelpKey   lipgloss.Style
 	HelpValue lipgloss.Style
+	Panel     lipgloss.Style
+	Focus     lipgloss.Style
 }
 
 // Default returns a high-contrast palette that plays nicely with common terminals.
@@ -33,8 +36,11 @@ func Default() Theme {
 		Danger:    lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Bold(true),
 		Faint:     lipgloss.NewStyle().Foreground(lipgloss.Color("243")),
 		Highlight: lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true),
+		Tag:       lipgloss.NewStyle().Foreground(lipgloss.Color("230")).Background(lipgloss.Color("61")).Padding(0, 1),
 		Border:    lipgloss.NewStyle().Foreground(lipgloss.Color("240")),
 		HelpKey:   lipgloss.NewStyle().Foreground(lipgloss.Color("117")).Bold(true),
 		HelpValue: lipgloss.NewStyle().Foreground(lipgloss.Color("249")),
+		Panel:     lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("240")).Padding(0, 1),
+		Focus:     lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("205")).Padding(0, 1),
 	}
 }
@@ -0,0 +1,461 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"crmterm/internal/storage"
+)
+
+const (
+	activityEntryPrompt   = "1=Edit  2=Delete  3=Back"
+	activitySeriesPrompt  = "1=Edit series  2=Delete series  e <#>=Edit one  c <#>=Cancel one  3=Back"
+	activityConfirmPrompt = "Delete? (y/n)"
+	activityCancelPrompt  = "Cancel this occurrence? (y/n)"
+	accountDeletePrompt   = "c=Delete everything  o=Keep & unlink  n=Cancel"
+)
+
+// activityEntryModel holds the note or event opened from an account's
+// activity list. Other activity types are shown read-only.
+type activityEntryModel struct {
+	activity storage.Activity
+	note     *storage.Note
+	event    *storage.Event
+	confirm  bool
+	err      string
+	// occurrences lists the next occurrences of a recurring event.
+	occurrences []storage.Event
+	// cancelling is the occurrence a y/n confirmation will cancel.
+	cancelling *storage.Event
+}
+
+func (e activityEntryModel) editable() bool {
+	return e.note != nil || e.event != nil
+}
+
+func (e activityEntryModel) prompt() string {
+	if e.event != nil && e.event.Recurrence != "" {
+		return activitySeriesPrompt
+	}
+	return activityEntryPrompt
+}
+
+// accountActivityCommand handles "open 3", "edit 3" and "del 3" typed on the
+// account screen. It reports false when input is not an entry command.
+func (m *model) accountActivityCommand(input string) (tea.Cmd, bool) {
+	verbs := []struct {
+		names  []string
+		action string
+	}{
+		{[]string{"open", "o"}, "open"},
+		{[]string{"edit", "e"}, "edit"},
+		{[]string{"delete", "del", "d"}, "delete"},
+	}
+	for _, verb := range verbs {
+		arg, ok := cutCommand(input, verb.names...)
+		if !ok {
+			continue
+		}
+		idx, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+		if err != nil || idx <= 0 || idx > len(m.accountDetail.activity) {
+			m.accountDetail.err = "Invalid entry number"
+			return nil, true
+		}
+		m.accountDetail.err = ""
+		focus, err := m.openActivityEntry(m.accountDetail.activity[idx-1], verb.action)
+		if err != nil {
+			m.accountDetail.err = err.Error()
+		}
+		return focus, true
+	}
+	return nil, false
+}
+
+// openActivityEntry shows a single activity entry, optionally jumping
+// straight to its edit wizard or delete confirmation.
+func (m *model) openActivityEntry(act storage.Activity, action string) (tea.Cmd, error) {
+	m.entry = activityEntryModel{activity: act}
+	m.infoMessage = ""
+	if err := m.loadActivityEntry(); err != nil {
+		return nil, err
+	}
+	m.pushState(stateActivityEntry)
+	switch action {
+	case "edit":
+		if cmd, ok := m.editActivityEntry(); ok {
+			return cmd, nil
+		}
+	case "delete":
+		if m.entry.editable() {
+			m.entry.confirm = true
+			return m.setMenuInput(activityConfirmPrompt, 8), nil
+		}
+		m.entry.err = "Only notes and events can be deleted here"
+	}
+	return m.setMenuInput(m.entry.prompt(), 40), nil
+}
+
+func (m *model) loadActivityEntry() error {
+	ctx := context.Background()
+	m.entry.note, m.entry.event = nil, nil
+	switch m.entry.activity.Type {
+	case "note":
+		note, err := m.store.NoteByID(ctx, m.entry.activity.ID)
+		if err != nil {
+			return fmt.Errorf("load note: %w", err)
+		}
+		m.entry.note = note
+	case "event":
+		event, err := m.store.EventByID(ctx, m.entry.activity.ID)
+		if err != nil {
+			return fmt.Errorf("load event: %w", err)
+		}
+		m.entry.event = event
+		m.loadEntryOccurrences()
+	}
+	return nil
+}
+
+// ACTIVITY ENTRY
+func (m *model) updateActivityEntry(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+	placeholder, limit := m.entry.prompt(), 40
+	if m.entry.confirm {
+		placeholder, limit = activityConfirmPrompt, 8
+	} else if m.entry.cancelling != nil {
+		placeholder, limit = activityCancelPrompt, 8
+	}
+	if focus := m.ensureMenuInput(placeholder, limit); focus != nil {
+		cmds = append(cmds, focus)
+	}
+	var cmd tea.Cmd
+	m.menuInput, cmd = m.menuInput.Update(msg)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return batchCmds(cmds)
+	}
+	if key.Type == tea.KeyEsc {
+		if focus := m.closeActivityEntry(); focus != nil {
+			cmds = append(cmds, focus)
+		}
+		return batchCmds(cmds)
+	}
+	if key.Type != tea.KeyEnter {
+		return batchCmds(cmds)
+	}
+	choice := strings.TrimSpace(strings.ToLower(m.menuInput.Value()))
+	m.menuInput.SetValue("")
+	if isExitCommand(choice) {
+		m.prevStates = nil
+		m.state = stateMainMenu
+		if focus := m.setMenuInput("Choose an option", 32); focus != nil {
+			cmds = append(cmds, focus)
+		}
+		return batchCmds(cmds)
+	}
+
+	if m.entry.cancelling != nil {
+		switch choice {
+		case "y", "yes":
+			if err := m.cancelOccurrence(); err != nil {
+				m.entry.err = err.Error()
+			}
+			m.reopenActivityEntry()
+			m.refreshDashboard(time.Now().In(m.cfg.Location()))
+		case "n", "no", "/", "back":
+			m.entry.cancelling = nil
+			if focus := m.setMenuInput(m.entry.prompt(), 40); focus != nil {
+				cmds = append(cmds, focus)
+			}
+		default:
+			m.entry.err = "Please answer y or n"
+		}
+		return batchCmds(cmds)
+	}
+
+	if m.entry.confirm {
+		switch choice {
+		case "y", "yes":
+			if err := m.deleteActivityEntry(); err != nil {
+				m.entry.err = err.Error()
+				return batchCmds(cmds)
+			}
+			m.infoMessage = fmt.Sprintf("Moved %s '%s' to the trash", m.entry.activity.Type, m.entry.activity.Title)
+			if focus := m.closeActivityEntry(); focus != nil {
+				cmds = append(cmds, focus)
+			}
+		case "n", "no", "/", "back":
+			m.entry.confirm = false
+			if focus := m.setMenuInput(m.entry.prompt(), 40); focus != nil {
+				cmds = append(cmds, focus)
+			}
+		default:
+			m.entry.err = "Please answer y or n"
+		}
+		return batchCmds(cmds)
+	}
+
+	m.entry.err = ""
+	switch choice {
+	case "":
+	case "1", "edit", "e":
+		if cmd, ok := m.editActivityEntry(); ok {
+			cmds = append(cmds, cmd)
+		}
+	case "2", "delete", "del", "d":
+		if !m.entry.editable() {
+			m.entry.err = "Only notes and events can be deleted here"
+			break
+		}
+		m.entry.confirm = true
+		if focus := m.setMenuInput(activityConfirmPrompt, 8); focus != nil {
+			cmds = append(cmds, focus)
+		}
+	case "3", "back", "/":
+		if focus := m.closeActivityEntry(); focus != nil {
+			cmds = append(cmds, focus)
+		}
+	default:
+		if cmd, ok := m.occurrenceCommand(choice); ok {
+			cmds = append(cmds, cmd)
+			break
+		}
+		m.entry.err = "Unknown choice"
+	}
+	return batchCmds(cmds)
+}
+
+// occurrenceCommand handles "e 2" and "c 2" on a recurring event, which
+// edit or cancel its second listed occurrence only.
+func (m *model) occurrenceCommand(choice string) (tea.Cmd, bool) {
+	if len(m.entry.occurrences) == 0 {
+		return nil, false
+	}
+	if arg, ok := cutCommand(choice, "edit", "e"); ok {
+		if occ, ok := m.entryOccurrence(arg); ok {
+			m.eventWizard = editOccurrenceWizard(*m.entry.event, occ, m.cfg.Location())
+			m.pushState(stateCreateEvent)
+		}
+		return nil, true
+	}
+	if arg, ok := cutCommand(choice, "cancel", "c", "skip"); ok {
+		if occ, ok := m.entryOccurrence(arg); ok {
+			m.entry.cancelling = &occ
+			return m.setMenuInput(activityCancelPrompt, 8), true
+		}
+		return nil, true
+	}
+	return nil, false
+}
+
+// editActivityEntry opens the matching wizard pre-filled with the entry.
+func (m *model) editActivityEntry() (tea.Cmd, bool) {
+	switch {
+	case m.entry.note != nil:
+		m.noteWizard = editNoteWizard(*m.entry.note, linkedAccount(m.entry.note.AccountID, m.entry.note.AccountName))
+		m.pushState(stateCreateNote)
+		return nil, true
+	case m.entry.event != nil:
+		m.eventWizard = editEventWizard(*m.entry.event, linkedAccount(m.entry.event.AccountID, m.entry.event.AccountName), m.cfg.Location())
+		m.pushState(stateCreateEvent)
+		return nil, true
+	}
+	m.entry.err = "Only notes and events can be edited here"
+	return nil, false
+}
+
+// linkedAccount returns the account an entry belongs to, or nil so the edit
+// wizard asks for one.
+func linkedAccount(id sql.NullInt64, name sql.NullString) *storage.Account {
+	if !id.Valid || !name.Valid {
+		return nil
+	}
+	return &storage.Account{ID: id.Int64, Name: name.String}
+}
+
+func (m *model) deleteActivityEntry() error {
+	ctx := context.Background()
+	switch {
+	case m.entry.note != nil:
+		undo := m.snapshotRecord("note", m.entry.note.ID)
+		if err := m.store.DeleteNote(ctx, m.entry.note.ID); err != nil {
+			return err
+		}
+		m.pushUndo("note deletion", undo)
+		return nil
+	case m.entry.event != nil:
+		undo := m.snapshotRecord("event", m.entry.event.ID)
+		if err := m.store.DeleteEvent(ctx, m.entry.event.ID); err != nil {
+			return err
+		}
+		m.pushUndo(fmt.Sprintf("deletion of event '%s'", m.entry.event.Title), undo)
+		return nil
+	}
+	return errors.New("nothing to delete")
+}
+
+func (m *model) closeActivityEntry() tea.Cmd {
+	m.entry = activityEntryModel{}
+	m.popState()
+	switch m.state {
+	case stateMainMenu:
+		return m.setMenuInput("Choose an option", 32)
+	case stateAccountDetail:
+		m.refreshAccountDetailAccount()
+		m.loadAccountActivity()
+		m.refreshDashboard(time.Now().In(m.cfg.Location()))
+		return m.setMenuInput(accountDetailPrompt, 72)
+	case stateSearch:
+		if m.search.searched {
+			m.runSearch(m.search.query)
+		}
+		return m.search.input.Focus()
+	case stateCalendar:
+		m.refreshCalendar()
+	}
+	return nil
+}
+
+// reopenActivityEntry reloads the entry after its edit wizard closes.
+func (m *model) reopenActivityEntry() {
+	if err := m.loadActivityEntry(); err != nil {
+		m.entry.err = err.Error()
+	}
+	switch {
+	case m.entry.note != nil:
+		m.entry.activity.Title = m.entry.note.Content
+	case m.entry.event != nil:
+		m.entry.activity.Title = m.entry.event.Title
+	}
+	m.setMenuInput(m.entry.prompt(), 40)
+}
+
+func (m *model) viewActivityEntry() string {
+	loc := m.cfg.Location()
+	e := m.entry
+	typeLabel := e.activity.Type
+	if len(typeLabel) > 0 {
+		typeLabel = strings.ToUpper(typeLabel[:1]) + typeLabel[1:]
+	}
+	lines := []string{m.theme.Title.Render(typeLabel)}
+	switch {
+	case e.note != nil:
+		lines = append(lines, m.theme.Primary.Render(e.note.Content))
+		lines = append(lines, m.theme.Faint.Render(fmt.Sprintf("Written by %s on %s", e.note.Creator, e.note.CreatedAt.In(loc).Format("Jan 02 2006 15:04"))))
+	case e.event != nil:
+		lines = append(lines, m.theme.Primary.Render(e.event.Title))
+		when := e.event.EventTime.In(loc).Format("Mon Jan 02 2006 15:04")
+		if e.event.Duration > 0 {
+			when += " until " + e.event.End().In(loc).Format("15:04") + " (" + formatEventDuration(e.event.Duration) + ")"
+		}
+		lines = append(lines, m.theme.Secondary.Render(when))
+		if e.event.Location != "" {
+			lines = append(lines, m.theme.Secondary.Render("Where: "+e.event.Location))
+		}
+		if len(e.event.Attendees) > 0 {
+			lines = append(lines, m.theme.Secondary.Render("With: "+attendeeNames(e.event.Attendees)))
+		}
+		if e.event.Details != "" {
+			lines = append(lines, m.theme.Primary.Render(e.event.Details))
+		}
+		lines = append(lines, m.theme.Faint.Render(fmt.Sprintf("Created by %s on %s", e.event.Creator, e.event.CreatedAt.In(loc).Format("Jan 02 2006 15:04"))))
+		if rule, ok := e.event.Rule(); ok {
+			lines = append(lines, "", m.theme.Secondary.Render("Repeats "+formatRepeat(rule, loc)))
+			if len(e.occurrences) == 0 {
+				lines = append(lines, m.theme.Faint.Render("No upcoming occurrences."))
+			}
+			for i, occ := range e.occurrences {
+				item := fmt.Sprintf("%d. %s", i+1, occ.EventTime.In(loc).Format("Mon Jan 02 2006 15:04"))
+				if occ.Title != e.event.Title {
+					item += " — " + occ.Title
+				}
+				if !occ.EventTime.Equal(occ.Occurrence) {
+					item += m.theme.Faint.Render(" (moved from " + occ.Occurrence.In(loc).Format("Jan 02 15:04") + ")")
+				}
+				lines = append(lines, m.theme.Primary.Render(item))
+			}
+		}
+	default:
+		lines = append(lines, m.theme.Primary.Render(e.activity.Title))
+		if e.activity.Details != "" {
+			lines = append(lines, m.theme.Secondary.Render(e.activity.Details))
+		}
+		lines = append(lines, m.theme.Faint.Render(e.activity.CreatedAt.In(loc).Format("Jan 02 2006 15:04")))
+	}
+	lines = append(lines, "")
+	if e.confirm {
+		lines = append(lines, m.theme.Warning.Render(fmt.Sprintf("Delete this %s? It can be restored from the Trash.", e.activity.Type)))
+	} else if e.cancelling != nil {
+		lines = append(lines, m.theme.Warning.Render(fmt.Sprintf("Cancel the %s occurrence? The rest of the series stays.", e.cancelling.Occurrence.In(loc).Format("Mon Jan 02 15:04"))))
+	} else if len(e.occurrences) > 0 {
+		lines = append(lines, m.theme.Secondary.Render("1. Edit series"))
+		lines = append(lines, m.theme.Secondary.Render("2. Delete series"))
+		lines = append(lines, m.theme.Faint.Render("3. Back"))
+		lines = append(lines, m.theme.Faint.Render("'e 2' edits occurrence #2 only, 'c 2' cancels it."))
+	} else if e.editable() {
+		lines = append(lines, m.theme.Secondary.Render("1. Edit"))
+		lines = append(lines, m.theme.Secondary.Render("2. Delete"))
+		lines = append(lines, m.theme.Faint.Render("3. Back"))
+	} else {
+		lines = append(lines, m.theme.Faint.Render("3. Back"))
+	}
+	lines = append(lines, "")
+	lines = append(lines, m.theme.Accent.Render("> ")+m.menuInput.View())
+	if e.err != "" {
+		lines = append(lines, "", m.theme.Danger.Render(e.err))
+	}
+	if m.infoMessage != "" {
+		lines = append(lines, "", m.theme.Success.Render(m.infoMessage))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// deleteAccountChoice runs the confirmation answer for deleting the account
+// shown in the detail view.
+func (m *model) deleteAccountChoice(choice string) tea.Cmd {
+	var cascade bool
+	switch choice {
+	case "c", "cascade", "all":
+		cascade = true
+	case "o", "orphan", "keep":
+		cascade = false
+	case "n", "no", "/", "back", "cancel":
+		m.accountDetail.confirmDelete = false
+		return m.setMenuInput(accountDetailPrompt, 72)
+	default:
+		m.accountDetail.err = "Choose c, o, or n"
+		return nil
+	}
+	account := m.accountDetail.account
+	res, err := m.store.DeleteAccount(context.Background(), account.ID, cascade)
+	m.accountDetail.confirmDelete = false
+	if err != nil {
+		m.accountDetail.err = fmt.Sprintf("delete account: %v", err)
+		return m.setMenuInput(accountDetailPrompt, 72)
+	}
+	if cascade {
+		m.infoMessage = fmt.Sprintf("Moved %s to the trash with %d notes, %d events and %d tasks", account.Name, res.Notes, res.Events, res.Tasks)
+	} else {
+		m.infoMessage = fmt.Sprintf("Moved %s to the trash; its notes, events and tasks were kept", account.Name)
+	}
+	m.pushUndo(fmt.Sprintf("deletion of account '%s'", account.Name), res.Undo)
+	m.accountDetail = accountDetailModel{}
+	m.refreshAccounts()
+	m.refreshDashboard(time.Now().In(m.cfg.Location()))
+	m.popState()
+	if m.state == stateMainMenu {
+		return m.setMenuInput("Choose an option", 32)
+	}
+	return nil
+}
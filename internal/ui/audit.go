@@ -0,0 +1,210 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"crmterm/internal/dateparse"
+	"crmterm/internal/storage"
+)
+
+const auditPrompt = "actor:<name>  since:<date>  until:<date>  clear  /=Back"
+
+type auditModel struct {
+	query   string
+	entries []storage.AuditEntry
+	err     string
+}
+
+var auditVerbs = map[string]string{
+	"create":  "created",
+	"update":  "updated",
+	"delete":  "deleted",
+	"restore": "restored",
+	"purge":   "purged",
+	"tag":     "tagged",
+	"untag":   "untagged",
+}
+
+func (m *model) openAudit() tea.Cmd {
+	m.audit = auditModel{}
+	m.refreshAudit()
+	m.pushState(stateAudit)
+	return m.setMenuInput(auditPrompt, 96)
+}
+
+func (m *model) refreshAudit() {
+	filter, err := parseAuditFilter(m.audit.query, m.cfg.Location(), m.cfg.DateOrder())
+	if err != nil {
+		m.audit.err = err.Error()
+		return
+	}
+	entries, err := m.store.ListAuditLog(context.Background(), filter)
+	if err != nil {
+		m.audit.err = fmt.Sprintf("load audit log: %v", err)
+		return
+	}
+	m.audit.entries = entries
+}
+
+// parseAuditFilter reads `actor:`, `since:` and `until:` terms. Dates are
+// whole days in loc and until is inclusive.
+func parseAuditFilter(query string, loc *time.Location, order dateparse.Order) (storage.AuditFilter, error) {
+	var filter storage.AuditFilter
+	for _, word := range strings.Fields(query) {
+		key, value, ok := strings.Cut(word, ":")
+		if !ok || value == "" {
+			return filter, fmt.Errorf("Unknown filter '%s'; use actor:, since: or until:", word)
+		}
+		switch strings.ToLower(key) {
+		case "actor", "by":
+			filter.Actor = value
+		case "since", "from":
+			r, err := dateparse.ParseWithOrder(value, time.Now().In(loc), order)
+			if err != nil {
+				return filter, fmt.Errorf("Dates look like 2025-03-31, yesterday or -2w")
+			}
+			filter.Since = r.Time
+		case "until", "to":
+			r, err := dateparse.ParseWithOrder(value, time.Now().In(loc), order)
+			if err != nil {
+				return filter, fmt.Errorf("Dates look like 2025-03-31, yesterday or -2w")
+			}
+			filter.Until = r.Time.AddDate(0, 0, 1)
+		default:
+			return filter, fmt.Errorf("Unknown filter '%s'; use actor:, since: or until:", word)
+		}
+	}
+	return filter, nil
+}
+
+// AUDIT LOG
+func (m *model) updateAudit(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+	if focus := m.ensureMenuInput(auditPrompt, 96); focus != nil {
+		cmds = append(cmds, focus)
+	}
+	var cmd tea.Cmd
+	m.menuInput, cmd = m.menuInput.Update(msg)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+	key, ok := msg.(tea.KeyMsg)
+	if !ok || key.Type != tea.KeyEnter {
+		return batchCmds(cmds)
+	}
+	value := strings.TrimSpace(m.menuInput.Value())
+	m.menuInput.SetValue("")
+	if isExitCommand(value) {
+		m.prevStates = nil
+		m.state = stateMainMenu
+		if focus := m.setMenuInput("Choose an option", 32); focus != nil {
+			cmds = append(cmds, focus)
+		}
+		return batchCmds(cmds)
+	}
+	if isBackCommand(value) {
+		m.popState()
+		if m.state == stateSettings {
+			if focus := m.setMenuInput(settingsPrompt, 64); focus != nil {
+				cmds = append(cmds, focus)
+			}
+		}
+		return batchCmds(cmds)
+	}
+	m.audit.err = ""
+	switch strings.ToLower(value) {
+	case "":
+	case "clear":
+		m.audit.query = ""
+	default:
+		if _, err := parseAuditFilter(value, m.cfg.Location(), m.cfg.DateOrder()); err != nil {
+			m.audit.err = err.Error()
+			return batchCmds(cmds)
+		}
+		m.audit.query = value
+	}
+	m.refreshAudit()
+	return batchCmds(cmds)
+}
+
+func (m *model) viewAudit() string {
+	lines := []string{m.theme.Title.Render("Audit Log")}
+	lines = append(lines, m.theme.Faint.Render("Every change to accounts, contacts, notes, events, tasks, deals and fields, newest first. e.g. 'actor:jane since:2025-03-01'."))
+	if m.audit.query != "" {
+		lines = append(lines, m.theme.Secondary.Render("Filter: "+m.audit.query))
+	}
+	lines = append(lines, "")
+	if len(m.audit.entries) == 0 {
+		lines = append(lines, m.theme.Faint.Render("No changes recorded."))
+	}
+	for _, e := range m.audit.entries {
+		lines = append(lines, m.formatAuditEntry(e, true)...)
+	}
+	lines = append(lines, "")
+	lines = append(lines, m.theme.Accent.Render("> ")+m.menuInput.View())
+	if m.audit.err != "" {
+		lines = append(lines, "", m.theme.Danger.Render(m.audit.err))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// formatAuditEntry renders an entry as a headline followed by one line per
+// changed field. withAccount names the related account for entries that are
+// not about the account itself.
+func (m *model) formatAuditEntry(e storage.AuditEntry, withAccount bool) []string {
+	stamp := e.CreatedAt.In(m.cfg.Location()).Format("Jan 02 15:04")
+	verb := auditVerbs[e.Action]
+	if verb == "" {
+		verb = e.Action
+	}
+	subject := fmt.Sprintf("%s #%d", e.Entity, e.EntityID)
+	if e.Entity == "account" && e.AccountName != "" {
+		subject = "account " + e.AccountName
+	} else if withAccount && e.AccountName != "" {
+		subject += " (" + e.AccountName + ")"
+	}
+	actor := e.Actor
+	if actor == "" {
+		actor = "unknown"
+	}
+	lines := []string{m.theme.Secondary.Render(stamp+"  ") + m.theme.Primary.Render(actor) + m.theme.Secondary.Render(" "+verb+" "+subject)}
+	for _, c := range e.Changes {
+		var change string
+		switch {
+		case e.Action == "update":
+			change = fmt.Sprintf("%s: %s → %s", c.Field, clipAuditValue(c.Before), clipAuditValue(c.After))
+		case c.After != "":
+			change = fmt.Sprintf("%s: %s", c.Field, clipAuditValue(c.After))
+		default:
+			change = fmt.Sprintf("%s: %s", c.Field, clipAuditValue(c.Before))
+		}
+		lines = append(lines, "    "+m.theme.Faint.Render(change))
+	}
+	return lines
+}
+
+func clipAuditValue(value string) string {
+	if value == "" {
+		return "(blank)"
+	}
+	if runes := []rune(value); len(runes) > 48 {
+		return string(runes[:47]) + "…"
+	}
+	return value
+}
+
+// loadAccountHistory loads the audit entries of the detail account.
+func (m *model) loadAccountHistory() {
+	entries, err := m.store.ListAuditLog(context.Background(), storage.AuditFilter{AccountID: m.accountDetail.account.ID, Limit: 50})
+	if err != nil {
+		m.accountDetail.err = fmt.Sprintf("load history: %v", err)
+		return
+	}
+	m.accountDetail.err = ""
+	m.accountDetail.history = entries
+}
@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"crmterm/internal/dateparse"
+)
+
+func TestParseAuditFilter(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	filter, err := parseAuditFilter("actor:Alice since:2026-03-01 until:02/03/2026", loc, dateparse.OrderDayFirst)
+	if err != nil {
+		t.Fatalf("parseAuditFilter: %v", err)
+	}
+	if filter.Actor != "Alice" {
+		t.Errorf("actor = %q, want Alice", filter.Actor)
+	}
+	if want := time.Date(2026, 3, 1, 0, 0, 0, 0, loc); !filter.Since.Equal(want) {
+		t.Errorf("since = %v, want %v", filter.Since, want)
+	}
+	if want := time.Date(2026, 3, 3, 0, 0, 0, 0, loc); !filter.Until.Equal(want) {
+		t.Errorf("until = %v, want the end of 2 March, %v", filter.Until, want)
+	}
+
+	filter, err = parseAuditFilter("BY:bob", loc, dateparse.OrderDayFirst)
+	if err != nil || filter.Actor != "bob" || !filter.Since.IsZero() || !filter.Until.IsZero() {
+		t.Errorf("parseAuditFilter(by:bob) = %+v, %v", filter, err)
+	}
+	for _, query := range []string{"alice", "actor:", "since:someday", "until:31/31/2026", "who:alice"} {
+		if _, err := parseAuditFilter(query, loc, dateparse.OrderDayFirst); err == nil {
+			t.Errorf("parseAuditFilter(%q) gave no error", query)
+		}
+	}
+}
+
+func TestClipAuditValue(t *testing.T) {
+	long := "0123456789012345678901234567890123456789012345678901"
+	tests := []struct{ in, want string }{
+		{"", "(blank)"},
+		{"Acme", "Acme"},
+		{long, long[:47] + "…"},
+	}
+	for _, tt := range tests {
+		if got := clipAuditValue(tt.in); got != tt.want {
+			t.Errorf("clipAuditValue(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"crmterm/internal/config"
+)
+
+// backupUsage explains the Settings backup prompt.
+const backupUsage = "'backup <path>' to write a JSON backup or 'restore <path>' to load one"
+
+// backupSummaryTables are the tables named in backup and restore messages.
+var backupSummaryTables = []struct{ table, noun string }{
+	{"accounts", "account"},
+	{"notes", "note"},
+	{"events", "event"},
+}
+
+// handleBackup runs a `backup <path>` or `restore <path>` command from
+// Settings.
+func (m *model) handleBackup(value string) {
+	m.infoMessage = ""
+	if path, ok := cutCommand(value, "backup", "save"); ok {
+		if resolved, ok := m.backupPath(path); ok {
+			m.writeBackup(resolved)
+		}
+		return
+	}
+	if path, ok := cutCommand(value, "restore", "load"); ok {
+		if resolved, ok := m.backupPath(path); ok {
+			m.restoreBackup(resolved)
+		}
+		return
+	}
+	m.errMessage = "Type " + backupUsage
+}
+
+func (m *model) backupPath(path string) (string, bool) {
+	resolved, err := expandPath(path)
+	if err != nil {
+		m.errMessage = fmt.Sprintf("backup path: %v", err)
+		return "", false
+	}
+	return resolved, true
+}
+
+func (m *model) writeBackup(path string) {
+	file, err := os.Create(path)
+	if err != nil {
+		m.errMessage = fmt.Sprintf("create file: %v", err)
+		return
+	}
+	counts, err := m.store.ExportJSON(context.Background(), file, m.cfg.Config)
+	if cerr := file.Close(); err == nil && cerr != nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		m.errMessage = fmt.Sprintf("backup: %v", err)
+		return
+	}
+	var parts []string
+	for _, t := range backupSummaryTables {
+		parts = append(parts, pluralize(counts[t.table], t.noun))
+	}
+	m.infoMessage = fmt.Sprintf("Backed up %s and settings to %s", strings.Join(parts, ", "), path)
+}
+
+// restoreBackup loads a backup into the open database and applies the
+// settings saved with it.
+func (m *model) restoreBackup(path string) {
+	file, err := os.Open(path)
+	if err != nil {
+		m.errMessage = fmt.Sprintf("open file: %v", err)
+		return
+	}
+	defer file.Close()
+	ctx := context.Background()
+	result, err := m.store.ImportJSON(ctx, file)
+	if err != nil {
+		m.errMessage = fmt.Sprintf("restore: %v", err)
+		return
+	}
+	var parts []string
+	for _, t := range backupSummaryTables {
+		parts = append(parts, pluralize(result.Inserted[t.table], t.noun))
+	}
+	message := "Restored " + strings.Join(parts, ", ")
+	matched := 0
+	for _, n := range result.Matched {
+		matched += n
+	}
+	if matched > 0 {
+		message += fmt.Sprintf("; %d record(s) were already here", matched)
+	}
+	if result.Skipped > 0 {
+		message += fmt.Sprintf("; skipped %d orphaned row(s)", result.Skipped)
+	}
+	if len(result.Config) > 0 {
+		var data config.Data
+		if err := json.Unmarshal(result.Config, &data); err != nil {
+			m.errMessage = fmt.Sprintf("restore settings: %v", err)
+		} else {
+			m.cfg.Config = restoredSettings(m.cfg.Config, data)
+			if err := m.cfg.Save(); err != nil {
+				m.errMessage = fmt.Sprintf("save settings: %v", err)
+			}
+		}
+	}
+	m.refreshDataAfterCleanup()
+	m.infoMessage = message
+}
+
+// restoredSettings applies the settings that describe the data from a
+// backup's config. The name and timezone belong to whoever runs this copy,
+// so restoring a colleague's backup does not change who audit entries are
+// attributed to.
+func restoredSettings(local, backup config.Data) config.Data {
+	local.DealStages = backup.DealStages
+	local.AccountStatuses = backup.AccountStatuses
+	local.TrashRetentionDays = backup.TrashRetentionDays
+	local.PhoneCountry = backup.PhoneCountry
+	local.DateOrder = backup.DateOrder
+	return local
+}
@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"crmterm/internal/config"
+)
+
+func TestRestoreBackupKeepsLocalIdentity(t *testing.T) {
+	m := newTestModel(t)
+	m.cfg.Config.Name = "Me"
+	m.cfg.Config.Timezone = "Europe/London"
+	theirs := config.Data{
+		Name:               "Colleague",
+		Timezone:           "Asia/Tokyo",
+		DealStages:         []string{"New", "Won"},
+		AccountStatuses:    []string{"Lead", "Client"},
+		TrashRetentionDays: 7,
+		PhoneCountry:       "GB",
+		DateOrder:          "mdy",
+	}
+	path := filepath.Join(t.TempDir(), "backup.json")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.store.ExportJSON(context.Background(), file, theirs); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	file.Close()
+
+	m.restoreBackup(path)
+	if m.errMessage != "" {
+		t.Fatalf("restore: %s", m.errMessage)
+	}
+	want := theirs
+	want.Name, want.Timezone = "Me", "Europe/London"
+	if !reflect.DeepEqual(m.cfg.Config, want) {
+		t.Errorf("settings after restore = %+v, want %+v", m.cfg.Config, want)
+	}
+}
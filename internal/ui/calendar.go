@@ -0,0 +1,396 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"crmterm/internal/storage"
+)
+
+type calendarView int
+
+const (
+	calendarMonth calendarView = iota
+	calendarWeek
+)
+
+const (
+	// The week timeline shows at least these hours, widened to fit the
+	// week's events.
+	calendarFirstHour = 8
+	calendarLastHour  = 18
+	calendarMinCell   = 6
+	calendarHourLabel = 6
+	// calendarDayEvents is how many of the selected day's events can be
+	// opened by number.
+	calendarDayEvents = 9
+)
+
+type calendarModel struct {
+	view calendarView
+	// day is the selected day, at midnight in the configured timezone.
+	day time.Time
+	// events are the occurrences between from and to.
+	events   []storage.Event
+	from, to time.Time
+	err      string
+}
+
+func (m *model) openCalendar() {
+	now := time.Now().In(m.cfg.Location())
+	m.calendar = calendarModel{day: startOfDay(now)}
+	m.refreshCalendar()
+	m.pushState(stateCalendar)
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// startOfWeek returns the Monday on or before day.
+func startOfWeek(day time.Time) time.Time {
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// calendarRange is the span the current view shows: six weeks around the
+// selected month, or the selected week.
+func (c calendarModel) calendarRange() (time.Time, time.Time) {
+	if c.view == calendarWeek {
+		from := startOfWeek(c.day)
+		return from, from.AddDate(0, 0, 7)
+	}
+	from := startOfWeek(time.Date(c.day.Year(), c.day.Month(), 1, 0, 0, 0, 0, c.day.Location()))
+	return from, from.AddDate(0, 0, 42)
+}
+
+func (m *model) refreshCalendar() {
+	m.calendar.err = ""
+	from, to := m.calendar.calendarRange()
+	events, err := m.store.ListEvents(context.Background())
+	if err != nil {
+		m.calendar.err = fmt.Sprintf("load events: %v", err)
+		return
+	}
+	var shown []storage.Event
+	for _, e := range storage.ExpandEvents(events, from, to) {
+		if t := e.EventTime.In(from.Location()); !t.Before(from) && t.Before(to) {
+			shown = append(shown, e)
+		}
+	}
+	m.calendar.events, m.calendar.from, m.calendar.to = shown, from, to
+}
+
+// selectDay moves the selection and reloads when it leaves the loaded span.
+func (m *model) selectDay(day time.Time) {
+	m.calendar.day = startOfDay(day)
+	if from, to := m.calendar.calendarRange(); !from.Equal(m.calendar.from) || !to.Equal(m.calendar.to) {
+		m.refreshCalendar()
+	}
+}
+
+// shiftMonth moves the selection by months, keeping the day of the month
+// where the target month has it.
+func (m *model) shiftMonth(delta int) {
+	day := m.calendar.day
+	first := time.Date(day.Year(), day.Month()+time.Month(delta), 1, 0, 0, 0, 0, day.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	d := day.Day()
+	if d > last {
+		d = last
+	}
+	m.selectDay(time.Date(first.Year(), first.Month(), d, 0, 0, 0, 0, day.Location()))
+}
+
+// dayEvents returns the loaded events starting on day.
+func (m *model) dayEvents(day time.Time) []storage.Event {
+	end := day.AddDate(0, 0, 1)
+	var out []storage.Event
+	for _, e := range m.calendar.events {
+		if t := e.EventTime.In(day.Location()); !t.Before(day) && t.Before(end) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// CALENDAR
+func (m *model) updateCalendar(msg tea.Msg) tea.Cmd {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+	day := m.calendar.day
+	switch key.String() {
+	case "left", "h":
+		m.selectDay(day.AddDate(0, 0, -1))
+	case "right", "l":
+		m.selectDay(day.AddDate(0, 0, 1))
+	case "up", "k":
+		m.selectDay(day.AddDate(0, 0, -7))
+	case "down", "j":
+		m.selectDay(day.AddDate(0, 0, 7))
+	case "[", "pgup":
+		if m.calendar.view == calendarWeek {
+			m.selectDay(day.AddDate(0, 0, -7))
+		} else {
+			m.shiftMonth(-1)
+		}
+	case "]", "pgdown":
+		if m.calendar.view == calendarWeek {
+			m.selectDay(day.AddDate(0, 0, 7))
+		} else {
+			m.shiftMonth(1)
+		}
+	case "m":
+		m.calendar.view = calendarMonth
+		m.refreshCalendar()
+	case "w":
+		m.calendar.view = calendarWeek
+		m.refreshCalendar()
+	case "t":
+		m.selectDay(time.Now().In(m.cfg.Location()))
+	case "r":
+		m.refreshCalendar()
+	case "a":
+		m.eventWizard = newEventWizard(nil)
+		m.eventWizard.scheduleInput.SetValue(day.Add(defaultEventHour * time.Hour).Format("2006-01-02 15:04"))
+		m.pushState(stateCreateEvent)
+		return m.eventWizard.titleInput.Focus()
+	case "esc", "q", "/":
+		m.popState()
+		if m.state == stateMainMenu {
+			return m.setMenuInput("Choose an option", 32)
+		}
+	default:
+		if s := key.String(); len(s) == 1 && s[0] >= '1' && s[0] <= '9' {
+			events := m.dayEvents(day)
+			idx := int(s[0] - '0')
+			if idx > len(events) {
+				m.calendar.err = fmt.Sprintf("No event #%d on %s", idx, day.Format("Jan 02"))
+				return nil
+			}
+			e := events[idx-1]
+			focus, err := m.openActivityEntry(storage.Activity{Type: "event", ID: e.ID, Title: e.Title}, "open")
+			if err != nil {
+				m.calendar.err = err.Error()
+			}
+			return focus
+		}
+	}
+	return nil
+}
+
+func (m *model) viewCalendar() string {
+	width := m.width
+	if width <= 0 {
+		width = boardDefaultWidth
+	}
+	day := m.calendar.day
+	title := "Calendar — " + day.Format("January 2006")
+	if m.calendar.view == calendarWeek {
+		from, to := m.calendar.calendarRange()
+		title = fmt.Sprintf("Calendar — week of %s – %s", from.Format("Jan 02"), to.AddDate(0, 0, -1).Format("Jan 02 2006"))
+	}
+	lines := []string{m.theme.Title.Render(title)}
+	lines = append(lines, m.theme.Faint.Render("←/→ day, ↑/↓ week, [/] previous/next, m month, w week, t today, 1-9 open, a add, esc back."))
+	lines = append(lines, "")
+	if m.calendar.view == calendarWeek {
+		lines = append(lines, m.viewCalendarWeek(width)...)
+	} else {
+		lines = append(lines, m.viewCalendarMonth(width)...)
+	}
+	lines = append(lines, "")
+	events := m.dayEvents(day)
+	lines = append(lines, m.theme.Subtitle.Render(fmt.Sprintf("%s — %s", day.Format("Mon Jan 02"), pluralize(len(events), "event"))))
+	if len(events) == 0 {
+		lines = append(lines, m.theme.Faint.Render("Nothing scheduled. Press a to add an event."))
+	}
+	clashes := overlappingEvents(events)
+	for i, e := range events {
+		item := formatEventLine(m, e, clashes[eventKey(e)])
+		if i < calendarDayEvents {
+			item = fmt.Sprintf("%d. %s", i+1, item)
+		}
+		lines = append(lines, m.calendarStyle(day).Render(item))
+	}
+	if m.calendar.err != "" {
+		lines = append(lines, "", m.theme.Danger.Render(m.calendar.err))
+	}
+	if m.infoMessage != "" {
+		lines = append(lines, "", m.theme.Success.Render(m.infoMessage))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// calendarStyle colors a day the way the dashboard colors events: past
+// days red, today green and days ahead yellow.
+func (m *model) calendarStyle(day time.Time) lipgloss.Style {
+	today := startOfDay(time.Now().In(m.cfg.Location()))
+	switch {
+	case day.Before(today):
+		return m.theme.Danger
+	case day.Equal(today):
+		return m.theme.Success
+	}
+	return m.theme.Warning
+}
+
+func (m *model) viewCalendarMonth(width int) []string {
+	cell := width / 7
+	if cell < calendarMinCell {
+		cell = calendarMinCell
+	}
+	from, _ := m.calendar.calendarRange()
+	header := make([]string, 7)
+	for i := range header {
+		header[i] = m.theme.Secondary.Render(padCell(from.AddDate(0, 0, i).Format("Mon"), cell))
+	}
+	lines := []string{strings.Join(header, "")}
+	counts := map[string]int{}
+	firstTitle := map[string]string{}
+	for _, e := range m.calendar.events {
+		d := e.EventTime.In(from.Location()).Format("2006-01-02")
+		if counts[d] == 0 {
+			firstTitle[d] = e.Title
+		}
+		counts[d]++
+	}
+	for week := 0; week < 6; week++ {
+		numbers := make([]string, 7)
+		badges := make([]string, 7)
+		titles := make([]string, 7)
+		for i := 0; i < 7; i++ {
+			d := from.AddDate(0, 0, week*7+i)
+			label := fmt.Sprintf(" %2d", d.Day())
+			style := m.theme.Primary
+			if d.Month() != m.calendar.day.Month() {
+				style = m.theme.Faint
+			}
+			if d.Equal(m.calendar.day) {
+				label = fmt.Sprintf("▶%2d", d.Day())
+				style = m.theme.Highlight
+			}
+			numbers[i] = style.Render(padCell(label, cell))
+			badges[i] = padCell("", cell)
+			titles[i] = padCell("", cell)
+			if n := counts[d.Format("2006-01-02")]; n > 0 {
+				badge := fmt.Sprintf(" ●%d", n)
+				if cell >= 12 {
+					badge = " ● " + pluralize(n, "event")
+				}
+				badges[i] = m.calendarStyle(d).Render(padCell(badge, cell))
+				titles[i] = m.theme.Faint.Render(padCell(" "+firstTitle[d.Format("2006-01-02")], cell))
+			}
+		}
+		lines = append(lines, strings.Join(numbers, ""), strings.Join(badges, ""))
+		if cell >= 12 {
+			lines = append(lines, strings.Join(titles, ""))
+		}
+	}
+	return lines
+}
+
+func (m *model) viewCalendarWeek(width int) []string {
+	cell := (width - calendarHourLabel) / 7
+	if cell < calendarMinCell {
+		cell = calendarMinCell
+	}
+	from, _ := m.calendar.calendarRange()
+	first, last := calendarFirstHour, calendarLastHour
+	for _, e := range m.calendar.events {
+		start := e.EventTime.In(from.Location())
+		if start.Hour() < first {
+			first = start.Hour()
+		}
+		end := e.End().In(from.Location())
+		if startOfDay(end).Equal(startOfDay(start)) && end.Hour() > last {
+			last = end.Hour()
+		}
+	}
+	header := []string{padCell("", calendarHourLabel)}
+	for i := 0; i < 7; i++ {
+		d := from.AddDate(0, 0, i)
+		label := d.Format("Mon 02")
+		style := m.theme.Secondary
+		if n := len(m.dayEvents(d)); n > 0 {
+			label += fmt.Sprintf(" ●%d", n)
+			style = m.calendarStyle(d)
+		}
+		if d.Equal(m.calendar.day) {
+			label = "▶" + label
+			style = m.theme.Highlight
+		}
+		header = append(header, style.Render(padCell(label, cell)))
+	}
+	lines := []string{strings.Join(header, "")}
+	for hour := first; hour <= last; hour++ {
+		row := []string{m.theme.Faint.Render(padCell(fmt.Sprintf("%02d:00", hour), calendarHourLabel))}
+		for i := 0; i < 7; i++ {
+			d := from.AddDate(0, 0, i)
+			slot := d.Add(time.Duration(hour) * time.Hour)
+			row = append(row, m.calendarSlot(d, slot, cell))
+		}
+		lines = append(lines, strings.Join(row, ""))
+	}
+	return lines
+}
+
+// calendarSlot renders the events of one hour on one day of the week
+// timeline: the title where an event starts and a bar while it runs.
+func (m *model) calendarSlot(day, slot time.Time, cell int) string {
+	end := slot.Add(time.Hour)
+	var starting []storage.Event
+	running := false
+	for _, e := range m.calendar.events {
+		start := e.EventTime.In(slot.Location())
+		finish := e.End()
+		if e.Duration <= 0 {
+			finish = e.EventTime.Add(time.Minute)
+		}
+		switch {
+		case !start.Before(slot) && start.Before(end):
+			starting = append(starting, e)
+		case start.Before(slot) && finish.After(slot):
+			running = true
+		}
+	}
+	if len(starting) == 0 {
+		if running {
+			return m.calendarStyle(day).Render(padCell(" │", cell))
+		}
+		text := " ·"
+		if day.Equal(m.calendar.day) {
+			return m.theme.Highlight.Render(padCell(text, cell))
+		}
+		return m.theme.Faint.Render(padCell(text, cell))
+	}
+	text := " " + starting[0].EventTime.In(slot.Location()).Format("15:04") + " " + starting[0].Title
+	if len(starting) > 1 {
+		more := fmt.Sprintf(" +%d", len(starting)-1)
+		text = truncateText(text, cell-1-lipgloss.Width(more)) + more
+	}
+	return m.calendarStyle(day).Render(padCell(text, cell))
+}
+
+// padCell truncates or pads s to exactly width cells so grid columns line
+// up before styling is applied.
+func padCell(s string, width int) string {
+	s = truncateText(s, width-1)
+	if gap := width - lipgloss.Width(s); gap > 0 {
+		s += strings.Repeat(" ", gap)
+	}
+	return s
+}
+
+// pluralize renders "1 event" or "3 events".
+func pluralize(n int, word string) string {
+	if n == 1 {
+		return "1 " + word
+	}
+	return fmt.Sprintf("%d %ss", n, word)
+}
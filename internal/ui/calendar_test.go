@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"crmterm/internal/storage"
+)
+
+func TestCalendarRange(t *testing.T) {
+	day := func(month time.Month, d int) time.Time { return time.Date(2026, month, d, 0, 0, 0, 0, time.UTC) }
+	for _, tt := range []struct{ in, want time.Time }{
+		{day(10, 16), day(10, 12)},
+		{day(10, 12), day(10, 12)},
+		{day(10, 18), day(10, 12)},
+		{day(3, 1), day(2, 23)},
+	} {
+		if got := startOfWeek(tt.in); !got.Equal(tt.want) {
+			t.Errorf("startOfWeek(%s) = %s, want %s", tt.in.Format("Mon Jan 02"), got.Format("Mon Jan 02"), tt.want.Format("Mon Jan 02"))
+		}
+	}
+
+	c := calendarModel{day: day(10, 16)}
+	from, to := c.calendarRange()
+	if !from.Equal(day(9, 28)) || !to.Equal(day(11, 9)) {
+		t.Errorf("month range = %s to %s, want Sep 28 to Nov 09", from.Format("Jan 02"), to.Format("Jan 02"))
+	}
+	c.view = calendarWeek
+	from, to = c.calendarRange()
+	if !from.Equal(day(10, 12)) || !to.Equal(day(10, 19)) {
+		t.Errorf("week range = %s to %s, want Oct 12 to Oct 19", from.Format("Jan 02"), to.Format("Jan 02"))
+	}
+}
+
+func TestCalendarNavigation(t *testing.T) {
+	m := newTestModel(t)
+	loc := m.cfg.Location()
+	review := storage.Event{Title: "Review", EventTime: time.Date(2026, 3, 2, 15, 0, 0, 0, loc), Creator: "tester"}
+	if err := m.store.CreateEvent(context.Background(), &review); err != nil {
+		t.Fatalf("create event: %v", err)
+	}
+	m.openCalendar()
+	m.selectDay(time.Date(2026, 1, 31, 9, 30, 0, 0, loc))
+
+	press := func(key string) {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	}
+	press("]")
+	if want := time.Date(2026, 2, 28, 0, 0, 0, 0, loc); !m.calendar.day.Equal(want) {
+		t.Fatalf("next month from Jan 31 = %s, want Feb 28", m.calendar.day.Format("Jan 02"))
+	}
+	press("l")
+	press("l")
+	if got := m.dayEvents(m.calendar.day); len(got) != 1 || got[0].Title != "Review" {
+		t.Errorf("events on %s = %+v, want the review", m.calendar.day.Format("Jan 02"), got)
+	}
+	press("w")
+	press("[")
+	if want := time.Date(2026, 2, 23, 0, 0, 0, 0, loc); !m.calendar.day.Equal(want) || !m.calendar.from.Equal(startOfWeek(want)) {
+		t.Errorf("previous week = %s loaded from %s, want Feb 23", m.calendar.day.Format("Jan 02"), m.calendar.from.Format("Jan 02"))
+	}
+	if len(m.calendar.events) != 0 {
+		t.Errorf("week of Feb 23 shows %d events, want none", len(m.calendar.events))
+	}
+	press("9")
+	if m.calendar.err == "" {
+		t.Errorf("opening a missing event number gave no error")
+	}
+}
+
+func TestPadCell(t *testing.T) {
+	for _, tt := range []struct {
+		in    string
+		width int
+	}{
+		{"", 6},
+		{"Demo", 6},
+		{"Quarterly review", 6},
+		{"日本語の会議", 6},
+	} {
+		if got := padCell(tt.in, tt.width); lipgloss.Width(got) != tt.width {
+			t.Errorf("padCell(%q, %d) = %q, %d cells wide", tt.in, tt.width, got, lipgloss.Width(got))
+		}
+	}
+	if got := pluralize(1, "event"); got != "1 event" {
+		t.Errorf("pluralize(1) = %q", got)
+	}
+	if got := pluralize(3, "event"); got != "3 events" {
+		t.Errorf("pluralize(3) = %q", got)
+	}
+}
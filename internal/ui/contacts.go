@@ -0,0 +1,366 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"crmterm/internal/storage"
+	"crmterm/internal/validate"
+)
+
+type contactForm struct {
+	index  int
+	fields []formField
+	input  textinput.Model
+	err    string
+	// warning explains why the value in warned looks invalid; entering
+	// the same value again keeps it anyway.
+	warning string
+	warned  string
+	account storage.Account
+	// editing is the contact being changed; nil when adding one.
+	editing *storage.Contact
+}
+
+// Steps of the contact form, in the order it asks for them.
+const (
+	contactFieldName = iota
+	contactFieldTitle
+	contactFieldEmail
+	contactFieldPhone
+	contactFieldRole
+	contactFieldPrimary
+)
+
+func newContactForm(account *storage.Account) contactForm {
+	ti := textinput.New()
+	ti.Placeholder = "Contact name"
+	ti.CharLimit = 96
+	ti.Focus()
+	form := contactForm{
+		fields: []formField{
+			contactFieldName:    {label: "Contact name", required: true},
+			contactFieldTitle:   {label: "Title", required: false},
+			contactFieldEmail:   {label: "Email", required: false},
+			contactFieldPhone:   {label: "Phone", required: false},
+			contactFieldRole:    {label: "Role", required: false},
+			contactFieldPrimary: {label: "Primary contact? (y/n)", required: false},
+		},
+		input: ti,
+	}
+	if account != nil {
+		form.account = *account
+	}
+	return form
+}
+
+// editContactForm opens the contact form pre-filled with c.
+func editContactForm(c storage.Contact, account *storage.Account) contactForm {
+	form := newContactForm(account)
+	values := []string{
+		contactFieldName:    c.Name,
+		contactFieldTitle:   c.Title,
+		contactFieldEmail:   c.Email,
+		contactFieldPhone:   c.Phone,
+		contactFieldRole:    c.Role,
+		contactFieldPrimary: "n",
+	}
+	if c.Primary {
+		values[contactFieldPrimary] = "y"
+	}
+	for i := range form.fields {
+		form.fields[i].value = values[i]
+	}
+	form.input.SetValue(c.Name)
+	form.editing = &c
+	return form
+}
+
+// CONTACT FORM
+func (m *model) updateContactForm(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+	var cmd tea.Cmd
+	m.contactForm.input, cmd = m.contactForm.input.Update(msg)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return batchCmds(cmds)
+	}
+	switch key.Type {
+	case tea.KeyEnter:
+		value := strings.TrimSpace(m.contactForm.input.Value())
+		if isExitCommand(value) {
+			m.contactForm = newContactForm(nil)
+			m.prevStates = nil
+			m.state = stateMainMenu
+			if focus := m.setMenuInput("Choose an option", 32); focus != nil {
+				cmds = append(cmds, focus)
+			}
+			return batchCmds(cmds)
+		}
+		if isBackCommand(value) {
+			if m.contactForm.index == contactFieldName {
+				if focus := m.closeContactForm(); focus != nil {
+					cmds = append(cmds, focus)
+				}
+				return batchCmds(cmds)
+			}
+			m.contactForm.index--
+			prev := m.contactForm.fields[m.contactForm.index]
+			m.contactForm.input.Placeholder = prev.label
+			m.contactForm.input.SetValue(prev.value)
+			m.contactForm.err = ""
+			m.contactForm.warning = ""
+			return batchCmds(cmds)
+		}
+		field := m.contactForm.fields[m.contactForm.index]
+		if field.required && value == "" {
+			m.contactForm.err = "This field is required"
+			return batchCmds(cmds)
+		}
+		if m.contactForm.index == contactFieldPrimary {
+			if _, ok := parseYesNo(value); !ok && value != "" {
+				m.contactForm.err = "Please answer y or n"
+				return batchCmds(cmds)
+			}
+		}
+		checked, warning := m.checkContactFormField(m.contactForm.index, value)
+		if warning != "" && value != m.contactForm.warned {
+			m.contactForm.warning = warning
+			m.contactForm.warned = value
+			m.contactForm.err = ""
+			return batchCmds(cmds)
+		}
+		value = checked
+		m.contactForm.warning = ""
+		m.contactForm.warned = ""
+		m.contactForm.fields[m.contactForm.index].value = value
+		m.contactForm.input.SetValue("")
+		m.contactForm.err = ""
+		if m.contactForm.index < len(m.contactForm.fields)-1 {
+			m.contactForm.index++
+			next := m.contactForm.fields[m.contactForm.index]
+			m.contactForm.input.Placeholder = next.label
+			m.contactForm.input.SetValue(next.value)
+			return batchCmds(cmds)
+		}
+		contact := buildContact(m.contactForm.fields, m.contactForm.account.ID)
+		if original := m.contactForm.editing; original != nil {
+			contact.ID = original.ID
+			contact.AccountID = original.AccountID
+			contact.Creator = original.Creator
+			contact.CreatedAt = original.CreatedAt
+			undo := m.snapshotRecord("contact", contact.ID)
+			if err := m.store.UpdateContact(context.Background(), &contact); err != nil {
+				m.contactForm.err = err.Error()
+				return batchCmds(cmds)
+			}
+			m.pushUndo(fmt.Sprintf("edit of contact '%s'", original.Name), undo)
+			m.infoMessage = fmt.Sprintf("Contact '%s' updated", contact.Name)
+		} else {
+			contact.Creator = m.cfg.Config.Name
+			contact.CreatedAt = time.Now().In(m.cfg.Location())
+			if err := m.store.CreateContact(context.Background(), &contact); err != nil {
+				m.contactForm.err = err.Error()
+				return batchCmds(cmds)
+			}
+			m.pushCreated(fmt.Sprintf("new contact '%s'", contact.Name), "contact", contact.ID)
+			m.infoMessage = fmt.Sprintf("Contact '%s' added to %s", contact.Name, m.contactForm.account.Name)
+		}
+		if focus := m.closeContactForm(); focus != nil {
+			cmds = append(cmds, focus)
+		}
+		m.refreshAccounts()
+		if m.state == stateAccountDetail {
+			m.loadAccountActivity()
+		}
+	case tea.KeyEsc:
+		if focus := m.closeContactForm(); focus != nil {
+			cmds = append(cmds, focus)
+		}
+	}
+	return batchCmds(cmds)
+}
+
+func (m *model) closeContactForm() tea.Cmd {
+	m.contactForm = newContactForm(nil)
+	m.popState()
+	switch m.state {
+	case stateMainMenu:
+		return m.setMenuInput("Choose an option", 32)
+	case stateAccountDetail:
+		m.refreshAccountDetailAccount()
+		return m.setMenuInput(accountDetailPrompt, 72)
+	}
+	return nil
+}
+
+func buildContact(fields []formField, accountID int64) storage.Contact {
+	contact := storage.Contact{AccountID: accountID}
+	if len(fields) > contactFieldName {
+		contact.Name = fields[contactFieldName].value
+	}
+	if len(fields) > contactFieldTitle {
+		contact.Title = fields[contactFieldTitle].value
+	}
+	if len(fields) > contactFieldEmail {
+		contact.Email = fields[contactFieldEmail].value
+	}
+	if len(fields) > contactFieldPhone {
+		contact.Phone = fields[contactFieldPhone].value
+	}
+	if len(fields) > contactFieldRole {
+		contact.Role = fields[contactFieldRole].value
+	}
+	if len(fields) > contactFieldPrimary {
+		contact.Primary, _ = parseYesNo(fields[contactFieldPrimary].value)
+	}
+	return contact
+}
+
+// checkContactFormField validates the email and phone steps of the contact
+// form like checkContactField does for accounts, returning the value to
+// save and a warning when it does not look valid.
+func (m *model) checkContactFormField(index int, value string) (string, string) {
+	var err error
+	switch index {
+	case contactFieldEmail:
+		value, err = validate.Email(value)
+	case contactFieldPhone:
+		value, err = validate.Phone(value, m.cfg.PhoneCountry())
+	default:
+		return value, ""
+	}
+	if err != nil {
+		return value, fmt.Sprintf("%s: %v", m.contactForm.fields[index].label, err)
+	}
+	return value, ""
+}
+
+func parseYesNo(value string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "y", "yes":
+		return true, true
+	case "n", "no":
+		return false, true
+	}
+	return false, false
+}
+
+func (m *model) viewContactForm() string {
+	field := m.contactForm.fields[m.contactForm.index]
+	title := "Add Contact"
+	if m.contactForm.editing != nil {
+		title = "Edit Contact"
+	}
+	lines := []string{
+		m.theme.Title.Render(title),
+		m.theme.Faint.Render(fmt.Sprintf("For %s. '/' to go back, 'exit.' to cancel.", m.contactForm.account.Name)),
+		"",
+		m.theme.Secondary.Render(fmt.Sprintf("%d/%d", m.contactForm.index+1, len(m.contactForm.fields))),
+		m.theme.Primary.Render(field.label + ":"),
+		m.contactForm.input.View(),
+	}
+	if m.contactForm.err != "" {
+		lines = append(lines, "", m.theme.Danger.Render(m.contactForm.err))
+	}
+	if m.contactForm.warning != "" {
+		lines = append(lines, "", m.theme.Warning.Render(m.contactForm.warning+"; press Enter again to keep it"))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func formatContactLine(m *model, n int, c storage.Contact) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%d. %s", n, c.Name)
+	if c.Title != "" {
+		builder.WriteString(", " + c.Title)
+	}
+	meta := []string{}
+	if c.Role != "" {
+		meta = append(meta, c.Role)
+	}
+	if c.Email != "" {
+		meta = append(meta, c.Email)
+	}
+	if c.Phone != "" {
+		meta = append(meta, c.Phone)
+	}
+	if len(meta) > 0 {
+		builder.WriteString(" • " + strings.Join(meta, " • "))
+	}
+	if c.Primary {
+		return m.theme.Accent.Render("★ " + builder.String())
+	}
+	return m.theme.Primary.Render("  " + builder.String())
+}
+
+// accountContactCommand handles "edit contact 2" and "del contact 2" typed on
+// the account screen. It reports false when input is not a contact command.
+func (m *model) accountContactCommand(input string) (tea.Cmd, bool) {
+	verb, arg, ok := "edit", "", false
+	for _, v := range []string{"edit", "e", "delete", "del", "d"} {
+		rest, found := cutCommand(input, v)
+		if !found {
+			continue
+		}
+		if arg, ok = cutCommand(rest, "contact", "c"); ok {
+			if v != "edit" && v != "e" {
+				verb = "delete"
+			}
+			break
+		}
+	}
+	if !ok {
+		return nil, false
+	}
+	idx, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+	if err != nil || idx <= 0 || idx > len(m.accountDetail.contacts) {
+		m.accountDetail.err = "Invalid contact number"
+		return nil, true
+	}
+	m.accountDetail.err = ""
+	contact := m.accountDetail.contacts[idx-1]
+	if verb == "delete" {
+		m.accountDetail.deletingContact = &contact
+		return m.setMenuInput(activityConfirmPrompt, 8), true
+	}
+	account := m.accountDetail.account
+	m.accountDetail.view = accountDetailSummary
+	m.contactForm = editContactForm(contact, &account)
+	m.pushState(stateCreateContact)
+	return nil, true
+}
+
+// deleteContactChoice answers the y/n confirmation for deleting a contact.
+func (m *model) deleteContactChoice(choice string) tea.Cmd {
+	contact := m.accountDetail.deletingContact
+	yes, ok := parseYesNo(choice)
+	if !ok {
+		m.accountDetail.err = "Please answer y or n"
+		return nil
+	}
+	m.accountDetail.deletingContact = nil
+	m.accountDetail.err = ""
+	if yes {
+		undo := m.snapshotRecord("contact", contact.ID)
+		if err := m.store.DeleteContact(context.Background(), contact.ID); err != nil {
+			m.accountDetail.err = fmt.Sprintf("delete contact: %v", err)
+		} else {
+			m.pushUndo(fmt.Sprintf("deletion of contact '%s'", contact.Name), undo)
+			m.infoMessage = fmt.Sprintf("Contact '%s' deleted", contact.Name)
+			m.refreshAccountDetailAccount()
+			m.refreshAccounts()
+		}
+	}
+	return m.setMenuInput(accountDetailPrompt, 72)
+}
@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"context"
+	"testing"
+
+	"crmterm/internal/storage"
+)
+
+func TestContactFormChecksEmailAndPhone(t *testing.T) {
+	m := newTestModel(t)
+	ctx := context.Background()
+	account := storage.Account{Name: "Acme", Creator: "tester"}
+	if err := m.store.CreateAccount(ctx, &account); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	m.openAccountDetail(account)
+	m.contactForm = newContactForm(&account)
+	m.pushState(stateCreateContact)
+
+	typeLine(m, "Jane")
+	typeLine(m, "CTO")
+	typeLine(m, "jane@")
+	if m.contactForm.index != contactFieldEmail || m.contactForm.warning == "" {
+		t.Fatalf("bad email moved on to step %d without a warning", m.contactForm.index)
+	}
+	m.contactForm.input.SetValue("")
+	typeLine(m, "Jane@Acme.TEST")
+	if m.contactForm.index != contactFieldPhone {
+		t.Fatalf("valid email left the form at step %d: %s", m.contactForm.index, m.contactForm.warning)
+	}
+	typeLine(m, "555")
+	if m.contactForm.warning == "" {
+		t.Fatalf("short phone gave no warning")
+	}
+	m.contactForm.input.SetValue("")
+	typeLine(m, "(555) 555-5555")
+	typeLine(m, "")
+	typeLine(m, "y")
+
+	contacts, err := m.store.ListContacts(ctx, account.ID)
+	if err != nil {
+		t.Fatalf("list contacts: %v", err)
+	}
+	if len(contacts) != 1 {
+		t.Fatalf("contacts = %+v, want Jane: %s", contacts, m.contactForm.err)
+	}
+	if got := contacts[0]; got.Email != "Jane@acme.test" || got.Phone != "+15555555555" {
+		t.Errorf("saved email %q and phone %q, want them normalized", got.Email, got.Phone)
+	}
+}
+
+func TestAccountFormChecksNamedSteps(t *testing.T) {
+	m := newTestModel(t)
+	m.accountForm = newAccountForm(nil)
+	tests := []struct {
+		step        int
+		in, want    string
+		wantWarning bool
+	}{
+		{step: accountNameStep, in: "acme@", want: "acme@"},
+		{step: accountPhoneStep, in: "(555) 555-5555", want: "+15555555555"},
+		{step: accountPhoneStep, in: "555", want: "555", wantWarning: true},
+		{step: accountAddressStep, in: "1 Main St\nSpringfield", want: "1 Main St, Springfield"},
+		{step: accountEmailStep, in: "Bob@Example.COM", want: "Bob@example.com"},
+		{step: accountEmailStep, in: "bob@", want: "bob@", wantWarning: true},
+		{step: accountDecisionMakerStep, in: "555", want: "555"},
+	}
+	for _, tt := range tests {
+		got, warning := m.checkContactField(tt.step, tt.in)
+		if got != tt.want || (warning != "") != tt.wantWarning {
+			t.Errorf("checkContactField(%s, %q) = %q, %q", m.accountForm.fields[tt.step].label, tt.in, got, warning)
+		}
+	}
+}
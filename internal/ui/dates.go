@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"crmterm/internal/dateparse"
+)
+
+// defaultEventHour is when an event typed as a bare day ("fri") starts.
+const defaultEventHour = 9
+
+// dateHint lists the forms every date prompt accepts.
+const dateHint = "e.g. tomorrow 3pm, next tue 10:30, in 2 weeks, fri, +3d, 2026-10-20"
+
+// parseWhen resolves a typed date relative to now in the configured time
+// zone and date order.
+func (m *model) parseWhen(value string) (dateparse.Result, error) {
+	return dateparse.ParseWithOrder(value, time.Now().In(m.cfg.Location()), m.cfg.DateOrder())
+}
+
+// parseEventTime resolves the event wizard's schedule. Blank means now and
+// a bare day starts at defaultEventHour.
+func (m *model) parseEventTime(value string) (time.Time, error) {
+	r, err := m.parseWhen(value)
+	if errors.Is(err, dateparse.ErrEmpty) {
+		return time.Now().In(m.cfg.Location()), nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return r.At(defaultEventHour, 0), nil
+}
+
+// datePreview renders what a date prompt's input currently resolves to, for
+// showing under the input as the user types. withTime previews bare days
+// at defaultEventHour.
+func (m *model) datePreview(value string, withTime bool) string {
+	value = strings.TrimSpace(value)
+	if value == "" || isBackCommand(value) || isExitCommand(value) {
+		return ""
+	}
+	r, err := m.parseWhen(value)
+	if err != nil {
+		return m.theme.Faint.Render("? " + err.Error())
+	}
+	if withTime && !r.HasTime {
+		r = dateparse.Result{Time: r.At(defaultEventHour, 0), HasTime: true}
+	}
+	return m.theme.Faint.Render("→ " + r.String())
+}
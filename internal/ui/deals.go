@@ -0,0 +1,454 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"crmterm/internal/storage"
+)
+
+const dealsPrompt = "n=New  b=Board  <#> <stage>=Move  r=Refresh  /=Back"
+
+type dealsModel struct {
+	all     []storage.Deal
+	groups  []storage.StageGroup
+	ordered []storage.Deal
+	err     string
+}
+
+type dealForm struct {
+	index   int
+	fields  []formField
+	input   textinput.Model
+	err     string
+	account *storage.Account
+	preset  bool
+}
+
+const (
+	dealFieldAccount = iota
+	dealFieldTitle
+	dealFieldAmount
+	dealFieldCurrency
+	dealFieldStage
+	dealFieldProbability
+	dealFieldClose
+	dealFieldOwner
+)
+
+func newDealForm(account *storage.Account) dealForm {
+	ti := textinput.New()
+	ti.CharLimit = 96
+	ti.Focus()
+	form := dealForm{
+		fields: []formField{
+			{label: "Account name", required: true},
+			{label: "Deal title", required: true},
+			{label: "Amount (e.g. 12,500.00)", required: false},
+			{label: "Currency (blank = " + storage.DefaultCurrency + ")", required: false},
+			{label: "Stage (blank = first stage)", required: false},
+			{label: "Probability % (0-100)", required: false},
+			{label: "Expected close (e.g. 2026-12-31, next month, +6w)", required: false},
+			{label: "Owner (blank = you)", required: false},
+		},
+		input: ti,
+	}
+	if account != nil {
+		clone := *account
+		form.account = &clone
+		form.preset = true
+		form.fields[dealFieldAccount].value = clone.Name
+		form.index = dealFieldTitle
+	}
+	form.input.Placeholder = form.fields[form.index].label
+	return form
+}
+
+func (m *model) refreshDeals() {
+	deals, err := m.store.ListDeals(context.Background())
+	if err != nil {
+		m.deals.err = fmt.Sprintf("load deals: %v", err)
+		return
+	}
+	m.deals.err = ""
+	m.deals.all = deals
+	m.deals.groups = storage.GroupDeals(deals, m.cfg.DealStages())
+	m.deals.ordered = m.deals.ordered[:0]
+	for _, g := range m.deals.groups {
+		m.deals.ordered = append(m.deals.ordered, g.Deals...)
+	}
+}
+
+// resolveStage matches input against the configured stages by exact name or
+// unique prefix.
+func resolveStage(input string, stages []string) (string, bool) {
+	value := strings.ToLower(strings.TrimSpace(input))
+	if value == "" {
+		return "", false
+	}
+	for _, stage := range stages {
+		if strings.ToLower(stage) == value {
+			return stage, true
+		}
+	}
+	match := ""
+	for _, stage := range stages {
+		if strings.HasPrefix(strings.ToLower(stage), value) {
+			if match != "" {
+				return "", false
+			}
+			match = stage
+		}
+	}
+	return match, match != ""
+}
+
+// DEALS LIST
+func (m *model) updateDeals(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+	if focus := m.ensureMenuInput(dealsPrompt, 64); focus != nil {
+		cmds = append(cmds, focus)
+	}
+	var cmd tea.Cmd
+	m.menuInput, cmd = m.menuInput.Update(msg)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return batchCmds(cmds)
+	}
+	switch key.Type {
+	case tea.KeyEnter:
+		command := strings.TrimSpace(m.menuInput.Value())
+		lower := strings.ToLower(command)
+		m.menuInput.SetValue("")
+		m.deals.err = ""
+		switch {
+		case lower == "":
+		case lower == "n" || lower == "new":
+			m.resetMessages()
+			m.dealForm = newDealForm(nil)
+			m.pushState(stateCreateDeal)
+		case lower == "r" || lower == "refresh":
+			m.refreshDeals()
+		case lower == "b" || lower == "board" || lower == "kanban":
+			m.menuInput.Blur()
+			m.openPipelineBoard()
+		case isExitCommand(lower) || lower == "exit":
+			m.prevStates = nil
+			m.state = stateMainMenu
+			if focus := m.setMenuInput("Choose an option", 32); focus != nil {
+				cmds = append(cmds, focus)
+			}
+		case isBackCommand(lower):
+			m.popState()
+			if m.state == stateMainMenu {
+				if focus := m.setMenuInput("Choose an option", 32); focus != nil {
+					cmds = append(cmds, focus)
+				}
+			}
+		default:
+			m.moveDealCommand(strings.TrimPrefix(lower, "move "))
+		}
+	case tea.KeyEsc:
+		m.popState()
+		if m.state == stateMainMenu {
+			if focus := m.setMenuInput("Choose an option", 32); focus != nil {
+				cmds = append(cmds, focus)
+			}
+		}
+	}
+	return batchCmds(cmds)
+}
+
+func (m *model) moveDealCommand(command string) {
+	parts := strings.Fields(command)
+	if len(parts) < 2 {
+		m.deals.err = "Use '<#> <stage>' to move a deal, or 'n' for a new one"
+		return
+	}
+	idx, err := strconv.Atoi(parts[0])
+	if err != nil || idx <= 0 || idx > len(m.deals.ordered) {
+		m.deals.err = "Invalid deal number"
+		return
+	}
+	stage, ok := resolveStage(strings.Join(parts[1:], " "), m.cfg.DealStages())
+	if !ok {
+		m.deals.err = "Unknown stage"
+		return
+	}
+	m.moveDeal(m.deals.ordered[idx-1], stage)
+}
+
+func (m *model) moveDeal(deal storage.Deal, stage string) {
+	undo := m.snapshotRecord("deal", deal.ID)
+	if err := m.store.MoveDealStage(context.Background(), deal.ID, stage, m.cfg.Config.Name); err != nil {
+		m.deals.err = err.Error()
+		return
+	}
+	m.pushUndo(fmt.Sprintf("move of '%s' to %s", deal.Title, stage), undo)
+	m.infoMessage = fmt.Sprintf("Moved '%s' to %s", deal.Title, stage)
+	m.refreshDeals()
+	m.refreshDashboard(time.Now().In(m.cfg.Location()))
+}
+
+func (m *model) viewDeals() string {
+	lines := []string{m.theme.Title.Render("Deals Pipeline")}
+	lines = append(lines, m.theme.Faint.Render("'n' adds a deal, 'b' opens the board, '3 won' moves deal #3 to Won, '/' to go back."))
+	lines = append(lines, "")
+	if len(m.deals.all) == 0 {
+		lines = append(lines, m.theme.Warning.Render("No deals yet."))
+		lines = append(lines, "")
+	}
+	n := 0
+	for _, g := range m.deals.groups {
+		header := fmt.Sprintf("%s (%d) — %s", g.Stage, len(g.Deals), storage.FormatTotals(g.Totals))
+		lines = append(lines, m.theme.Subtitle.Render(header))
+		for _, d := range g.Deals {
+			n++
+			lines = append(lines, m.theme.Primary.Render(fmt.Sprintf("%d. %s", n, formatDealLine(m, d))))
+		}
+		lines = append(lines, "")
+	}
+	lines = append(lines, m.theme.Accent.Render("> ")+m.menuInput.View())
+	if m.deals.err != "" {
+		lines = append(lines, "", m.theme.Danger.Render(m.deals.err))
+	}
+	if m.infoMessage != "" {
+		lines = append(lines, "", m.theme.Success.Render(m.infoMessage))
+	}
+	if m.errMessage != "" {
+		lines = append(lines, "", m.theme.Danger.Render(m.errMessage))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func formatDealLine(m *model, d storage.Deal) string {
+	var builder strings.Builder
+	builder.WriteString(d.Title)
+	builder.WriteString(" (" + d.AccountName + ")")
+	builder.WriteString(" • " + storage.FormatAmount(d.AmountCents, d.Currency))
+	builder.WriteString(fmt.Sprintf(" • %d%%", d.Probability))
+	if !d.ExpectedClose.IsZero() {
+		builder.WriteString(" • closes " + d.ExpectedClose.Format("Jan 02 2006"))
+	}
+	if d.Owner != "" {
+		builder.WriteString(" • " + d.Owner)
+	}
+	return builder.String()
+}
+
+// DEAL FORM
+func (m *model) updateDealForm(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+	var cmd tea.Cmd
+	m.dealForm.input, cmd = m.dealForm.input.Update(msg)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return batchCmds(cmds)
+	}
+	switch key.Type {
+	case tea.KeyEnter:
+		value := strings.TrimSpace(m.dealForm.input.Value())
+		if isExitCommand(value) {
+			m.dealForm = newDealForm(nil)
+			m.prevStates = nil
+			m.state = stateMainMenu
+			if focus := m.setMenuInput("Choose an option", 32); focus != nil {
+				cmds = append(cmds, focus)
+			}
+			return batchCmds(cmds)
+		}
+		if isBackCommand(value) {
+			first := dealFieldAccount
+			if m.dealForm.preset {
+				first = dealFieldTitle
+			}
+			if m.dealForm.index == first {
+				if focus := m.closeDealForm(); focus != nil {
+					cmds = append(cmds, focus)
+				}
+				return batchCmds(cmds)
+			}
+			m.dealForm.index--
+			prev := m.dealForm.fields[m.dealForm.index]
+			m.dealForm.input.Placeholder = prev.label
+			m.dealForm.input.SetValue(prev.value)
+			m.dealForm.err = ""
+			return batchCmds(cmds)
+		}
+		if m.dealForm.fields[m.dealForm.index].required && value == "" {
+			m.dealForm.err = "This field is required"
+			return batchCmds(cmds)
+		}
+		if problem := m.validateDealField(m.dealForm.index, value); problem != "" {
+			m.dealForm.err = problem
+			return batchCmds(cmds)
+		}
+		m.dealForm.fields[m.dealForm.index].value = value
+		m.dealForm.input.SetValue("")
+		m.dealForm.err = ""
+		if m.dealForm.index < len(m.dealForm.fields)-1 {
+			m.dealForm.index++
+			next := m.dealForm.fields[m.dealForm.index]
+			m.dealForm.input.Placeholder = next.label
+			m.dealForm.input.SetValue(next.value)
+			return batchCmds(cmds)
+		}
+		deal, err := m.buildDeal()
+		if err != nil {
+			m.dealForm.err = err.Error()
+			return batchCmds(cmds)
+		}
+		if err := m.store.CreateDeal(context.Background(), &deal); err != nil {
+			m.dealForm.err = err.Error()
+			return batchCmds(cmds)
+		}
+		m.infoMessage = fmt.Sprintf("Deal '%s' created in %s", deal.Title, deal.Stage)
+		if focus := m.closeDealForm(); focus != nil {
+			cmds = append(cmds, focus)
+		}
+		m.refreshDashboard(time.Now().In(m.cfg.Location()))
+	case tea.KeyEsc:
+		if focus := m.closeDealForm(); focus != nil {
+			cmds = append(cmds, focus)
+		}
+	}
+	return batchCmds(cmds)
+}
+
+// validateDealField checks one wizard answer and returns a user-facing
+// problem, or "" when the value is acceptable.
+func (m *model) validateDealField(index int, value string) string {
+	switch index {
+	case dealFieldAccount:
+		account, err := m.store.AccountByName(context.Background(), value)
+		if err != nil {
+			return "Account not found"
+		}
+		m.dealForm.account = account
+	case dealFieldAmount:
+		if _, err := storage.ParseAmount(value); err != nil {
+			return "Amount: " + err.Error()
+		}
+	case dealFieldCurrency:
+		if value != "" && len(value) != 3 {
+			return "Use a three-letter currency code"
+		}
+	case dealFieldStage:
+		if value != "" {
+			if _, ok := resolveStage(value, m.cfg.DealStages()); !ok {
+				return "Stage must be one of: " + strings.Join(m.cfg.DealStages(), ", ")
+			}
+		}
+	case dealFieldProbability:
+		if value != "" {
+			p, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+			if err != nil || p < 0 || p > 100 {
+				return "Probability must be a number from 0 to 100"
+			}
+		}
+	case dealFieldClose:
+		if value != "" {
+			if _, err := m.parseWhen(value); err != nil {
+				return "Couldn't read the date: " + err.Error()
+			}
+		}
+	}
+	return ""
+}
+
+func (m *model) buildDeal() (storage.Deal, error) {
+	fields := m.dealForm.fields
+	deal := storage.Deal{
+		Title:     fields[dealFieldTitle].value,
+		Currency:  fields[dealFieldCurrency].value,
+		Owner:     fields[dealFieldOwner].value,
+		Creator:   m.cfg.Config.Name,
+		CreatedAt: time.Now().In(m.cfg.Location()),
+	}
+	if m.dealForm.account == nil {
+		return deal, fmt.Errorf("account not found")
+	}
+	deal.AccountID = m.dealForm.account.ID
+	amount, err := storage.ParseAmount(fields[dealFieldAmount].value)
+	if err != nil {
+		return deal, err
+	}
+	deal.AmountCents = amount
+	stages := m.cfg.DealStages()
+	deal.Stage = stages[0]
+	if stage, ok := resolveStage(fields[dealFieldStage].value, stages); ok {
+		deal.Stage = stage
+	}
+	if p := strings.TrimSuffix(fields[dealFieldProbability].value, "%"); p != "" {
+		deal.Probability, _ = strconv.Atoi(p)
+	}
+	if c := fields[dealFieldClose].value; c != "" {
+		if r, err := m.parseWhen(c); err == nil {
+			deal.ExpectedClose = r.Time
+		}
+	}
+	if deal.Owner == "" {
+		deal.Owner = m.cfg.Config.Name
+	}
+	return deal, nil
+}
+
+func (m *model) closeDealForm() tea.Cmd {
+	m.dealForm = newDealForm(nil)
+	m.popState()
+	switch m.state {
+	case stateMainMenu:
+		return m.setMenuInput("Choose an option", 32)
+	case stateDeals:
+		m.refreshDeals()
+		return m.setMenuInput(dealsPrompt, 64)
+	case stateAccountDetail:
+		m.refreshAccountDetailAccount()
+		m.loadAccountActivity()
+		return m.setMenuInput(accountDetailPrompt, 72)
+	}
+	return nil
+}
+
+func (m *model) viewDealForm() string {
+	field := m.dealForm.fields[m.dealForm.index]
+	lines := []string{
+		m.theme.Title.Render("New Deal"),
+		m.theme.Faint.Render("Enter details. '/' to go back, 'exit.' to cancel."),
+	}
+	if m.dealForm.account != nil && m.dealForm.index > dealFieldAccount {
+		lines = append(lines, m.theme.Faint.Render(fmt.Sprintf("For %s", m.dealForm.account.Name)))
+	}
+	if m.dealForm.index == dealFieldStage {
+		lines = append(lines, m.theme.Faint.Render("Stages: "+strings.Join(m.cfg.DealStages(), " → ")))
+	}
+	lines = append(lines,
+		"",
+		m.theme.Secondary.Render(fmt.Sprintf("%d/%d", m.dealForm.index+1, len(m.dealForm.fields))),
+		m.theme.Primary.Render(field.label+":"),
+		m.dealForm.input.View(),
+	)
+	if m.dealForm.index == dealFieldClose {
+		if preview := m.datePreview(m.dealForm.input.Value(), false); preview != "" {
+			lines = append(lines, preview)
+		}
+	}
+	if m.dealForm.err != "" {
+		lines = append(lines, "", m.theme.Danger.Render(m.dealForm.err))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
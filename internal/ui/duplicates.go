@@ -0,0 +1,209 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"crmterm/internal/storage"
+)
+
+const (
+	duplicatesPrompt        = "merge <#> [a|b]  ignore <#>  /=Back"
+	duplicatesConfirmPrompt = "Merge these accounts? (y/n)"
+)
+
+type duplicatesModel struct {
+	pairs []storage.DuplicatePair
+	// ignored hides pairs dismissed this session, keyed by both IDs.
+	ignored map[[2]int64]bool
+	// pending is the merge a y/n confirmation will run.
+	pending *pendingMerge
+	err     string
+}
+
+type pendingMerge struct {
+	keep, drop storage.Account
+}
+
+func (m *model) openDuplicates() tea.Cmd {
+	m.duplicates = duplicatesModel{ignored: map[[2]int64]bool{}}
+	m.refreshDuplicates()
+	m.pushState(stateDuplicates)
+	return m.setMenuInput(duplicatesPrompt, 48)
+}
+
+func (m *model) refreshDuplicates() {
+	pairs, err := m.store.FindDuplicateAccounts(context.Background())
+	if err != nil {
+		m.duplicates.err = fmt.Sprintf("find duplicates: %v", err)
+		return
+	}
+	m.duplicates.pairs = m.duplicates.pairs[:0]
+	for _, pair := range pairs {
+		if !m.duplicates.ignored[[2]int64{pair.A.ID, pair.B.ID}] {
+			m.duplicates.pairs = append(m.duplicates.pairs, pair)
+		}
+	}
+}
+
+// DUPLICATES
+func (m *model) updateDuplicates(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+	placeholder := duplicatesPrompt
+	if m.duplicates.pending != nil {
+		placeholder = duplicatesConfirmPrompt
+	}
+	if focus := m.ensureMenuInput(placeholder, 48); focus != nil {
+		cmds = append(cmds, focus)
+	}
+	var cmd tea.Cmd
+	m.menuInput, cmd = m.menuInput.Update(msg)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+	key, ok := msg.(tea.KeyMsg)
+	if !ok || key.Type != tea.KeyEnter {
+		return batchCmds(cmds)
+	}
+	value := strings.TrimSpace(strings.ToLower(m.menuInput.Value()))
+	m.menuInput.SetValue("")
+	if isExitCommand(value) {
+		m.prevStates = nil
+		m.state = stateMainMenu
+		if focus := m.setMenuInput("Choose an option", 32); focus != nil {
+			cmds = append(cmds, focus)
+		}
+		return batchCmds(cmds)
+	}
+
+	if m.duplicates.pending != nil {
+		switch value {
+		case "y", "yes":
+			m.mergePending()
+		case "n", "no", "/", "back":
+			m.duplicates.pending = nil
+		default:
+			m.duplicates.err = "Please answer y or n"
+			return batchCmds(cmds)
+		}
+		if focus := m.setMenuInput(duplicatesPrompt, 48); focus != nil {
+			cmds = append(cmds, focus)
+		}
+		return batchCmds(cmds)
+	}
+
+	m.duplicates.err = ""
+	if isBackCommand(value) {
+		m.popState()
+		return batchCmds(cmds)
+	}
+	switch {
+	case value == "":
+	case value == "refresh":
+		m.refreshDuplicates()
+	default:
+		if arg, ok := cutCommand(value, "merge", "m"); ok {
+			num, side, _ := strings.Cut(arg, " ")
+			if pair, ok := m.duplicatePair(num); ok {
+				switch strings.TrimSpace(side) {
+				case "", "a":
+					m.duplicates.pending = &pendingMerge{keep: pair.A, drop: pair.B}
+				case "b":
+					m.duplicates.pending = &pendingMerge{keep: pair.B, drop: pair.A}
+				default:
+					m.duplicates.err = "Say which account to keep: 'merge 2 a' or 'merge 2 b'"
+				}
+			}
+			break
+		}
+		if arg, ok := cutCommand(value, "ignore", "i", "skip"); ok {
+			if pair, ok := m.duplicatePair(arg); ok {
+				m.duplicates.ignored[[2]int64{pair.A.ID, pair.B.ID}] = true
+				m.refreshDuplicates()
+			}
+			break
+		}
+		m.duplicates.err = "Use 'merge 2' to keep a, 'merge 2 b' to keep b, or 'ignore 2'"
+	}
+	if m.duplicates.pending != nil {
+		if focus := m.setMenuInput(duplicatesConfirmPrompt, 48); focus != nil {
+			cmds = append(cmds, focus)
+		}
+	}
+	return batchCmds(cmds)
+}
+
+func (m *model) duplicatePair(arg string) (storage.DuplicatePair, bool) {
+	idx, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(arg), "#"))
+	if err != nil || idx <= 0 || idx > len(m.duplicates.pairs) {
+		m.duplicates.err = "Invalid pair number"
+		return storage.DuplicatePair{}, false
+	}
+	return m.duplicates.pairs[idx-1], true
+}
+
+func (m *model) mergePending() {
+	merge := m.duplicates.pending
+	m.duplicates.pending = nil
+	ctx := context.Background()
+	snap, err := m.store.SnapshotMerge(ctx, merge.keep.ID, merge.drop.ID)
+	if err != nil {
+		m.duplicates.err = fmt.Sprintf("prepare undo: %v", err)
+	}
+	if err := m.store.MergeAccounts(ctx, merge.keep.ID, merge.drop.ID, m.cfg.Config.Name); err != nil {
+		m.duplicates.err = fmt.Sprintf("merge: %v", err)
+		return
+	}
+	m.pushUndo(fmt.Sprintf("merge of '%s' into '%s'", merge.drop.Name, merge.keep.Name), snap)
+	m.infoMessage = fmt.Sprintf("Merged '%s' into '%s'", merge.drop.Name, merge.keep.Name)
+	m.refreshDuplicates()
+	m.refreshAccounts()
+	m.refreshDashboard(time.Now().In(m.cfg.Location()))
+}
+
+func (m *model) viewDuplicates() string {
+	lines := []string{m.theme.Title.Render("Possible Duplicates")}
+	lines = append(lines, m.theme.Faint.Render("Pairs scored by name, email domain, phone and address. 'merge 2' keeps a and folds b into it, 'merge 2 b' keeps b, 'ignore 2' hides the pair."))
+	lines = append(lines, "")
+	if len(m.duplicates.pairs) == 0 {
+		lines = append(lines, m.theme.Faint.Render("No likely duplicates found."))
+	}
+	for i, pair := range m.duplicates.pairs {
+		lines = append(lines, m.theme.Primary.Render(fmt.Sprintf("%d. ", i+1))+m.theme.Warning.Render(fmt.Sprintf("%d%%", pair.Score))+
+			m.theme.Faint.Render(" — "+strings.Join(pair.Reasons, ", ")))
+		lines = append(lines, "   "+m.formatDuplicateSide("a", pair.A))
+		lines = append(lines, "   "+m.formatDuplicateSide("b", pair.B))
+		lines = append(lines, "")
+	}
+	if merge := m.duplicates.pending; merge != nil {
+		lines = append(lines, m.theme.Warning.Render(fmt.Sprintf("Merge '%s' into '%s'? Its notes, events, tasks, contacts and deals move over and '%s' is removed.", merge.drop.Name, merge.keep.Name, merge.drop.Name)))
+	}
+	lines = append(lines, m.theme.Accent.Render("> ")+m.menuInput.View())
+	if m.duplicates.err != "" {
+		lines = append(lines, "", m.theme.Danger.Render(m.duplicates.err))
+	}
+	if m.infoMessage != "" {
+		lines = append(lines, "", m.theme.Success.Render(m.infoMessage))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func (m *model) formatDuplicateSide(label string, a storage.Account) string {
+	item := m.theme.Secondary.Render(label+") ") + m.theme.Primary.Render(a.Name)
+	var meta []string
+	for _, value := range []string{a.Phone, a.Email, a.Address} {
+		if value != "" {
+			meta = append(meta, value)
+		}
+	}
+	if len(meta) > 0 {
+		item += m.theme.Faint.Render("  " + strings.Join(meta, " • "))
+	}
+	created := a.CreatedAt.In(m.cfg.Location()).Format("Jan 02 2006")
+	return item + m.theme.Faint.Render("  added "+created)
+}
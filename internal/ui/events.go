@@ -0,0 +1,187 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"crmterm/internal/dateparse"
+	"crmterm/internal/storage"
+)
+
+// parseEventDuration reads the duration step of the event wizard: "45m",
+// "1h30", "1.5h", a bare number of minutes, or an end time in any form the
+// start prompt takes, such as "16:30", "5pm" or "in 2 hours", read from
+// the start. Blank means the event has no set length.
+func parseEventDuration(value string, start time.Time, order dateparse.Order) (time.Duration, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "", "0", "none", "n", "no":
+		return 0, nil
+	}
+	if n, err := strconv.Atoi(value); err == nil {
+		if n < 0 {
+			return 0, fmt.Errorf("Duration can't be negative")
+		}
+		return time.Duration(n) * time.Minute, nil
+	}
+	length := value
+	if strings.Contains(length, "h") && !strings.HasSuffix(length, "h") && !strings.HasSuffix(length, "m") {
+		// "1h30" means 1h30m.
+		length += "m"
+	}
+	if d, err := time.ParseDuration(length); err == nil {
+		if d < 0 {
+			return 0, fmt.Errorf("Duration can't be negative")
+		}
+		return d.Round(time.Minute), nil
+	}
+	r, err := dateparse.ParseWithOrder(value, start, order)
+	if err != nil || !r.HasTime {
+		return 0, fmt.Errorf("Durations look like 45m or 1h30, end times like 16:30, 5pm or 'in 2 hours'")
+	}
+	if !r.Time.After(start) {
+		return 0, fmt.Errorf("The end time must be after the start (%s)", start.Format("15:04"))
+	}
+	return r.Time.Sub(start).Round(time.Minute), nil
+}
+
+// formatEventDuration renders a duration the way parseEventDuration reads
+// it, e.g. "1h30m".
+func formatEventDuration(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	switch {
+	case minutes < 60:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes%60 == 0:
+		return fmt.Sprintf("%dh", minutes/60)
+	}
+	return fmt.Sprintf("%dh%02dm", minutes/60, minutes%60)
+}
+
+// resolveAttendees matches a comma separated list of contact names against
+// the account's contacts. "all" invites everyone; blank invites no one.
+func resolveAttendees(value string, contacts []storage.Contact) ([]int64, error) {
+	value = strings.TrimSpace(value)
+	switch strings.ToLower(value) {
+	case "", "none", "n", "no":
+		return nil, nil
+	case "all":
+		ids := make([]int64, len(contacts))
+		for i, c := range contacts {
+			ids[i] = c.ID
+		}
+		return ids, nil
+	}
+	var ids []int64
+	seen := map[int64]bool{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		var matches []storage.Contact
+		for _, c := range contacts {
+			if strings.ToLower(c.Name) == name {
+				matches = []storage.Contact{c}
+				break
+			}
+			if strings.HasPrefix(strings.ToLower(c.Name), name) {
+				matches = append(matches, c)
+			}
+		}
+		switch len(matches) {
+		case 0:
+			return nil, fmt.Errorf("No contact named '%s'", name)
+		case 1:
+		default:
+			return nil, fmt.Errorf("'%s' matches more than one contact; type more of the name", name)
+		}
+		if !seen[matches[0].ID] {
+			seen[matches[0].ID] = true
+			ids = append(ids, matches[0].ID)
+		}
+	}
+	return ids, nil
+}
+
+// attendeeNames lists the attendees for display and for pre-filling the
+// wizard.
+func attendeeNames(attendees []storage.Attendee) string {
+	names := make([]string, len(attendees))
+	for i, a := range attendees {
+		names[i] = a.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// chooseEventAccount records the account the wizard's event belongs to.
+// When that account has contacts the wizard moves on to ask who attends;
+// otherwise the event is saved straight away and true is returned.
+func (m *model) chooseEventAccount(accountID *sql.NullInt64, message string, back eventStage) bool {
+	w := &m.eventWizard
+	w.accountID = accountID
+	w.message = message
+	w.attendeesBack = back
+	w.contacts = nil
+	w.attendees = nil
+	if accountID != nil && accountID.Valid {
+		contacts, err := m.store.ListContacts(context.Background(), accountID.Int64)
+		if err != nil {
+			w.err = err.Error()
+			return false
+		}
+		if len(contacts) > 0 {
+			w.contacts = contacts
+			w.stage = eventStageAttendees
+			return false
+		}
+	}
+	if err := m.saveEvent(accountID); err != nil {
+		w.err = err.Error()
+		return false
+	}
+	m.completeEventSave(message)
+	return true
+}
+
+// eventConflictWarning describes the events that overlap the one just
+// saved, or returns "" when its creator is free.
+func (m *model) eventConflictWarning(e storage.Event) string {
+	conflicts, err := m.store.EventConflicts(context.Background(), e)
+	if err != nil || len(conflicts) == 0 {
+		return ""
+	}
+	loc := m.cfg.Location()
+	first := conflicts[0]
+	warning := fmt.Sprintf("overlaps '%s' on %s", first.Title, first.EventTime.In(loc).Format("Mon Jan 02 15:04"))
+	if len(conflicts) > 1 {
+		warning += fmt.Sprintf(" and %d more", len(conflicts)-1)
+	}
+	return warning
+}
+
+// eventKey identifies one occurrence among expanded events.
+func eventKey(e storage.Event) string {
+	return fmt.Sprintf("%d@%d", e.ID, e.EventTime.Unix())
+}
+
+// overlappingEvents marks the events that clash with another in the list.
+func overlappingEvents(events []storage.Event) map[string]bool {
+	clashes := map[string]bool{}
+	for i := range events {
+		for j := i + 1; j < len(events); j++ {
+			if events[i].Overlaps(events[j]) {
+				clashes[eventKey(events[i])] = true
+				clashes[eventKey(events[j])] = true
+			}
+		}
+	}
+	return clashes
+}
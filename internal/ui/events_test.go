@@ -0,0 +1,131 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"crmterm/internal/dateparse"
+	"crmterm/internal/storage"
+)
+
+func TestParseEventDuration(t *testing.T) {
+	start := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "", want: 0},
+		{in: "none", want: 0},
+		{in: "45", want: 45 * time.Minute},
+		{in: "45m", want: 45 * time.Minute},
+		{in: "1h30", want: 90 * time.Minute},
+		{in: "1.5h", want: 90 * time.Minute},
+		{in: "16:30", want: 150 * time.Minute},
+		{in: "5pm", want: 3 * time.Hour},
+		{in: "5.30pm", want: 210 * time.Minute},
+		{in: "in 2 hours", want: 2 * time.Hour},
+		{in: "+90m", want: 90 * time.Minute},
+		{in: "tomorrow 9am", want: 19 * time.Hour},
+
+		{in: "-5", wantErr: true},
+		{in: "-1h", wantErr: true},
+		{in: "13:00", wantErr: true},
+		{in: "2pm", wantErr: true},
+		{in: "fri", wantErr: true},
+		{in: "soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseEventDuration(tt.in, start, dateparse.OrderAuto)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseEventDuration(%q) = %v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseEventDuration(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseEventDuration(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatEventDuration(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{0, ""},
+		{-time.Hour, ""},
+		{45 * time.Minute, "45m"},
+		{2 * time.Hour, "2h"},
+		{90 * time.Minute, "1h30m"},
+		{125*time.Minute + 20*time.Second, "2h05m"},
+	}
+	start := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		got := formatEventDuration(tt.in)
+		if got != tt.want {
+			t.Errorf("formatEventDuration(%v) = %q, want %q", tt.in, got, tt.want)
+			continue
+		}
+		if back, err := parseEventDuration(got, start, dateparse.OrderAuto); tt.in > 0 && (err != nil || back != tt.in.Round(time.Minute)) {
+			t.Errorf("parseEventDuration(%q) = %v, %v; want it to read back %v", got, back, err, tt.in.Round(time.Minute))
+		}
+	}
+}
+
+func TestResolveAttendees(t *testing.T) {
+	contacts := []storage.Contact{
+		{ID: 1, Name: "Ann Lee"},
+		{ID: 2, Name: "Ann"},
+		{ID: 3, Name: "Bob Stone"},
+		{ID: 4, Name: "Bea Stone"},
+	}
+	tests := []struct {
+		in      string
+		want    []int64
+		wantErr bool
+	}{
+		{in: "", want: nil},
+		{in: "none", want: nil},
+		{in: "ALL", want: []int64{1, 2, 3, 4}},
+		{in: "ann", want: []int64{2}},
+		{in: "ann l, bob", want: []int64{1, 3}},
+		{in: "bob, Bob Stone,", want: []int64{3}},
+		{in: "b", wantErr: true},
+		{in: "carol", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := resolveAttendees(tt.in, contacts)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("resolveAttendees(%q) = %v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("resolveAttendees(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestOverlappingEvents(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2026, 5, 4, hour, 0, 0, 0, time.UTC) }
+	events := []storage.Event{
+		{ID: 1, EventTime: at(9), Duration: 2 * time.Hour, Creator: "alice"},
+		{ID: 2, EventTime: at(10), Duration: time.Hour, Creator: "alice"},
+		{ID: 3, EventTime: at(10), Duration: time.Hour, Creator: "bob"},
+		{ID: 4, EventTime: at(11), Duration: time.Hour, Creator: "alice"},
+	}
+	clashes := overlappingEvents(events)
+	for i, want := range []bool{true, true, false, false} {
+		if got := clashes[eventKey(events[i])]; got != want {
+			t.Errorf("event %d clashes = %v, want %v", events[i].ID, got, want)
+		}
+	}
+}
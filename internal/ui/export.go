@@ -0,0 +1,150 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"crmterm/internal/dateparse"
+	"crmterm/internal/storage"
+)
+
+// exportUsage explains the export command wherever it is offered.
+const exportUsage = "<path> [notes|events|all] [since:<date>] [until:<date>] [tag:x] [status:x] [search]"
+
+// exportRequest is a parsed export command.
+type exportRequest struct {
+	path string
+	// kind is "accounts", "notes", "events" or "all".
+	kind   string
+	filter storage.ExportFilter
+	// query holds the account search and tag/status terms that scope the
+	// export, read the same way as the accounts list filter.
+	query string
+}
+
+// parseExportRequest reads an export command: the path comes first and
+// the remaining words pick what to export and how to scope it. Dates are
+// whole days in loc and until is inclusive.
+func parseExportRequest(value string, loc *time.Location, order dateparse.Order) (exportRequest, error) {
+	req := exportRequest{kind: "accounts"}
+	words := strings.Fields(value)
+	if len(words) == 0 {
+		return req, fmt.Errorf("Provide a CSV path: export %s", exportUsage)
+	}
+	req.path = words[0]
+	var query []string
+	for _, word := range words[1:] {
+		lower := strings.ToLower(word)
+		switch lower {
+		case "accounts", "notes", "events", "all":
+			req.kind = lower
+			continue
+		}
+		key, date, ok := strings.Cut(word, ":")
+		if !ok || date == "" {
+			query = append(query, word)
+			continue
+		}
+		switch strings.ToLower(key) {
+		case "since", "from":
+			r, err := dateparse.ParseWithOrder(date, time.Now().In(loc), order)
+			if err != nil {
+				return req, fmt.Errorf("Dates look like 2025-03-31, yesterday or -2w")
+			}
+			req.filter.Since = r.Time
+		case "until", "to":
+			r, err := dateparse.ParseWithOrder(date, time.Now().In(loc), order)
+			if err != nil {
+				return req, fmt.Errorf("Dates look like 2025-03-31, yesterday or -2w")
+			}
+			req.filter.Until = r.Time.AddDate(0, 0, 1)
+		default:
+			query = append(query, word)
+		}
+	}
+	req.query = strings.Join(query, " ")
+	return req, nil
+}
+
+// handleExport writes the CSV files an export command asks for and reports
+// what was written. "all" writes one file per kind next to path, named
+// <name>-accounts.csv and so on.
+func (m *model) handleExport(value string) {
+	m.infoMessage = ""
+	loc := m.cfg.Location()
+	req, err := parseExportRequest(value, loc, m.cfg.DateOrder())
+	if err != nil {
+		m.errMessage = err.Error()
+		return
+	}
+	resolved, err := expandPath(req.path)
+	if err != nil {
+		m.errMessage = fmt.Sprintf("export path: %v", err)
+		return
+	}
+	if strings.TrimSpace(req.query) != "" {
+		req.filter.AccountIDs = []int64{}
+		for _, a := range m.filterAccounts(req.query) {
+			req.filter.AccountIDs = append(req.filter.AccountIDs, a.ID)
+		}
+	}
+
+	ctx := context.Background()
+	exporters := map[string]func(context.Context, io.Writer, storage.ExportFilter, *time.Location) (int, error){
+		"accounts": m.store.ExportAccountsCSV,
+		"notes":    m.store.ExportNotesCSV,
+		"events":   m.store.ExportEventsCSV,
+	}
+	kinds := []string{req.kind}
+	paths := map[string]string{req.kind: resolved}
+	if req.kind == "all" {
+		kinds = []string{"accounts", "notes", "events"}
+		ext := filepath.Ext(resolved)
+		if ext == "" {
+			ext = ".csv"
+		}
+		base := strings.TrimSuffix(resolved, filepath.Ext(resolved))
+		for _, kind := range kinds {
+			paths[kind] = base + "-" + kind + ext
+		}
+	}
+	var parts []string
+	for _, kind := range kinds {
+		n, err := writeExport(paths[kind], func(w io.Writer) (int, error) {
+			return exporters[kind](ctx, w, req.filter, loc)
+		})
+		if err != nil {
+			m.errMessage = fmt.Sprintf("export %s: %v", kind, err)
+			return
+		}
+		parts = append(parts, pluralize(n, strings.TrimSuffix(kind, "s")))
+	}
+	where := resolved
+	if req.kind == "all" {
+		where = filepath.Dir(resolved)
+	}
+	m.infoMessage = fmt.Sprintf("Exported %s to %s", strings.Join(parts, ", "), where)
+}
+
+// writeExport creates path and fills it with write, removing the file
+// again if the export fails part way.
+func writeExport(path string, write func(io.Writer) (int, error)) (int, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, fmt.Errorf("create file: %w", err)
+	}
+	n, err := write(file)
+	if cerr := file.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("close file: %w", cerr)
+	}
+	if err != nil {
+		os.Remove(path)
+		return 0, err
+	}
+	return n, nil
+}
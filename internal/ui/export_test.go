@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"crmterm/internal/dateparse"
+	"crmterm/internal/storage"
+)
+
+func TestParseExportRequest(t *testing.T) {
+	loc := time.FixedZone("UTC-5", -5*60*60)
+	req, err := parseExportRequest("~/out.csv NOTES since:01/03/2026 until:2026-03-31 tag:vip acme", loc, dateparse.OrderDayFirst)
+	if err != nil {
+		t.Fatalf("parseExportRequest: %v", err)
+	}
+	if req.path != "~/out.csv" || req.kind != "notes" || req.query != "tag:vip acme" {
+		t.Errorf("request = %+v, want notes of 'tag:vip acme' to ~/out.csv", req)
+	}
+	if want := time.Date(2026, 3, 1, 0, 0, 0, 0, loc); !req.filter.Since.Equal(want) {
+		t.Errorf("since = %v, want %v", req.filter.Since, want)
+	}
+	if want := time.Date(2026, 4, 1, 0, 0, 0, 0, loc); !req.filter.Until.Equal(want) {
+		t.Errorf("until = %v, want the end of 31 March, %v", req.filter.Until, want)
+	}
+
+	req, err = parseExportRequest("out.csv status:customer note:x", loc, dateparse.OrderAuto)
+	if err != nil || req.kind != "accounts" || req.query != "status:customer note:x" || !req.filter.Since.IsZero() {
+		t.Errorf("parseExportRequest(status filter) = %+v, %v", req, err)
+	}
+	for _, value := range []string{"", "   ", "out.csv since:someday", "out.csv to:13/13/2026"} {
+		if _, err := parseExportRequest(value, loc, dateparse.OrderDayFirst); err == nil {
+			t.Errorf("parseExportRequest(%q) gave no error", value)
+		}
+	}
+}
+
+func TestHandleExportAll(t *testing.T) {
+	m := newTestModel(t)
+	ctx := context.Background()
+	for _, name := range []string{"Acme", "Beta"} {
+		a := storage.Account{Name: name, Creator: "tester"}
+		if err := m.store.CreateAccount(ctx, &a); err != nil {
+			t.Fatalf("create account: %v", err)
+		}
+		if name == "Acme" {
+			if err := m.store.AddAccountTags(ctx, a.ID, "vip"); err != nil {
+				t.Fatalf("tag account: %v", err)
+			}
+		}
+	}
+	m.refreshAccounts()
+
+	dir := t.TempDir()
+	m.handleExport(filepath.Join(dir, "crm.csv") + " all tag:vip")
+	if m.errMessage != "" {
+		t.Fatalf("export failed: %s", m.errMessage)
+	}
+	if want := "Exported 1 account, 0 notes, 0 events"; !strings.HasPrefix(m.infoMessage, want) {
+		t.Errorf("info = %q, want it to start with %q", m.infoMessage, want)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "crm-accounts.csv"))
+	if err != nil {
+		t.Fatalf("read accounts export: %v", err)
+	}
+	if !strings.Contains(string(data), "Acme") || strings.Contains(string(data), "Beta") {
+		t.Errorf("accounts export = %q, want only Acme", data)
+	}
+	for _, kind := range []string{"notes", "events"} {
+		if _, err := os.Stat(filepath.Join(dir, "crm-"+kind+".csv")); err != nil {
+			t.Errorf("%s export missing: %v", kind, err)
+		}
+	}
+
+	m.handleExport(filepath.Join(dir, "missing", "crm.csv"))
+	if m.errMessage == "" {
+		t.Errorf("exporting into a missing directory gave no error")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "missing")); !os.IsNotExist(err) {
+		t.Errorf("failed export left %v behind", err)
+	}
+}
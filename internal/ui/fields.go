@@ -0,0 +1,322 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"crmterm/internal/dateparse"
+	"crmterm/internal/storage"
+)
+
+const (
+	fieldsPrompt        = "add <name>: <type>  rename <#> <name>  options <#> <a, b>  del <#>  /=Back"
+	fieldsConfirmPrompt = "Delete field and its values? (y/n)"
+)
+
+type fieldsModel struct {
+	fields []storage.CustomField
+	// pending is the field a y/n confirmation will delete.
+	pending *storage.CustomField
+	err     string
+}
+
+// withCustomFields appends a wizard step for every custom field, pre-filled
+// with the account's current values when editing.
+func (m *model) withCustomFields(form accountForm) accountForm {
+	ctx := context.Background()
+	fields, err := m.store.ListCustomFields(ctx)
+	if err != nil {
+		form.err = fmt.Sprintf("load custom fields: %v", err)
+		return form
+	}
+	values := map[int64]string{}
+	if form.editing {
+		if values, err = m.store.AccountFieldValues(ctx, form.original.ID); err != nil {
+			form.err = fmt.Sprintf("load custom fields: %v", err)
+			return form
+		}
+	}
+	for i := range fields {
+		field := fields[i]
+		form.fields = append(form.fields, formField{
+			label:  customFieldLabel(field),
+			value:  formatFieldValue(field, values[field.ID]),
+			custom: &field,
+		})
+	}
+	return form
+}
+
+// customFieldValues validates the custom steps of an account form and
+// returns their values keyed by field ID, including blanks so cleared
+// fields are removed. Dates are read relative to now in order.
+func customFieldValues(fields []formField, now time.Time, order dateparse.Order) (map[int64]string, error) {
+	values := map[int64]string{}
+	for _, f := range fields {
+		if f.custom == nil {
+			continue
+		}
+		value, err := f.custom.NormalizeAt(f.value, now, order)
+		if err != nil {
+			return nil, err
+		}
+		values[f.custom.ID] = value
+	}
+	return values, nil
+}
+
+func customFieldLabel(f storage.CustomField) string {
+	switch f.Type {
+	case storage.FieldNumber:
+		return f.Name + " (number)"
+	case storage.FieldDate:
+		return f.Name + " (" + dateHint + ")"
+	case storage.FieldBool:
+		return f.Name + " (y/n)"
+	case storage.FieldEnum:
+		return f.Name + " (" + strings.Join(f.Options, "/") + ")"
+	}
+	return f.Name
+}
+
+// formatFieldValue renders a stored value for display and editing.
+func formatFieldValue(f storage.CustomField, value string) string {
+	if f.Type == storage.FieldBool && value != "" {
+		if value == "true" {
+			return "yes"
+		}
+		return "no"
+	}
+	return value
+}
+
+func (m *model) openCustomFields() tea.Cmd {
+	m.fields = fieldsModel{}
+	m.refreshCustomFields()
+	m.pushState(stateCustomFields)
+	return m.setMenuInput(fieldsPrompt, 96)
+}
+
+func (m *model) refreshCustomFields() {
+	fields, err := m.store.ListCustomFields(context.Background())
+	if err != nil {
+		m.fields.err = fmt.Sprintf("load custom fields: %v", err)
+		return
+	}
+	m.fields.fields = fields
+}
+
+// CUSTOM FIELDS
+func (m *model) updateCustomFields(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+	placeholder := fieldsPrompt
+	if m.fields.pending != nil {
+		placeholder = fieldsConfirmPrompt
+	}
+	if focus := m.ensureMenuInput(placeholder, 96); focus != nil {
+		cmds = append(cmds, focus)
+	}
+	var cmd tea.Cmd
+	m.menuInput, cmd = m.menuInput.Update(msg)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+	key, ok := msg.(tea.KeyMsg)
+	if !ok || key.Type != tea.KeyEnter {
+		return batchCmds(cmds)
+	}
+	value := strings.TrimSpace(m.menuInput.Value())
+	m.menuInput.SetValue("")
+	if isExitCommand(value) {
+		m.prevStates = nil
+		m.state = stateMainMenu
+		if focus := m.setMenuInput("Choose an option", 32); focus != nil {
+			cmds = append(cmds, focus)
+		}
+		return batchCmds(cmds)
+	}
+
+	if m.fields.pending != nil {
+		switch strings.ToLower(value) {
+		case "y", "yes":
+			m.deletePendingField()
+		case "n", "no", "/", "back":
+			m.fields.pending = nil
+		default:
+			m.fields.err = "Please answer y or n"
+			return batchCmds(cmds)
+		}
+		if focus := m.setMenuInput(fieldsPrompt, 96); focus != nil {
+			cmds = append(cmds, focus)
+		}
+		return batchCmds(cmds)
+	}
+
+	m.fields.err = ""
+	if isBackCommand(value) {
+		m.popState()
+		if m.state == stateSettings {
+			if focus := m.setMenuInput(settingsPrompt, 64); focus != nil {
+				cmds = append(cmds, focus)
+			}
+		}
+		return batchCmds(cmds)
+	}
+	switch {
+	case value == "":
+	default:
+		if arg, ok := cutCommand(value, "add", "a"); ok {
+			m.addCustomField(arg)
+			break
+		}
+		if arg, ok := cutCommand(value, "rename"); ok {
+			num, name, _ := strings.Cut(arg, " ")
+			if field, ok := m.customField(num); ok {
+				field.Name = strings.TrimSpace(name)
+				m.saveCustomField(field, fmt.Sprintf("Renamed field to '%s'", field.Name))
+			}
+			break
+		}
+		if arg, ok := cutCommand(value, "options", "opts"); ok {
+			num, options, _ := strings.Cut(arg, " ")
+			if field, ok := m.customField(num); ok {
+				if field.Type != storage.FieldEnum {
+					m.fields.err = "Only enum fields have options"
+					break
+				}
+				field.Options = splitList(options, ",")
+				m.saveCustomField(field, fmt.Sprintf("Updated options for '%s'", field.Name))
+			}
+			break
+		}
+		if arg, ok := cutCommand(value, "delete", "del", "d"); ok {
+			if field, ok := m.customField(arg); ok {
+				m.fields.pending = &field
+			}
+			break
+		}
+		m.fields.err = "Try 'add Seats: number' or 'add Tier: enum Gold, Silver'"
+	}
+	if m.fields.pending != nil {
+		if focus := m.setMenuInput(fieldsConfirmPrompt, 96); focus != nil {
+			cmds = append(cmds, focus)
+		}
+	}
+	return batchCmds(cmds)
+}
+
+// addCustomField parses "<name>: <type> [options]", where enum options are
+// comma-separated.
+func (m *model) addCustomField(arg string) {
+	name, spec, ok := strings.Cut(arg, ":")
+	if !ok {
+		spec = "text"
+	}
+	typeName, options, _ := strings.Cut(strings.TrimSpace(spec), " ")
+	fieldType, err := storage.ParseFieldType(typeName)
+	if err != nil {
+		m.fields.err = fmt.Sprintf("Unknown type '%s'; use text, number, date, bool or enum", typeName)
+		return
+	}
+	field := storage.CustomField{Name: strings.TrimSpace(name), Type: fieldType}
+	if fieldType == storage.FieldEnum {
+		field.Options = splitList(options, ",")
+	}
+	if err := m.store.CreateCustomField(context.Background(), &field); err != nil {
+		if errors.Is(err, storage.ErrFieldExists) {
+			m.fields.err = "A field with that name already exists"
+			return
+		}
+		m.fields.err = err.Error()
+		return
+	}
+	m.infoMessage = fmt.Sprintf("Added %s field '%s'", field.Type, field.Name)
+	m.refreshCustomFields()
+}
+
+func (m *model) saveCustomField(field storage.CustomField, message string) {
+	if err := m.store.UpdateCustomField(context.Background(), &field); err != nil {
+		if errors.Is(err, storage.ErrFieldExists) {
+			m.fields.err = "A field with that name already exists"
+			return
+		}
+		m.fields.err = err.Error()
+		return
+	}
+	m.infoMessage = message
+	m.refreshCustomFields()
+}
+
+func (m *model) customField(arg string) (storage.CustomField, bool) {
+	idx, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(arg), "#"))
+	if err != nil || idx <= 0 || idx > len(m.fields.fields) {
+		m.fields.err = "Invalid field number"
+		return storage.CustomField{}, false
+	}
+	return m.fields.fields[idx-1], true
+}
+
+func (m *model) deletePendingField() {
+	field := m.fields.pending
+	m.fields.pending = nil
+	if err := m.store.DeleteCustomField(context.Background(), field.ID); err != nil {
+		m.fields.err = fmt.Sprintf("delete field: %v", err)
+		return
+	}
+	m.infoMessage = fmt.Sprintf("Deleted field '%s'", field.Name)
+	m.refreshCustomFields()
+}
+
+func (m *model) viewCustomFields() string {
+	lines := []string{m.theme.Title.Render("Custom Fields")}
+	lines = append(lines, m.theme.Faint.Render("Extra account fields, asked for in the account wizard and matched to CSV columns by name."))
+	lines = append(lines, m.theme.Faint.Render("Types: text, number, date, bool, enum. e.g. 'add Tier: enum Gold, Silver', 'rename 2 Seats', 'del 2'."))
+	lines = append(lines, "")
+	if len(m.fields.fields) == 0 {
+		lines = append(lines, m.theme.Faint.Render("No custom fields yet."))
+	}
+	for i, f := range m.fields.fields {
+		item := m.theme.Primary.Render(fmt.Sprintf("%d. %s", i+1, f.Name)) + m.theme.Faint.Render(" — "+string(f.Type))
+		if len(f.Options) > 0 {
+			item += m.theme.Faint.Render(": " + strings.Join(f.Options, ", "))
+		}
+		lines = append(lines, item)
+	}
+	lines = append(lines, "")
+	if m.fields.pending != nil {
+		lines = append(lines, m.theme.Warning.Render(fmt.Sprintf("Delete '%s' and every account's value for it?", m.fields.pending.Name)))
+	}
+	lines = append(lines, m.theme.Accent.Render("> ")+m.menuInput.View())
+	if m.fields.err != "" {
+		lines = append(lines, "", m.theme.Danger.Render(m.fields.err))
+	}
+	if m.infoMessage != "" {
+		lines = append(lines, "", m.theme.Success.Render(m.infoMessage))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// viewAccountCustomFields lists the custom field values set on the account
+// shown in the detail screen.
+func (m *model) viewAccountCustomFields() []string {
+	var lines []string
+	for _, f := range m.accountDetail.fields {
+		value, ok := m.accountDetail.fieldValues[f.ID]
+		if !ok {
+			continue
+		}
+		if f.Type == storage.FieldDate {
+			if t, ok := f.DateValue(value); ok {
+				value = t.Format("Jan 02 2006")
+			}
+		}
+		lines = append(lines, m.theme.Secondary.Render(fmt.Sprintf("%s: %s", f.Name, formatFieldValue(f, value))))
+	}
+	return lines
+}
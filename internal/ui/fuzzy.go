@@ -0,0 +1,249 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"crmterm/internal/storage"
+)
+
+// Scores follow fzf's v1 algorithm: every matched character earns a base
+// score, gaps cost a little, and matches at word boundaries or in runs earn
+// bonuses so "plc3" ranks Place3 above an account that merely contains
+// those letters somewhere.
+const (
+	fuzzyScoreMatch        = 16
+	fuzzyScoreGapStart     = -3
+	fuzzyScoreGapExtension = -1
+	fuzzyBonusBoundary     = 8
+	fuzzyBonusCamel        = 7
+	fuzzyBonusConsecutive  = 4
+	fuzzyBonusFirstChar    = 2
+)
+
+// fuzzyMatch reports whether pattern is a case-insensitive subsequence of
+// text, with its score and the rune positions of the matched characters.
+func fuzzyMatch(pattern, text string) (int, []int, bool) {
+	needle := []rune(strings.ToLower(pattern))
+	if len(needle) == 0 {
+		return 0, nil, true
+	}
+	original := []rune(text)
+	haystack := []rune(strings.ToLower(text))
+	if len(haystack) != len(original) {
+		haystack = make([]rune, len(original))
+		for i, r := range original {
+			haystack[i] = unicode.ToLower(r)
+		}
+	}
+
+	// Forward pass: find where the first full occurrence ends.
+	pi, end := 0, -1
+	for i, r := range haystack {
+		if r == needle[pi] {
+			pi++
+			if pi == len(needle) {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+	// Backward pass: tighten the window to the shortest occurrence.
+	pi, start := len(needle)-1, end
+	for i := end; i >= 0; i-- {
+		if haystack[i] == needle[pi] {
+			pi--
+			if pi < 0 {
+				start = i
+				break
+			}
+		}
+	}
+
+	positions := make([]int, 0, len(needle))
+	score, consecutive := 0, 0
+	inGap := false
+	pi = 0
+	for i := start; i <= end && pi < len(needle); i++ {
+		if haystack[i] != needle[pi] {
+			if inGap {
+				score += fuzzyScoreGapExtension
+			} else {
+				score += fuzzyScoreGapStart
+			}
+			inGap = true
+			consecutive = 0
+			continue
+		}
+		bonus := fuzzyBonusAt(original, i)
+		if consecutive > 0 && bonus < fuzzyBonusConsecutive {
+			bonus = fuzzyBonusConsecutive
+		}
+		if pi == 0 {
+			bonus *= fuzzyBonusFirstChar
+		}
+		score += fuzzyScoreMatch + bonus
+		positions = append(positions, i)
+		inGap = false
+		consecutive++
+		pi++
+	}
+	return score, positions, true
+}
+
+func fuzzyBonusAt(text []rune, i int) int {
+	if i == 0 {
+		return fuzzyBonusBoundary
+	}
+	prev, cur := text[i-1], text[i]
+	switch {
+	case !unicode.IsLetter(prev) && !unicode.IsDigit(prev):
+		return fuzzyBonusBoundary
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		return fuzzyBonusCamel
+	case unicode.IsLetter(prev) != unicode.IsLetter(cur) && unicode.IsDigit(cur):
+		return fuzzyBonusCamel
+	}
+	return 0
+}
+
+// accountHighlights holds the matched rune positions for each searchable
+// account field.
+type accountHighlights struct {
+	name          []int
+	decisionMaker []int
+	email         []int
+	phone         []int
+}
+
+type accountMatch struct {
+	account    storage.Account
+	score      int
+	highlights accountHighlights
+}
+
+// matchAccount scores every word of query against the account's name,
+// decision maker, email and phone; each word must match at least one field.
+func matchAccount(a storage.Account, words []string) (accountMatch, bool) {
+	match := accountMatch{account: a}
+	for _, word := range words {
+		fields := []struct {
+			text string
+			dest *[]int
+		}{
+			{a.Name, &match.highlights.name},
+			{a.DecisionMaker, &match.highlights.decisionMaker},
+			{a.Email, &match.highlights.email},
+			{a.Phone, &match.highlights.phone},
+		}
+		best, bestField := 0, -1
+		var bestPositions []int
+		for i, field := range fields {
+			score, positions, ok := fuzzyMatch(word, field.text)
+			if ok && (bestField < 0 || score > best) {
+				best, bestField, bestPositions = score, i, positions
+			}
+		}
+		if bestField < 0 {
+			return accountMatch{}, false
+		}
+		match.score += best
+		*fields[bestField].dest = append(*fields[bestField].dest, bestPositions...)
+	}
+	return match, true
+}
+
+// fuzzyAccounts returns the accounts matching query, best first. Ties keep
+// the incoming order so equal matches stay alphabetical. Tag and status
+// terms such as `tag:vip` or `-status:churned` filter the accounts before
+// fuzzy matching.
+func fuzzyAccounts(accounts []storage.Account, query string) []accountMatch {
+	statuses, rest := parseStatusFilter(query)
+	filter, rest := storage.ParseTagFilter(rest)
+	words := strings.Fields(rest)
+	matches := make([]accountMatch, 0, len(accounts))
+	for _, a := range accounts {
+		if !filter.Matches(a.Tags) || !statuses.matches(a.Status) {
+			continue
+		}
+		if match, ok := matchAccount(a, words); ok {
+			matches = append(matches, match)
+		}
+	}
+	if len(words) > 0 {
+		sort.SliceStable(matches, func(i, j int) bool {
+			return matches[i].score > matches[j].score
+		})
+	}
+	return matches
+}
+
+// bestFuzzyAccount returns the top match for query when it outscores every
+// other account, so an ambiguous query never picks one at random.
+func bestFuzzyAccount(accounts []storage.Account, query string) (storage.Account, bool) {
+	matches := fuzzyAccounts(accounts, query)
+	if len(matches) == 0 || (len(matches) > 1 && matches[0].score == matches[1].score) {
+		return storage.Account{}, false
+	}
+	return matches[0].account, true
+}
+
+// filterAccounts narrows m.accounts to the fuzzy matches for query,
+// remembering which characters matched so viewAccounts can highlight them.
+// Without free-text words the result is shown as a parent/subsidiary tree.
+func (m *model) filterAccounts(query string) []storage.Account {
+	matches := fuzzyAccounts(m.accounts, query)
+	accounts := make([]storage.Account, len(matches))
+	m.accountHighlights = make(map[int64]accountHighlights, len(matches))
+	for i, match := range matches {
+		accounts[i] = match.account
+		m.accountHighlights[match.account.ID] = match.highlights
+	}
+	m.accountDepth = nil
+	_, rest := parseStatusFilter(query)
+	if _, rest = storage.ParseTagFilter(rest); strings.TrimSpace(rest) == "" {
+		accounts, m.accountDepth = accountTree(accounts)
+	}
+	return accounts
+}
+
+// renderHighlighted renders text in base style with the runes at positions
+// picked out in the theme's highlight style.
+func (m *model) renderHighlighted(text string, positions []int, base lipgloss.Style) string {
+	if len(positions) == 0 {
+		return base.Render(text)
+	}
+	marked := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		marked[p] = true
+	}
+	var builder strings.Builder
+	var run []rune
+	runMarked := false
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if runMarked {
+			builder.WriteString(m.theme.Highlight.Render(string(run)))
+		} else {
+			builder.WriteString(base.Render(string(run)))
+		}
+		run = run[:0]
+	}
+	for i, r := range []rune(text) {
+		if marked[i] != runMarked {
+			flush()
+			runMarked = marked[i]
+		}
+		run = append(run, r)
+	}
+	flush()
+	return builder.String()
+}
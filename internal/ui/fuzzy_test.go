@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"crmterm/internal/storage"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern, text string
+		positions     []int
+		ok            bool
+	}{
+		{"", "Acme", nil, true},
+		{"plc3", "Place3", []int{0, 1, 3, 5}, true},
+		{"PLC", "place3", []int{0, 1, 3}, true},
+		{"acm", "The Acme Co", []int{4, 5, 6}, true},
+		{"ce", "Place Centre", []int{3, 4}, true},
+		{"xyz", "Acme", nil, false},
+		{"acmee", "Acme", nil, false},
+	}
+	for _, tt := range tests {
+		_, positions, ok := fuzzyMatch(tt.pattern, tt.text)
+		if ok != tt.ok || !reflect.DeepEqual(positions, tt.positions) {
+			t.Errorf("fuzzyMatch(%q, %q) = %v, %v; want %v, %v", tt.pattern, tt.text, positions, ok, tt.positions, tt.ok)
+		}
+	}
+}
+
+func TestFuzzyMatchRanksBoundariesAndRuns(t *testing.T) {
+	better := []struct{ pattern, a, b string }{
+		{"plc3", "Place3", "Apple Co 3"},
+		{"acme", "Acme", "A Cheap Meal"},
+		{"ac", "Acme", "Bianca"},
+		{"gm", "GreenMart", "Big Farm"},
+	}
+	for _, tt := range better {
+		sa, _, okA := fuzzyMatch(tt.pattern, tt.a)
+		sb, _, okB := fuzzyMatch(tt.pattern, tt.b)
+		if !okA || !okB || sa <= sb {
+			t.Errorf("%q scores %d in %q and %d in %q, want the first higher", tt.pattern, sa, tt.a, sb, tt.b)
+		}
+	}
+}
+
+func TestFuzzyAccounts(t *testing.T) {
+	accounts := []storage.Account{
+		{ID: 1, Name: "Apple Co 3"},
+		{ID: 2, Name: "Beta", Email: "sales@beta.test"},
+		{ID: 3, Name: "Place3", Phone: "+15555550100"},
+		{ID: 4, Name: "Place4"},
+	}
+	ids := func(matches []accountMatch) []int64 {
+		var out []int64
+		for _, m := range matches {
+			out = append(out, m.account.ID)
+		}
+		return out
+	}
+	tests := []struct {
+		query string
+		want  []int64
+	}{
+		{"", []int64{1, 2, 3, 4}},
+		{"plc3", []int64{3, 1}},
+		{"sales", []int64{2}},
+		{"place 0100", []int64{3}},
+		{"zzz", nil},
+	}
+	for _, tt := range tests {
+		if got := ids(fuzzyAccounts(accounts, tt.query)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("fuzzyAccounts(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	matches := fuzzyAccounts(accounts, "place 0100")
+	if hl := matches[0].highlights; len(hl.name) != 5 || len(hl.phone) != 4 {
+		t.Errorf("highlights = %+v, want the name and phone matches", hl)
+	}
+	if a, ok := bestFuzzyAccount(accounts, "plc3"); !ok || a.ID != 3 {
+		t.Errorf("bestFuzzyAccount(plc3) = %v, %v; want Place3", a.Name, ok)
+	}
+	if _, ok := bestFuzzyAccount(accounts, "place"); ok {
+		t.Errorf("bestFuzzyAccount picked one of two equal matches for 'place'")
+	}
+}
@@ -0,0 +1,148 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"crmterm/internal/storage"
+)
+
+// resolveParentAccount finds the account named by value for the parent
+// step, trying an exact name before a unique fuzzy match. A blank value
+// means no parent.
+func (m *model) resolveParentAccount(value string) (*storage.Account, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	for i := range m.accounts {
+		if strings.EqualFold(m.accounts[i].Name, value) {
+			return &m.accounts[i], nil
+		}
+	}
+	if account, ok := bestFuzzyAccount(m.accounts, value); ok {
+		return &account, nil
+	}
+	return nil, fmt.Errorf("No single account matches '%s'", value)
+}
+
+// accountTree orders accounts so each one is followed by its subsidiaries,
+// returning the nesting depth of every account. Accounts whose parent is
+// not in the list are treated as top level, and siblings keep their
+// incoming order.
+func accountTree(accounts []storage.Account) ([]storage.Account, map[int64]int) {
+	present := make(map[int64]bool, len(accounts))
+	for _, a := range accounts {
+		present[a.ID] = true
+	}
+	children := map[int64][]storage.Account{}
+	var roots []storage.Account
+	for _, a := range accounts {
+		if a.ParentID.Valid && present[a.ParentID.Int64] {
+			children[a.ParentID.Int64] = append(children[a.ParentID.Int64], a)
+			continue
+		}
+		roots = append(roots, a)
+	}
+	ordered := make([]storage.Account, 0, len(accounts))
+	depth := make(map[int64]int, len(accounts))
+	var visit func(a storage.Account, level int)
+	visit = func(a storage.Account, level int) {
+		if _, seen := depth[a.ID]; seen {
+			return
+		}
+		depth[a.ID] = level
+		ordered = append(ordered, a)
+		for _, child := range children[a.ID] {
+			visit(child, level+1)
+		}
+	}
+	for _, a := range roots {
+		visit(a, 0)
+	}
+	return ordered, depth
+}
+
+// accountHierarchyCommand handles the parent/subsidiary commands typed on
+// the account screen: "sub 2" opens subsidiary #2, "parent" opens the
+// parent account and "rollup" toggles including subsidiaries' activity.
+func (m *model) accountHierarchyCommand(input string) (tea.Cmd, bool) {
+	switch input {
+	case "parent", "up":
+		account := m.accountDetail.account
+		if !account.ParentID.Valid || account.ParentName == "" {
+			m.accountDetail.err = "This account has no parent"
+			return nil, true
+		}
+		m.showAccountInDetail(account.ParentID.Int64)
+		return nil, true
+	case "rollup", "roll":
+		m.accountDetail.rollup = !m.accountDetail.rollup
+		m.accountDetail.view = accountDetailActivity
+		m.loadAccountActivity()
+		return nil, true
+	}
+	arg, ok := cutCommand(input, "sub", "subsidiary")
+	if !ok {
+		return nil, false
+	}
+	idx, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+	if err != nil || idx <= 0 || idx > len(m.accountDetail.children) {
+		m.accountDetail.err = "Invalid subsidiary number"
+		return nil, true
+	}
+	m.showAccountInDetail(m.accountDetail.children[idx-1].ID)
+	return nil, true
+}
+
+// showAccountInDetail switches the account screen to another account in
+// place, so '/' still returns to wherever the screen was opened from.
+func (m *model) showAccountInDetail(id int64) {
+	m.accountDetail.account = storage.Account{ID: id}
+	m.accountDetail.view = accountDetailSummary
+	m.accountDetail.activity = nil
+	m.accountDetail.err = ""
+	m.refreshAccountDetailAccount()
+}
+
+// viewAccountSubsidiaries lists the accounts directly under the one shown
+// in the detail screen.
+func (m *model) viewAccountSubsidiaries() []string {
+	if len(m.accountDetail.children) == 0 {
+		return nil
+	}
+	lines := []string{m.theme.Subtitle.Render("Subsidiaries")}
+	for i, child := range m.accountDetail.children {
+		item := m.theme.Primary.Render(fmt.Sprintf("%d. %s", i+1, child.Name))
+		if child.Status != "" {
+			item += "  " + m.renderStatusBadge(child.Status)
+		}
+		lines = append(lines, item)
+	}
+	lines = append(lines, m.theme.Faint.Render("'sub 2' opens subsidiary #2, 'rollup' includes their activity."), "")
+	return lines
+}
+
+// loadAccountChildren refreshes the subsidiaries of the detail account.
+func (m *model) loadAccountChildren() error {
+	children, err := m.store.ListChildAccounts(context.Background(), m.accountDetail.account.ID)
+	if err != nil {
+		return err
+	}
+	m.accountDetail.children = children
+	return nil
+}
+
+// accountName looks up an account's name in the loaded account list.
+func (m *model) accountName(id int64) string {
+	for _, a := range m.accounts {
+		if a.ID == id {
+			return a.Name
+		}
+	}
+	return fmt.Sprintf("account #%d", id)
+}
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"database/sql"
+	"testing"
+
+	"crmterm/internal/storage"
+)
+
+func TestAccountTree(t *testing.T) {
+	under := func(id int64) sql.NullInt64 { return sql.NullInt64{Int64: id, Valid: true} }
+	accounts := []storage.Account{
+		{ID: 4, Name: "Boston", ParentID: under(2)},
+		{ID: 1, Name: "Group"},
+		{ID: 2, Name: "East", ParentID: under(1)},
+		{ID: 5, Name: "Orphan", ParentID: under(9)},
+		{ID: 3, Name: "Atlanta", ParentID: under(1)},
+	}
+	ordered, depth := accountTree(accounts)
+	want := []struct {
+		name  string
+		depth int
+	}{
+		{"Group", 0}, {"East", 1}, {"Boston", 2}, {"Atlanta", 1}, {"Orphan", 0},
+	}
+	if len(ordered) != len(want) {
+		t.Fatalf("accountTree returned %d accounts, want %d", len(ordered), len(want))
+	}
+	for i, w := range want {
+		if ordered[i].Name != w.name || depth[ordered[i].ID] != w.depth {
+			t.Errorf("row %d = %s at depth %d, want %s at depth %d", i, ordered[i].Name, depth[ordered[i].ID], w.name, w.depth)
+		}
+	}
+}
+
+func TestResolveParentAccount(t *testing.T) {
+	m := &model{accounts: []storage.Account{
+		{ID: 1, Name: "Acme"},
+		{ID: 2, Name: "Acme East"},
+		{ID: 3, Name: "Globex"},
+	}}
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{in: "", want: 0},
+		{in: "acme", want: 1},
+		{in: "ACME EAST", want: 2},
+		{in: "glbx", want: 3},
+		{in: "initech", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := m.resolveParentAccount(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("resolveParentAccount(%q) = %+v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("resolveParentAccount(%q): %v", tt.in, err)
+			continue
+		}
+		var id int64
+		if got != nil {
+			id = got.ID
+		}
+		if id != tt.want {
+			t.Errorf("resolveParentAccount(%q) = account %d, want %d", tt.in, id, tt.want)
+		}
+	}
+}
@@ -0,0 +1,468 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"crmterm/internal/storage"
+)
+
+const importPrompt = "y=Import  map <#> <column>  mode skip|fill|overwrite|copy  match name|email  strict  problems  /=Back"
+
+// importStrategyLabels describes what each import strategy does to rows
+// that match an existing account.
+var importStrategyLabels = map[string]string{
+	storage.ImportSkipExisting: "skip the row",
+	storage.ImportFillBlanks:   "fill in blank fields",
+	storage.ImportOverwrite:    "overwrite with the CSV values",
+	storage.ImportCopy:         "create a suffixed copy",
+}
+
+// importTargetLabels names the built-in import targets for the preview.
+var importTargetLabels = map[string]string{
+	"name":           "Account name",
+	"phone":          "Phone",
+	"address":        "Address",
+	"email":          "Email",
+	"decision_maker": "Decision maker",
+	"tags":           "Tags",
+	"status":         "Status",
+	"creator":        "Creator",
+	"created_at":     "Created at",
+	"note":           "Note",
+}
+
+// importModel is a CSV import that has been dry run and waits for the user
+// to check the column mapping and confirm.
+type importModel struct {
+	path string
+	// data is the file as read when the preview opened, so the import
+	// that is confirmed is the one that was previewed.
+	data   []byte
+	fields []storage.CustomField
+	// columns holds the targets the user remapped, by column index.
+	columns map[int]string
+	// strategy and matchBy decide what happens to rows that match an
+	// existing account; see storage.ImportOptions.
+	strategy string
+	matchBy  string
+	// strict skips rows with an invalid phone number or email address.
+	strict bool
+	result storage.ImportResult
+	// problemsOnly hides the rows that import cleanly.
+	problemsOnly bool
+	offset       int
+	err          string
+}
+
+// openImportPreview reads a CSV file and dry runs it, leaving the user on
+// a preview of what importing it would do.
+func (m *model) openImportPreview(path string) tea.Cmd {
+	m.infoMessage = ""
+	trimmed := strings.TrimSpace(path)
+	if trimmed == "" {
+		m.errMessage = "Provide a CSV path"
+		return nil
+	}
+	resolved, err := expandPath(trimmed)
+	if err != nil {
+		m.errMessage = fmt.Sprintf("import path: %v", err)
+		return nil
+	}
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		m.errMessage = fmt.Sprintf("open file: %v", err)
+		return nil
+	}
+	fields, err := m.store.ListCustomFields(context.Background())
+	if err != nil {
+		m.errMessage = fmt.Sprintf("load custom fields: %v", err)
+		return nil
+	}
+	m.errMessage = ""
+	m.importer = importModel{
+		path:     resolved,
+		data:     data,
+		fields:   fields,
+		columns:  map[int]string{},
+		strategy: storage.ImportSkipExisting,
+		matchBy:  storage.ImportMatchName,
+	}
+	if !m.dryRunImport() && len(m.importer.result.Columns) == 0 {
+		// Not even a header could be read; there is nothing to preview.
+		m.errMessage = m.importer.err
+		return nil
+	}
+	m.pushState(stateImportPreview)
+	return m.setMenuInput(importPrompt, 64)
+}
+
+func (m *model) importOptions(dryRun bool) storage.ImportOptions {
+	return storage.ImportOptions{
+		Creator:       m.cfg.Config.Name,
+		Location:      m.cfg.Location(),
+		DryRun:        dryRun,
+		Columns:       m.importer.columns,
+		Strategy:      m.importer.strategy,
+		MatchBy:       m.importer.matchBy,
+		PhoneCountry:  m.cfg.PhoneCountry(),
+		DateOrder:     m.cfg.DateOrder(),
+		RejectInvalid: m.importer.strict,
+	}
+}
+
+// dryRunImport refreshes the preview and reports whether the file can be
+// imported as mapped.
+func (m *model) dryRunImport() bool {
+	result, err := m.store.ImportAccountsCSV(context.Background(), bytes.NewReader(m.importer.data), m.importOptions(true))
+	m.importer.result = result
+	m.importer.err = ""
+	switch {
+	case errors.Is(err, storage.ErrImportNoName):
+		m.importer.err = "No column holds the account name; map one with 'map <#> name'"
+		return false
+	case err != nil:
+		m.importer.err = fmt.Sprintf("import csv: %v", err)
+		return false
+	}
+	m.clampImportOffset()
+	return true
+}
+
+// importRows returns the rows the preview lists.
+func (m *model) importRows() []storage.ImportRow {
+	if !m.importer.problemsOnly {
+		return m.importer.result.Rows
+	}
+	var rows []storage.ImportRow
+	for _, row := range m.importer.result.Rows {
+		if len(row.Problems) > 0 {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// importPageSize is how many rows fit under the column mapping.
+func (m *model) importPageSize() int {
+	size := m.height - len(m.importer.result.Columns) - 21
+	if size < 5 {
+		size = 5
+	}
+	return size
+}
+
+func (m *model) clampImportOffset() {
+	last := len(m.importRows()) - m.importPageSize()
+	if m.importer.offset > last {
+		m.importer.offset = last
+	}
+	if m.importer.offset < 0 {
+		m.importer.offset = 0
+	}
+}
+
+// commitImport runs the previewed import for real, in one transaction.
+func (m *model) commitImport() {
+	ctx := context.Background()
+	result, err := m.store.ImportAccountsCSV(ctx, bytes.NewReader(m.importer.data), m.importOptions(false))
+	if err != nil {
+		m.importer.err = fmt.Sprintf("import csv: %v", err)
+		return
+	}
+	parts := []string{fmt.Sprintf("Imported %d account(s)", result.Created)}
+	if result.Updated > 0 {
+		parts = append(parts, fmt.Sprintf("updated %d", result.Updated))
+	}
+	if result.Unchanged > 0 {
+		parts = append(parts, fmt.Sprintf("%d unchanged", result.Unchanged))
+	}
+	if result.Notes > 0 {
+		parts = append(parts, fmt.Sprintf("added %d note(s)", result.Notes))
+	}
+	if result.Skipped > 0 {
+		parts = append(parts, fmt.Sprintf("skipped %d", result.Skipped))
+	}
+	m.infoMessage = strings.Join(parts, ", ") + " from " + filepath.Base(m.importer.path)
+	m.errMessage = ""
+	m.importer = importModel{}
+	m.refreshAccounts()
+	m.refreshDashboard(time.Now().In(m.cfg.Location()))
+}
+
+// closeImportPreview returns to the screen the import was started from.
+func (m *model) closeImportPreview() tea.Cmd {
+	m.popState()
+	switch m.state {
+	case stateSettings:
+		return m.setMenuInput(settingsPrompt, 64)
+	case stateMainMenu:
+		return m.setMenuInput("Choose an option", 32)
+	}
+	return nil
+}
+
+// mapImportColumn handles `map <#> <column>`.
+func (m *model) mapImportColumn(arg string) {
+	num, name, _ := strings.Cut(strings.TrimSpace(arg), " ")
+	idx, err := strconv.Atoi(strings.TrimPrefix(num, "#"))
+	if err != nil || idx <= 0 || idx > len(m.importer.result.Columns) {
+		m.importer.err = "Invalid column number"
+		return
+	}
+	name = strings.TrimSpace(name)
+	var target string
+	switch strings.ToLower(name) {
+	case "":
+		m.importer.err = "Say what the column holds, e.g. 'map 3 phone', or 'map 3 skip'"
+		return
+	case "skip", "ignore", "none", "-":
+	default:
+		if target = storage.ImportTarget(name, m.importer.fields); target == "" {
+			m.importer.err = fmt.Sprintf("Unknown column '%s'", name)
+			return
+		}
+	}
+	m.importer.columns[idx-1] = target
+	m.dryRunImport()
+}
+
+// setImportStrategy handles `mode <strategy>`.
+func (m *model) setImportStrategy(arg string) {
+	strategy := strings.ToLower(strings.TrimSpace(arg))
+	switch strategy {
+	case "update", "blanks":
+		strategy = storage.ImportFillBlanks
+	case "replace":
+		strategy = storage.ImportOverwrite
+	case "suffix":
+		strategy = storage.ImportCopy
+	}
+	if _, ok := importStrategyLabels[strategy]; !ok {
+		m.importer.err = "Modes are " + strings.Join(storage.ImportStrategies, ", ")
+		return
+	}
+	m.importer.strategy = strategy
+	m.dryRunImport()
+}
+
+// setImportMatch handles `match name|email`.
+func (m *model) setImportMatch(arg string) {
+	switch key := strings.ToLower(strings.TrimSpace(arg)); key {
+	case storage.ImportMatchName, storage.ImportMatchEmail:
+		m.importer.matchBy = key
+		m.dryRunImport()
+	default:
+		m.importer.err = "Match existing accounts by 'name' or 'email'"
+	}
+}
+
+// IMPORT PREVIEW
+func (m *model) updateImportPreview(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+	if focus := m.ensureMenuInput(importPrompt, 64); focus != nil {
+		cmds = append(cmds, focus)
+	}
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.Type {
+		case tea.KeyUp:
+			m.importer.offset--
+			m.clampImportOffset()
+			return batchCmds(cmds)
+		case tea.KeyDown:
+			m.importer.offset++
+			m.clampImportOffset()
+			return batchCmds(cmds)
+		case tea.KeyPgUp:
+			m.importer.offset -= m.importPageSize()
+			m.clampImportOffset()
+			return batchCmds(cmds)
+		case tea.KeyPgDown:
+			m.importer.offset += m.importPageSize()
+			m.clampImportOffset()
+			return batchCmds(cmds)
+		case tea.KeyEsc:
+			return m.closeImportPreview()
+		}
+	}
+	var cmd tea.Cmd
+	m.menuInput, cmd = m.menuInput.Update(msg)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+	key, ok := msg.(tea.KeyMsg)
+	if !ok || key.Type != tea.KeyEnter {
+		return batchCmds(cmds)
+	}
+	value := strings.TrimSpace(m.menuInput.Value())
+	m.menuInput.SetValue("")
+	lower := strings.ToLower(value)
+	if isExitCommand(lower) {
+		m.importer = importModel{}
+		m.prevStates = nil
+		m.state = stateMainMenu
+		return m.setMenuInput("Choose an option", 32)
+	}
+	if isBackCommand(lower) {
+		m.importer = importModel{}
+		return m.closeImportPreview()
+	}
+	m.importer.err = ""
+	switch lower {
+	case "":
+	case "y", "yes", "import", "confirm":
+		if !m.dryRunImport() {
+			break
+		}
+		if res := m.importer.result; res.Created+res.Updated == 0 {
+			m.importer.err = "Nothing to import; no row would create or update an account"
+			break
+		}
+		m.commitImport()
+		if m.importer.err == "" {
+			return m.closeImportPreview()
+		}
+	case "problems", "p", "errors":
+		m.importer.problemsOnly = !m.importer.problemsOnly
+		m.importer.offset = 0
+	case "all":
+		m.importer.problemsOnly = false
+	case "strict", "reject":
+		m.importer.strict = !m.importer.strict
+		m.dryRunImport()
+	default:
+		if arg, ok := cutCommand(value, "map"); ok {
+			m.mapImportColumn(arg)
+			break
+		}
+		if arg, ok := cutCommand(value, "mode", "strategy"); ok {
+			m.setImportStrategy(arg)
+			break
+		}
+		if arg, ok := cutCommand(value, "match"); ok {
+			m.setImportMatch(arg)
+			break
+		}
+		m.importer.err = "Use 'y' to import, 'map 3 phone' to remap column 3, 'mode fill' to choose what happens to existing accounts, or 'problems' to list only rows with problems"
+	}
+	return batchCmds(cmds)
+}
+
+func (m *model) viewImportPreview() string {
+	imp := m.importer
+	lines := []string{m.theme.Title.Render("Import Preview — " + filepath.Base(imp.path))}
+	lines = append(lines, m.theme.Faint.Render("Nothing is saved until you confirm with 'y'. 'map 3 phone' reads column 3 as phone numbers ('map 3 skip' ignores it); ↑/↓ and PgUp/PgDn scroll the rows."))
+	lines = append(lines, "")
+	lines = append(lines, m.theme.Highlight.Render("Columns"))
+	for _, c := range imp.result.Columns {
+		label := m.theme.Primary.Render(fmt.Sprintf("%d. ", c.Index+1)) + c.Header + m.theme.Faint.Render(" → ")
+		if c.Target == "" {
+			label += m.theme.Warning.Render("ignored")
+		} else {
+			label += m.theme.Success.Render(m.importTargetLabel(c.Target))
+		}
+		if _, remapped := imp.columns[c.Index]; remapped {
+			label += m.theme.Faint.Render(" (mapped)")
+		}
+		lines = append(lines, "  "+label)
+	}
+	lines = append(lines, m.theme.Faint.Render("  Columns: name, phone, address, email, dm, tags, status, creator, created, note or a custom field name."))
+	lines = append(lines, "")
+	lines = append(lines, m.theme.Highlight.Render("Existing accounts")+
+		fmt.Sprintf(" matched by %s: %s", imp.matchBy, importStrategyLabels[imp.strategy]))
+	lines = append(lines, m.theme.Faint.Render("  'mode skip|fill|overwrite|copy' changes this; 'match email' matches on email before name."))
+	invalid := "imported as written, with a warning"
+	if imp.strict {
+		invalid = "row skipped"
+	}
+	lines = append(lines, m.theme.Highlight.Render("Invalid phones and emails")+": "+invalid)
+	lines = append(lines, m.theme.Faint.Render(fmt.Sprintf("  'strict' toggles this. Phone numbers without a country code are read as %s numbers.", m.cfg.PhoneCountry())))
+	lines = append(lines, "")
+
+	if imp.err == "" {
+		res := imp.result
+		problems := 0
+		for _, row := range res.Rows {
+			if len(row.Problems) > 0 {
+				problems++
+			}
+		}
+		summary := fmt.Sprintf("Would create %s", pluralize(res.Created, "account"))
+		if res.Updated > 0 {
+			summary += fmt.Sprintf(", update %d", res.Updated)
+		}
+		if res.Notes > 0 {
+			summary += fmt.Sprintf(", add %s", pluralize(res.Notes, "note"))
+		}
+		if res.Unchanged > 0 {
+			summary += fmt.Sprintf(", leave %d unchanged", res.Unchanged)
+		}
+		if res.Skipped > 0 {
+			summary += fmt.Sprintf(", skip %s", pluralize(res.Skipped, "row"))
+		}
+		lines = append(lines, m.theme.Highlight.Render(summary)+m.theme.Faint.Render(fmt.Sprintf("  (%s with problems)", pluralize(problems, "row"))))
+
+		rows := m.importRows()
+		if len(rows) == 0 {
+			lines = append(lines, m.theme.Faint.Render("No rows to show."))
+		}
+		end := imp.offset + m.importPageSize()
+		if end > len(rows) {
+			end = len(rows)
+		}
+		for _, row := range rows[imp.offset:end] {
+			lines = append(lines, m.formatImportRow(row)...)
+		}
+		if len(rows) > 0 {
+			lines = append(lines, m.theme.Faint.Render(fmt.Sprintf("Rows %d–%d of %d", imp.offset+1, end, len(rows))))
+		}
+		lines = append(lines, "")
+	}
+	lines = append(lines, m.theme.Accent.Render("> ")+m.menuInput.View())
+	if imp.err != "" {
+		lines = append(lines, "", m.theme.Danger.Render(imp.err))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func (m *model) importTargetLabel(target string) string {
+	if label, ok := importTargetLabels[target]; ok {
+		return label
+	}
+	if f, ok := storage.ImportTargetField(target, m.importer.fields); ok {
+		return f.Name + " (custom field)"
+	}
+	return target
+}
+
+func (m *model) formatImportRow(row storage.ImportRow) []string {
+	name := row.Name
+	if name == "" {
+		name = "(no name)"
+	}
+	line := m.theme.Faint.Render(fmt.Sprintf("line %-4d ", row.Line))
+	switch row.Action {
+	case storage.ImportCreated:
+		line += m.theme.Success.Render("✓ create ") + name
+	case storage.ImportUpdated:
+		line += m.theme.Primary.Render("~ update ") + name + m.theme.Faint.Render(" ("+strings.Join(row.Changed, ", ")+")")
+	case storage.ImportUnchanged:
+		line += m.theme.Faint.Render("= same   ") + name
+	default:
+		line += m.theme.Danger.Render("✗ skip   ") + name
+	}
+	lines := []string{line}
+	for _, problem := range row.Problems {
+		lines = append(lines, "            "+m.theme.Warning.Render("! "+problem))
+	}
+	return lines
+}
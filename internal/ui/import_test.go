@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"crmterm/internal/storage"
+)
+
+func TestImportPreviewMapsAndCommits(t *testing.T) {
+	m := newTestModel(t)
+	ctx := context.Background()
+	beta := storage.Account{Name: "Beta", Creator: "tester"}
+	if err := m.store.CreateAccount(ctx, &beta); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "leads.csv")
+	csv := "Company,Tel,Colour\nAcme,+1 555 555 0100,blue\nBeta,+1 555 555 0199,red\n"
+	if err := os.WriteFile(path, []byte(csv), 0o600); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+
+	m.openImportPreview(path)
+	if m.state != stateImportPreview {
+		t.Fatalf("state = %v, want the import preview: %s", m.state, m.errMessage)
+	}
+	if !strings.Contains(m.importer.err, "map <#> name") {
+		t.Errorf("preview error = %q, want a hint to map the name column", m.importer.err)
+	}
+	typeLine(m, "y")
+	if accounts, _ := m.store.ListAccounts(ctx); len(accounts) != 1 {
+		t.Fatalf("confirming an unmapped import wrote accounts: %+v", accounts)
+	}
+
+	for _, tt := range []struct {
+		command string
+		wantErr bool
+	}{
+		{"map 9 name", true},
+		{"map 1", true},
+		{"map 3 favourite", true},
+		{"map 1 name", false},
+		{"map #2 phone", false},
+		{"map 3 skip", false},
+		{"mode sometimes", true},
+		{"mode replace", false},
+		{"match phone", true},
+	} {
+		typeLine(m, tt.command)
+		if got := m.importer.err != ""; got != tt.wantErr {
+			t.Errorf("%q: error = %q, want an error: %v", tt.command, m.importer.err, tt.wantErr)
+		}
+	}
+	if m.importer.strategy != storage.ImportOverwrite {
+		t.Errorf("strategy = %q, want %q", m.importer.strategy, storage.ImportOverwrite)
+	}
+	if res := m.importer.result; res.Created != 1 || res.Updated != 1 {
+		t.Errorf("preview creates %d and updates %d, want 1 and 1", res.Created, res.Updated)
+	}
+	if accounts, _ := m.store.ListAccounts(ctx); len(accounts) != 1 {
+		t.Fatalf("the preview wrote accounts: %+v", accounts)
+	}
+
+	typeLine(m, "y")
+	if m.state == stateImportPreview {
+		t.Fatalf("still on the preview after confirming: %s", m.importer.err)
+	}
+	if !strings.HasPrefix(m.infoMessage, "Imported 1 account(s), updated 1") {
+		t.Errorf("info = %q, want one account imported and one updated", m.infoMessage)
+	}
+	for name, phone := range map[string]string{"Acme": "+15555550100", "Beta": "+15555550199"} {
+		a, err := m.store.AccountByName(ctx, name)
+		if err != nil {
+			t.Fatalf("find %s: %v", name, err)
+		}
+		if a.Phone != phone {
+			t.Errorf("%s phone = %q, want %q", name, a.Phone, phone)
+		}
+	}
+}
+
+func TestOpenImportPreviewErrors(t *testing.T) {
+	m := newTestModel(t)
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.csv")
+	if err := os.WriteFile(empty, nil, 0o600); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+	for _, path := range []string{"  ", filepath.Join(dir, "missing.csv"), empty} {
+		m.errMessage = ""
+		m.openImportPreview(path)
+		if m.errMessage == "" || m.state == stateImportPreview {
+			t.Errorf("openImportPreview(%q) opened the preview, want an error", path)
+		}
+	}
+}
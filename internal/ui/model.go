@@ -15,8 +15,10 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 
 	"crmterm/internal/config"
+	"crmterm/internal/dateparse"
 	"crmterm/internal/storage"
 	"crmterm/internal/theme"
+	"crmterm/internal/validate"
 )
 
 // Program wraps the Bubble Tea program lifecycle.
@@ -53,10 +55,23 @@ const (
 	stateDashboard
 	stateAccounts
 	stateAccountDetail
+	stateActivityEntry
 	stateCreateAccount
 	stateCreateChoice
 	stateCreateNote
 	stateCreateEvent
+	stateCreateTask
+	stateCreateContact
+	stateDeals
+	stateCreateDeal
+	statePipelineBoard
+	stateCalendar
+	stateTrash
+	stateSearch
+	stateCustomFields
+	stateDuplicates
+	stateImportPreview
+	stateAudit
 	stateSettings
 	stateSettingsEditName
 	stateSettingsEditTimezone
@@ -74,6 +89,13 @@ const (
 	settingsEditingName
 	settingsEditingTimezone
 	settingsImportPath
+	settingsEditingStages
+	settingsEditingRetention
+	settingsEditingStatuses
+	settingsExportPath
+	settingsBackup
+	settingsEditingCountry
+	settingsEditingDateOrder
 )
 
 const (
@@ -86,8 +108,12 @@ const (
 	eventStageTitle eventStage = iota
 	eventStageDetails
 	eventStageSchedule
+	eventStageDuration
+	eventStageLocation
+	eventStageRepeat
 	eventStageAssociatePrompt
 	eventStageAssociateChoose
+	eventStageAttendees
 )
 
 type accountDetailView int
@@ -95,6 +121,7 @@ type accountDetailView int
 const (
 	accountDetailSummary accountDetailView = iota
 	accountDetailActivity
+	accountDetailHistory
 )
 
 type debugMode int
@@ -123,26 +150,61 @@ type model struct {
 	accounts         []storage.Account
 	accountFilter    textinput.Model
 	filteredAccounts []storage.Account
+	// accountHighlights maps account IDs to the characters the current
+	// filter matched.
+	accountHighlights map[int64]accountHighlights
+	// accountDepth maps account IDs to their nesting level when the list
+	// is shown as a parent/subsidiary tree; nil for ranked search results.
+	accountDepth map[int64]int
 
 	accountForm accountForm
 
 	noteWizard  noteWizard
 	eventWizard eventWizard
+	taskWizard  taskWizard
+
+	contactForm contactForm
+
+	deals    dealsModel
+	dealForm dealForm
+	board    boardModel
 
 	dashboard dashboardModel
+	calendar  calendarModel
+
+	trash trashModel
+
+	search searchModel
+
+	fields fieldsModel
+
+	duplicates duplicatesModel
+
+	importer importModel
+
+	audit auditModel
 
 	settings settingsModel
 
 	accountDetail accountDetailModel
+	entry         activityEntryModel
 
 	debug debugModel
+
+	undoStack []undoEntry
+	// undoStatus reports the last Ctrl+Z until the next key press.
+	undoStatus string
 }
 
 type accountForm struct {
-	index    int
-	fields   []formField
-	input    textinput.Model
-	err      string
+	index  int
+	fields []formField
+	input  textinput.Model
+	err    string
+	// warning explains why the value in warned looks invalid; entering
+	// the same value again keeps it anyway.
+	warning  string
+	warned   string
 	editing  bool
 	original storage.Account
 }
@@ -151,6 +213,8 @@ type formField struct {
 	label    string
 	value    string
 	required bool
+	// custom is set for steps that fill a user-defined account field.
+	custom *storage.CustomField
 }
 
 type noteWizard struct {
@@ -161,6 +225,7 @@ type noteWizard struct {
 	associate      bool
 	err            string
 	presetAccount  *storage.Account
+	editing        *storage.Note
 }
 
 type eventWizard struct {
@@ -168,17 +233,37 @@ type eventWizard struct {
 	titleInput     textinput.Model
 	detailsInput   textinput.Model
 	scheduleInput  textinput.Model
+	durationInput  textinput.Model
+	locationInput  textinput.Model
+	repeatInput    textinput.Model
 	associateInput textinput.Model
 	accountInput   textinput.Model
+	attendeesInput textinput.Model
 	associate      bool
 	err            string
 	presetAccount  *storage.Account
+	editing        *storage.Event
+	// accountID, message and contacts are kept while the attendees step
+	// asks who from the chosen account takes part.
+	accountID     *sql.NullInt64
+	message       string
+	contacts      []storage.Contact
+	attendees     []int64
+	attendeesBack eventStage
+	// warning notes events the saved one overlaps.
+	warning string
+	// occurrence is the single occurrence of editing's series being
+	// changed; the wizard then skips the repeat and account steps.
+	occurrence *storage.Event
 }
 
 type dashboardModel struct {
 	view     dashboardView
 	events   []storage.Event
+	tasks    []storage.Task
 	activity []storage.Activity
+	// statusCounts counts accounts per status for the summary line.
+	statusCounts map[string]int
 }
 
 type settingsModel struct {
@@ -188,10 +273,20 @@ type settingsModel struct {
 }
 
 type accountDetailModel struct {
-	account  storage.Account
-	activity []storage.Activity
-	view     accountDetailView
-	err      string
+	account       storage.Account
+	contacts      []storage.Contact
+	activity      []storage.Activity
+	view          accountDetailView
+	err           string
+	confirmDelete bool
+	// deletingContact is the contact a y/n confirmation will delete.
+	deletingContact *storage.Contact
+	fields          []storage.CustomField
+	fieldValues     map[int64]string
+	children        []storage.Account
+	history         []storage.AuditEntry
+	// rollup includes subsidiaries' activity in the activity list.
+	rollup bool
 }
 
 type debugModel struct {
@@ -216,6 +311,8 @@ const (
 	menuAddAccount = "add-account"
 	menuCreate     = "create"
 	menuSettings   = "settings"
+	menuDeals      = "deals"
+	menuTrash      = "trash"
 	menuQuit       = "quit"
 )
 
@@ -224,9 +321,19 @@ const (
 	accountActionAddNote  = "add-note"
 	accountActionAddEvent = "add-event"
 	accountActionEdit     = "edit-account"
+	accountActionContact  = "add-contact"
+	accountActionDelete   = "delete-account"
+	accountActionHistory  = "history"
 	accountActionBack     = "back"
 )
 
+const (
+	dashboardPrompt    = "Command (t=toggle, c=calendar, r=refresh, done <#>, /, exit.)"
+	createChoicePrompt = "1=Note  2=Event  3=Task  4=Back"
+)
+
+const accountDetailPrompt = "1=Activity  2=Add note  3=Add event  4=Edit  5=Add contact  6=Delete  7=History  8=Back"
+
 var mainMenuOptions = []menuOption{
 	{
 		id:       menuDashboard,
@@ -245,18 +352,28 @@ var mainMenuOptions = []menuOption{
 	},
 	{
 		id:       menuCreate,
-		keywords: []string{"create", "note", "event"},
-		synonyms: []string{"4", "create", "note", "event", "create note", "create event"},
+		keywords: []string{"create", "note", "event", "task"},
+		synonyms: []string{"4", "create", "note", "event", "task", "create note", "create event", "create task"},
 	},
 	{
 		id:       menuSettings,
 		keywords: []string{"settings", "help"},
 		synonyms: []string{"5", "settings", "help", "settings & help"},
 	},
+	{
+		id:       menuDeals,
+		keywords: []string{"deals", "pipeline", "opportunities"},
+		synonyms: []string{"6", "deals", "deal", "pipeline"},
+	},
+	{
+		id:       menuTrash,
+		keywords: []string{"trash", "deleted", "restore"},
+		synonyms: []string{"7", "trash", "bin", "deleted"},
+	},
 	{
 		id:       menuQuit,
 		keywords: []string{"quit", "exit"},
-		synonyms: []string{"6", "quit", "exit", "exit.", "q"},
+		synonyms: []string{"8", "quit", "exit", "exit.", "q"},
 	},
 }
 
@@ -281,10 +398,25 @@ var accountDetailOptions = []menuOption{
 		keywords: []string{"edit", "update"},
 		synonyms: []string{"4", "edit", "update"},
 	},
+	{
+		id:       accountActionContact,
+		keywords: []string{"contact", "person"},
+		synonyms: []string{"5", "contact", "add contact", "new contact"},
+	},
+	{
+		id:       accountActionDelete,
+		keywords: []string{"delete", "remove"},
+		synonyms: []string{"6", "delete", "remove", "delete account"},
+	},
+	{
+		id:       accountActionHistory,
+		keywords: []string{"history", "audit", "changes"},
+		synonyms: []string{"7", "history", "audit", "changes"},
+	},
 	{
 		id:       accountActionBack,
 		keywords: []string{"back", "close"},
-		synonyms: []string{"5", "back", "exit", "exit.", "/"},
+		synonyms: []string{"8", "back", "exit", "exit.", "/"},
 	},
 }
 
@@ -305,7 +437,7 @@ func newModel(store *storage.Store, cfg *config.Store) *model {
 	filter := textinput.New()
 	filter.Prompt = ""
 	filter.Placeholder = "Type to search, / to go back"
-	filter.CharLimit = 64
+	filter.CharLimit = 128
 
 	now := time.Now().In(cfg.Location())
 
@@ -329,31 +461,50 @@ func newModel(store *storage.Store, cfg *config.Store) *model {
 	m.accountForm = newAccountForm(nil)
 	m.noteWizard = newNoteWizard(nil)
 	m.eventWizard = newEventWizard(nil)
+	m.taskWizard = newTaskWizard(nil)
+	m.search = newSearchModel()
 	m.debug.input = textinput.New()
 	m.debug.input.Placeholder = "Choose an option"
 	m.debug.input.CharLimit = 64
 	m.debug.startInput = textinput.New()
-	m.debug.startInput.Placeholder = "Start YYYY-MM-DD"
+	m.debug.startInput.Placeholder = "Start date, e.g. 2026-01-31 or -3mo"
 	m.debug.startInput.CharLimit = 32
 	m.debug.endInput = textinput.New()
-	m.debug.endInput.Placeholder = "End YYYY-MM-DD"
+	m.debug.endInput.Placeholder = "End date, e.g. yesterday"
 	m.debug.endInput.CharLimit = 32
+	if err := store.SetActor(context.Background(), cfg.Config.Name); err != nil {
+		m.errMessage = err.Error()
+	}
+	m.purgeExpiredTrash()
 	m.refreshDashboard(now)
 	m.refreshAccounts()
 	return &m
 }
 
+// Steps of the account form, in the order it asks for them. Custom fields
+// follow the last one.
+const (
+	accountNameStep = iota
+	accountPhoneStep
+	accountAddressStep
+	accountEmailStep
+	accountDecisionMakerStep
+	// accountParentStep names the parent account.
+	accountParentStep
+)
+
 func newAccountForm(existing *storage.Account) accountForm {
 	ti := textinput.New()
 	ti.Placeholder = "Account name"
 	ti.CharLimit = 96
 	ti.Focus()
 	fields := []formField{
-		{label: "Account name", required: true},
-		{label: "Phone", required: false},
-		{label: "Address", required: false},
-		{label: "Email", required: false},
-		{label: "Decision maker", required: false},
+		accountNameStep:          {label: "Account name", required: true},
+		accountPhoneStep:         {label: "Phone", required: false},
+		accountAddressStep:       {label: "Address", required: false},
+		accountEmailStep:         {label: "Email", required: false},
+		accountDecisionMakerStep: {label: "Decision maker", required: false},
+		accountParentStep:        {label: "Parent account (blank for none)", required: false},
 	}
 	form := accountForm{
 		index:  0,
@@ -364,11 +515,12 @@ func newAccountForm(existing *storage.Account) accountForm {
 		clone := *existing
 		form.editing = true
 		form.original = clone
-		form.fields[0].value = existing.Name
-		form.fields[1].value = existing.Phone
-		form.fields[2].value = existing.Address
-		form.fields[3].value = existing.Email
-		form.fields[4].value = existing.DecisionMaker
+		form.fields[accountNameStep].value = existing.Name
+		form.fields[accountPhoneStep].value = existing.Phone
+		form.fields[accountAddressStep].value = existing.Address
+		form.fields[accountEmailStep].value = existing.Email
+		form.fields[accountDecisionMakerStep].value = existing.DecisionMaker
+		form.fields[accountParentStep].value = existing.ParentName
 		form.input.SetValue(existing.Name)
 	}
 	return form
@@ -401,6 +553,14 @@ func newNoteWizard(account *storage.Account) noteWizard {
 	return wizard
 }
 
+// editNoteWizard returns a note wizard pre-filled with an existing note.
+func editNoteWizard(note storage.Note, account *storage.Account) noteWizard {
+	wizard := newNoteWizard(account)
+	wizard.contentInput.SetValue(note.Content)
+	wizard.editing = &note
+	return wizard
+}
+
 func newEventWizard(account *storage.Account) eventWizard {
 	title := textinput.New()
 	title.Placeholder = "Event title"
@@ -412,9 +572,21 @@ func newEventWizard(account *storage.Account) eventWizard {
 	details.CharLimit = 256
 
 	schedule := textinput.New()
-	schedule.Placeholder = "YYYY-MM-DD HH:MM (blank = now)"
+	schedule.Placeholder = "tomorrow 3pm, next tue 10:30, 2026-10-20 09:00 (blank = now)"
 	schedule.CharLimit = 32
 
+	duration := textinput.New()
+	duration.Placeholder = "e.g. 45m, 1h30, 16:30 or 5pm (blank = none)"
+	duration.CharLimit = 16
+
+	location := textinput.New()
+	location.Placeholder = "Room, address or meeting link (optional)"
+	location.CharLimit = 256
+
+	repeat := textinput.New()
+	repeat.Placeholder = "e.g. weekly on mon,thu until 2026-12-31 (blank = no)"
+	repeat.CharLimit = 96
+
 	assoc := textinput.New()
 	assoc.Placeholder = "Associate with account? (y/n)"
 	assoc.CharLimit = 5
@@ -423,13 +595,21 @@ func newEventWizard(account *storage.Account) eventWizard {
 	accountInput.Placeholder = "Type account name"
 	accountInput.CharLimit = 96
 
+	attendees := textinput.New()
+	attendees.Placeholder = "Contact names, comma separated, or 'all' (blank = none)"
+	attendees.CharLimit = 256
+
 	wizard := eventWizard{
 		stage:          eventStageTitle,
 		titleInput:     title,
 		detailsInput:   details,
 		scheduleInput:  schedule,
+		durationInput:  duration,
+		locationInput:  location,
+		repeatInput:    repeat,
 		associateInput: assoc,
 		accountInput:   accountInput,
+		attendeesInput: attendees,
 	}
 	if account != nil {
 		clone := *account
@@ -438,6 +618,33 @@ func newEventWizard(account *storage.Account) eventWizard {
 	return wizard
 }
 
+// editEventWizard returns an event wizard pre-filled with an existing event.
+func editEventWizard(event storage.Event, account *storage.Account, loc *time.Location) eventWizard {
+	wizard := newEventWizard(account)
+	wizard.titleInput.SetValue(event.Title)
+	wizard.detailsInput.SetValue(event.Details)
+	wizard.scheduleInput.SetValue(event.EventTime.In(loc).Format("2006-01-02 15:04"))
+	wizard.durationInput.SetValue(formatEventDuration(event.Duration))
+	wizard.locationInput.SetValue(event.Location)
+	wizard.attendeesInput.SetValue(attendeeNames(event.Attendees))
+	if rule, ok := event.Rule(); ok {
+		wizard.repeatInput.SetValue(formatRepeat(rule, loc))
+	}
+	wizard.editing = &event
+	return wizard
+}
+
+// editOccurrenceWizard returns an event wizard for changing one occurrence
+// of series without touching the others.
+func editOccurrenceWizard(series, occurrence storage.Event, loc *time.Location) eventWizard {
+	wizard := editEventWizard(series, nil, loc)
+	wizard.titleInput.SetValue(occurrence.Title)
+	wizard.detailsInput.SetValue(occurrence.Details)
+	wizard.scheduleInput.SetValue(occurrence.EventTime.In(loc).Format("2006-01-02 15:04"))
+	wizard.occurrence = &occurrence
+	return wizard
+}
+
 func (m *model) Init() tea.Cmd {
 	return textinput.Blink
 }
@@ -445,11 +652,16 @@ func (m *model) Init() tea.Cmd {
 func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		m.undoStatus = ""
 		switch msg.Type {
 		case tea.KeyCtrlC:
 			return m, tea.Quit
+		case tea.KeyCtrlZ:
+			return m, m.undoLast()
 		case tea.KeyCtrlD:
 			return m, m.openDebugMenu()
+		case tea.KeyCtrlF:
+			return m, m.openSearch()
 		}
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -464,6 +676,8 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmd = m.updateAccounts(msg)
 	case stateAccountDetail:
 		cmd = m.updateAccountDetail(msg)
+	case stateActivityEntry:
+		cmd = m.updateActivityEntry(msg)
 	case stateCreateAccount:
 		cmd = m.updateAccountForm(msg)
 	case stateCreateChoice:
@@ -472,8 +686,32 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmd = m.updateNoteWizard(msg)
 	case stateCreateEvent:
 		cmd = m.updateEventWizard(msg)
+	case stateCreateTask:
+		cmd = m.updateTaskWizard(msg)
+	case stateCreateContact:
+		cmd = m.updateContactForm(msg)
+	case stateDeals:
+		cmd = m.updateDeals(msg)
+	case stateCreateDeal:
+		cmd = m.updateDealForm(msg)
+	case statePipelineBoard:
+		cmd = m.updatePipelineBoard(msg)
+	case stateCalendar:
+		cmd = m.updateCalendar(msg)
 	case stateDashboard:
 		cmd = m.updateDashboard(msg)
+	case stateTrash:
+		cmd = m.updateTrash(msg)
+	case stateSearch:
+		cmd = m.updateSearch(msg)
+	case stateCustomFields:
+		cmd = m.updateCustomFields(msg)
+	case stateDuplicates:
+		cmd = m.updateDuplicates(msg)
+	case stateImportPreview:
+		cmd = m.updateImportPreview(msg)
+	case stateAudit:
+		cmd = m.updateAudit(msg)
 	case stateSettings, stateSettingsEditName, stateSettingsEditTimezone, stateSettingsImport:
 		cmd = m.updateSettings(msg)
 	case stateDebug:
@@ -486,6 +724,14 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m *model) View() string {
+	view := m.viewScreen()
+	if m.undoStatus != "" {
+		view += "\n" + m.theme.Secondary.Render("↶ "+m.undoStatus) + "\n"
+	}
+	return view
+}
+
+func (m *model) viewScreen() string {
 	switch m.state {
 	case stateMainMenu:
 		return m.viewMainMenu()
@@ -493,6 +739,8 @@ func (m *model) View() string {
 		return m.viewAccounts()
 	case stateAccountDetail:
 		return m.viewAccountDetail()
+	case stateActivityEntry:
+		return m.viewActivityEntry()
 	case stateCreateAccount:
 		return m.viewAccountForm()
 	case stateCreateChoice:
@@ -501,8 +749,32 @@ func (m *model) View() string {
 		return m.viewNoteWizard()
 	case stateCreateEvent:
 		return m.viewEventWizard()
+	case stateCreateTask:
+		return m.viewTaskWizard()
+	case stateCreateContact:
+		return m.viewContactForm()
+	case stateDeals:
+		return m.viewDeals()
+	case stateCreateDeal:
+		return m.viewDealForm()
+	case statePipelineBoard:
+		return m.viewPipelineBoard()
+	case stateCalendar:
+		return m.viewCalendar()
 	case stateDashboard:
 		return m.viewDashboard()
+	case stateTrash:
+		return m.viewTrash()
+	case stateSearch:
+		return m.viewSearch()
+	case stateCustomFields:
+		return m.viewCustomFields()
+	case stateDuplicates:
+		return m.viewDuplicates()
+	case stateImportPreview:
+		return m.viewImportPreview()
+	case stateAudit:
+		return m.viewAudit()
 	case stateSettings, stateSettingsEditName, stateSettingsEditTimezone, stateSettingsImport:
 		return m.viewSettings()
 	case stateDebug:
@@ -638,6 +910,9 @@ func (m *model) resolveAccountSelection(input string) (storage.Account, bool) {
 			return match, true
 		}
 	}
+	if matches := fuzzyAccounts(m.accounts, query); len(matches) == 1 {
+		return matches[0].account, true
+	}
 	return empty, false
 }
 
@@ -721,6 +996,7 @@ func (m *model) applyCleanupResult(res storage.CleanupResult, err error, label s
 	}
 	m.debug.err = ""
 	m.infoMessage = fmt.Sprintf("%s — accounts:%d notes:%d events:%d", label, res.Accounts, res.Notes, res.Events)
+	m.pushUndo(strings.ToLower(label[:1])+label[1:], res.Undo)
 	m.refreshDataAfterCleanup()
 }
 
@@ -733,24 +1009,17 @@ func (m *model) refreshDataAfterCleanup() {
 	}
 }
 
-func parseDateInput(value string, loc *time.Location) (time.Time, error) {
-	layouts := []string{"2006-01-02", time.RFC3339, "2006-01-02 15:04"}
-	for _, layout := range layouts {
-		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
-			return t, nil
-		}
-	}
-	return time.Time{}, fmt.Errorf("unsupported date format")
-}
-
 func (m *model) openAccountDetail(account storage.Account) tea.Cmd {
 	m.accountDetail.account = account
 	m.accountDetail.view = accountDetailSummary
 	m.accountDetail.activity = nil
+	m.accountDetail.contacts = nil
+	m.accountDetail.children = nil
+	m.accountDetail.rollup = false
 	m.accountDetail.err = ""
 	m.refreshAccountDetailAccount()
 	m.pushState(stateAccountDetail)
-	return m.setMenuInput("1=Activity  2=Add note  3=Add event  4=Edit  5=Back", 64)
+	return m.setMenuInput(accountDetailPrompt, 72)
 }
 
 func (m *model) refreshAccountDetailAccount() {
@@ -764,62 +1033,43 @@ func (m *model) refreshAccountDetailAccount() {
 		return
 	}
 	m.accountDetail.account = *account
-}
-
-func (m *model) loadAccountActivity() {
-	if m.accountDetail.account.ID == 0 {
-		m.accountDetail.activity = nil
-		return
-	}
-	ctx := context.Background()
-	activity, err := m.store.ListAccountActivity(ctx, m.accountDetail.account.ID, 50)
+	contacts, err := m.store.ListContacts(ctx, account.ID)
 	if err != nil {
-		m.accountDetail.err = fmt.Sprintf("load activity: %v", err)
+		m.accountDetail.err = fmt.Sprintf("load contacts: %v", err)
 		return
 	}
-	m.accountDetail.err = ""
-	m.accountDetail.activity = activity
-}
-
-func (m *model) handleAccountImport(path string) {
-	m.infoMessage = ""
-	trimmed := strings.TrimSpace(path)
-	if trimmed == "" {
-		m.errMessage = "Provide a CSV path"
+	m.accountDetail.contacts = contacts
+	if err := m.loadAccountChildren(); err != nil {
+		m.accountDetail.err = fmt.Sprintf("load subsidiaries: %v", err)
 		return
 	}
-	resolved, err := expandPath(trimmed)
+	fields, err := m.store.ListCustomFields(ctx)
 	if err != nil {
-		m.errMessage = fmt.Sprintf("import path: %v", err)
+		m.accountDetail.err = fmt.Sprintf("load custom fields: %v", err)
 		return
 	}
-	file, err := os.Open(resolved)
+	values, err := m.store.AccountFieldValues(ctx, account.ID)
 	if err != nil {
-		m.errMessage = fmt.Sprintf("open file: %v", err)
+		m.accountDetail.err = fmt.Sprintf("load custom fields: %v", err)
+		return
+	}
+	m.accountDetail.fields = fields
+	m.accountDetail.fieldValues = values
+}
+
+func (m *model) loadAccountActivity() {
+	if m.accountDetail.account.ID == 0 {
+		m.accountDetail.activity = nil
 		return
 	}
-	defer file.Close()
 	ctx := context.Background()
-	result, err := m.store.ImportAccountsCSV(ctx, file, m.cfg.Config.Name, m.cfg.Location())
+	activity, err := m.store.ListAccountActivity(ctx, m.accountDetail.account.ID, 50, m.accountDetail.rollup)
 	if err != nil {
-		m.errMessage = fmt.Sprintf("import csv: %v", err)
+		m.accountDetail.err = fmt.Sprintf("load activity: %v", err)
 		return
 	}
-	parts := []string{fmt.Sprintf("Imported %d account(s)", result.Created)}
-	if result.Notes > 0 {
-		parts = append(parts, fmt.Sprintf("added %d note(s)", result.Notes))
-	}
-	if result.Skipped > 0 {
-		parts = append(parts, fmt.Sprintf("skipped %d", result.Skipped))
-	}
-	m.infoMessage = strings.Join(parts, ", ")
-	if len(result.Errors) > 0 {
-		m.errMessage = strings.Join(result.Errors, "; ")
-	} else {
-		m.errMessage = ""
-	}
-	m.refreshAccounts()
-	m.refreshDashboard(time.Now().In(m.cfg.Location()))
+	m.accountDetail.err = ""
+	m.accountDetail.activity = activity
 }
 
 func expandPath(p string) (string, error) {
@@ -936,11 +1186,11 @@ func (m *model) updateDebug(msg tea.Msg) tea.Cmd {
 					}
 					return batchCmds(cmds)
 				}
-				parsed, err := parseDateInput(text, m.cfg.Location())
+				parsed, err := m.parseWhen(text)
 				if err != nil {
 					m.debug.err = fmt.Sprintf("start date: %v", err)
 				} else {
-					m.debug.rangeStart = parsed
+					m.debug.rangeStart = parsed.Time
 					m.debug.mode = debugRangeEnd
 					m.debug.endInput.SetValue("")
 					if focus := m.debug.endInput.Focus(); focus != nil {
@@ -972,11 +1222,11 @@ func (m *model) updateDebug(msg tea.Msg) tea.Cmd {
 					m.runCleanupRange()
 					return batchCmds(cmds)
 				}
-				parsed, err := parseDateInput(text, m.cfg.Location())
+				parsed, err := m.parseWhen(text)
 				if err != nil {
 					m.debug.err = fmt.Sprintf("end date: %v", err)
 				} else {
-					m.debug.rangeEnd = parsed
+					m.debug.rangeEnd = parsed.Time
 					m.runCleanupRange()
 				}
 			case tea.KeyEsc:
@@ -990,7 +1240,7 @@ func (m *model) updateDebug(msg tea.Msg) tea.Cmd {
 
 func (m *model) viewDebug() string {
 	lines := []string{m.theme.Title.Render("Debug Tools")}
-	lines = append(lines, m.theme.Faint.Render("Ctrl+D to open. Choose a cleanup option."))
+	lines = append(lines, m.theme.Faint.Render("Ctrl+D to open. Choose a cleanup option. Cleared items go to the Trash (main menu 7)."))
 	lines = append(lines, "")
 	switch m.debug.mode {
 	case debugViewing:
@@ -1004,11 +1254,17 @@ func (m *model) viewDebug() string {
 		lines = append(lines, "")
 		lines = append(lines, m.theme.Accent.Render("> ")+m.menuInput.View())
 	case debugRangeStart:
-		lines = append(lines, m.theme.Secondary.Render("Enter start date (YYYY-MM-DD) or leave blank:"))
+		lines = append(lines, m.theme.Secondary.Render("Enter start date or leave blank:"))
 		lines = append(lines, m.debug.startInput.View())
+		if preview := m.datePreview(m.debug.startInput.Value(), false); preview != "" {
+			lines = append(lines, preview)
+		}
 	case debugRangeEnd:
-		lines = append(lines, m.theme.Secondary.Render("Enter end date (YYYY-MM-DD) or leave blank:"))
+		lines = append(lines, m.theme.Secondary.Render("Enter end date or leave blank:"))
 		lines = append(lines, m.debug.endInput.View())
+		if preview := m.datePreview(m.debug.endInput.Value(), false); preview != "" {
+			lines = append(lines, preview)
+		}
 	}
 	if m.debug.err != "" {
 		lines = append(lines, "", m.theme.Danger.Render(m.debug.err))
@@ -1050,6 +1306,18 @@ func isBackCommand(value string) bool {
 	return v == "/" || v == "back"
 }
 
+// cutCommand splits "verb argument" input when verb is one of names.
+func cutCommand(value string, names ...string) (string, bool) {
+	trimmed := strings.TrimSpace(value)
+	lower := strings.ToLower(trimmed)
+	for _, name := range names {
+		if strings.HasPrefix(lower, name+" ") {
+			return strings.TrimSpace(trimmed[len(name)+1:]), true
+		}
+	}
+	return "", false
+}
+
 func (m *model) refreshAccounts() {
 	ctx := context.Background()
 	accounts, err := m.store.ListAccounts(ctx)
@@ -1058,17 +1326,7 @@ func (m *model) refreshAccounts() {
 		return
 	}
 	m.accounts = accounts
-	filter := strings.TrimSpace(m.accountFilter.Value())
-	if filter == "" {
-		m.filteredAccounts = accounts
-		return
-	}
-	filtered, err := m.store.SearchAccounts(ctx, filter)
-	if err != nil {
-		m.errMessage = fmt.Sprintf("search accounts: %v", err)
-		return
-	}
-	m.filteredAccounts = filtered
+	m.filteredAccounts = m.filterAccounts(strings.TrimSpace(m.accountFilter.Value()))
 }
 
 func (m *model) refreshDashboard(now time.Time) {
@@ -1079,12 +1337,24 @@ func (m *model) refreshDashboard(now time.Time) {
 	} else {
 		m.dashboard.events = events
 	}
+	tasks, err := m.store.ListTasks(ctx, false)
+	if err != nil {
+		m.errMessage = fmt.Sprintf("load tasks: %v", err)
+	} else {
+		m.dashboard.tasks = tasks
+	}
 	activity, err := m.store.ListActivities(ctx, 50)
 	if err != nil {
 		m.errMessage = fmt.Sprintf("load activity: %v", err)
 	} else {
 		m.dashboard.activity = activity
 	}
+	counts, err := m.store.CountAccountsByStatus(ctx)
+	if err != nil {
+		m.errMessage = fmt.Sprintf("load account statuses: %v", err)
+	} else {
+		m.dashboard.statusCounts = counts
+	}
 }
 
 // MAIN MENU
@@ -1117,7 +1387,7 @@ func (m *model) updateMainMenu(msg tea.Msg) tea.Cmd {
 			m.resetMessages()
 			m.pushState(stateDashboard)
 			m.refreshDashboard(time.Now().In(m.cfg.Location()))
-			if focus := m.setMenuInput("Command (t=toggle, r=refresh, /, exit.)", 48); focus != nil {
+			if focus := m.setMenuInput(dashboardPrompt, 64); focus != nil {
 				cmds = append(cmds, focus)
 			}
 		case menuAccounts:
@@ -1131,12 +1401,12 @@ func (m *model) updateMainMenu(msg tea.Msg) tea.Cmd {
 			m.refreshAccounts()
 		case menuAddAccount:
 			m.resetMessages()
-			m.accountForm = newAccountForm(nil)
+			m.accountForm = m.withCustomFields(newAccountForm(nil))
 			m.pushState(stateCreateAccount)
 		case menuCreate:
 			m.resetMessages()
 			m.pushState(stateCreateChoice)
-			if focus := m.setMenuInput("1=Note  2=Event  3=Back", 32); focus != nil {
+			if focus := m.setMenuInput(createChoicePrompt, 40); focus != nil {
 				cmds = append(cmds, focus)
 			}
 		case menuSettings:
@@ -1145,7 +1415,19 @@ func (m *model) updateMainMenu(msg tea.Msg) tea.Cmd {
 			m.settings.input.CharLimit = 96
 			m.settings.input.Prompt = ""
 			m.pushState(stateSettings)
-			if focus := m.setMenuInput("1=Name  2=Timezone  3=Back", 40); focus != nil {
+			if focus := m.setMenuInput(settingsPrompt, 64); focus != nil {
+				cmds = append(cmds, focus)
+			}
+		case menuDeals:
+			m.resetMessages()
+			m.pushState(stateDeals)
+			m.refreshDeals()
+			if focus := m.setMenuInput(dealsPrompt, 64); focus != nil {
+				cmds = append(cmds, focus)
+			}
+		case menuTrash:
+			m.resetMessages()
+			if focus := m.openTrash(); focus != nil {
 				cmds = append(cmds, focus)
 			}
 		case menuQuit:
@@ -1174,9 +1456,11 @@ func (m *model) viewMainMenu() string {
 		"1. Dashboard",
 		"2. View accounts",
 		"3. Add account",
-		"4. Create note/event",
+		"4. Create note/event/task",
 		"5. Settings & Help",
-		"6. Quit",
+		"6. Deals pipeline",
+		"7. Trash",
+		"8. Quit",
 	}
 	lines = append(lines, "")
 	for _, item := range menu {
@@ -1224,20 +1508,35 @@ func (m *model) updateAccounts(msg tea.Msg) tea.Cmd {
 			lowerValue := strings.ToLower(trimmedValue)
 			if strings.HasPrefix(lowerValue, "import ") {
 				path := strings.TrimSpace(trimmedValue[len("import "):])
-				m.handleAccountImport(path)
 				m.accountFilter.SetValue("")
 				m.refreshAccounts()
+				if focus := m.openImportPreview(path); focus != nil {
+					cmds = append(cmds, focus)
+				}
+				return batchCmds(cmds)
+			}
+			if strings.HasPrefix(lowerValue, "export ") {
+				m.errMessage = ""
+				m.handleExport(trimmedValue[len("export "):])
+				m.accountFilter.SetValue("")
+				m.refreshAccounts()
+				return batchCmds(cmds)
+			}
+			if lowerValue == "dupes" || lowerValue == "duplicates" {
+				m.accountFilter.SetValue("")
+				if focus := m.openDuplicates(); focus != nil {
+					cmds = append(cmds, focus)
+				}
 				return batchCmds(cmds)
 			}
-			if base, index, ok := extractTrailingNumber(trimmedValue); ok {
+			// "acme 2" picks the second match for "acme". Only when the
+			// number is not a position in that list does a fuzzy query
+			// like "plc3" open its clear best match, e.g. Place3.
+			base, index, hasIndex := extractTrailingNumber(trimmedValue)
+			if hasIndex {
 				list := m.accounts
 				if base != "" {
-					accounts, err := m.store.SearchAccounts(context.Background(), base)
-					if err != nil {
-						m.errMessage = fmt.Sprintf("search accounts: %v", err)
-						return batchCmds(cmds)
-					}
-					list = accounts
+					list = m.filterAccounts(base)
 				}
 				if index > 0 && index <= len(list) {
 					selected := list[index-1]
@@ -1248,6 +1547,17 @@ func (m *model) updateAccounts(msg tea.Msg) tea.Cmd {
 					}
 					return batchCmds(cmds)
 				}
+			}
+			if base != "" {
+				if account, ok := bestFuzzyAccount(m.accounts, trimmedValue); ok {
+					m.accountFilter.SetValue("")
+					if focus := m.openAccountDetail(account); focus != nil {
+						cmds = append(cmds, focus)
+					}
+					return batchCmds(cmds)
+				}
+			}
+			if hasIndex {
 				m.errMessage = "Invalid selection"
 				return batchCmds(cmds)
 			}
@@ -1270,51 +1580,57 @@ func (m *model) updateAccounts(msg tea.Msg) tea.Cmd {
 		}
 	}
 
-	filter := strings.TrimSpace(m.accountFilter.Value())
-	if filter == "" {
-		m.filteredAccounts = m.accounts
-	} else {
-		accounts, err := m.store.SearchAccounts(context.Background(), filter)
-		if err == nil {
-			m.filteredAccounts = accounts
-		}
-	}
+	m.filteredAccounts = m.filterAccounts(strings.TrimSpace(m.accountFilter.Value()))
 	return batchCmds(cmds)
 }
 
 func (m *model) viewAccounts() string {
 	lines := []string{m.theme.Title.Render("Accounts")}
-	lines = append(lines, m.theme.Faint.Render("Type to search. Enter a number or name to manage, or 'import <path>' to load CSV. '/' to go back, 'exit.' home."))
+	lines = append(lines, m.theme.Faint.Render("Type to search (fuzzy: 'plc3' finds Place3; 'tag:vip' or 'status:customer' filters, '-' excludes). Enter a number or name to manage, 'import <path>' to load CSV, 'export <path> [tag:x]' to save one, or 'dupes' to review likely duplicates. '/' to go back, 'exit.' home."))
 	lines = append(lines, "")
 	if len(m.filteredAccounts) == 0 {
 		lines = append(lines, m.theme.Warning.Render("No accounts found."))
 	} else {
 		for i, a := range m.filteredAccounts {
 			created := a.CreatedAt.In(m.cfg.Location()).Format("Jan 02 2006 15:04")
-			header := fmt.Sprintf("%d. %s", i+1, a.Name)
-			lines = append(lines, m.theme.Primary.Render(header))
+			hl := m.accountHighlights[a.ID]
+			indent := strings.Repeat("    ", m.accountDepth[a.ID])
+			header := indent + m.theme.Primary.Render(fmt.Sprintf("%d. ", i+1)) + m.renderHighlighted(a.Name, hl.name, m.theme.Primary)
+			if a.Status != "" {
+				header += "  " + m.renderStatusBadge(a.Status)
+			}
+			if len(a.Tags) > 0 {
+				header += "  " + m.renderTagChips(a.Tags)
+			}
+			if m.accountDepth == nil && a.ParentName != "" {
+				header += m.theme.Faint.Render("  ↳ under " + a.ParentName)
+			}
+			lines = append(lines, header)
 			meta := []string{}
 			if a.Phone != "" {
-				meta = append(meta, fmt.Sprintf("Phone: %s", a.Phone))
+				meta = append(meta, m.theme.Secondary.Render("Phone: ")+m.renderHighlighted(a.Phone, hl.phone, m.theme.Secondary))
 			}
 			if a.Email != "" {
-				meta = append(meta, fmt.Sprintf("Email: %s", a.Email))
+				meta = append(meta, m.theme.Secondary.Render("Email: ")+m.renderHighlighted(a.Email, hl.email, m.theme.Secondary))
 			}
 			if a.DecisionMaker != "" {
-				meta = append(meta, fmt.Sprintf("Decision Maker: %s", a.DecisionMaker))
+				meta = append(meta, m.theme.Secondary.Render("Decision Maker: ")+m.renderHighlighted(a.DecisionMaker, hl.decisionMaker, m.theme.Secondary))
 			}
 			if len(meta) > 0 {
-				lines = append(lines, "  "+m.theme.Secondary.Render(strings.Join(meta, "  •  ")))
+				lines = append(lines, indent+"  "+strings.Join(meta, m.theme.Secondary.Render("  •  ")))
 			}
 			if a.Address != "" {
-				lines = append(lines, "  "+m.theme.Faint.Render(a.Address))
+				lines = append(lines, indent+"  "+m.theme.Faint.Render(a.Address))
 			}
-			lines = append(lines, "  "+m.theme.Faint.Render(fmt.Sprintf("Created by %s on %s", a.Creator, created)))
+			lines = append(lines, indent+"  "+m.theme.Faint.Render(fmt.Sprintf("Created by %s on %s", a.Creator, created)))
 			lines = append(lines, "")
 		}
 	}
 	lines = append(lines, m.theme.Border.Render(strings.Repeat("─", 40)))
 	lines = append(lines, m.theme.Accent.Render("find> ")+m.accountFilter.View())
+	if m.infoMessage != "" {
+		lines = append(lines, "", m.theme.Success.Render(m.infoMessage))
+	}
 	return strings.Join(lines, "\n") + "\n"
 }
 
@@ -1341,7 +1657,7 @@ func (m *model) updateAccountForm(msg tea.Msg) tea.Cmd {
 				return batchCmds(cmds)
 			}
 			if isBackCommand(value) {
-				if m.accountForm.index == 0 {
+				if m.accountForm.index == accountNameStep {
 					var focus tea.Cmd
 					if m.accountForm.editing {
 						account := m.accountForm.original
@@ -1353,7 +1669,7 @@ func (m *model) updateAccountForm(msg tea.Msg) tea.Cmd {
 					if m.state == stateMainMenu {
 						focus = m.setMenuInput("Choose an option", 32)
 					} else if m.state == stateAccountDetail {
-						focus = m.setMenuInput("1=Activity  2=Add note  3=Add event  4=Edit  5=Back", 64)
+						focus = m.setMenuInput(accountDetailPrompt, 72)
 					}
 					if focus != nil {
 						cmds = append(cmds, focus)
@@ -1365,12 +1681,45 @@ func (m *model) updateAccountForm(msg tea.Msg) tea.Cmd {
 				m.accountForm.input.Placeholder = prev.label
 				m.accountForm.input.SetValue(prev.value)
 				m.accountForm.err = ""
+				m.accountForm.warning = ""
 				return batchCmds(cmds)
 			}
 			if m.accountForm.fields[m.accountForm.index].required && value == "" {
 				m.accountForm.err = "This field is required"
 				return batchCmds(cmds)
 			}
+			checked, warning := m.checkContactField(m.accountForm.index, value)
+			if warning != "" && value != m.accountForm.warned {
+				m.accountForm.warning = warning
+				m.accountForm.warned = value
+				m.accountForm.err = ""
+				return batchCmds(cmds)
+			}
+			value = checked
+			m.accountForm.warning = ""
+			m.accountForm.warned = ""
+			if custom := m.accountForm.fields[m.accountForm.index].custom; custom != nil {
+				normalized, err := custom.NormalizeAt(value, time.Now().In(m.cfg.Location()), m.cfg.DateOrder())
+				if err != nil {
+					m.accountForm.err = err.Error()
+					return batchCmds(cmds)
+				}
+				if custom.Type == storage.FieldDate {
+					// Relative dates are pinned now rather than re-read
+					// against a later day when the form is saved.
+					value = normalized
+				}
+			}
+			if m.accountForm.index == accountParentStep {
+				parent, err := m.resolveParentAccount(value)
+				if err != nil {
+					m.accountForm.err = err.Error()
+					return batchCmds(cmds)
+				}
+				if parent != nil {
+					value = parent.Name
+				}
+			}
 			m.accountForm.fields[m.accountForm.index].value = value
 			m.accountForm.input.SetValue("")
 			m.accountForm.err = ""
@@ -1380,14 +1729,32 @@ func (m *model) updateAccountForm(msg tea.Msg) tea.Cmd {
 					base = m.accountForm.original
 				}
 				account := buildAccount(m.accountForm.fields, base)
+				account.ParentID = sql.NullInt64{}
+				if parent, _ := m.resolveParentAccount(m.accountForm.fields[accountParentStep].value); parent != nil {
+					account.ParentID = sql.NullInt64{Int64: parent.ID, Valid: true}
+				}
+				values, err := customFieldValues(m.accountForm.fields, time.Now().In(m.cfg.Location()), m.cfg.DateOrder())
+				if err != nil {
+					m.accountForm.err = err.Error()
+					return batchCmds(cmds)
+				}
 				ctx := context.Background()
+				var undo *storage.Snapshot
 				if m.accountForm.editing {
+					undo = m.snapshotRecord("account", account.ID)
 					if err := m.store.UpdateAccount(ctx, &account); err != nil {
-						if err == storage.ErrAccountExists {
-							m.accountForm.err = "An account with that name already exists"
-							m.accountForm.index = 0
+						if err == storage.ErrAccountExists || err == storage.ErrAccountTrashed {
+							m.accountForm.err = accountNameTaken(account.Name, err)
+							m.accountForm.index = accountNameStep
 							m.accountForm.input.SetValue(account.Name)
-							m.accountForm.input.Placeholder = m.accountForm.fields[0].label
+							m.accountForm.input.Placeholder = m.accountForm.fields[accountNameStep].label
+							return batchCmds(cmds)
+						}
+						if err == storage.ErrAccountCycle {
+							m.accountForm.err = "An account can't be placed under itself or one of its subsidiaries"
+							m.accountForm.index = accountParentStep
+							m.accountForm.input.SetValue(m.accountForm.fields[accountParentStep].value)
+							m.accountForm.input.Placeholder = m.accountForm.fields[accountParentStep].label
 							return batchCmds(cmds)
 						}
 						m.accountForm.err = err.Error()
@@ -1397,12 +1764,13 @@ func (m *model) updateAccountForm(msg tea.Msg) tea.Cmd {
 				} else {
 					account.Creator = m.cfg.Config.Name
 					account.CreatedAt = time.Now().In(m.cfg.Location())
+					account.Status = m.cfg.AccountStatuses()[0]
 					if err := m.store.CreateAccount(ctx, &account); err != nil {
-						if err == storage.ErrAccountExists {
-							m.accountForm.err = "An account with that name already exists"
-							m.accountForm.index = 0
+						if err == storage.ErrAccountExists || err == storage.ErrAccountTrashed {
+							m.accountForm.err = accountNameTaken(account.Name, err)
+							m.accountForm.index = accountNameStep
 							m.accountForm.input.SetValue("")
-							m.accountForm.input.Placeholder = m.accountForm.fields[0].label
+							m.accountForm.input.Placeholder = m.accountForm.fields[accountNameStep].label
 							return batchCmds(cmds)
 						}
 						m.accountForm.err = err.Error()
@@ -1410,6 +1778,14 @@ func (m *model) updateAccountForm(msg tea.Msg) tea.Cmd {
 					}
 					m.infoMessage = fmt.Sprintf("Account '%s' created", account.Name)
 				}
+				if err := m.store.SetAccountFieldValues(ctx, account.ID, values); err != nil {
+					m.errMessage = fmt.Sprintf("save custom fields: %v", err)
+				}
+				if m.accountForm.editing {
+					m.pushUndo(fmt.Sprintf("edit of account '%s'", account.Name), undo)
+				} else {
+					m.pushCreated(fmt.Sprintf("new account '%s'", account.Name), "account", account.ID)
+				}
 				if m.accountForm.editing {
 					m.accountDetail.account = account
 					m.refreshAccountDetailAccount()
@@ -1420,7 +1796,7 @@ func (m *model) updateAccountForm(msg tea.Msg) tea.Cmd {
 				if m.state == stateMainMenu {
 					focus = m.setMenuInput("Choose an option", 32)
 				} else if m.state == stateAccountDetail {
-					focus = m.setMenuInput("1=Activity  2=Add note  3=Add event  4=Edit  5=Back", 64)
+					focus = m.setMenuInput(accountDetailPrompt, 72)
 				}
 				if focus != nil {
 					cmds = append(cmds, focus)
@@ -1447,7 +1823,7 @@ func (m *model) updateAccountForm(msg tea.Msg) tea.Cmd {
 			if m.state == stateMainMenu {
 				focus = m.setMenuInput("Choose an option", 32)
 			} else if m.state == stateAccountDetail {
-				focus = m.setMenuInput("1=Activity  2=Add note  3=Add event  4=Edit  5=Back", 64)
+				focus = m.setMenuInput(accountDetailPrompt, 72)
 			}
 			if focus != nil {
 				cmds = append(cmds, focus)
@@ -1458,22 +1834,52 @@ func (m *model) updateAccountForm(msg tea.Msg) tea.Cmd {
 	return batchCmds(cmds)
 }
 
+// checkContactField validates the phone, address and email steps of the
+// account form, returning the value to save and a warning when it does not
+// look valid.
+func (m *model) checkContactField(index int, value string) (string, string) {
+	var err error
+	switch index {
+	case accountPhoneStep:
+		value, err = validate.Phone(value, m.cfg.PhoneCountry())
+	case accountAddressStep:
+		value = validate.Address(value)
+	case accountEmailStep:
+		value, err = validate.Email(value)
+	default:
+		return value, ""
+	}
+	if err != nil {
+		return value, fmt.Sprintf("%s: %v", m.accountForm.fields[index].label, err)
+	}
+	return value, ""
+}
+
+// accountNameTaken explains a name clash from CreateAccount or
+// UpdateAccount, pointing at the trash when that is where the name is held.
+func accountNameTaken(name string, err error) string {
+	if err == storage.ErrAccountTrashed {
+		return fmt.Sprintf("'%s' is in the trash; restore it or purge it first", strings.TrimSpace(name))
+	}
+	return "An account with that name already exists"
+}
+
 func buildAccount(fields []formField, base storage.Account) storage.Account {
 	account := base
-	if len(fields) > 0 {
-		account.Name = fields[0].value
+	if len(fields) > accountNameStep {
+		account.Name = fields[accountNameStep].value
 	}
-	if len(fields) > 1 {
-		account.Phone = fields[1].value
+	if len(fields) > accountPhoneStep {
+		account.Phone = fields[accountPhoneStep].value
 	}
-	if len(fields) > 2 {
-		account.Address = fields[2].value
+	if len(fields) > accountAddressStep {
+		account.Address = fields[accountAddressStep].value
 	}
-	if len(fields) > 3 {
-		account.Email = fields[3].value
+	if len(fields) > accountEmailStep {
+		account.Email = fields[accountEmailStep].value
 	}
-	if len(fields) > 4 {
-		account.DecisionMaker = fields[4].value
+	if len(fields) > accountDecisionMakerStep {
+		account.DecisionMaker = fields[accountDecisionMakerStep].value
 	}
 	return account
 }
@@ -1492,6 +1898,14 @@ func (m *model) viewAccountForm() string {
 		m.theme.Primary.Render(field.label + ":"),
 		m.accountForm.input.View(),
 	}
+	if field.custom != nil && field.custom.Type == storage.FieldDate {
+		if preview := m.datePreview(m.accountForm.input.Value(), false); preview != "" {
+			lines = append(lines, preview)
+		}
+	}
+	if m.accountForm.warning != "" {
+		lines = append(lines, "", m.theme.Warning.Render(m.accountForm.warning+"; press Enter again to keep it"))
+	}
 	if m.accountForm.err != "" {
 		lines = append(lines, "", m.theme.Danger.Render(m.accountForm.err))
 	}
@@ -1500,7 +1914,13 @@ func (m *model) viewAccountForm() string {
 
 func (m *model) updateAccountDetail(msg tea.Msg) tea.Cmd {
 	var cmds []tea.Cmd
-	if focus := m.ensureMenuInput("1=Activity  2=Add note  3=Add event  4=Edit  5=Back", 64); focus != nil {
+	placeholder := accountDetailPrompt
+	if m.accountDetail.confirmDelete {
+		placeholder = accountDeletePrompt
+	} else if m.accountDetail.deletingContact != nil {
+		placeholder = activityConfirmPrompt
+	}
+	if focus := m.ensureMenuInput(placeholder, 72); focus != nil {
 		cmds = append(cmds, focus)
 	}
 	var cmd tea.Cmd
@@ -1515,6 +1935,48 @@ func (m *model) updateAccountDetail(msg tea.Msg) tea.Cmd {
 		case tea.KeyEnter:
 			choice := strings.TrimSpace(strings.ToLower(m.menuInput.Value()))
 			m.menuInput.SetValue("")
+			if m.accountDetail.confirmDelete {
+				if focus := m.deleteAccountChoice(choice); focus != nil {
+					cmds = append(cmds, focus)
+				}
+				return batchCmds(cmds)
+			}
+			if m.accountDetail.deletingContact != nil {
+				if focus := m.deleteContactChoice(choice); focus != nil {
+					cmds = append(cmds, focus)
+				}
+				return batchCmds(cmds)
+			}
+			if focus, ok := m.accountContactCommand(choice); ok {
+				if focus != nil {
+					cmds = append(cmds, focus)
+				}
+				return batchCmds(cmds)
+			}
+			if focus, ok := m.accountActivityCommand(choice); ok {
+				if focus != nil {
+					cmds = append(cmds, focus)
+				}
+				return batchCmds(cmds)
+			}
+			if focus, ok := m.accountTagCommand(choice); ok {
+				if focus != nil {
+					cmds = append(cmds, focus)
+				}
+				return batchCmds(cmds)
+			}
+			if focus, ok := m.accountStatusCommand(choice); ok {
+				if focus != nil {
+					cmds = append(cmds, focus)
+				}
+				return batchCmds(cmds)
+			}
+			if focus, ok := m.accountHierarchyCommand(choice); ok {
+				if focus != nil {
+					cmds = append(cmds, focus)
+				}
+				return batchCmds(cmds)
+			}
 			action, ok := resolveAccountDetailAction(choice)
 			if !ok {
 				if choice == "" {
@@ -1528,6 +1990,9 @@ func (m *model) updateAccountDetail(msg tea.Msg) tea.Cmd {
 			case accountActionActivity:
 				m.accountDetail.view = accountDetailActivity
 				m.loadAccountActivity()
+			case accountActionHistory:
+				m.accountDetail.view = accountDetailHistory
+				m.loadAccountHistory()
 			case accountActionAddNote:
 				m.accountDetail.view = accountDetailSummary
 				account := m.accountDetail.account
@@ -1543,9 +2008,21 @@ func (m *model) updateAccountDetail(msg tea.Msg) tea.Cmd {
 			case accountActionEdit:
 				m.accountDetail.view = accountDetailSummary
 				account := m.accountDetail.account
-				m.accountForm = newAccountForm(&account)
+				m.accountForm = m.withCustomFields(newAccountForm(&account))
 				m.pushState(stateCreateAccount)
 				return batchCmds(cmds)
+			case accountActionContact:
+				m.accountDetail.view = accountDetailSummary
+				account := m.accountDetail.account
+				m.contactForm = newContactForm(&account)
+				m.pushState(stateCreateContact)
+				return batchCmds(cmds)
+			case accountActionDelete:
+				m.accountDetail.confirmDelete = true
+				if focus := m.setMenuInput(accountDeletePrompt, 72); focus != nil {
+					cmds = append(cmds, focus)
+				}
+				return batchCmds(cmds)
 			case accountActionBack:
 				m.popState()
 				if m.state == stateMainMenu {
@@ -1556,6 +2033,14 @@ func (m *model) updateAccountDetail(msg tea.Msg) tea.Cmd {
 				return batchCmds(cmds)
 			}
 		case tea.KeyEsc:
+			if m.accountDetail.confirmDelete || m.accountDetail.deletingContact != nil {
+				m.accountDetail.confirmDelete = false
+				m.accountDetail.deletingContact = nil
+				if focus := m.setMenuInput(accountDetailPrompt, 72); focus != nil {
+					cmds = append(cmds, focus)
+				}
+				return batchCmds(cmds)
+			}
 			m.popState()
 			if m.state == stateMainMenu {
 				if focus := m.setMenuInput("Choose an option", 32); focus != nil {
@@ -1570,7 +2055,14 @@ func (m *model) updateAccountDetail(msg tea.Msg) tea.Cmd {
 
 func (m *model) viewAccountDetail() string {
 	a := m.accountDetail.account
-	lines := []string{m.theme.Title.Render(a.Name)}
+	title := m.theme.Title.Render(a.Name)
+	if a.Status != "" {
+		title += "  " + m.renderStatusBadge(a.Status)
+	}
+	lines := []string{title}
+	if a.ParentName != "" {
+		lines = append(lines, m.theme.Faint.Render(fmt.Sprintf("Part of %s ('parent' to open)", a.ParentName)))
+	}
 	meta := []string{}
 	if a.Phone != "" {
 		meta = append(meta, fmt.Sprintf("Phone: %s", a.Phone))
@@ -1578,9 +2070,6 @@ func (m *model) viewAccountDetail() string {
 	if a.Email != "" {
 		meta = append(meta, fmt.Sprintf("Email: %s", a.Email))
 	}
-	if a.DecisionMaker != "" {
-		meta = append(meta, fmt.Sprintf("Decision Maker: %s", a.DecisionMaker))
-	}
 	if len(meta) > 0 {
 		lines = append(lines, m.theme.Secondary.Render(strings.Join(meta, "  •  ")))
 	}
@@ -1589,22 +2078,59 @@ func (m *model) viewAccountDetail() string {
 	}
 	created := a.CreatedAt.In(m.cfg.Location()).Format("Jan 02 2006 15:04")
 	lines = append(lines, m.theme.Faint.Render(fmt.Sprintf("Created by %s on %s", a.Creator, created)))
+	lines = append(lines, m.viewAccountCustomFields()...)
+	if len(a.Tags) > 0 {
+		lines = append(lines, m.renderTagChips(a.Tags))
+	}
+	lines = append(lines, m.theme.Faint.Render("'tag vip, emea' adds tags, 'untag vip' removes one, 'status customer' moves the account along its lifecycle."))
+	lines = append(lines, "")
+
+	lines = append(lines, m.theme.Subtitle.Render("Contacts"))
+	if len(m.accountDetail.contacts) == 0 {
+		lines = append(lines, m.theme.Faint.Render("No contacts yet."))
+	}
+	for i, c := range m.accountDetail.contacts {
+		lines = append(lines, formatContactLine(m, i+1, c))
+	}
+	if len(m.accountDetail.contacts) > 0 {
+		lines = append(lines, m.theme.Faint.Render("'edit contact 2' changes a contact, 'del contact 2' removes one."))
+	}
 	lines = append(lines, "")
+	lines = append(lines, m.viewAccountSubsidiaries()...)
 
 	if m.accountDetail.view == accountDetailActivity {
-		lines = append(lines, m.theme.Subtitle.Render("Recent Activity"))
+		heading := "Recent Activity"
+		if m.accountDetail.rollup {
+			heading += " (incl. subsidiaries)"
+		}
+		lines = append(lines, m.theme.Subtitle.Render(heading))
 		if len(m.accountDetail.activity) == 0 {
 			lines = append(lines, m.theme.Faint.Render("No activity yet."))
 		} else {
-			for _, act := range m.accountDetail.activity {
+			for i, act := range m.accountDetail.activity {
 				stamp := act.CreatedAt.In(m.cfg.Location()).Format("Jan 02 15:04")
 				typeLabel := act.Type
 				if len(typeLabel) > 0 {
 					typeLabel = strings.ToUpper(typeLabel[:1]) + typeLabel[1:]
 				}
-				item := fmt.Sprintf("[%s] %s — %s", typeLabel, act.Title, stamp)
+				item := fmt.Sprintf("%d. [%s] %s — %s", i+1, typeLabel, act.Title, stamp)
+				if act.AccountID != a.ID {
+					item += " · " + m.accountName(act.AccountID)
+				}
 				lines = append(lines, m.theme.Primary.Render(item))
 			}
+			lines = append(lines, m.theme.Faint.Render("'open 2', 'edit 2' or 'del 2' works on entry #2."))
+		}
+		lines = append(lines, "")
+	}
+
+	if m.accountDetail.view == accountDetailHistory {
+		lines = append(lines, m.theme.Subtitle.Render("History"))
+		if len(m.accountDetail.history) == 0 {
+			lines = append(lines, m.theme.Faint.Render("No changes recorded."))
+		}
+		for _, e := range m.accountDetail.history {
+			lines = append(lines, m.formatAuditEntry(e, false)...)
 		}
 		lines = append(lines, "")
 	}
@@ -1614,8 +2140,18 @@ func (m *model) viewAccountDetail() string {
 	lines = append(lines, m.theme.Secondary.Render("2. Add note (auto links)"))
 	lines = append(lines, m.theme.Secondary.Render("3. Add event (auto links)"))
 	lines = append(lines, m.theme.Secondary.Render("4. Edit account"))
-	lines = append(lines, m.theme.Faint.Render("5. Back"))
+	lines = append(lines, m.theme.Secondary.Render("5. Add contact"))
+	lines = append(lines, m.theme.Secondary.Render("6. Delete account"))
+	lines = append(lines, m.theme.Secondary.Render("7. History"))
+	lines = append(lines, m.theme.Faint.Render("8. Back"))
 	lines = append(lines, "")
+	if m.accountDetail.confirmDelete {
+		lines = append(lines, m.theme.Warning.Render(fmt.Sprintf("Delete %s? Contacts and deals go with it.", a.Name)))
+		lines = append(lines, m.theme.Warning.Render("c = also delete its notes, events and tasks, o = keep them unlinked, n = cancel"))
+	}
+	if c := m.accountDetail.deletingContact; c != nil {
+		lines = append(lines, m.theme.Warning.Render(fmt.Sprintf("Delete contact %s? Events keep their other attendees.", c.Name)))
+	}
 	lines = append(lines, m.theme.Accent.Render("> ")+m.menuInput.View())
 	if m.accountDetail.err != "" {
 		lines = append(lines, "", m.theme.Danger.Render(m.accountDetail.err))
@@ -1632,7 +2168,7 @@ func (m *model) viewAccountDetail() string {
 // CREATE CHOICE
 func (m *model) updateCreateChoice(msg tea.Msg) tea.Cmd {
 	var cmds []tea.Cmd
-	if focus := m.ensureMenuInput("1=Note  2=Event  3=Back", 32); focus != nil {
+	if focus := m.ensureMenuInput(createChoicePrompt, 40); focus != nil {
 		cmds = append(cmds, focus)
 	}
 	var cmd tea.Cmd
@@ -1651,7 +2187,10 @@ func (m *model) updateCreateChoice(msg tea.Msg) tea.Cmd {
 		case "2", "event", "e":
 			m.eventWizard = newEventWizard(nil)
 			m.state = stateCreateEvent
-		case "3", "back", "/":
+		case "3", "task", "t", "todo":
+			m.taskWizard = newTaskWizard(nil)
+			m.state = stateCreateTask
+		case "4", "back", "/":
 			m.popState()
 			if m.state == stateMainMenu {
 				if focus := m.setMenuInput("Choose an option", 32); focus != nil {
@@ -1665,7 +2204,7 @@ func (m *model) updateCreateChoice(msg tea.Msg) tea.Cmd {
 				cmds = append(cmds, focus)
 			}
 		default:
-			m.errMessage = "Choose 1 for note or 2 for event"
+			m.errMessage = "Choose 1 for note, 2 for event, or 3 for task"
 		}
 	}
 	return batchCmds(cmds)
@@ -1673,10 +2212,11 @@ func (m *model) updateCreateChoice(msg tea.Msg) tea.Cmd {
 
 func (m *model) viewCreateChoice() string {
 	lines := []string{
-		m.theme.Title.Render("Create Note or Event"),
+		m.theme.Title.Render("Create Note, Event or Task"),
 		m.theme.Secondary.Render("1. Note"),
 		m.theme.Secondary.Render("2. Event"),
-		m.theme.Faint.Render("3. Back"),
+		m.theme.Secondary.Render("3. Task"),
+		m.theme.Faint.Render("4. Back"),
 		"",
 		m.theme.Accent.Render("> ") + m.menuInput.View(),
 	}
@@ -1837,7 +2377,11 @@ func (m *model) updateNoteWizard(msg tea.Msg) tea.Cmd {
 }
 
 func (m *model) viewNoteWizard() string {
-	lines := []string{m.theme.Title.Render("New Note")}
+	title := "New Note"
+	if m.noteWizard.editing != nil {
+		title = "Edit Note"
+	}
+	lines := []string{m.theme.Title.Render(title)}
 	switch m.noteWizard.stage {
 	case noteStageContent:
 		lines = append(lines, m.theme.Faint.Render("Type note text and press enter. '/' to cancel."))
@@ -1861,6 +2405,19 @@ func (m *model) viewNoteWizard() string {
 
 func (m *model) saveNote(accountID *sql.NullInt64) error {
 	content := strings.TrimSpace(m.noteWizard.contentInput.Value())
+	if m.noteWizard.editing != nil {
+		note := *m.noteWizard.editing
+		note.Content = content
+		if accountID != nil {
+			note.AccountID = *accountID
+		}
+		undo := m.snapshotRecord("note", note.ID)
+		if err := m.store.UpdateNote(context.Background(), &note); err != nil {
+			return err
+		}
+		m.pushUndo("note edit", undo)
+		return nil
+	}
 	note := storage.Note{
 		Content:   content,
 		Creator:   m.cfg.Config.Name,
@@ -1870,14 +2427,23 @@ func (m *model) saveNote(accountID *sql.NullInt64) error {
 		note.AccountID = *accountID
 	}
 	ctx := context.Background()
-	return m.store.CreateNote(ctx, &note)
+	if err := m.store.CreateNote(ctx, &note); err != nil {
+		return err
+	}
+	m.pushCreated("new note", "note", note.ID)
+	return nil
 }
 
 func (m *model) completeNoteSave(message string) {
+	if m.noteWizard.editing != nil {
+		message = "Note updated"
+	}
 	m.noteWizard = newNoteWizard(nil)
 	m.infoMessage = message
 	m.popState()
-	if m.state == stateAccountDetail {
+	if m.state == stateActivityEntry {
+		m.reopenActivityEntry()
+	} else if m.state == stateAccountDetail {
 		m.refreshAccountDetailAccount()
 		m.loadAccountActivity()
 	} else if m.state == stateMainMenu {
@@ -1982,42 +2548,139 @@ func (m *model) updateEventWizard(msg tea.Msg) tea.Cmd {
 				m.eventWizard.stage = eventStageDetails
 				return batchCmds(cmds)
 			}
-			if value != "" {
-				loc := m.cfg.Location()
-				if _, err := time.ParseInLocation("2006-01-02 15:04", value, loc); err != nil {
-					m.eventWizard.err = "Use format YYYY-MM-DD HH:MM"
-					return batchCmds(cmds)
-				}
+			if _, err := m.parseEventTime(value); err != nil {
+				m.eventWizard.err = "Couldn't read the time: " + err.Error()
+				return batchCmds(cmds)
 			}
 			m.eventWizard.err = ""
-			if m.eventWizard.presetAccount != nil {
-				accountID := sql.NullInt64{Int64: m.eventWizard.presetAccount.ID, Valid: true}
-				if err := m.saveEvent(&accountID); err != nil {
+			if m.eventWizard.occurrence != nil {
+				if err := m.saveEvent(nil); err != nil {
 					m.eventWizard.err = err.Error()
 				} else {
-					name := m.eventWizard.presetAccount.Name
-					if name == "" {
-						name = m.accountDetail.account.Name
-					}
-					message := "Event created"
-					if name != "" {
-						message = fmt.Sprintf("Event created for %s", name)
-					}
-					m.completeEventSave(message)
+					m.completeEventSave("Occurrence updated")
 					return batchCmds(cmds)
 				}
-			} else {
-				m.eventWizard.stage = eventStageAssociatePrompt
+				break
 			}
+			m.eventWizard.stage = eventStageDuration
 		}
-	case eventStageAssociatePrompt:
-		if !m.eventWizard.associateInput.Focused() {
-			if focus := m.eventWizard.associateInput.Focus(); focus != nil {
+	case eventStageDuration:
+		if !m.eventWizard.durationInput.Focused() {
+			if focus := m.eventWizard.durationInput.Focus(); focus != nil {
 				cmds = append(cmds, focus)
 			}
 		}
 		var cmd tea.Cmd
-		m.eventWizard.associateInput, cmd = m.eventWizard.associateInput.Update(msg)
+		m.eventWizard.durationInput, cmd = m.eventWizard.durationInput.Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		if key, ok := msg.(tea.KeyMsg); ok && key.Type == tea.KeyEnter {
+			value := strings.TrimSpace(m.eventWizard.durationInput.Value())
+			if isExitCommand(value) {
+				m.eventWizard = newEventWizard(nil)
+				m.prevStates = nil
+				m.state = stateMainMenu
+				if focus := m.setMenuInput("Choose an option", 32); focus != nil {
+					cmds = append(cmds, focus)
+				}
+				return batchCmds(cmds)
+			}
+			if isBackCommand(value) {
+				m.eventWizard.stage = eventStageSchedule
+				return batchCmds(cmds)
+			}
+			if _, err := parseEventDuration(value, m.eventWizardStart(), m.cfg.DateOrder()); err != nil {
+				m.eventWizard.err = err.Error()
+				return batchCmds(cmds)
+			}
+			m.eventWizard.err = ""
+			m.eventWizard.stage = eventStageLocation
+		}
+	case eventStageLocation:
+		if !m.eventWizard.locationInput.Focused() {
+			if focus := m.eventWizard.locationInput.Focus(); focus != nil {
+				cmds = append(cmds, focus)
+			}
+		}
+		var cmd tea.Cmd
+		m.eventWizard.locationInput, cmd = m.eventWizard.locationInput.Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		if key, ok := msg.(tea.KeyMsg); ok && key.Type == tea.KeyEnter {
+			value := strings.TrimSpace(m.eventWizard.locationInput.Value())
+			if isExitCommand(value) {
+				m.eventWizard = newEventWizard(nil)
+				m.prevStates = nil
+				m.state = stateMainMenu
+				if focus := m.setMenuInput("Choose an option", 32); focus != nil {
+					cmds = append(cmds, focus)
+				}
+				return batchCmds(cmds)
+			}
+			if isBackCommand(value) {
+				m.eventWizard.stage = eventStageDuration
+				return batchCmds(cmds)
+			}
+			m.eventWizard.stage = eventStageRepeat
+		}
+	case eventStageRepeat:
+		if !m.eventWizard.repeatInput.Focused() {
+			if focus := m.eventWizard.repeatInput.Focus(); focus != nil {
+				cmds = append(cmds, focus)
+			}
+		}
+		var cmd tea.Cmd
+		m.eventWizard.repeatInput, cmd = m.eventWizard.repeatInput.Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		if key, ok := msg.(tea.KeyMsg); ok && key.Type == tea.KeyEnter {
+			value := strings.TrimSpace(m.eventWizard.repeatInput.Value())
+			if isExitCommand(value) {
+				m.eventWizard = newEventWizard(nil)
+				m.prevStates = nil
+				m.state = stateMainMenu
+				if focus := m.setMenuInput("Choose an option", 32); focus != nil {
+					cmds = append(cmds, focus)
+				}
+				return batchCmds(cmds)
+			}
+			if isBackCommand(value) {
+				m.eventWizard.stage = eventStageLocation
+				return batchCmds(cmds)
+			}
+			if _, err := parseRepeat(value, m.cfg.Location(), m.cfg.DateOrder()); err != nil {
+				m.eventWizard.err = err.Error()
+				return batchCmds(cmds)
+			}
+			m.eventWizard.err = ""
+			if m.eventWizard.presetAccount != nil {
+				accountID := sql.NullInt64{Int64: m.eventWizard.presetAccount.ID, Valid: true}
+				name := m.eventWizard.presetAccount.Name
+				if name == "" {
+					name = m.accountDetail.account.Name
+				}
+				message := "Event created"
+				if name != "" {
+					message = fmt.Sprintf("Event created for %s", name)
+				}
+				if m.chooseEventAccount(&accountID, message, eventStageRepeat) {
+					return batchCmds(cmds)
+				}
+			} else {
+				m.eventWizard.stage = eventStageAssociatePrompt
+			}
+		}
+	case eventStageAssociatePrompt:
+		if !m.eventWizard.associateInput.Focused() {
+			if focus := m.eventWizard.associateInput.Focus(); focus != nil {
+				cmds = append(cmds, focus)
+			}
+		}
+		var cmd tea.Cmd
+		m.eventWizard.associateInput, cmd = m.eventWizard.associateInput.Update(msg)
 		if cmd != nil {
 			cmds = append(cmds, cmd)
 		}
@@ -2034,16 +2697,13 @@ func (m *model) updateEventWizard(msg tea.Msg) tea.Cmd {
 				}
 				return batchCmds(cmds)
 			case isBackCommand(value):
-				m.eventWizard.stage = eventStageSchedule
+				m.eventWizard.stage = eventStageRepeat
 			case value == "y" || value == "yes":
 				m.eventWizard.associate = true
 				m.eventWizard.stage = eventStageAssociateChoose
 			case value == "n" || value == "no" || value == "":
 				m.eventWizard.associate = false
-				if err := m.saveEvent(nil); err != nil {
-					m.eventWizard.err = err.Error()
-				} else {
-					m.completeEventSave("Event created")
+				if m.chooseEventAccount(nil, "Event created", eventStageAssociatePrompt) {
 					return batchCmds(cmds)
 				}
 			default:
@@ -2075,10 +2735,7 @@ func (m *model) updateEventWizard(msg tea.Msg) tea.Cmd {
 			case isBackCommand(value):
 				m.eventWizard.stage = eventStageAssociatePrompt
 			case value == "":
-				if err := m.saveEvent(nil); err != nil {
-					m.eventWizard.err = err.Error()
-				} else {
-					m.completeEventSave("Event created")
+				if m.chooseEventAccount(nil, "Event created", eventStageAssociateChoose) {
 					return batchCmds(cmds)
 				}
 			default:
@@ -2087,21 +2744,73 @@ func (m *model) updateEventWizard(msg tea.Msg) tea.Cmd {
 					m.eventWizard.err = "Account not found"
 				} else {
 					id := sql.NullInt64{Int64: account.ID, Valid: true}
-					if err := m.saveEvent(&id); err != nil {
-						m.eventWizard.err = err.Error()
-					} else {
-						m.completeEventSave(fmt.Sprintf("Event created for %s", account.Name))
+					if m.chooseEventAccount(&id, fmt.Sprintf("Event created for %s", account.Name), eventStageAssociateChoose) {
 						return batchCmds(cmds)
 					}
 				}
 			}
 		}
+	case eventStageAttendees:
+		if !m.eventWizard.attendeesInput.Focused() {
+			if focus := m.eventWizard.attendeesInput.Focus(); focus != nil {
+				cmds = append(cmds, focus)
+			}
+		}
+		var cmd tea.Cmd
+		m.eventWizard.attendeesInput, cmd = m.eventWizard.attendeesInput.Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		if key, ok := msg.(tea.KeyMsg); ok && key.Type == tea.KeyEnter {
+			value := strings.TrimSpace(m.eventWizard.attendeesInput.Value())
+			switch {
+			case isExitCommand(value):
+				m.eventWizard = newEventWizard(nil)
+				m.prevStates = nil
+				m.state = stateMainMenu
+				if focus := m.setMenuInput("Choose an option", 32); focus != nil {
+					cmds = append(cmds, focus)
+				}
+				return batchCmds(cmds)
+			case isBackCommand(value):
+				m.eventWizard.err = ""
+				m.eventWizard.stage = m.eventWizard.attendeesBack
+			default:
+				ids, err := resolveAttendees(value, m.eventWizard.contacts)
+				if err != nil {
+					m.eventWizard.err = err.Error()
+					break
+				}
+				m.eventWizard.attendees = ids
+				if err := m.saveEvent(m.eventWizard.accountID); err != nil {
+					m.eventWizard.err = err.Error()
+				} else {
+					m.completeEventSave(m.eventWizard.message)
+					return batchCmds(cmds)
+				}
+			}
+		}
 	}
 	return batchCmds(cmds)
 }
 
+// eventWizardStart is when the event being entered begins; a blank
+// schedule means now.
+func (m *model) eventWizardStart() time.Time {
+	if t, err := m.parseEventTime(m.eventWizard.scheduleInput.Value()); err == nil {
+		return t
+	}
+	return time.Now().In(m.cfg.Location())
+}
+
 func (m *model) viewEventWizard() string {
-	lines := []string{m.theme.Title.Render("New Event")}
+	title := "New Event"
+	if m.eventWizard.occurrence != nil {
+		title = "Edit Occurrence"
+	} else if m.eventWizard.editing != nil {
+		title = "Edit Event"
+	}
+	lines := []string{m.theme.Title.Render(title)}
 	switch m.eventWizard.stage {
 	case eventStageTitle:
 		lines = append(lines, m.theme.Secondary.Render("Event title:"))
@@ -2113,14 +2822,37 @@ func (m *model) viewEventWizard() string {
 		lines = append(lines, m.theme.Secondary.Render("Details (optional):"))
 		lines = append(lines, m.eventWizard.detailsInput.View())
 	case eventStageSchedule:
-		lines = append(lines, m.theme.Secondary.Render("Schedule time (YYYY-MM-DD HH:MM, blank = now):"))
+		lines = append(lines, m.theme.Secondary.Render("When? ("+dateHint+"; blank = now):"))
 		lines = append(lines, m.eventWizard.scheduleInput.View())
+		if preview := m.datePreview(m.eventWizard.scheduleInput.Value(), true); preview != "" {
+			lines = append(lines, preview)
+		}
+		if occ := m.eventWizard.occurrence; occ != nil {
+			lines = append(lines, m.theme.Faint.Render("Only the "+occ.Occurrence.In(m.cfg.Location()).Format("Mon Jan 02 15:04")+" occurrence changes; the rest of the series stays as it is."))
+		}
+	case eventStageDuration:
+		lines = append(lines, m.theme.Secondary.Render("Duration or end time (45m, 1h30, 16:30, 5pm; blank = none):"))
+		lines = append(lines, m.eventWizard.durationInput.View())
+	case eventStageLocation:
+		lines = append(lines, m.theme.Secondary.Render("Location or meeting link (optional):"))
+		lines = append(lines, m.eventWizard.locationInput.View())
+	case eventStageRepeat:
+		lines = append(lines, m.theme.Secondary.Render("Repeats (daily, weekly, monthly, yearly; 'every 2 weeks on mon,thu', 'until 2026-12-31', '10 times'; blank = no):"))
+		lines = append(lines, m.eventWizard.repeatInput.View())
 	case eventStageAssociatePrompt:
 		lines = append(lines, m.theme.Secondary.Render("Associate with an account? (y/n)"))
 		lines = append(lines, m.eventWizard.associateInput.View())
 	case eventStageAssociateChoose:
 		lines = append(lines, m.theme.Secondary.Render("Enter account name (blank to skip):"))
 		lines = append(lines, m.eventWizard.accountInput.View())
+	case eventStageAttendees:
+		lines = append(lines, m.theme.Secondary.Render("Attendees (contact names, comma separated, or 'all'; blank = none):"))
+		lines = append(lines, m.eventWizard.attendeesInput.View())
+		names := make([]string, len(m.eventWizard.contacts))
+		for i, c := range m.eventWizard.contacts {
+			names[i] = c.Name
+		}
+		lines = append(lines, m.theme.Faint.Render("Contacts: "+strings.Join(names, ", ")))
 	}
 	lines = append(lines, m.theme.Faint.Render("'/' goes back, 'exit.' returns home."))
 	if m.eventWizard.err != "" {
@@ -2132,38 +2864,87 @@ func (m *model) viewEventWizard() string {
 func (m *model) saveEvent(accountID *sql.NullInt64) error {
 	title := strings.TrimSpace(m.eventWizard.titleInput.Value())
 	details := strings.TrimSpace(m.eventWizard.detailsInput.Value())
-	scheduleStr := strings.TrimSpace(m.eventWizard.scheduleInput.Value())
 	loc := m.cfg.Location()
-	var eventTime time.Time
-	if scheduleStr == "" {
-		eventTime = time.Now().In(loc)
-	} else {
-		parsed, err := time.ParseInLocation("2006-01-02 15:04", scheduleStr, loc)
-		if err != nil {
-			return fmt.Errorf("invalid schedule format")
-		}
-		eventTime = parsed
+	eventTime, err := m.parseEventTime(m.eventWizard.scheduleInput.Value())
+	if err != nil {
+		return fmt.Errorf("invalid schedule: %w", err)
+	}
+	if occ := m.eventWizard.occurrence; occ != nil {
+		return m.saveOccurrence(*m.eventWizard.editing, *occ, title, details, eventTime)
+	}
+	rule, err := parseRepeat(m.eventWizard.repeatInput.Value(), loc, m.cfg.DateOrder())
+	if err != nil {
+		return err
+	}
+	duration, err := parseEventDuration(m.eventWizard.durationInput.Value(), eventTime, m.cfg.DateOrder())
+	if err != nil {
+		return err
+	}
+	location := strings.TrimSpace(m.eventWizard.locationInput.Value())
+	ctx := context.Background()
+	if m.eventWizard.editing != nil {
+		evt := *m.eventWizard.editing
+		evt.Title = title
+		evt.Details = details
+		evt.EventTime = eventTime
+		evt.Duration = duration
+		evt.Location = location
+		evt.Recurrence = rule
+		if accountID != nil {
+			evt.AccountID = *accountID
+		}
+		undo := m.snapshotRecord("event", evt.ID)
+		if err := m.store.UpdateEvent(ctx, &evt); err != nil {
+			return err
+		}
+		if err := m.store.SetEventAttendees(ctx, evt.ID, m.eventWizard.attendees); err != nil {
+			return err
+		}
+		m.pushUndo(fmt.Sprintf("edit of event '%s'", evt.Title), undo)
+		m.eventWizard.warning = m.eventConflictWarning(evt)
+		return nil
 	}
 	evt := storage.Event{
-		Title:     title,
-		Details:   details,
-		EventTime: eventTime,
-		Creator:   m.cfg.Config.Name,
-		CreatedAt: time.Now().In(loc),
+		Title:      title,
+		Details:    details,
+		EventTime:  eventTime,
+		Duration:   duration,
+		Location:   location,
+		Recurrence: rule,
+		Creator:    m.cfg.Config.Name,
+		CreatedAt:  time.Now().In(loc),
 	}
 	if accountID != nil {
 		evt.AccountID = *accountID
 	}
-	return m.store.CreateEvent(context.Background(), &evt)
+	if err := m.store.CreateEvent(ctx, &evt); err != nil {
+		return err
+	}
+	if err := m.store.SetEventAttendees(ctx, evt.ID, m.eventWizard.attendees); err != nil {
+		return err
+	}
+	m.pushCreated(fmt.Sprintf("new event '%s'", evt.Title), "event", evt.ID)
+	m.eventWizard.warning = m.eventConflictWarning(evt)
+	return nil
 }
 
 func (m *model) completeEventSave(message string) {
+	if m.eventWizard.editing != nil && m.eventWizard.occurrence == nil {
+		message = "Event updated"
+	}
+	if warning := m.eventWizard.warning; warning != "" {
+		message += " — ⚠ " + warning
+	}
 	m.eventWizard = newEventWizard(nil)
 	m.infoMessage = message
 	m.popState()
-	if m.state == stateAccountDetail {
+	if m.state == stateActivityEntry {
+		m.reopenActivityEntry()
+	} else if m.state == stateAccountDetail {
 		m.refreshAccountDetailAccount()
 		m.loadAccountActivity()
+	} else if m.state == stateCalendar {
+		m.refreshCalendar()
 	} else if m.state == stateMainMenu {
 		m.setMenuInput("Choose an option", 32)
 	}
@@ -2173,7 +2954,7 @@ func (m *model) completeEventSave(message string) {
 // DASHBOARD
 func (m *model) updateDashboard(msg tea.Msg) tea.Cmd {
 	var cmds []tea.Cmd
-	if focus := m.ensureMenuInput("Command (t=toggle, r=refresh, /, exit.)", 48); focus != nil {
+	if focus := m.ensureMenuInput(dashboardPrompt, 64); focus != nil {
 		cmds = append(cmds, focus)
 	}
 	var cmd tea.Cmd
@@ -2194,6 +2975,8 @@ func (m *model) updateDashboard(msg tea.Msg) tea.Cmd {
 			}
 		case "r", "refresh":
 			m.refreshDashboard(time.Now().In(m.cfg.Location()))
+		case "c", "cal", "calendar":
+			m.openCalendar()
 		case "/", "back":
 			m.popState()
 			if m.state == stateMainMenu {
@@ -2210,6 +2993,10 @@ func (m *model) updateDashboard(msg tea.Msg) tea.Cmd {
 		case "":
 			// ignore
 		default:
+			if arg, ok := cutCommand(command, "done", "x"); ok {
+				m.completeDashboardTask(arg)
+				break
+			}
 			m.errMessage = "Unknown dashboard command"
 		}
 	}
@@ -2218,40 +3005,46 @@ func (m *model) updateDashboard(msg tea.Msg) tea.Cmd {
 
 func (m *model) viewDashboard() string {
 	lines := []string{m.theme.Title.Render("Dashboard")}
-	lines = append(lines, m.theme.Faint.Render("Press t to toggle events/activity, r to refresh, '/' to go back."))
+	lines = append(lines, m.theme.Faint.Render("Press t to toggle events/activity, c for the calendar, r to refresh, 'done 2' to complete task #2, '/' to go back."))
+	lines = append(lines, "")
+	lines = append(lines, m.viewStatusSummary())
 	lines = append(lines, "")
 	if m.dashboard.view == dashboardEvents {
 		now := time.Now().In(m.cfg.Location())
 		today, upcoming, past := storage.SplitEvents(m.dashboard.events, now)
+		if len(upcoming) > 5 {
+			upcoming = upcoming[:5]
+		}
+		if len(past) > 3 {
+			past = past[:3]
+		}
+		shown := append(append(append([]storage.Event{}, today...), upcoming...), past...)
+		clashes := overlappingEvents(shown)
 		lines = append(lines, m.theme.Subtitle.Render("Today's Events"))
 		if len(today) == 0 {
 			lines = append(lines, m.theme.Faint.Render("Nothing scheduled today."))
 		}
 		for _, e := range today {
-			lines = append(lines, m.theme.Success.Render(formatEventLine(m, e)))
+			lines = append(lines, m.theme.Success.Render(formatEventLine(m, e, clashes[eventKey(e)])))
 		}
 		lines = append(lines, "")
 		lines = append(lines, m.theme.Subtitle.Render("Upcoming"))
 		if len(upcoming) == 0 {
 			lines = append(lines, m.theme.Faint.Render("No upcoming events."))
 		}
-		for i, e := range upcoming {
-			if i >= 5 {
-				break
-			}
-			lines = append(lines, m.theme.Warning.Render(formatEventLine(m, e)))
+		for _, e := range upcoming {
+			lines = append(lines, m.theme.Warning.Render(formatEventLine(m, e, clashes[eventKey(e)])))
 		}
 		lines = append(lines, "")
 		lines = append(lines, m.theme.Subtitle.Render("Recent"))
 		if len(past) == 0 {
 			lines = append(lines, m.theme.Faint.Render("No recent events."))
 		}
-		for i, e := range past {
-			if i >= 3 {
-				break
-			}
-			lines = append(lines, m.theme.Danger.Render(formatEventLine(m, e)))
+		for _, e := range past {
+			lines = append(lines, m.theme.Danger.Render(formatEventLine(m, e, clashes[eventKey(e)])))
 		}
+		lines = append(lines, "")
+		lines = append(lines, m.viewDashboardTasks(now)...)
 	} else {
 		lines = append(lines, m.theme.Subtitle.Render("Recent CRM Activity"))
 		if len(m.dashboard.activity) == 0 {
@@ -2268,6 +3061,12 @@ func (m *model) viewDashboard() string {
 				colorized = m.theme.Success.Render(item)
 			case "event":
 				colorized = m.theme.Warning.Render(item)
+			case "deal", "stage":
+				colorized = m.theme.Highlight.Render(item)
+			case "status", "merge":
+				colorized = m.theme.Subtitle.Render(item)
+			case "task":
+				colorized = m.theme.Secondary.Render(item)
 			}
 			lines = append(lines, colorized)
 		}
@@ -2281,15 +3080,38 @@ func (m *model) viewDashboard() string {
 	return strings.Join(lines, "\n") + "\n"
 }
 
-func formatEventLine(m *model, e storage.Event) string {
-	when := e.EventTime.In(m.cfg.Location()).Format("Mon Jan 02 15:04")
+// formatEventLine renders an event for the dashboard. clash marks events
+// that overlap another one by the same creator.
+func formatEventLine(m *model, e storage.Event, clash bool) string {
+	loc := m.cfg.Location()
+	when := e.EventTime.In(loc).Format("Mon Jan 02 15:04")
 	var builder strings.Builder
+	if clash {
+		builder.WriteString("⚠ ")
+	}
 	builder.WriteString(when)
+	if e.Duration > 0 {
+		end := e.End().In(loc)
+		if end.YearDay() == e.EventTime.In(loc).YearDay() && end.Year() == e.EventTime.In(loc).Year() {
+			builder.WriteString("–" + end.Format("15:04"))
+		} else {
+			builder.WriteString(" (" + formatEventDuration(e.Duration) + ")")
+		}
+	}
 	builder.WriteString(" — ")
+	if e.Recurrence != "" {
+		builder.WriteString("↻ ")
+	}
 	builder.WriteString(e.Title)
 	if e.AccountName.Valid {
 		builder.WriteString(" (" + e.AccountName.String + ")")
 	}
+	if e.Location != "" {
+		builder.WriteString(" @ " + e.Location)
+	}
+	if len(e.Attendees) > 0 {
+		builder.WriteString(" with " + attendeeNames(e.Attendees))
+	}
 	if e.Details != "" {
 		builder.WriteString(" • " + e.Details)
 	}
@@ -2304,7 +3126,7 @@ func (m *model) updateSettings(msg tea.Msg) tea.Cmd {
 	switch m.settings.mode {
 	case settingsViewing:
 		m.settings.err = ""
-		if focus := m.ensureMenuInput("1=Name  2=Timezone  3=Import CSV  4=Back", 56); focus != nil {
+		if focus := m.ensureMenuInput(settingsPrompt, 64); focus != nil {
 			cmds = append(cmds, focus)
 		}
 		var cmd tea.Cmd
@@ -2343,7 +3165,83 @@ func (m *model) updateSettings(msg tea.Msg) tea.Cmd {
 				if focus := m.settings.input.Focus(); focus != nil {
 					cmds = append(cmds, focus)
 				}
-			case "4", "back", "/":
+			case "4", "stages", "deal stages", "pipeline":
+				m.settings.mode = settingsEditingStages
+				m.settings.input = textinput.New()
+				m.settings.input.Prompt = ""
+				m.settings.input.CharLimit = 256
+				m.settings.input.Placeholder = "Comma-separated, e.g. Lead, Proposal, Won"
+				m.settings.input.SetValue(strings.Join(m.cfg.DealStages(), ", "))
+				if focus := m.settings.input.Focus(); focus != nil {
+					cmds = append(cmds, focus)
+				}
+			case "5", "retention", "trash":
+				m.settings.mode = settingsEditingRetention
+				m.settings.input = textinput.New()
+				m.settings.input.Prompt = ""
+				m.settings.input.CharLimit = 8
+				m.settings.input.Placeholder = "Days, or 'never'"
+				m.settings.input.SetValue(formatRetention(m.cfg.TrashRetention()))
+				if focus := m.settings.input.Focus(); focus != nil {
+					cmds = append(cmds, focus)
+				}
+			case "6", "fields", "custom fields":
+				if focus := m.openCustomFields(); focus != nil {
+					cmds = append(cmds, focus)
+				}
+			case "7", "statuses", "status", "lifecycle":
+				m.settings.mode = settingsEditingStatuses
+				m.settings.input = textinput.New()
+				m.settings.input.Prompt = ""
+				m.settings.input.CharLimit = 256
+				m.settings.input.Placeholder = "Comma-separated, e.g. Lead, Prospect, Customer"
+				m.settings.input.SetValue(strings.Join(m.cfg.AccountStatuses(), ", "))
+				if focus := m.settings.input.Focus(); focus != nil {
+					cmds = append(cmds, focus)
+				}
+			case "8", "audit", "audit log", "history":
+				if focus := m.openAudit(); focus != nil {
+					cmds = append(cmds, focus)
+				}
+			case "9", "export":
+				m.settings.mode = settingsExportPath
+				m.settings.input = textinput.New()
+				m.settings.input.Prompt = ""
+				m.settings.input.CharLimit = 256
+				m.settings.input.Placeholder = "Path to CSV, then options"
+				if focus := m.settings.input.Focus(); focus != nil {
+					cmds = append(cmds, focus)
+				}
+			case "10", "backup", "restore", "json":
+				m.settings.mode = settingsBackup
+				m.settings.input = textinput.New()
+				m.settings.input.Prompt = ""
+				m.settings.input.CharLimit = 256
+				m.settings.input.Placeholder = "backup ~/crmterm.json"
+				if focus := m.settings.input.Focus(); focus != nil {
+					cmds = append(cmds, focus)
+				}
+			case "11", "country", "phone", "phone country":
+				m.settings.mode = settingsEditingCountry
+				m.settings.input = textinput.New()
+				m.settings.input.Prompt = ""
+				m.settings.input.CharLimit = 8
+				m.settings.input.Placeholder = "Country code, e.g. US or GB"
+				m.settings.input.SetValue(m.cfg.PhoneCountry())
+				if focus := m.settings.input.Focus(); focus != nil {
+					cmds = append(cmds, focus)
+				}
+			case "12", "date order", "dates":
+				m.settings.mode = settingsEditingDateOrder
+				m.settings.input = textinput.New()
+				m.settings.input.Prompt = ""
+				m.settings.input.CharLimit = 16
+				m.settings.input.Placeholder = "dmy, mdy or auto"
+				m.settings.input.SetValue(m.cfg.DateOrder().String())
+				if focus := m.settings.input.Focus(); focus != nil {
+					cmds = append(cmds, focus)
+				}
+			case "0", "back", "/":
 				m.popState()
 				if m.state == stateMainMenu {
 					if focus := m.setMenuInput("Choose an option", 32); focus != nil {
@@ -2357,7 +3255,7 @@ func (m *model) updateSettings(msg tea.Msg) tea.Cmd {
 					cmds = append(cmds, focus)
 				}
 			default:
-				m.settings.err = "Choose 1-3 to edit settings"
+				m.settings.err = "Choose 1-12 from the list, or 0 to go back"
 			}
 		}
 	case settingsEditingName:
@@ -2388,6 +3286,8 @@ func (m *model) updateSettings(msg tea.Msg) tea.Cmd {
 				m.cfg.Config.Name = value
 				if err := m.cfg.Save(); err != nil {
 					m.settings.err = err.Error()
+				} else if err := m.store.SetActor(context.Background(), value); err != nil {
+					m.settings.err = err.Error()
 				} else {
 					m.settings.err = ""
 					m.infoMessage = "Name updated"
@@ -2457,27 +3357,355 @@ func (m *model) updateSettings(msg tea.Msg) tea.Cmd {
 			case isBackCommand(value):
 				m.settings.mode = settingsViewing
 			default:
-				m.handleAccountImport(value)
+				m.errMessage = ""
+				focus := m.openImportPreview(value)
+				m.settings.err = m.errMessage
+				m.errMessage = ""
+				if focus != nil {
+					m.settings.mode = settingsViewing
+					cmds = append(cmds, focus)
+				}
+			}
+		}
+	case settingsExportPath:
+		if !m.settings.input.Focused() {
+			if focus := m.settings.input.Focus(); focus != nil {
+				cmds = append(cmds, focus)
+			}
+		}
+		var cmd tea.Cmd
+		m.settings.input, cmd = m.settings.input.Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		if key, ok := msg.(tea.KeyMsg); ok && key.Type == tea.KeyEnter {
+			value := strings.TrimSpace(m.settings.input.Value())
+			switch {
+			case isExitCommand(value):
+				m.prevStates = nil
+				m.state = stateMainMenu
+				if focus := m.setMenuInput("Choose an option", 32); focus != nil {
+					cmds = append(cmds, focus)
+				}
+			case isBackCommand(value):
+				m.settings.mode = settingsViewing
+				m.settings.err = ""
+			default:
+				m.errMessage = ""
+				m.handleExport(value)
+				if m.errMessage != "" {
+					m.settings.err = m.errMessage
+					m.errMessage = ""
+					break
+				}
 				m.settings.mode = settingsViewing
 				m.settings.err = ""
 			}
 		}
+	case settingsBackup:
+		if !m.settings.input.Focused() {
+			if focus := m.settings.input.Focus(); focus != nil {
+				cmds = append(cmds, focus)
+			}
+		}
+		var cmd tea.Cmd
+		m.settings.input, cmd = m.settings.input.Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		if key, ok := msg.(tea.KeyMsg); ok && key.Type == tea.KeyEnter {
+			value := strings.TrimSpace(m.settings.input.Value())
+			switch {
+			case isExitCommand(value):
+				m.prevStates = nil
+				m.state = stateMainMenu
+				if focus := m.setMenuInput("Choose an option", 32); focus != nil {
+					cmds = append(cmds, focus)
+				}
+			case isBackCommand(value):
+				m.settings.mode = settingsViewing
+				m.settings.err = ""
+			default:
+				m.errMessage = ""
+				m.handleBackup(value)
+				// A restore can succeed and still fail to save the
+				// settings, so both messages may be set.
+				m.settings.err = m.errMessage
+				m.errMessage = ""
+				if m.infoMessage != "" {
+					m.settings.mode = settingsViewing
+				}
+			}
+		}
+	case settingsEditingStages:
+		if !m.settings.input.Focused() {
+			if focus := m.settings.input.Focus(); focus != nil {
+				cmds = append(cmds, focus)
+			}
+		}
+		var cmd tea.Cmd
+		m.settings.input, cmd = m.settings.input.Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		if key, ok := msg.(tea.KeyMsg); ok && key.Type == tea.KeyEnter {
+			value := strings.TrimSpace(m.settings.input.Value())
+			switch {
+			case isExitCommand(value):
+				m.prevStates = nil
+				m.state = stateMainMenu
+				if focus := m.setMenuInput("Choose an option", 32); focus != nil {
+					cmds = append(cmds, focus)
+				}
+			case isBackCommand(value):
+				m.settings.mode = settingsViewing
+			default:
+				stages := splitList(value, ",")
+				if len(stages) == 0 {
+					m.settings.err = "Enter at least one stage"
+					break
+				}
+				m.cfg.Config.DealStages = stages
+				if err := m.cfg.Save(); err != nil {
+					m.settings.err = err.Error()
+				} else {
+					m.settings.err = ""
+					m.infoMessage = "Deal stages updated"
+					m.settings.mode = settingsViewing
+				}
+			}
+		}
+	case settingsEditingStatuses:
+		if !m.settings.input.Focused() {
+			if focus := m.settings.input.Focus(); focus != nil {
+				cmds = append(cmds, focus)
+			}
+		}
+		var cmd tea.Cmd
+		m.settings.input, cmd = m.settings.input.Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		if key, ok := msg.(tea.KeyMsg); ok && key.Type == tea.KeyEnter {
+			value := strings.TrimSpace(m.settings.input.Value())
+			switch {
+			case isExitCommand(value):
+				m.prevStates = nil
+				m.state = stateMainMenu
+				if focus := m.setMenuInput("Choose an option", 32); focus != nil {
+					cmds = append(cmds, focus)
+				}
+			case isBackCommand(value):
+				m.settings.mode = settingsViewing
+			default:
+				statuses := splitList(value, ",")
+				if len(statuses) == 0 {
+					m.settings.err = "Enter at least one status"
+					break
+				}
+				m.cfg.Config.AccountStatuses = statuses
+				if err := m.cfg.Save(); err != nil {
+					m.settings.err = err.Error()
+				} else {
+					m.settings.err = ""
+					m.infoMessage = "Account statuses updated"
+					m.settings.mode = settingsViewing
+				}
+			}
+		}
+	case settingsEditingRetention:
+		if !m.settings.input.Focused() {
+			if focus := m.settings.input.Focus(); focus != nil {
+				cmds = append(cmds, focus)
+			}
+		}
+		var cmd tea.Cmd
+		m.settings.input, cmd = m.settings.input.Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		if key, ok := msg.(tea.KeyMsg); ok && key.Type == tea.KeyEnter {
+			value := strings.TrimSpace(strings.ToLower(m.settings.input.Value()))
+			switch {
+			case isExitCommand(value):
+				m.prevStates = nil
+				m.state = stateMainMenu
+				if focus := m.setMenuInput("Choose an option", 32); focus != nil {
+					cmds = append(cmds, focus)
+				}
+			case isBackCommand(value):
+				m.settings.mode = settingsViewing
+			default:
+				days, ok := parseRetention(value)
+				if !ok {
+					m.settings.err = "Enter a number of days, or 'never'"
+					break
+				}
+				m.cfg.Config.TrashRetentionDays = days
+				if err := m.cfg.Save(); err != nil {
+					m.settings.err = err.Error()
+				} else {
+					m.settings.err = ""
+					m.infoMessage = "Trash retention updated"
+					m.settings.mode = settingsViewing
+				}
+			}
+		}
+	case settingsEditingCountry:
+		if !m.settings.input.Focused() {
+			if focus := m.settings.input.Focus(); focus != nil {
+				cmds = append(cmds, focus)
+			}
+		}
+		var cmd tea.Cmd
+		m.settings.input, cmd = m.settings.input.Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		if key, ok := msg.(tea.KeyMsg); ok && key.Type == tea.KeyEnter {
+			value := strings.TrimSpace(strings.ToUpper(m.settings.input.Value()))
+			switch {
+			case isExitCommand(value):
+				m.prevStates = nil
+				m.state = stateMainMenu
+				if focus := m.setMenuInput("Choose an option", 32); focus != nil {
+					cmds = append(cmds, focus)
+				}
+			case isBackCommand(value):
+				m.settings.mode = settingsViewing
+			case !validate.KnownCountry(value):
+				m.settings.err = "Use one of " + strings.Join(validate.Countries(), ", ")
+			default:
+				m.cfg.Config.PhoneCountry = value
+				if err := m.cfg.Save(); err != nil {
+					m.settings.err = err.Error()
+				} else {
+					m.settings.err = ""
+					m.infoMessage = "Phone country updated"
+					m.settings.mode = settingsViewing
+				}
+			}
+		}
+	case settingsEditingDateOrder:
+		if !m.settings.input.Focused() {
+			if focus := m.settings.input.Focus(); focus != nil {
+				cmds = append(cmds, focus)
+			}
+		}
+		var cmd tea.Cmd
+		m.settings.input, cmd = m.settings.input.Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		if key, ok := msg.(tea.KeyMsg); ok && key.Type == tea.KeyEnter {
+			value := strings.TrimSpace(m.settings.input.Value())
+			order, orderErr := dateparse.OrderNamed(value)
+			switch {
+			case isExitCommand(value):
+				m.prevStates = nil
+				m.state = stateMainMenu
+				if focus := m.setMenuInput("Choose an option", 32); focus != nil {
+					cmds = append(cmds, focus)
+				}
+			case isBackCommand(value):
+				m.settings.mode = settingsViewing
+			case orderErr != nil:
+				m.settings.err = orderErr.Error()
+			default:
+				m.cfg.Config.DateOrder = ""
+				if order != dateparse.OrderAuto {
+					m.cfg.Config.DateOrder = order.String()
+				}
+				if err := m.cfg.Save(); err != nil {
+					m.settings.err = err.Error()
+				} else {
+					m.settings.err = ""
+					m.infoMessage = "Date order updated"
+					m.settings.mode = settingsViewing
+				}
+			}
+		}
 	}
 	return batchCmds(cmds)
 }
 
+// settingsPrompt lists the settings entries. Back keeps the key 0 so it
+// stays put as entries are added above it.
+const settingsPrompt = "1=Name  2=Timezone  3=Import CSV  4=Stages  5=Retention  6=Fields  7=Statuses  8=Audit  9=Export CSV  10=Backup  11=Phone country  12=Date order  0=Back"
+
+// parseRetention reads a trash retention answer as days; "never" keeps
+// deleted items until they are purged by hand.
+func parseRetention(value string) (int, bool) {
+	switch value {
+	case "never", "off", "0":
+		return -1, true
+	}
+	days, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSuffix(value, "days"), "d"))
+	if err != nil || days <= 0 {
+		return 0, false
+	}
+	return days, true
+}
+
+// dateOrderLabel describes how numeric dates are read, for the settings
+// summary.
+func dateOrderLabel(order dateparse.Order) string {
+	switch order {
+	case dateparse.OrderDayFirst:
+		return "day first (dmy)"
+	case dateparse.OrderMonthFirst:
+		return "month first (mdy)"
+	}
+	return "auto, from the timezone"
+}
+
+func formatRetention(d time.Duration) string {
+	if d <= 0 {
+		return "never"
+	}
+	return strconv.Itoa(int(d.Hours() / 24))
+}
+
+// splitList breaks a delimited answer into trimmed, non-empty, de-duplicated items.
+func splitList(value, sep string) []string {
+	var items []string
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, sep) {
+		part = strings.TrimSpace(part)
+		key := strings.ToLower(part)
+		if part == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		items = append(items, part)
+	}
+	return items
+}
+
 func (m *model) viewSettings() string {
 	lines := []string{m.theme.Title.Render("Settings & Help")}
 	lines = append(lines, m.theme.Faint.Render("'/' goes back, 'exit.' returns home."))
 	lines = append(lines, "")
 	lines = append(lines, m.theme.Secondary.Render("Name: "+m.cfg.Config.Name))
 	lines = append(lines, m.theme.Secondary.Render("Timezone: "+m.cfg.Config.Timezone))
+	lines = append(lines, m.theme.Secondary.Render("Deal stages: "+strings.Join(m.cfg.DealStages(), " → ")))
+	lines = append(lines, m.theme.Secondary.Render("Account statuses: "+strings.Join(m.cfg.AccountStatuses(), " → ")))
+	retention := formatRetention(m.cfg.TrashRetention())
+	if retention != "never" {
+		retention += " days"
+	}
+	lines = append(lines, m.theme.Secondary.Render("Trash retention: "+retention))
+	lines = append(lines, m.theme.Secondary.Render("Phone country: "+m.cfg.PhoneCountry()))
+	lines = append(lines, m.theme.Secondary.Render("Date order: "+dateOrderLabel(m.cfg.DateOrder())))
+	lines = append(lines, m.theme.Secondary.Render("Search: "+searchModeLabel(m.store.SearchEnabled())))
 	lines = append(lines, "")
 	lines = append(lines, m.theme.Highlight.Render("Shortcuts"))
 	lines = append(lines, m.theme.HelpKey.Render("/")+" → "+m.theme.HelpValue.Render("Back"))
 	lines = append(lines, m.theme.HelpKey.Render("exit.")+" → "+m.theme.HelpValue.Render("Main menu"))
 	lines = append(lines, m.theme.HelpKey.Render("Ctrl+C")+" → "+m.theme.HelpValue.Render("Quit"))
 	lines = append(lines, m.theme.HelpKey.Render("Ctrl+D")+" → "+m.theme.HelpValue.Render("Debug"))
+	lines = append(lines, m.theme.HelpKey.Render("Ctrl+F")+" → "+m.theme.HelpValue.Render("Search everything"))
+	lines = append(lines, m.theme.HelpKey.Render("Ctrl+Z")+" → "+m.theme.HelpValue.Render("Undo last change"))
 	lines = append(lines, "")
 	lines = append(lines, m.theme.Highlight.Render("Repo"))
 	lines = append(lines, m.theme.Primary.Render("github.com/Azteriisk/CRM-Term"))
@@ -2489,7 +3717,16 @@ func (m *model) viewSettings() string {
 		lines = append(lines, m.theme.Secondary.Render("1. Update name"))
 		lines = append(lines, m.theme.Secondary.Render("2. Update timezone"))
 		lines = append(lines, m.theme.Secondary.Render("3. Import accounts from CSV"))
-		lines = append(lines, m.theme.Faint.Render("4. Back"))
+		lines = append(lines, m.theme.Secondary.Render("4. Edit deal stages"))
+		lines = append(lines, m.theme.Secondary.Render("5. Set trash retention"))
+		lines = append(lines, m.theme.Secondary.Render("6. Manage custom account fields"))
+		lines = append(lines, m.theme.Secondary.Render("7. Edit account statuses"))
+		lines = append(lines, m.theme.Secondary.Render("8. Audit log"))
+		lines = append(lines, m.theme.Secondary.Render("9. Export data to CSV"))
+		lines = append(lines, m.theme.Secondary.Render("10. Back up or restore (JSON)"))
+		lines = append(lines, m.theme.Secondary.Render("11. Set default phone country"))
+		lines = append(lines, m.theme.Secondary.Render("12. Set date order"))
+		lines = append(lines, m.theme.Faint.Render("0. Back"))
 		lines = append(lines, "")
 		lines = append(lines, m.theme.Accent.Render("> ")+m.menuInput.View())
 	case settingsEditingName:
@@ -2501,6 +3738,29 @@ func (m *model) viewSettings() string {
 	case settingsImportPath:
 		lines = append(lines, m.theme.Secondary.Render("Enter CSV path:"))
 		lines = append(lines, m.settings.input.View())
+	case settingsExportPath:
+		lines = append(lines, m.theme.Secondary.Render("Enter export path and options:"))
+		lines = append(lines, m.theme.Faint.Render(exportUsage))
+		lines = append(lines, m.settings.input.View())
+	case settingsBackup:
+		lines = append(lines, m.theme.Secondary.Render("Back up or restore everything, settings included:"))
+		lines = append(lines, m.theme.Faint.Render("Type "+backupUsage+"."))
+		lines = append(lines, m.settings.input.View())
+	case settingsEditingStages:
+		lines = append(lines, m.theme.Secondary.Render("Enter deal stages in pipeline order, separated by commas:"))
+		lines = append(lines, m.settings.input.View())
+	case settingsEditingStatuses:
+		lines = append(lines, m.theme.Secondary.Render("Enter account statuses in lifecycle order, separated by commas:"))
+		lines = append(lines, m.settings.input.View())
+	case settingsEditingRetention:
+		lines = append(lines, m.theme.Secondary.Render("Days to keep deleted items before purging ('never' to keep them):"))
+		lines = append(lines, m.settings.input.View())
+	case settingsEditingCountry:
+		lines = append(lines, m.theme.Secondary.Render("Country phone numbers without a country code are read in (ISO code, e.g. US or GB):"))
+		lines = append(lines, m.settings.input.View())
+	case settingsEditingDateOrder:
+		lines = append(lines, m.theme.Secondary.Render("How to read dates like 03/04/2026: dmy (3 April), mdy (March 4) or auto (from the timezone):"))
+		lines = append(lines, m.settings.input.View())
 	}
 	if m.settings.err != "" {
 		lines = append(lines, "", m.theme.Danger.Render(m.settings.err))
@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"context"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"crmterm/internal/config"
+	"crmterm/internal/storage"
+)
+
+// newTestModel opens a model over a fresh store and config under a
+// temporary config directory.
+func newTestModel(t *testing.T) *model {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	store, err := storage.Open(context.Background())
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	m := newModel(store, cfg)
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	return m
+}
+
+// typeLine types value into the focused input and presses Enter.
+func typeLine(m *model, value string) {
+	for _, r := range value {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+}
+
+func TestAccountsSelectByTrailingIndex(t *testing.T) {
+	m := newTestModel(t)
+	ctx := context.Background()
+	for _, a := range []storage.Account{
+		{Name: "Acme East", Phone: "+12125550100", Creator: "tester"},
+		{Name: "Acme West", Phone: "+13105550100", Creator: "tester"},
+		{Name: "Place3", Creator: "tester"},
+	} {
+		if err := m.store.CreateAccount(ctx, &a); err != nil {
+			t.Fatalf("create account: %v", err)
+		}
+	}
+	tests := []struct{ in, want string }{
+		{"acme 2", "Acme West"},
+		{"acme 1", "Acme East"},
+		{"2", "Acme West"},
+		{"plc3", "Place3"},
+	}
+	for _, tt := range tests {
+		typeLine(m, "accounts")
+		m.accountFilter.SetValue("")
+		typeLine(m, tt.in)
+		if m.state != stateAccountDetail {
+			t.Errorf("%q stayed on screen %v: %s", tt.in, m.state, m.errMessage)
+		} else if got := m.accountDetail.account.Name; got != tt.want {
+			t.Errorf("%q opened %s, want %s", tt.in, got, tt.want)
+		}
+		typeLine(m, "exit.")
+	}
+}
@@ -0,0 +1,248 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"crmterm/internal/storage"
+)
+
+const (
+	boardDefaultWidth  = 80
+	boardDefaultHeight = 24
+	boardMinColumn     = 22
+	boardCardLines     = 3
+	boardChromeLines   = 9
+)
+
+type boardModel struct {
+	col int
+	row int
+}
+
+func (m *model) openPipelineBoard() {
+	m.refreshDeals()
+	m.board = boardModel{}
+	m.clampBoardFocus()
+	m.pushState(statePipelineBoard)
+}
+
+func (m *model) clampBoardFocus() {
+	groups := m.deals.groups
+	if len(groups) == 0 {
+		m.board = boardModel{}
+		return
+	}
+	if m.board.col >= len(groups) {
+		m.board.col = len(groups) - 1
+	}
+	if m.board.col < 0 {
+		m.board.col = 0
+	}
+	count := len(groups[m.board.col].Deals)
+	if m.board.row >= count {
+		m.board.row = count - 1
+	}
+	if m.board.row < 0 {
+		m.board.row = 0
+	}
+}
+
+func (m *model) focusedDeal() (storage.Deal, bool) {
+	if m.board.col >= len(m.deals.groups) {
+		return storage.Deal{}, false
+	}
+	deals := m.deals.groups[m.board.col].Deals
+	if m.board.row >= len(deals) {
+		return storage.Deal{}, false
+	}
+	return deals[m.board.row], true
+}
+
+// PIPELINE BOARD
+func (m *model) updatePipelineBoard(msg tea.Msg) tea.Cmd {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+	switch key.String() {
+	case "left", "h":
+		m.board.col--
+		m.clampBoardFocus()
+	case "right", "l":
+		m.board.col++
+		m.clampBoardFocus()
+	case "up", "k":
+		m.board.row--
+		m.clampBoardFocus()
+	case "down", "j":
+		m.board.row++
+		m.clampBoardFocus()
+	case ">", "L", "shift+right", "]":
+		m.shiftFocusedDeal(1)
+	case "<", "H", "shift+left", "[":
+		m.shiftFocusedDeal(-1)
+	case "r":
+		m.refreshDeals()
+		m.clampBoardFocus()
+	case "esc", "q", "/":
+		m.popState()
+		switch m.state {
+		case stateMainMenu:
+			return m.setMenuInput("Choose an option", 32)
+		case stateDeals:
+			m.refreshDeals()
+			return m.setMenuInput(dealsPrompt, 64)
+		}
+	}
+	return nil
+}
+
+// shiftFocusedDeal moves the focused deal to the neighbouring stage and keeps
+// focus on it.
+func (m *model) shiftFocusedDeal(delta int) {
+	deal, ok := m.focusedDeal()
+	if !ok {
+		return
+	}
+	target := m.board.col + delta
+	if target < 0 || target >= len(m.deals.groups) {
+		return
+	}
+	m.deals.err = ""
+	m.moveDeal(deal, m.deals.groups[target].Stage)
+	if m.deals.err != "" {
+		return
+	}
+	m.board.col = target
+	for i, d := range m.deals.groups[target].Deals {
+		if d.ID == deal.ID {
+			m.board.row = i
+		}
+	}
+	m.clampBoardFocus()
+}
+
+// boardWindow picks which columns fit on screen, keeping the focused one visible.
+func boardWindow(total, focus, width int) (start, end, colWidth int) {
+	if total == 0 {
+		return 0, 0, width
+	}
+	visible := width / boardMinColumn
+	if visible < 1 {
+		visible = 1
+	}
+	if visible > total {
+		visible = total
+	}
+	start = focus - visible/2
+	if start < 0 {
+		start = 0
+	}
+	if start+visible > total {
+		start = total - visible
+	}
+	return start, start + visible, width / visible
+}
+
+func (m *model) viewPipelineBoard() string {
+	width := m.width
+	if width <= 0 {
+		width = boardDefaultWidth
+	}
+	height := m.height
+	if height <= 0 {
+		height = boardDefaultHeight
+	}
+	lines := []string{m.theme.Title.Render("Pipeline Board")}
+	lines = append(lines, m.theme.Faint.Render("←/→ or h/l change stage, ↑/↓ or j/k pick a deal, >/< move it, r refresh, esc back."))
+	lines = append(lines, "")
+
+	groups := m.deals.groups
+	start, end, colWidth := boardWindow(len(groups), m.board.col, width)
+	// Border and padding take four cells from every column.
+	inner := colWidth - 4
+	if inner < 8 {
+		inner = 8
+	}
+	maxCards := (height - boardChromeLines) / boardCardLines
+	if maxCards < 1 {
+		maxCards = 1
+	}
+
+	bodies := make([][]string, 0, end-start)
+	tallest := 0
+	for i := start; i < end; i++ {
+		g := groups[i]
+		body := []string{
+			m.theme.Subtitle.Render(truncateText(g.Stage, inner)),
+			m.theme.Faint.Render(truncateText(fmt.Sprintf("%d • %s", len(g.Deals), storage.FormatTotals(g.Totals)), inner)),
+			m.theme.Border.Render(strings.Repeat("─", inner)),
+		}
+		first := 0
+		if i == m.board.col && m.board.row >= maxCards {
+			first = m.board.row - maxCards + 1
+		}
+		if first > 0 {
+			body = append(body, m.theme.Faint.Render(fmt.Sprintf("↑ %d more", first)))
+		}
+		for j := first; j < len(g.Deals) && j < first+maxCards; j++ {
+			d := g.Deals[j]
+			title := truncateText(d.Title, inner)
+			meta := truncateText(fmt.Sprintf("%s • %s", d.AccountName, storage.FormatAmount(d.AmountCents, d.Currency)), inner)
+			if i == m.board.col && j == m.board.row {
+				body = append(body, m.theme.Highlight.Render("▶ "+truncateText(d.Title, inner-2)), m.theme.Secondary.Render(meta), "")
+				continue
+			}
+			body = append(body, m.theme.Primary.Render(title), m.theme.Faint.Render(meta), "")
+		}
+		if rest := len(g.Deals) - first - maxCards; rest > 0 {
+			body = append(body, m.theme.Faint.Render(fmt.Sprintf("↓ %d more", rest)))
+		}
+		if len(body) > tallest {
+			tallest = len(body)
+		}
+		bodies = append(bodies, body)
+	}
+	columns := make([]string, 0, len(bodies))
+	for i, body := range bodies {
+		style := m.theme.Panel
+		if start+i == m.board.col {
+			style = m.theme.Focus
+		}
+		columns = append(columns, style.Copy().Width(colWidth-2).Height(tallest).Render(strings.Join(body, "\n")))
+	}
+	if len(columns) == 0 {
+		lines = append(lines, m.theme.Warning.Render("No stages configured."))
+	} else {
+		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Top, columns...))
+	}
+	if start > 0 || end < len(groups) {
+		lines = append(lines, m.theme.Faint.Render(fmt.Sprintf("Stages %d-%d of %d", start+1, end, len(groups))))
+	}
+	if m.deals.err != "" {
+		lines = append(lines, m.theme.Danger.Render(m.deals.err))
+	}
+	if m.infoMessage != "" {
+		lines = append(lines, m.theme.Success.Render(m.infoMessage))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// truncateText shortens s to at most width cells, marking the cut with an ellipsis.
+func truncateText(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"crmterm/internal/storage"
+)
+
+func TestBoardWindow(t *testing.T) {
+	tests := []struct {
+		total, focus, width int
+		start, end, col     int
+	}{
+		{total: 0, focus: 0, width: 80, start: 0, end: 0, col: 80},
+		{total: 3, focus: 0, width: 120, start: 0, end: 3, col: 40},
+		{total: 6, focus: 0, width: 80, start: 0, end: 3, col: 26},
+		{total: 6, focus: 3, width: 80, start: 2, end: 5, col: 26},
+		{total: 6, focus: 5, width: 80, start: 3, end: 6, col: 26},
+		{total: 6, focus: 2, width: 10, start: 2, end: 3, col: 10},
+	}
+	for _, tt := range tests {
+		start, end, col := boardWindow(tt.total, tt.focus, tt.width)
+		if start != tt.start || end != tt.end || col != tt.col {
+			t.Errorf("boardWindow(%d, %d, %d) = %d, %d, %d; want %d, %d, %d",
+				tt.total, tt.focus, tt.width, start, end, col, tt.start, tt.end, tt.col)
+		}
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		want  string
+	}{
+		{"Renewal", 10, "Renewal"},
+		{"Renewal", 7, "Renewal"},
+		{"Renewal", 5, "Rene…"},
+		{"Renewal", 0, ""},
+	}
+	for _, tt := range tests {
+		if got := truncateText(tt.in, tt.width); got != tt.want {
+			t.Errorf("truncateText(%q, %d) = %q, want %q", tt.in, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestPipelineBoardMovesFocusedDeal(t *testing.T) {
+	m := newTestModel(t)
+	ctx := context.Background()
+	account := storage.Account{Name: "Acme", Creator: "tester"}
+	if err := m.store.CreateAccount(ctx, &account); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	for _, title := range []string{"Pilot", "Renewal"} {
+		d := storage.Deal{AccountID: account.ID, Title: title, Stage: "Lead", Currency: "USD", AmountCents: 100000, Creator: "tester"}
+		if err := m.store.CreateDeal(ctx, &d); err != nil {
+			t.Fatalf("create deal: %v", err)
+		}
+	}
+	m.openPipelineBoard()
+	press := func(keys string) {
+		for _, r := range keys {
+			m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+	}
+
+	press("j")
+	deal, ok := m.focusedDeal()
+	if !ok {
+		t.Fatalf("no deal focused on the board")
+	}
+	press(">")
+	if m.board.col != 1 || m.board.row != 0 {
+		t.Errorf("focus = column %d row %d, want it to follow the deal to column 1", m.board.col, m.board.row)
+	}
+	moved, err := m.store.DealByID(ctx, deal.ID)
+	if err != nil {
+		t.Fatalf("load deal: %v", err)
+	}
+	if moved.Stage != m.cfg.DealStages()[1] {
+		t.Errorf("%s is in %s, want %s", deal.Title, moved.Stage, m.cfg.DealStages()[1])
+	}
+	if got := len(m.deals.groups[0].Deals); got != 1 {
+		t.Errorf("first column has %d deals, want 1", got)
+	}
+
+	// Focus stays inside the board and deals can't move past the ends.
+	press("hhhh<")
+	if m.board.col != 0 {
+		t.Errorf("focus column = %d after moving left past the edge, want 0", m.board.col)
+	}
+	if got := len(m.deals.groups[0].Deals); got != 1 {
+		t.Errorf("first column has %d deals after '<' at the edge, want 1", got)
+	}
+	if view := m.viewPipelineBoard(); !strings.Contains(view, "1 • USD 1,000.00") {
+		t.Errorf("board does not show the column count and total:\n%s", view)
+	}
+}
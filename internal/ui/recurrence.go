@@ -0,0 +1,227 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"crmterm/internal/dateparse"
+	"crmterm/internal/storage"
+)
+
+// entryOccurrences is how many upcoming occurrences a recurring event's
+// entry screen lists.
+const entryOccurrences = 5
+
+var repeatFreqs = map[string]string{
+	"daily": storage.FreqDaily, "day": storage.FreqDaily, "days": storage.FreqDaily,
+	"weekly": storage.FreqWeekly, "week": storage.FreqWeekly, "weeks": storage.FreqWeekly,
+	"monthly": storage.FreqMonthly, "month": storage.FreqMonthly, "months": storage.FreqMonthly,
+	"yearly": storage.FreqYearly, "annually": storage.FreqYearly, "year": storage.FreqYearly, "years": storage.FreqYearly,
+}
+
+var repeatFreqNames = map[string][2]string{
+	storage.FreqDaily:   {"daily", "days"},
+	storage.FreqWeekly:  {"weekly", "weeks"},
+	storage.FreqMonthly: {"monthly", "months"},
+	storage.FreqYearly:  {"yearly", "years"},
+}
+
+// parseRepeat reads the repeat step of the event wizard and returns the
+// RRULE to store, or "" for a one-off event. It accepts phrases such as
+// "weekly", "every 2 weeks on mon,thu", "monthly until 2026-12-31",
+// "monthly until dec 31", "daily 10 times" and "weekdays", or a raw RRULE.
+func parseRepeat(value string, loc *time.Location, order dateparse.Order) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "", "n", "no", "none", "never":
+		return "", nil
+	}
+	if strings.HasPrefix(value, "freq=") || strings.HasPrefix(value, "rrule:") {
+		rule, err := storage.ParseRecurrence(strings.ToUpper(value))
+		if err != nil {
+			return "", fmt.Errorf("Invalid rule: %v", err)
+		}
+		return rule.String(), nil
+	}
+	var rule storage.Recurrence
+	words := strings.FieldsFunc(value, func(r rune) bool { return r == ' ' || r == ',' })
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		next := ""
+		if i+1 < len(words) {
+			next = words[i+1]
+		}
+		switch {
+		case word == "every" || word == "on" || word == "and" || word == "repeat" || word == "repeats":
+		case word == "weekdays":
+			rule.Freq = storage.FreqWeekly
+			rule.ByDay = append(rule.ByDay, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+		case repeatFreqs[word] != "":
+			rule.Freq = repeatFreqs[word]
+		case word == "until" || word == "till":
+			end := repeatPhraseEnd(words, i+1)
+			r, err := dateparse.ParseWithOrder(strings.Join(words[i+1:end], " "), time.Now().In(loc), order)
+			if err != nil {
+				return "", fmt.Errorf("End dates look like 'until 2026-12-31' or 'until dec 31'")
+			}
+			rule.Until = r.Time.AddDate(0, 0, 1).Add(-time.Second)
+			i = end - 1
+		case word == "count" || word == "for":
+			n, err := strconv.Atoi(next)
+			if err != nil || n <= 0 {
+				return "", fmt.Errorf("Counts look like '10 times'")
+			}
+			rule.Count = n
+			i++
+		case word == "times" || word == "x":
+		default:
+			if day, ok := parseWeekday(word); ok {
+				rule.ByDay = append(rule.ByDay, day)
+				break
+			}
+			if n, err := strconv.Atoi(strings.TrimPrefix(word, "x")); err == nil && n > 0 {
+				// "every 2 weeks" sets the interval, "10 times" the count.
+				switch {
+				case next == "times" || next == "x" || strings.HasPrefix(word, "x"):
+					rule.Count = n
+				case repeatFreqs[next] != "":
+					rule.Interval = n
+				default:
+					return "", fmt.Errorf("Didn't understand '%s'; say 'every %s weeks' or '%s times'", word, word, word)
+				}
+				break
+			}
+			return "", fmt.Errorf("Didn't understand '%s'; try 'weekly on mon,thu' or 'every 2 months'", word)
+		}
+	}
+	if rule.Freq == "" {
+		if len(rule.ByDay) == 0 {
+			return "", fmt.Errorf("Say how often: daily, weekly, monthly or yearly")
+		}
+		rule.Freq = storage.FreqWeekly
+	}
+	rule, err := storage.ParseRecurrence(rule.String())
+	if err != nil {
+		return "", fmt.Errorf("Invalid repeat: %v", err)
+	}
+	return rule.String(), nil
+}
+
+// repeatPhraseEnd returns the index just past the date phrase starting at
+// words[start], which runs up to the next repeat keyword so that
+// "until next friday" and "until dec 31 10 times" both read the whole date.
+func repeatPhraseEnd(words []string, start int) int {
+	for j := start; j < len(words); j++ {
+		switch word := words[j]; {
+		case word == "every" || word == "on" || word == "count" || word == "for" || word == "times" || word == "weekdays":
+			return j
+		case repeatFreqs[word] != "":
+			return j
+		case j+1 < len(words) && (words[j+1] == "times" || words[j+1] == "x"):
+			return j
+		}
+	}
+	return len(words)
+}
+
+// parseWeekday reads "mon", "monday" or "mondays".
+func parseWeekday(word string) (time.Weekday, bool) {
+	if len(word) < 3 {
+		return 0, false
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.HasPrefix(strings.ToLower(day.String())+"s", word) {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// formatRepeat renders a rule the way parseRepeat reads it, e.g.
+// "every 2 weeks on mon,thu until 2026-12-31".
+func formatRepeat(rule storage.Recurrence, loc *time.Location) string {
+	names := repeatFreqNames[rule.Freq]
+	text := names[0]
+	if rule.Interval > 1 {
+		text = fmt.Sprintf("every %d %s", rule.Interval, names[1])
+	}
+	if len(rule.ByDay) > 0 {
+		days := make([]string, len(rule.ByDay))
+		for i, d := range rule.ByDay {
+			days[i] = strings.ToLower(d.String()[:3])
+		}
+		text += " on " + strings.Join(days, ",")
+	}
+	if !rule.Until.IsZero() {
+		text += " until " + rule.Until.In(loc).Format("2006-01-02")
+	}
+	if rule.Count > 0 {
+		text += fmt.Sprintf(" %d times", rule.Count)
+	}
+	return text
+}
+
+// loadEntryOccurrences lists the next occurrences of the entry's event when
+// it repeats.
+func (m *model) loadEntryOccurrences() {
+	m.entry.occurrences = nil
+	if m.entry.event == nil || m.entry.event.Recurrence == "" {
+		return
+	}
+	loc := m.cfg.Location()
+	now := time.Now().In(loc)
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	occurrences := m.entry.event.Occurrences(from, from.AddDate(1, 0, 0), loc)
+	if len(occurrences) > entryOccurrences {
+		occurrences = occurrences[:entryOccurrences]
+	}
+	m.entry.occurrences = occurrences
+}
+
+// entryOccurrence resolves "2" to the entry's second listed occurrence.
+func (m *model) entryOccurrence(arg string) (storage.Event, bool) {
+	idx, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(arg), "#"))
+	if err != nil || idx <= 0 || idx > len(m.entry.occurrences) {
+		m.entry.err = "Invalid occurrence number"
+		return storage.Event{}, false
+	}
+	return m.entry.occurrences[idx-1], true
+}
+
+// saveOccurrence stores the wizard's values as an exception for one
+// occurrence. Only what differs from the series is kept, so later edits to
+// the series still reach the occurrence.
+func (m *model) saveOccurrence(series, occ storage.Event, title, details string, at time.Time) error {
+	ex := storage.EventException{Occurrence: occ.Occurrence}
+	if title != series.Title {
+		ex.Title = title
+	}
+	if details != series.Details {
+		ex.Details = details
+	}
+	if !at.Equal(occ.Occurrence) {
+		ex.EventTime = at
+	}
+	undo := m.snapshotRecord("event", series.ID)
+	if err := m.store.SetEventOccurrence(context.Background(), series.ID, ex); err != nil {
+		return err
+	}
+	m.pushUndo(fmt.Sprintf("edit of one '%s' occurrence", series.Title), undo)
+	return nil
+}
+
+// cancelOccurrence drops the pending occurrence from its series.
+func (m *model) cancelOccurrence() error {
+	occ := m.entry.cancelling
+	m.entry.cancelling = nil
+	undo := m.snapshotRecord("event", occ.ID)
+	if err := m.store.CancelEventOccurrence(context.Background(), occ.ID, occ.Occurrence); err != nil {
+		return fmt.Errorf("cancel occurrence: %w", err)
+	}
+	m.pushUndo(fmt.Sprintf("cancellation of '%s' on %s", occ.Title, occ.Occurrence.In(m.cfg.Location()).Format("Jan 02")), undo)
+	m.infoMessage = fmt.Sprintf("Cancelled '%s' on %s", occ.Title, occ.Occurrence.In(m.cfg.Location()).Format("Mon Jan 02 15:04"))
+	return nil
+}
@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"crmterm/internal/dateparse"
+	"crmterm/internal/storage"
+)
+
+func TestParseRepeat(t *testing.T) {
+	loc := time.UTC
+	tests := []struct {
+		in       string
+		freq     string
+		interval int
+		count    int
+		days     int
+		// until is the end date phrase, resolved with dateparse.
+		until   string
+		wantErr bool
+	}{
+		{in: "", freq: ""},
+		{in: "weekly", freq: storage.FreqWeekly},
+		{in: "every 2 weeks on mon,thu", freq: storage.FreqWeekly, interval: 2, days: 2},
+		{in: "daily 10 times", freq: storage.FreqDaily, count: 10},
+		{in: "weekdays for 5", freq: storage.FreqWeekly, days: 5, count: 5},
+		{in: "monthly until 2026-12-31", freq: storage.FreqMonthly, until: "2026-12-31"},
+		{in: "monthly until dec 31", freq: storage.FreqMonthly, until: "dec 31"},
+		{in: "weekly until next friday", freq: storage.FreqWeekly, until: "next friday"},
+		{in: "until dec 31 every 2 weeks", freq: storage.FreqWeekly, interval: 2, until: "dec 31"},
+		{in: "weekly until dec 31 on mon", freq: storage.FreqWeekly, days: 1, until: "dec 31"},
+		{in: "freq=weekly;byday=mo", freq: storage.FreqWeekly, days: 1},
+
+		{in: "monthly 31", wantErr: true},
+		{in: "monthly until", wantErr: true},
+		{in: "monthly until someday", wantErr: true},
+		{in: "sometimes", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseRepeat(tt.in, loc, dateparse.OrderAuto)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseRepeat(%q) = %q, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseRepeat(%q): %v", tt.in, err)
+			continue
+		}
+		if tt.freq == "" {
+			if got != "" {
+				t.Errorf("parseRepeat(%q) = %q, want a one-off", tt.in, got)
+			}
+			continue
+		}
+		rule, err := storage.ParseRecurrence(got)
+		if err != nil {
+			t.Errorf("parseRepeat(%q) = %q: %v", tt.in, got, err)
+			continue
+		}
+		interval := rule.Interval
+		if interval == 1 {
+			interval = 0
+		}
+		if rule.Freq != tt.freq || interval != tt.interval || rule.Count != tt.count || len(rule.ByDay) != tt.days {
+			t.Errorf("parseRepeat(%q) = %q", tt.in, got)
+		}
+		wantUntil := ""
+		if tt.until != "" {
+			r, err := dateparse.Parse(tt.until, time.Now().In(loc))
+			if err != nil {
+				t.Fatalf("resolve %q: %v", tt.until, err)
+			}
+			wantUntil = r.Time.Format("2006-01-02")
+		}
+		gotUntil := ""
+		if !rule.Until.IsZero() {
+			gotUntil = rule.Until.In(loc).Format("2006-01-02")
+		}
+		if gotUntil != wantUntil {
+			t.Errorf("parseRepeat(%q) ends %q, want %q", tt.in, gotUntil, wantUntil)
+		}
+	}
+}
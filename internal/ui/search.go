@@ -0,0 +1,204 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"crmterm/internal/storage"
+)
+
+type searchModel struct {
+	input    textinput.Model
+	query    string
+	results  []storage.SearchResult
+	searched bool
+	err      string
+}
+
+func newSearchModel() searchModel {
+	ti := textinput.New()
+	ti.Prompt = ""
+	ti.Placeholder = "Words to find, or a result number to open"
+	ti.CharLimit = 128
+	return searchModel{input: ti}
+}
+
+// openSearch shows the global search screen from any state, keeping the
+// previous query so Ctrl+F returns to the last results.
+func (m *model) openSearch() tea.Cmd {
+	m.infoMessage = ""
+	m.errMessage = ""
+	m.search.err = ""
+	m.search.input.SetValue("")
+	if m.state != stateSearch {
+		m.pushState(stateSearch)
+	}
+	return m.search.input.Focus()
+}
+
+func (m *model) runSearch(query string) {
+	results, err := m.store.Search(context.Background(), query)
+	if err != nil {
+		m.search.err = fmt.Sprintf("search: %v", err)
+		return
+	}
+	m.search.query = query
+	m.search.results = results
+	m.search.searched = true
+	m.search.err = ""
+}
+
+// SEARCH
+func (m *model) updateSearch(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+	if !m.search.input.Focused() {
+		if focus := m.search.input.Focus(); focus != nil {
+			cmds = append(cmds, focus)
+		}
+	}
+	var cmd tea.Cmd
+	m.search.input, cmd = m.search.input.Update(msg)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return batchCmds(cmds)
+	}
+	switch key.Type {
+	case tea.KeyEsc:
+		if focus := m.closeSearch(); focus != nil {
+			cmds = append(cmds, focus)
+		}
+	case tea.KeyEnter:
+		value := strings.TrimSpace(m.search.input.Value())
+		m.search.input.SetValue("")
+		switch {
+		case isExitCommand(value):
+			m.prevStates = nil
+			m.state = stateMainMenu
+			if focus := m.setMenuInput("Choose an option", 32); focus != nil {
+				cmds = append(cmds, focus)
+			}
+		case isBackCommand(value):
+			if focus := m.closeSearch(); focus != nil {
+				cmds = append(cmds, focus)
+			}
+		case value == "":
+		default:
+			if idx, err := strconv.Atoi(value); err == nil && len(m.search.results) > 0 {
+				if idx <= 0 || idx > len(m.search.results) {
+					m.search.err = "Invalid result number"
+					break
+				}
+				if focus := m.openSearchResult(m.search.results[idx-1]); focus != nil {
+					cmds = append(cmds, focus)
+				}
+				break
+			}
+			m.runSearch(value)
+		}
+	}
+	return batchCmds(cmds)
+}
+
+func (m *model) closeSearch() tea.Cmd {
+	m.search.input.Blur()
+	m.popState()
+	switch m.state {
+	case stateMainMenu:
+		return m.setMenuInput("Choose an option", 32)
+	case stateAccounts:
+		return m.accountFilter.Focus()
+	}
+	return nil
+}
+
+func (m *model) openSearchResult(r storage.SearchResult) tea.Cmd {
+	m.search.err = ""
+	if r.Type == "account" {
+		account, err := m.store.AccountByID(context.Background(), r.ID)
+		if err != nil {
+			m.search.err = fmt.Sprintf("open account: %v", err)
+			return nil
+		}
+		return m.openAccountDetail(*account)
+	}
+	focus, err := m.openActivityEntry(storage.Activity{Type: r.Type, ID: r.ID, Title: r.Title}, "open")
+	if err != nil {
+		m.search.err = err.Error()
+	}
+	return focus
+}
+
+func (m *model) viewSearch() string {
+	lines := []string{m.theme.Title.Render("Search")}
+	lines = append(lines, m.theme.Faint.Render("Searches account names, emails, phones and addresses, notes and events."))
+	if m.store.SearchEnabled() {
+		lines = append(lines, m.theme.Faint.Render("Mode: "+searchModeLabel(true)+"."))
+	} else {
+		lines = append(lines, m.theme.Warning.Render("Mode: "+searchModeLabel(false)+". Build with 'make' or -tags sqlite_fts5 for ranked full-text search."))
+	}
+	lines = append(lines, m.theme.Faint.Render("Enter a number to open a result. '/' or esc goes back, 'exit.' home."))
+	lines = append(lines, "")
+	if m.search.searched {
+		lines = append(lines, m.theme.Subtitle.Render(fmt.Sprintf("Results for \"%s\"", m.search.query)))
+		if len(m.search.results) == 0 {
+			lines = append(lines, m.theme.Warning.Render("No matches."))
+		}
+		for i, r := range m.search.results {
+			typeLabel := strings.ToUpper(r.Type[:1]) + r.Type[1:]
+			header := fmt.Sprintf("%d. [%s] %s", i+1, typeLabel, r.Title)
+			if r.Type != "account" && r.AccountName != "" {
+				header += " (" + r.AccountName + ")"
+			}
+			lines = append(lines, m.theme.Primary.Render(header))
+			if r.Snippet != "" {
+				lines = append(lines, "   "+m.renderSnippet(r.Snippet))
+			}
+		}
+		lines = append(lines, "")
+	}
+	lines = append(lines, m.theme.Border.Render(strings.Repeat("─", 40)))
+	lines = append(lines, m.theme.Accent.Render("search> ")+m.search.input.View())
+	if m.search.err != "" {
+		lines = append(lines, "", m.theme.Danger.Render(m.search.err))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// renderSnippet styles the matched terms storage marks in a search snippet.
+func (m *model) renderSnippet(snippet string) string {
+	var builder strings.Builder
+	rest := snippet
+	for {
+		start := strings.Index(rest, storage.SnippetOpen)
+		if start < 0 {
+			builder.WriteString(m.theme.Faint.Render(rest))
+			break
+		}
+		builder.WriteString(m.theme.Faint.Render(rest[:start]))
+		rest = rest[start+len(storage.SnippetOpen):]
+		end := strings.Index(rest, storage.SnippetClose)
+		if end < 0 {
+			end = len(rest)
+		}
+		builder.WriteString(m.theme.Highlight.Render(rest[:end]))
+		rest = strings.TrimPrefix(rest[end:], storage.SnippetClose)
+	}
+	return builder.String()
+}
+
+// searchModeLabel names the search the store runs: FTS5 when SQLite was
+// built with it, otherwise substring matching.
+func searchModeLabel(fts bool) string {
+	if fts {
+		return "ranked full-text (FTS5)"
+	}
+	return "basic substring matching"
+}
@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// statusPrefix introduces a status term in the accounts filter.
+const statusPrefix = "status:"
+
+// statusFilter keeps accounts whose status starts with any include term and
+// with none of the exclude terms. "none" matches accounts without a status.
+type statusFilter struct {
+	include []string
+	exclude []string
+}
+
+// parseStatusFilter pulls `status:x` and `-status:x` terms out of query and
+// returns them with the remaining text.
+func parseStatusFilter(query string) (statusFilter, string) {
+	var filter statusFilter
+	var rest []string
+	for _, word := range strings.Fields(query) {
+		lower := strings.ToLower(word)
+		switch {
+		case strings.HasPrefix(lower, "-"+statusPrefix):
+			if term := lower[len(statusPrefix)+1:]; term != "" {
+				filter.exclude = append(filter.exclude, term)
+			}
+		case strings.HasPrefix(lower, statusPrefix):
+			if term := lower[len(statusPrefix):]; term != "" {
+				filter.include = append(filter.include, term)
+			}
+		default:
+			rest = append(rest, word)
+		}
+	}
+	return filter, strings.Join(rest, " ")
+}
+
+func (f statusFilter) matches(status string) bool {
+	is := func(term string) bool {
+		if term == "none" {
+			return status == ""
+		}
+		return status != "" && strings.HasPrefix(strings.ToLower(status), term)
+	}
+	for _, term := range f.exclude {
+		if is(term) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, term := range f.include {
+		if is(term) {
+			return true
+		}
+	}
+	return false
+}
+
+// statusStyle colors a status by its place in the configured lifecycle so
+// each status keeps the same color everywhere.
+func (m *model) statusStyle(status string) lipgloss.Style {
+	palette := []lipgloss.Style{m.theme.Subtitle, m.theme.Warning, m.theme.Success, m.theme.Danger, m.theme.Accent, m.theme.Primary}
+	for i, s := range m.cfg.AccountStatuses() {
+		if strings.EqualFold(s, status) {
+			return palette[i%len(palette)]
+		}
+	}
+	return m.theme.Faint
+}
+
+// renderStatusBadge renders a status as a colored badge, or nothing when
+// the account has no status.
+func (m *model) renderStatusBadge(status string) string {
+	if status == "" {
+		return ""
+	}
+	return m.statusStyle(status).Render("● " + status)
+}
+
+// accountStatusCommand handles "status <name>" typed on the account screen.
+// Names match the configured statuses by prefix.
+func (m *model) accountStatusCommand(input string) (tea.Cmd, bool) {
+	arg, ok := cutCommand(input, "status", "st")
+	if !ok {
+		return nil, false
+	}
+	statuses := m.cfg.AccountStatuses()
+	status, ok := resolveStage(arg, statuses)
+	if !ok {
+		m.accountDetail.err = fmt.Sprintf("Unknown status; use one of: %s", strings.Join(statuses, ", "))
+		return nil, true
+	}
+	account := m.accountDetail.account
+	undo := m.snapshotRecord("account", account.ID)
+	if err := m.store.SetAccountStatus(context.Background(), account.ID, status, m.cfg.Config.Name); err != nil {
+		m.accountDetail.err = err.Error()
+		return nil, true
+	}
+	m.pushUndo(fmt.Sprintf("status change of '%s'", account.Name), undo)
+	m.accountDetail.err = ""
+	m.infoMessage = fmt.Sprintf("%s is now %s", account.Name, status)
+	m.refreshAccountDetailAccount()
+	m.refreshAccounts()
+	if m.accountDetail.view == accountDetailActivity {
+		m.loadAccountActivity()
+	}
+	return nil, true
+}
+
+// viewStatusSummary renders one line counting accounts per status, in
+// lifecycle order, followed by any statuses no longer configured.
+func (m *model) viewStatusSummary() string {
+	counts := m.dashboard.statusCounts
+	if len(counts) == 0 {
+		return m.theme.Faint.Render("Accounts: none yet")
+	}
+	var parts []string
+	seen := map[string]bool{}
+	for _, status := range m.cfg.AccountStatuses() {
+		seen[status] = true
+		parts = append(parts, m.renderStatusBadge(status)+m.theme.Secondary.Render(fmt.Sprintf(" %d", counts[status])))
+	}
+	var others []string
+	for status := range counts {
+		if status != "" && !seen[status] {
+			others = append(others, status)
+		}
+	}
+	sort.Strings(others)
+	for _, status := range others {
+		parts = append(parts, m.renderStatusBadge(status)+m.theme.Secondary.Render(fmt.Sprintf(" %d", counts[status])))
+	}
+	if n := counts[""]; n > 0 {
+		parts = append(parts, m.theme.Faint.Render(fmt.Sprintf("no status %d", n)))
+	}
+	return m.theme.Secondary.Render("Accounts: ") + strings.Join(parts, m.theme.Faint.Render("  ·  "))
+}
@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseStatusFilter(t *testing.T) {
+	filter, rest := parseStatusFilter("acme Status:cust -status:churned status: west")
+	want := statusFilter{include: []string{"cust"}, exclude: []string{"churned"}}
+	if !reflect.DeepEqual(filter, want) || rest != "acme west" {
+		t.Errorf("parseStatusFilter = %+v, %q; want %+v, %q", filter, rest, want, "acme west")
+	}
+
+	tests := []struct {
+		query  string
+		status string
+		want   bool
+	}{
+		{"", "", true},
+		{"status:cust", "Customer", true},
+		{"status:cust", "Churned", false},
+		{"status:cust", "", false},
+		{"status:none", "", true},
+		{"status:none", "Lead", false},
+		{"-status:none", "", false},
+		{"-status:none", "Lead", true},
+		{"status:lead status:prospect", "Prospect", true},
+		{"status:c -status:churned", "Customer", true},
+		{"status:c -status:churned", "Churned", false},
+	}
+	for _, tt := range tests {
+		f, _ := parseStatusFilter(tt.query)
+		if got := f.matches(tt.status); got != tt.want {
+			t.Errorf("%q matches %q = %v, want %v", tt.query, tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestResolveStage(t *testing.T) {
+	statuses := []string{"Lead", "Prospect", "Customer", "Churned"}
+	tests := []struct {
+		in     string
+		want   string
+		wantOK bool
+	}{
+		{"lead", "Lead", true},
+		{" PROS ", "Prospect", true},
+		{"cu", "Customer", true},
+		{"c", "", false},
+		{"", "", false},
+		{"partner", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := resolveStage(tt.in, statuses)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("resolveStage(%q) = %q, %v; want %q, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
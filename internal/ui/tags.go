@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"crmterm/internal/storage"
+)
+
+// accountTagCommand handles "tag <names>" and "untag <names>" typed on the
+// account screen. Names are separated by commas or semicolons.
+func (m *model) accountTagCommand(input string) (tea.Cmd, bool) {
+	remove := false
+	arg, ok := cutCommand(input, "tag")
+	if !ok {
+		arg, ok = cutCommand(input, "untag")
+		remove = true
+	}
+	if !ok {
+		return nil, false
+	}
+	names := strings.FieldsFunc(arg, func(r rune) bool { return r == ',' || r == ';' })
+	if len(names) == 0 {
+		m.accountDetail.err = "Name at least one tag"
+		return nil, true
+	}
+	ctx := context.Background()
+	account := m.accountDetail.account
+	undo := m.snapshotRecord("account", account.ID)
+	var err error
+	if remove {
+		err = m.store.RemoveAccountTags(ctx, account.ID, names...)
+	} else {
+		err = m.store.AddAccountTags(ctx, account.ID, names...)
+	}
+	switch {
+	case errors.Is(err, storage.ErrNotFound) && remove:
+		m.accountDetail.err = fmt.Sprintf("%s has no such tag", account.Name)
+		return nil, true
+	case err != nil:
+		m.accountDetail.err = err.Error()
+		return nil, true
+	}
+	m.pushUndo(fmt.Sprintf("tag change on '%s'", account.Name), undo)
+	m.accountDetail.err = ""
+	m.refreshAccountDetailAccount()
+	m.refreshAccounts()
+	if remove {
+		m.infoMessage = fmt.Sprintf("Removed tags from %s", account.Name)
+	} else {
+		m.infoMessage = fmt.Sprintf("Tagged %s", account.Name)
+	}
+	return nil, true
+}
+
+// renderTagChips renders tag names as a row of chips.
+func (m *model) renderTagChips(tags []string) string {
+	chips := make([]string, len(tags))
+	for i, tag := range tags {
+		chips[i] = m.theme.Tag.Render(tag)
+	}
+	return strings.Join(chips, " ")
+}
@@ -0,0 +1,385 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"crmterm/internal/dateparse"
+	"crmterm/internal/storage"
+)
+
+type taskStage int
+
+const (
+	taskStageTitle taskStage = iota
+	taskStageDetails
+	taskStageDue
+	taskStagePriority
+	taskStageAssociatePrompt
+	taskStageAssociateChoose
+)
+
+// dashboardUpcomingTasks caps how many undated or future tasks the dashboard lists.
+const dashboardUpcomingTasks = 5
+
+type taskWizard struct {
+	stage          taskStage
+	titleInput     textinput.Model
+	detailsInput   textinput.Model
+	dueInput       textinput.Model
+	priorityInput  textinput.Model
+	associateInput textinput.Model
+	accountInput   textinput.Model
+	err            string
+	presetAccount  *storage.Account
+}
+
+func newTaskWizard(account *storage.Account) taskWizard {
+	title := textinput.New()
+	title.Placeholder = "Task title"
+	title.CharLimit = 96
+	title.Focus()
+
+	details := textinput.New()
+	details.Placeholder = "Details (optional)"
+	details.CharLimit = 256
+
+	due := textinput.New()
+	due.Placeholder = "fri, tomorrow 5pm, 2026-10-20 (blank = no due date)"
+	due.CharLimit = 32
+
+	priority := textinput.New()
+	priority.Placeholder = "h=high  n=normal  l=low (blank = normal)"
+	priority.CharLimit = 8
+
+	assoc := textinput.New()
+	assoc.Placeholder = "Associate with account? (y/n)"
+	assoc.CharLimit = 5
+
+	accountInput := textinput.New()
+	accountInput.Placeholder = "Type account name"
+	accountInput.CharLimit = 96
+
+	wizard := taskWizard{
+		stage:          taskStageTitle,
+		titleInput:     title,
+		detailsInput:   details,
+		dueInput:       due,
+		priorityInput:  priority,
+		associateInput: assoc,
+		accountInput:   accountInput,
+	}
+	if account != nil {
+		clone := *account
+		wizard.presetAccount = &clone
+	}
+	return wizard
+}
+
+func parseTaskDue(value string, loc *time.Location, order dateparse.Order) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	r, err := dateparse.ParseWithOrder(value, time.Now().In(loc), order)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return r.Time, nil
+}
+
+func parseTaskPriority(value string) (int, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "n", "normal", "2":
+		return storage.PriorityNormal, true
+	case "h", "high", "!", "1":
+		return storage.PriorityHigh, true
+	case "l", "low", "3":
+		return storage.PriorityLow, true
+	}
+	return 0, false
+}
+
+// TASK WIZARD
+func (m *model) updateTaskWizard(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+	input := m.taskWizardInput()
+	if !input.Focused() {
+		if focus := input.Focus(); focus != nil {
+			cmds = append(cmds, focus)
+		}
+	}
+	var cmd tea.Cmd
+	*input, cmd = input.Update(msg)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+	key, ok := msg.(tea.KeyMsg)
+	if !ok || key.Type != tea.KeyEnter {
+		return batchCmds(cmds)
+	}
+
+	value := strings.TrimSpace(input.Value())
+	if isExitCommand(value) {
+		m.taskWizard = newTaskWizard(nil)
+		m.prevStates = nil
+		m.state = stateMainMenu
+		if focus := m.setMenuInput("Choose an option", 32); focus != nil {
+			cmds = append(cmds, focus)
+		}
+		return batchCmds(cmds)
+	}
+	if isBackCommand(value) {
+		switch m.taskWizard.stage {
+		case taskStageTitle:
+			m.taskWizard = newTaskWizard(nil)
+			m.popState()
+			if m.state == stateMainMenu {
+				if focus := m.setMenuInput("Choose an option", 32); focus != nil {
+					cmds = append(cmds, focus)
+				}
+			} else if m.state == stateAccountDetail {
+				if focus := m.setMenuInput(accountDetailPrompt, 72); focus != nil {
+					cmds = append(cmds, focus)
+				}
+			}
+		case taskStageAssociatePrompt:
+			m.taskWizard.associateInput.SetValue("")
+			m.taskWizard.stage = taskStagePriority
+		default:
+			input.SetValue(strings.TrimSuffix(input.Value(), value))
+			m.taskWizard.stage--
+		}
+		m.taskWizard.err = ""
+		return batchCmds(cmds)
+	}
+
+	m.taskWizard.err = ""
+	switch m.taskWizard.stage {
+	case taskStageTitle:
+		if value == "" {
+			m.taskWizard.err = "Title is required"
+			return batchCmds(cmds)
+		}
+		m.taskWizard.stage = taskStageDetails
+	case taskStageDetails:
+		m.taskWizard.stage = taskStageDue
+	case taskStageDue:
+		if _, err := parseTaskDue(value, m.cfg.Location(), m.cfg.DateOrder()); err != nil {
+			m.taskWizard.err = "Couldn't read the due date: " + err.Error()
+			return batchCmds(cmds)
+		}
+		m.taskWizard.stage = taskStagePriority
+	case taskStagePriority:
+		if _, ok := parseTaskPriority(value); !ok {
+			m.taskWizard.err = "Choose h, n, or l"
+			return batchCmds(cmds)
+		}
+		if m.taskWizard.presetAccount != nil {
+			accountID := sql.NullInt64{Int64: m.taskWizard.presetAccount.ID, Valid: true}
+			m.finishTaskWizard(&accountID, fmt.Sprintf("Task added for %s", m.taskWizard.presetAccount.Name))
+			return batchCmds(cmds)
+		}
+		m.taskWizard.stage = taskStageAssociatePrompt
+	case taskStageAssociatePrompt:
+		m.taskWizard.associateInput.SetValue("")
+		switch strings.ToLower(value) {
+		case "y", "yes":
+			m.taskWizard.stage = taskStageAssociateChoose
+		case "n", "no", "":
+			m.finishTaskWizard(nil, "Task added")
+		default:
+			m.taskWizard.err = "Please answer y or n"
+		}
+	case taskStageAssociateChoose:
+		if value == "" {
+			m.finishTaskWizard(nil, "Task added")
+			return batchCmds(cmds)
+		}
+		account, err := m.store.AccountByName(context.Background(), value)
+		if err != nil {
+			m.taskWizard.err = "Account not found"
+			return batchCmds(cmds)
+		}
+		accountID := sql.NullInt64{Int64: account.ID, Valid: true}
+		m.finishTaskWizard(&accountID, fmt.Sprintf("Task added for %s", account.Name))
+	}
+	return batchCmds(cmds)
+}
+
+func (m *model) taskWizardInput() *textinput.Model {
+	switch m.taskWizard.stage {
+	case taskStageDetails:
+		return &m.taskWizard.detailsInput
+	case taskStageDue:
+		return &m.taskWizard.dueInput
+	case taskStagePriority:
+		return &m.taskWizard.priorityInput
+	case taskStageAssociatePrompt:
+		return &m.taskWizard.associateInput
+	case taskStageAssociateChoose:
+		return &m.taskWizard.accountInput
+	default:
+		return &m.taskWizard.titleInput
+	}
+}
+
+func (m *model) finishTaskWizard(accountID *sql.NullInt64, message string) {
+	if err := m.saveTask(accountID); err != nil {
+		m.taskWizard.err = err.Error()
+		return
+	}
+	m.taskWizard = newTaskWizard(nil)
+	m.infoMessage = message
+	m.popState()
+	if m.state == stateAccountDetail {
+		m.refreshAccountDetailAccount()
+		m.loadAccountActivity()
+		m.setMenuInput(accountDetailPrompt, 72)
+	} else if m.state == stateMainMenu {
+		m.setMenuInput("Choose an option", 32)
+	}
+	m.refreshDashboard(time.Now().In(m.cfg.Location()))
+}
+
+func (m *model) saveTask(accountID *sql.NullInt64) error {
+	loc := m.cfg.Location()
+	due, err := parseTaskDue(strings.TrimSpace(m.taskWizard.dueInput.Value()), loc, m.cfg.DateOrder())
+	if err != nil {
+		return err
+	}
+	priority, _ := parseTaskPriority(m.taskWizard.priorityInput.Value())
+	task := storage.Task{
+		Title:     strings.TrimSpace(m.taskWizard.titleInput.Value()),
+		Details:   strings.TrimSpace(m.taskWizard.detailsInput.Value()),
+		DueAt:     due,
+		Priority:  priority,
+		Creator:   m.cfg.Config.Name,
+		CreatedAt: time.Now().In(loc),
+	}
+	if accountID != nil {
+		task.AccountID = *accountID
+	}
+	if err := m.store.CreateTask(context.Background(), &task); err != nil {
+		return err
+	}
+	m.pushCreated(fmt.Sprintf("new task '%s'", task.Title), "task", task.ID)
+	return nil
+}
+
+func (m *model) viewTaskWizard() string {
+	lines := []string{m.theme.Title.Render("New Task")}
+	switch m.taskWizard.stage {
+	case taskStageTitle:
+		lines = append(lines, m.theme.Secondary.Render("Task title:"))
+		lines = append(lines, m.taskWizard.titleInput.View())
+		if m.taskWizard.presetAccount != nil {
+			lines = append(lines, m.theme.Faint.Render(fmt.Sprintf("Will link to %s", m.taskWizard.presetAccount.Name)))
+		}
+	case taskStageDetails:
+		lines = append(lines, m.theme.Secondary.Render("Details (optional):"))
+		lines = append(lines, m.taskWizard.detailsInput.View())
+	case taskStageDue:
+		lines = append(lines, m.theme.Secondary.Render("Due ("+dateHint+"; blank = none):"))
+		lines = append(lines, m.taskWizard.dueInput.View())
+		if preview := m.datePreview(m.taskWizard.dueInput.Value(), false); preview != "" {
+			lines = append(lines, preview)
+		}
+	case taskStagePriority:
+		lines = append(lines, m.theme.Secondary.Render("Priority (h/n/l, blank = normal):"))
+		lines = append(lines, m.taskWizard.priorityInput.View())
+	case taskStageAssociatePrompt:
+		lines = append(lines, m.theme.Secondary.Render("Associate with an account? (y/n)"))
+		lines = append(lines, m.taskWizard.associateInput.View())
+	case taskStageAssociateChoose:
+		lines = append(lines, m.theme.Secondary.Render("Enter account name (blank to skip):"))
+		lines = append(lines, m.taskWizard.accountInput.View())
+	}
+	lines = append(lines, m.theme.Faint.Render("'/' goes back, 'exit.' returns home."))
+	if m.taskWizard.err != "" {
+		lines = append(lines, "", m.theme.Danger.Render(m.taskWizard.err))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// dashboardTaskList returns open tasks in the order the dashboard numbers them.
+func (m *model) dashboardTaskList(now time.Time) (overdue, today, upcoming []storage.Task) {
+	overdue, today, upcoming = storage.SplitTasks(m.dashboard.tasks, now)
+	if len(upcoming) > dashboardUpcomingTasks {
+		upcoming = upcoming[:dashboardUpcomingTasks]
+	}
+	return overdue, today, upcoming
+}
+
+func (m *model) completeDashboardTask(arg string) {
+	idx, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+	now := time.Now().In(m.cfg.Location())
+	overdue, today, upcoming := m.dashboardTaskList(now)
+	numbered := append(append(append([]storage.Task(nil), overdue...), today...), upcoming...)
+	if err != nil || idx <= 0 || idx > len(numbered) {
+		m.errMessage = "Invalid task number"
+		return
+	}
+	task := numbered[idx-1]
+	undo := m.snapshotRecord("task", task.ID)
+	if err := m.store.CompleteTask(context.Background(), task.ID, time.Now()); err != nil {
+		m.errMessage = fmt.Sprintf("complete task: %v", err)
+		return
+	}
+	m.pushUndo(fmt.Sprintf("completion of '%s'", task.Title), undo)
+	m.errMessage = ""
+	m.infoMessage = fmt.Sprintf("Completed '%s'", task.Title)
+	m.refreshDashboard(now)
+}
+
+func (m *model) viewDashboardTasks(now time.Time) []string {
+	overdue, today, upcoming := m.dashboardTaskList(now)
+	n := 0
+	section := func(title, empty string, tasks []storage.Task, render func(...string) string) []string {
+		lines := []string{m.theme.Subtitle.Render(title)}
+		if len(tasks) == 0 {
+			lines = append(lines, m.theme.Faint.Render(empty))
+		}
+		for _, t := range tasks {
+			n++
+			lines = append(lines, render(fmt.Sprintf("%d. %s", n, formatTaskLine(m, t))))
+		}
+		return lines
+	}
+	lines := section("Overdue Tasks", "Nothing overdue.", overdue, m.theme.Danger.Render)
+	lines = append(lines, "")
+	lines = append(lines, section("Due Today", "No tasks due today.", today, m.theme.Success.Render)...)
+	lines = append(lines, "")
+	lines = append(lines, section("Upcoming Tasks", "No upcoming tasks.", upcoming, m.theme.Warning.Render)...)
+	return lines
+}
+
+func formatTaskLine(m *model, t storage.Task) string {
+	var builder strings.Builder
+	if !t.DueAt.IsZero() {
+		due := t.DueAt.In(m.cfg.Location())
+		if due.Hour() == 0 && due.Minute() == 0 {
+			builder.WriteString(due.Format("Mon Jan 02"))
+		} else {
+			builder.WriteString(due.Format("Mon Jan 02 15:04"))
+		}
+		builder.WriteString(" — ")
+	}
+	builder.WriteString(t.Title)
+	if t.AccountName.Valid {
+		builder.WriteString(" (" + t.AccountName.String + ")")
+	}
+	if t.Priority != storage.PriorityNormal {
+		builder.WriteString(" [" + storage.PriorityLabel(t.Priority) + "]")
+	}
+	if t.Details != "" {
+		builder.WriteString(" • " + t.Details)
+	}
+	return builder.String()
+}
@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"crmterm/internal/dateparse"
+	"crmterm/internal/storage"
+)
+
+func TestParseTaskPriority(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   int
+		wantOK bool
+	}{
+		{"", storage.PriorityNormal, true},
+		{"normal", storage.PriorityNormal, true},
+		{" H ", storage.PriorityHigh, true},
+		{"!", storage.PriorityHigh, true},
+		{"1", storage.PriorityHigh, true},
+		{"low", storage.PriorityLow, true},
+		{"3", storage.PriorityLow, true},
+		{"urgent", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseTaskPriority(tt.in)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("parseTaskPriority(%q) = %d, %v; want %d, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestParseTaskDue(t *testing.T) {
+	if due, err := parseTaskDue("", time.UTC, dateparse.OrderAuto); err != nil || !due.IsZero() {
+		t.Errorf("blank due date = %v, %v; want none", due, err)
+	}
+	due, err := parseTaskDue("2026-10-20 17:00", time.UTC, dateparse.OrderAuto)
+	if err != nil {
+		t.Fatalf("parse due date: %v", err)
+	}
+	if want := time.Date(2026, 10, 20, 17, 0, 0, 0, time.UTC); !due.Equal(want) {
+		t.Errorf("due = %v, want %v", due, want)
+	}
+	if _, err := parseTaskDue("someday", time.UTC, dateparse.OrderAuto); err == nil {
+		t.Errorf("parseTaskDue(someday) gave no error")
+	}
+}
@@ -0,0 +1,229 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"crmterm/internal/storage"
+)
+
+const (
+	trashPrompt        = "r <#>=Restore  p <#>=Purge  empty  /=Back"
+	trashConfirmPrompt = "Purge permanently? (y/n)"
+)
+
+type trashModel struct {
+	items []storage.TrashItem
+	// pending holds what a y/n confirmation will purge; all of it when
+	// emptying the trash.
+	pending []storage.TrashItem
+	err     string
+}
+
+// purgeExpiredTrash permanently removes items older than the configured
+// retention period.
+func (m *model) purgeExpiredTrash() {
+	retention := m.cfg.TrashRetention()
+	if retention <= 0 {
+		return
+	}
+	if _, err := m.store.PurgeTrash(context.Background(), time.Now().Add(-retention)); err != nil {
+		m.errMessage = fmt.Sprintf("purge trash: %v", err)
+	}
+}
+
+func (m *model) openTrash() tea.Cmd {
+	m.trash = trashModel{}
+	m.purgeExpiredTrash()
+	m.refreshTrash()
+	m.pushState(stateTrash)
+	return m.setMenuInput(trashPrompt, 48)
+}
+
+func (m *model) refreshTrash() {
+	items, err := m.store.ListTrash(context.Background())
+	if err != nil {
+		m.trash.err = fmt.Sprintf("load trash: %v", err)
+		return
+	}
+	m.trash.items = items
+}
+
+// TRASH
+func (m *model) updateTrash(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+	placeholder := trashPrompt
+	if len(m.trash.pending) > 0 {
+		placeholder = trashConfirmPrompt
+	}
+	if focus := m.ensureMenuInput(placeholder, 48); focus != nil {
+		cmds = append(cmds, focus)
+	}
+	var cmd tea.Cmd
+	m.menuInput, cmd = m.menuInput.Update(msg)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+	key, ok := msg.(tea.KeyMsg)
+	if !ok || key.Type != tea.KeyEnter {
+		return batchCmds(cmds)
+	}
+	value := strings.TrimSpace(strings.ToLower(m.menuInput.Value()))
+	m.menuInput.SetValue("")
+	if isExitCommand(value) {
+		m.prevStates = nil
+		m.state = stateMainMenu
+		if focus := m.setMenuInput("Choose an option", 32); focus != nil {
+			cmds = append(cmds, focus)
+		}
+		return batchCmds(cmds)
+	}
+
+	if len(m.trash.pending) > 0 {
+		switch value {
+		case "y", "yes":
+			m.purgePending()
+		case "n", "no", "/", "back":
+			m.trash.pending = nil
+		default:
+			m.trash.err = "Please answer y or n"
+			return batchCmds(cmds)
+		}
+		if focus := m.setMenuInput(trashPrompt, 48); focus != nil {
+			cmds = append(cmds, focus)
+		}
+		return batchCmds(cmds)
+	}
+
+	m.trash.err = ""
+	if isBackCommand(value) {
+		m.popState()
+		if m.state == stateMainMenu {
+			if focus := m.setMenuInput("Choose an option", 32); focus != nil {
+				cmds = append(cmds, focus)
+			}
+		}
+		return batchCmds(cmds)
+	}
+	switch {
+	case value == "":
+	case value == "empty":
+		if len(m.trash.items) == 0 {
+			m.trash.err = "Trash is already empty"
+			break
+		}
+		m.trash.pending = append([]storage.TrashItem(nil), m.trash.items...)
+	case value == "refresh":
+		m.refreshTrash()
+	default:
+		if arg, ok := cutCommand(value, "restore", "r"); ok {
+			if item, ok := m.trashItem(arg); ok {
+				m.restoreTrashItem(item)
+			}
+			break
+		}
+		if arg, ok := cutCommand(value, "purge", "p"); ok {
+			if item, ok := m.trashItem(arg); ok {
+				m.trash.pending = []storage.TrashItem{item}
+			}
+			break
+		}
+		m.trash.err = "Use 'r 2' to restore or 'p 2' to purge item #2"
+	}
+	if len(m.trash.pending) > 0 {
+		if focus := m.setMenuInput(trashConfirmPrompt, 48); focus != nil {
+			cmds = append(cmds, focus)
+		}
+	}
+	return batchCmds(cmds)
+}
+
+func (m *model) trashItem(arg string) (storage.TrashItem, bool) {
+	idx, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+	if err != nil || idx <= 0 || idx > len(m.trash.items) {
+		m.trash.err = "Invalid item number"
+		return storage.TrashItem{}, false
+	}
+	return m.trash.items[idx-1], true
+}
+
+func (m *model) restoreTrashItem(item storage.TrashItem) {
+	ctx := context.Background()
+	undo, err := m.store.SnapshotTrashItem(ctx, item)
+	if err != nil {
+		m.trash.err = fmt.Sprintf("prepare undo: %v", err)
+	}
+	if err := m.store.Restore(ctx, item); err != nil {
+		m.trash.err = fmt.Sprintf("restore: %v", err)
+		return
+	}
+	m.pushUndo(fmt.Sprintf("restore of %s '%s'", item.Type, item.Title), undo)
+	m.infoMessage = fmt.Sprintf("Restored %s '%s'", item.Type, item.Title)
+	m.refreshTrash()
+	m.refreshAccounts()
+	m.refreshDashboard(time.Now().In(m.cfg.Location()))
+}
+
+func (m *model) purgePending() {
+	pending := m.trash.pending
+	m.trash.pending = nil
+	purged := 0
+	for _, item := range pending {
+		if err := m.store.Purge(context.Background(), item); err != nil && err != storage.ErrNotFound {
+			m.trash.err = fmt.Sprintf("purge: %v", err)
+			break
+		}
+		purged++
+	}
+	if len(pending) == 1 && purged == 1 {
+		m.infoMessage = fmt.Sprintf("Purged %s '%s'", pending[0].Type, pending[0].Title)
+	} else if purged > 0 {
+		m.infoMessage = fmt.Sprintf("Purged %d items", purged)
+	}
+	m.refreshTrash()
+}
+
+func (m *model) viewTrash() string {
+	loc := m.cfg.Location()
+	lines := []string{m.theme.Title.Render("Trash")}
+	hint := "Deleted items stay here until you purge them."
+	if retention := m.cfg.TrashRetention(); retention > 0 {
+		hint = fmt.Sprintf("Deleted items are purged automatically after %d days.", int(retention.Hours()/24))
+	}
+	lines = append(lines, m.theme.Faint.Render(hint+" 'r 2' restores #2, 'p 2' purges it, 'empty' purges everything."))
+	lines = append(lines, "")
+	if len(m.trash.items) == 0 {
+		lines = append(lines, m.theme.Faint.Render("Trash is empty."))
+	}
+	for i, item := range m.trash.items {
+		typeLabel := item.Type
+		if len(typeLabel) > 0 {
+			typeLabel = strings.ToUpper(typeLabel[:1]) + typeLabel[1:]
+		}
+		stamp := item.DeletedAt.In(loc).Format("Jan 02 15:04")
+		lines = append(lines, m.theme.Primary.Render(fmt.Sprintf("%d. [%s] %s", i+1, typeLabel, item.Title))+
+			m.theme.Faint.Render(" — deleted "+stamp))
+	}
+	lines = append(lines, "")
+	if n := len(m.trash.pending); n == 1 {
+		lines = append(lines, m.theme.Warning.Render(fmt.Sprintf("Permanently delete %s '%s'? This cannot be undone.", m.trash.pending[0].Type, m.trash.pending[0].Title)))
+	} else if n > 1 {
+		lines = append(lines, m.theme.Warning.Render(fmt.Sprintf("Permanently delete all %d items? This cannot be undone.", n)))
+	}
+	lines = append(lines, m.theme.Accent.Render("> ")+m.menuInput.View())
+	if m.trash.err != "" {
+		lines = append(lines, "", m.theme.Danger.Render(m.trash.err))
+	}
+	if m.infoMessage != "" {
+		lines = append(lines, "", m.theme.Success.Render(m.infoMessage))
+	}
+	if m.errMessage != "" {
+		lines = append(lines, "", m.theme.Danger.Render(m.errMessage))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"context"
+	"testing"
+
+	"crmterm/internal/storage"
+)
+
+func TestPurgeExpiredTrash(t *testing.T) {
+	m := newTestModel(t)
+	ctx := context.Background()
+	note := storage.Note{Content: "old call", Creator: "tester"}
+	if err := m.store.CreateNote(ctx, &note); err != nil {
+		t.Fatalf("create note: %v", err)
+	}
+	if err := m.store.DeleteNote(ctx, note.ID); err != nil {
+		t.Fatalf("delete note: %v", err)
+	}
+	trashed := func() int {
+		t.Helper()
+		items, err := m.store.ListTrash(ctx)
+		if err != nil {
+			t.Fatalf("list trash: %v", err)
+		}
+		return len(items)
+	}
+
+	m.cfg.Config.TrashRetentionDays = -1
+	m.purgeExpiredTrash()
+	if trashed() != 1 {
+		t.Fatalf("purged with retention disabled")
+	}
+	m.cfg.Config.TrashRetentionDays = 0
+	m.purgeExpiredTrash()
+	if trashed() != 1 {
+		t.Errorf("purged a note deleted today with the default retention of %v", m.cfg.TrashRetention())
+	}
+}
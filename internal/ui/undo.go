@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"crmterm/internal/storage"
+)
+
+// undoLimit caps how many actions Ctrl+Z can step back through.
+const undoLimit = 20
+
+type undoEntry struct {
+	label    string
+	snapshot *storage.Snapshot
+}
+
+// snapshotRecord captures a record before it is edited or deleted. A failed
+// snapshot does not block the change; it only means it can't be undone.
+func (m *model) snapshotRecord(kind string, id int64) *storage.Snapshot {
+	snap, err := m.store.SnapshotRecord(context.Background(), kind, id)
+	if err != nil {
+		m.errMessage = fmt.Sprintf("prepare undo: %v", err)
+		return nil
+	}
+	return snap
+}
+
+// pushCreated records the creation of a record so undo deletes it again.
+func (m *model) pushCreated(label, kind string, id int64) {
+	snap, err := storage.CreatedRecord(kind, id)
+	if err != nil {
+		m.errMessage = fmt.Sprintf("prepare undo: %v", err)
+		return
+	}
+	m.pushUndo(label, snap)
+}
+
+func (m *model) pushUndo(label string, snap *storage.Snapshot) {
+	if snap == nil {
+		return
+	}
+	m.undoStack = append(m.undoStack, undoEntry{label: label, snapshot: snap})
+	if len(m.undoStack) > undoLimit {
+		m.undoStack = m.undoStack[len(m.undoStack)-undoLimit:]
+	}
+}
+
+// undoLast restores the snapshot of the most recent action and reloads the
+// screens that may show the affected records.
+func (m *model) undoLast() tea.Cmd {
+	if len(m.undoStack) == 0 {
+		m.undoStatus = "Nothing to undo"
+		return nil
+	}
+	last := m.undoStack[len(m.undoStack)-1]
+	if err := m.store.RestoreSnapshot(context.Background(), last.snapshot); err != nil {
+		m.undoStatus = fmt.Sprintf("Undo failed: %v", err)
+		return nil
+	}
+	m.undoStack = m.undoStack[:len(m.undoStack)-1]
+	m.undoStatus = "Undid: " + last.label
+	var cmd tea.Cmd
+	if id := m.accountDetail.account.ID; id != 0 {
+		if _, err := m.store.AccountByID(context.Background(), id); errors.Is(err, storage.ErrNotFound) {
+			m.accountDetail = accountDetailModel{}
+			if m.state == stateAccountDetail {
+				m.popState()
+				if m.state == stateMainMenu {
+					cmd = m.setMenuInput("Choose an option", 32)
+				}
+			}
+		}
+	}
+	m.refreshDataAfterCleanup()
+	switch m.state {
+	case stateTrash:
+		m.refreshTrash()
+	case stateAudit:
+		m.refreshAudit()
+	case stateCalendar:
+		m.refreshCalendar()
+	case stateDeals, statePipelineBoard:
+		m.refreshDeals()
+	}
+	return cmd
+}
@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"crmterm/internal/storage"
+)
+
+func TestUndoTagAndStatusChanges(t *testing.T) {
+	m := newTestModel(t)
+	ctx := context.Background()
+	account := storage.Account{Name: "Acme", Creator: "tester"}
+	if err := m.store.CreateAccount(ctx, &account); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	m.openAccountDetail(account)
+	typeLine(m, "status customer")
+	typeLine(m, "tag vip")
+	if tagged, _ := m.store.ListAccountsByTag(ctx, "tag:vip"); len(tagged) != 1 {
+		t.Fatalf("tag command did not tag the account: %s", m.accountDetail.err)
+	}
+
+	for _, want := range []string{"tag change on 'Acme'", "status change of 'Acme'"} {
+		m.Update(tea.KeyMsg{Type: tea.KeyCtrlZ})
+		if !strings.Contains(m.undoStatus, want) {
+			t.Errorf("undo status = %q, want it to undo the %s", m.undoStatus, want)
+		}
+	}
+	got, err := m.store.AccountByID(ctx, account.ID)
+	if err != nil {
+		t.Fatalf("load account: %v", err)
+	}
+	if got.Status != account.Status {
+		t.Errorf("status after undo = %q, want %q", got.Status, account.Status)
+	}
+	if tagged, _ := m.store.ListAccountsByTag(ctx, "tag:vip"); len(tagged) != 0 {
+		t.Errorf("accounts tagged vip after undo: %+v", tagged)
+	}
+}
@@ -0,0 +1,160 @@
+package validate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// country describes how numbers are dialled in one country.
+type country struct {
+	// code is the calling code without the "+".
+	code string
+	// trunk is dialled before national numbers and dropped in E.164.
+	trunk string
+	// intl is dialled before international numbers instead of "+".
+	intl string
+	// min and max bound the length of the national number, trunk prefix
+	// excluded.
+	min, max int
+}
+
+// countries is keyed by ISO 3166 alpha-2 code.
+var countries = map[string]country{
+	"AT": {code: "43", trunk: "0", intl: "00", min: 4, max: 13},
+	"AU": {code: "61", trunk: "0", intl: "0011", min: 9, max: 9},
+	"BE": {code: "32", trunk: "0", intl: "00", min: 8, max: 9},
+	"BR": {code: "55", trunk: "0", intl: "00", min: 10, max: 11},
+	"CA": {code: "1", trunk: "1", intl: "011", min: 10, max: 10},
+	"CH": {code: "41", trunk: "0", intl: "00", min: 9, max: 9},
+	"CN": {code: "86", trunk: "0", intl: "00", min: 10, max: 11},
+	"DE": {code: "49", trunk: "0", intl: "00", min: 5, max: 13},
+	"DK": {code: "45", intl: "00", min: 8, max: 8},
+	"ES": {code: "34", intl: "00", min: 9, max: 9},
+	"FI": {code: "358", trunk: "0", intl: "00", min: 5, max: 12},
+	"FR": {code: "33", trunk: "0", intl: "00", min: 9, max: 9},
+	"GB": {code: "44", trunk: "0", intl: "00", min: 9, max: 10},
+	"HK": {code: "852", intl: "00", min: 8, max: 8},
+	"IE": {code: "353", trunk: "0", intl: "00", min: 7, max: 9},
+	"IN": {code: "91", trunk: "0", intl: "00", min: 10, max: 10},
+	"IT": {code: "39", intl: "00", min: 6, max: 11},
+	"JP": {code: "81", trunk: "0", intl: "010", min: 9, max: 10},
+	"LU": {code: "352", intl: "00", min: 4, max: 11},
+	"MX": {code: "52", intl: "00", min: 10, max: 10},
+	"NL": {code: "31", trunk: "0", intl: "00", min: 9, max: 9},
+	"NO": {code: "47", intl: "00", min: 8, max: 8},
+	"NZ": {code: "64", trunk: "0", intl: "00", min: 8, max: 10},
+	"PL": {code: "48", intl: "00", min: 9, max: 9},
+	"PT": {code: "351", intl: "00", min: 9, max: 9},
+	"RU": {code: "7", trunk: "8", intl: "810", min: 10, max: 10},
+	"SE": {code: "46", trunk: "0", intl: "00", min: 7, max: 9},
+	"SG": {code: "65", intl: "000", min: 8, max: 8},
+	"US": {code: "1", trunk: "1", intl: "011", min: 10, max: 10},
+	"ZA": {code: "27", trunk: "0", intl: "00", min: 9, max: 9},
+}
+
+// Countries returns the country codes Phone understands, sorted.
+func Countries() []string {
+	codes := make([]string, 0, len(countries))
+	for code := range countries {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// KnownCountry reports whether code, in any case, is one of Countries.
+func KnownCountry(code string) bool {
+	_, ok := countries[strings.ToUpper(strings.TrimSpace(code))]
+	return ok
+}
+
+// Phone normalizes a phone number to E.164, e.g. "+15555555555". Spaces,
+// dashes, dots, slashes and brackets are ignored. Numbers that start with
+// "+" or the country's international prefix keep their own calling code;
+// others are read as national numbers in country, an ISO 3166 code such
+// as "US". Blank input is valid. On error the trimmed input is returned.
+func Phone(value, countryCode string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	var digits strings.Builder
+	plus := false
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && digits.Len() == 0 && !plus:
+			plus = true
+		case strings.ContainsRune(" -.()/", r):
+		default:
+			return value, fmt.Errorf("'%c' is not allowed in a phone number", r)
+		}
+	}
+	number := digits.String()
+	if number == "" {
+		return value, fmt.Errorf("no digits")
+	}
+	c, known := countries[strings.ToUpper(strings.TrimSpace(countryCode))]
+	if !plus && known && strings.HasPrefix(number, c.intl) {
+		number = strings.TrimPrefix(number, c.intl)
+		plus = true
+	}
+	if plus {
+		return international(value, number)
+	}
+	if !known {
+		if countryCode == "" {
+			return value, fmt.Errorf("no country code; start it with '+'")
+		}
+		return value, fmt.Errorf("no country code, and numbers from '%s' are not understood; start it with '+'", countryCode)
+	}
+	name := strings.ToUpper(strings.TrimSpace(countryCode))
+	fits := func(n string) bool { return len(n) >= c.min && len(n) <= c.max }
+	switch {
+	case c.trunk != "" && strings.HasPrefix(number, c.trunk) && fits(number[len(c.trunk):]):
+		number = number[len(c.trunk):]
+	case fits(number):
+	case strings.HasPrefix(number, c.code) && fits(number[len(c.code):]):
+		number = number[len(c.code):]
+	case len(number) < c.min:
+		return value, fmt.Errorf("too short for a %s number", name)
+	default:
+		return value, fmt.Errorf("too long for a %s number", name)
+	}
+	return "+" + c.code + number, nil
+}
+
+// international checks a number given with its calling code.
+func international(value, number string) (string, error) {
+	if strings.HasPrefix(number, "0") {
+		return value, fmt.Errorf("calling codes don't start with 0")
+	}
+	// Calling codes are prefix-free, so at most one of these matches.
+	for n := 1; n <= 3 && n < len(number); n++ {
+		code := number[:n]
+		for _, c := range countries {
+			if c.code != code {
+				continue
+			}
+			national := number[n:]
+			if c.trunk != "" && len(national) > c.max && strings.HasPrefix(national, c.trunk) {
+				// Written as "+44 (0)20 ...", with the trunk prefix
+				// that international dialling leaves out.
+				national = national[len(c.trunk):]
+			}
+			if len(national) < c.min {
+				return value, fmt.Errorf("too short for a +%s number", code)
+			}
+			if len(national) > c.max {
+				return value, fmt.Errorf("too long for a +%s number", code)
+			}
+			return "+" + code + national, nil
+		}
+	}
+	if len(number) < 8 || len(number) > 15 {
+		return value, fmt.Errorf("international numbers have 8 to 15 digits")
+	}
+	return "+" + number, nil
+}
@@ -0,0 +1,66 @@
+package validate
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestPhone(t *testing.T) {
+	tests := []struct {
+		in, country string
+		want        string
+		wantErr     bool
+	}{
+		{in: "", country: "US", want: ""},
+		{in: "(555) 555-5555", country: "US", want: "+15555555555"},
+		{in: "1-555-555-5555", country: "US", want: "+15555555555"},
+		{in: "555.555.5555", country: "us", want: "+15555555555"},
+		{in: "+1 555 555 5555", country: "GB", want: "+15555555555"},
+		{in: "020 7946 0958", country: "GB", want: "+442079460958"},
+		{in: "0044 20 7946 0958", country: "GB", want: "+442079460958"},
+		{in: "011 44 20 7946 0958", country: "US", want: "+442079460958"},
+		{in: "+44 (0)20 7946 0958", country: "US", want: "+442079460958"},
+		{in: "+44 20 7946 0958", country: "", want: "+442079460958"},
+		{in: "44 20 7946 0958", country: "GB", want: "+442079460958"},
+		{in: "+999 1234 5678", country: "US", want: "+99912345678"},
+
+		{in: " 555-5555 ", country: "US", want: "555-5555", wantErr: true},
+		{in: "555 555 5555 55", country: "US", want: "555 555 5555 55", wantErr: true},
+		{in: "555-CALL-NOW", country: "US", want: "555-CALL-NOW", wantErr: true},
+		{in: "5++555", country: "US", want: "5++555", wantErr: true},
+		{in: "--", country: "US", want: "--", wantErr: true},
+		{in: "+0 20 7946 0958", country: "US", want: "+0 20 7946 0958", wantErr: true},
+		{in: "+1 555", country: "US", want: "+1 555", wantErr: true},
+		{in: "+1234", country: "US", want: "+1234", wantErr: true},
+		{in: "5555555555", country: "", want: "5555555555", wantErr: true},
+		{in: "5555555555", country: "XX", want: "5555555555", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Phone(tt.in, tt.country)
+		if tt.wantErr != (err != nil) {
+			t.Errorf("Phone(%q, %q) = %q, %v; want an error: %v", tt.in, tt.country, got, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Phone(%q, %q) = %q, want %q", tt.in, tt.country, got, tt.want)
+		}
+	}
+}
+
+func TestCountries(t *testing.T) {
+	codes := Countries()
+	if !sort.StringsAreSorted(codes) {
+		t.Errorf("Countries() = %v, want them sorted", codes)
+	}
+	for _, code := range codes {
+		if !KnownCountry(code) {
+			t.Errorf("listed country %s is not known", code)
+		}
+	}
+	if !KnownCountry(" gb ") {
+		t.Errorf("KnownCountry ignores case and spaces")
+	}
+	if KnownCountry("XX") || KnownCountry("") {
+		t.Errorf("unknown codes reported as known")
+	}
+}
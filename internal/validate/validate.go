@@ -0,0 +1,143 @@
+// Package validate checks and tidies the contact details people type or
+// import: email addresses, phone numbers and postal addresses. Phone
+// numbers are normalized to E.164, reading numbers without a country code
+// as belonging to a default country. Problems are reported as warnings
+// rather than failures, so callers decide whether a value is kept.
+package validate
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Warning is a problem with one field's value.
+type Warning struct {
+	// Field is "phone", "email" or "address".
+	Field   string
+	Value   string
+	Message string
+}
+
+// String renders the warning the way import previews list it, e.g.
+// "email 'bob@': missing the domain after '@'".
+func (w Warning) String() string {
+	return fmt.Sprintf("%s '%s': %s", w.Field, w.Value, w.Message)
+}
+
+// Contact is the set of an account's contact details checked together.
+type Contact struct {
+	Phone   string
+	Email   string
+	Address string
+}
+
+// CheckContact normalizes every field of c, reading phone numbers without
+// a country code as numbers in country. A field that cannot be normalized
+// is kept as typed, trimmed, and reported in the warnings.
+func CheckContact(c Contact, country string) (Contact, []Warning) {
+	var warnings []Warning
+	phone, err := Phone(c.Phone, country)
+	if err != nil {
+		warnings = append(warnings, Warning{Field: "phone", Value: phone, Message: err.Error()})
+	}
+	email, err := Email(c.Email)
+	if err != nil {
+		warnings = append(warnings, Warning{Field: "email", Value: email, Message: err.Error()})
+	}
+	return Contact{Phone: phone, Email: email, Address: Address(c.Address)}, warnings
+}
+
+// Email checks the syntax of an email address and lower-cases its domain.
+// Blank input is valid. On error the trimmed input is returned.
+func Email(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	at := strings.LastIndex(value, "@")
+	if at < 0 {
+		return value, fmt.Errorf("missing '@'")
+	}
+	local, domain := value[:at], strings.ToLower(value[at+1:])
+	switch {
+	case local == "":
+		return value, fmt.Errorf("missing the name before '@'")
+	case domain == "":
+		return value, fmt.Errorf("missing the domain after '@'")
+	case strings.Contains(local, "@"):
+		return value, fmt.Errorf("more than one '@'")
+	case len(local) > 64:
+		return value, fmt.Errorf("the name before '@' is over 64 characters")
+	case strings.HasPrefix(local, ".") || strings.HasSuffix(local, ".") || strings.Contains(local, ".."):
+		return value, fmt.Errorf("dots can't start, end or repeat in the name before '@'")
+	}
+	for _, r := range local {
+		if !localRune(r) {
+			return value, fmt.Errorf("'%c' is not allowed in an email address", r)
+		}
+	}
+	if err := checkDomain(domain); err != nil {
+		return value, err
+	}
+	return local + "@" + domain, nil
+}
+
+// localRune reports whether r may appear unquoted before the '@'.
+func localRune(r rune) bool {
+	if r > unicode.MaxASCII {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+	if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+		return true
+	}
+	return strings.ContainsRune("!#$%&'*+/=?^_`{|}~.-", r)
+}
+
+func checkDomain(domain string) error {
+	if len(domain) > 253 {
+		return fmt.Errorf("the domain is over 253 characters")
+	}
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return fmt.Errorf("domain '%s' has no ending such as .com", domain)
+	}
+	for _, label := range labels {
+		switch {
+		case label == "":
+			return fmt.Errorf("domain '%s' has an empty part", domain)
+		case len(label) > 63:
+			return fmt.Errorf("domain '%s' has a part over 63 characters", domain)
+		case strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-"):
+			return fmt.Errorf("domain '%s' has a part starting or ending with '-'", domain)
+		}
+		for _, r := range label {
+			if r != '-' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				return fmt.Errorf("'%c' is not allowed in a domain", r)
+			}
+		}
+	}
+	tld := labels[len(labels)-1]
+	if strings.HasPrefix(tld, "xn--") {
+		return nil
+	}
+	if len([]rune(tld)) < 2 || strings.IndexFunc(tld, unicode.IsDigit) >= 0 {
+		return fmt.Errorf("'.%s' is not a domain ending", tld)
+	}
+	return nil
+}
+
+// Address tidies a postal address: line breaks become commas, runs of
+// spaces collapse, and the parts are joined with ", " once empty ones and
+// stray commas are dropped. Addresses are free-form, so there is nothing
+// to warn about.
+func Address(value string) string {
+	value = strings.NewReplacer("\r\n", ",", "\n", ",", "\r", ",").Replace(value)
+	var parts []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.Join(strings.Fields(part), " "); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
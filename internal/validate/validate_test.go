@@ -0,0 +1,89 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmail(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "", want: ""},
+		{in: "  Bob@Example.COM ", want: "Bob@example.com"},
+		{in: "first.last+crm@mail.example.co.uk", want: "first.last+crm@mail.example.co.uk"},
+		{in: "jörg@Bücher.de", want: "jörg@bücher.de"},
+		{in: "info@xn--p1ai.xn--p1ai", want: "info@xn--p1ai.xn--p1ai"},
+
+		{in: "bob", wantErr: true},
+		{in: "@example.com", wantErr: true},
+		{in: "bob@", wantErr: true},
+		{in: "bob@work@example.com", wantErr: true},
+		{in: strings.Repeat("a", 65) + "@example.com", wantErr: true},
+		{in: ".bob@example.com", wantErr: true},
+		{in: "bob.@example.com", wantErr: true},
+		{in: "bo..b@example.com", wantErr: true},
+		{in: "bob smith@example.com", wantErr: true},
+		{in: "bob@localhost", wantErr: true},
+		{in: "bob@example..com", wantErr: true},
+		{in: "bob@-example.com", wantErr: true},
+		{in: "bob@exa_mple.com", wantErr: true},
+		{in: "bob@example.c", wantErr: true},
+		{in: "bob@example.c0m", wantErr: true},
+		{in: "bob@" + strings.Repeat("a", 64) + ".com", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Email(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Email(%q) = %q, want an error", tt.in, got)
+			} else if got != strings.TrimSpace(tt.in) {
+				t.Errorf("Email(%q) returned %q with its error, want the trimmed input", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Email(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Email(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAddress(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"", ""},
+		{"1 Main St", "1 Main St"},
+		{"  1  Main St \r\n\n Springfield ,, IL  ", "1 Main St, Springfield, IL"},
+		{", ,\n", ""},
+	}
+	for _, tt := range tests {
+		if got := Address(tt.in); got != tt.want {
+			t.Errorf("Address(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCheckContact(t *testing.T) {
+	got, warnings := CheckContact(Contact{Phone: "(555) 555-5555", Email: "Bob@Example.com", Address: "1 Main St\nSpringfield"}, "US")
+	want := Contact{Phone: "+15555555555", Email: "Bob@example.com", Address: "1 Main St, Springfield"}
+	if got != want || len(warnings) != 0 {
+		t.Errorf("CheckContact = %+v, %v; want %+v and no warnings", got, warnings, want)
+	}
+
+	got, warnings = CheckContact(Contact{Phone: " 555-5555 ", Email: " bob@ ", Address: " Springfield "}, "US")
+	want = Contact{Phone: "555-5555", Email: "bob@", Address: "Springfield"}
+	if got != want {
+		t.Errorf("CheckContact kept %+v, want the trimmed input %+v", got, want)
+	}
+	if len(warnings) != 2 || warnings[0].Field != "phone" || warnings[1].Field != "email" {
+		t.Fatalf("warnings = %v, want one for the phone and one for the email", warnings)
+	}
+	if s := warnings[1].String(); s != "email 'bob@': missing the domain after '@'" {
+		t.Errorf("warning reads %q", s)
+	}
+}